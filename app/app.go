@@ -134,14 +134,15 @@ func (mApp *DrepApp) Run() error {
 
 // action used to init and run each services
 func (mApp *DrepApp) action(ctx *cli.Context) error {
+	var started []Service
 	defer func() {
 		if err := recover(); err != nil {
 			debug.PrintStack()
 			fmt.Println("app action err", err)
 		}
-		length := len(mApp.Context.Services)
-		for i := length; i > 0; i-- {
-			err := mApp.Context.Services[i-1].Stop(mApp.Context)
+		//only the started services are stopped
+		for i := len(started); i > 0; i-- {
+			err := started[i-1].Stop(mApp.Context)
 			if err != nil {
 				return
 			}
@@ -190,13 +191,21 @@ func (mApp *DrepApp) action(ctx *cli.Context) error {
 		}
 	}
 
+	commander := mApp.Context.commandService(ctx.Command.Name)
 	for _, service := range mApp.Context.Services {
+		if _, online := service.(NetworkService); online && commander != nil {
+			continue
+		}
 		err := service.Start(mApp.Context)
 		if err != nil {
 			return err
 		}
+		started = append(started, service)
 		fmt.Println(service.Name(), "starting ok")
 	}
+	if commander != nil {
+		return commander.RunCommand(mApp.Context)
+	}
 	exit := make(chan struct{})
 	exitSignal(exit)
 	select {
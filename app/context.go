@@ -182,6 +182,26 @@ func (econtext *ExecuteContext) AggerateFlags() ([]cli.Command, []cli.Flag) {
 	return allCommands, allFlags
 }
 
+// commandService returns the CommandService running the command, nil if no service runs it offline
+func (econtext *ExecuteContext) commandService(command string) CommandService {
+	if command == "" {
+		return nil
+	}
+	for _, service := range econtext.Services {
+		commander, ok := service.(CommandService)
+		if !ok {
+			continue
+		}
+		commands, _ := service.CommandFlags()
+		for _, cmd := range commands {
+			if cmd.Name == command {
+				return commander
+			}
+		}
+	}
+	return nil
+}
+
 // GetApis aggregate interface functions for each service to provide for use by RPC services
 func (econtext *ExecuteContext) GetApis() []API {
 	apis := []API{}
@@ -28,3 +28,17 @@ type OrService interface {
 	Service
 	SelectService() Service
 }
+
+// CommandService runs the commands it returns from CommandFlags offline: the services which are not a
+// NetworkService are started, the command runs and the app exits
+type CommandService interface {
+	Service
+	RunCommand(executeContext *ExecuteContext) error
+}
+
+// NetworkService is a service which connects to peers, serves clients or produces blocks, it is not started
+// while a CommandService runs a command
+type NetworkService interface {
+	Service
+	Online()
+}
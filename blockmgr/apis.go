@@ -1,13 +1,17 @@
 package blockmgr
 
 import (
+	"context"
 	"math/big"
 
+	"github.com/drep-project/DREP-Chain/blockmgr/txpool"
 	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/event"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/database"
 	"github.com/drep-project/DREP-Chain/types"
 	"github.com/drep-project/binary"
+	"github.com/drep-project/rpc"
 )
 
 /*
@@ -133,3 +137,92 @@ func (blockMgrApi *BlockMgrAPI) GetTxInPool(hash string) (*types.Transaction, er
 	}
 	return tx, nil
 }
+
+/*
+ name: syncProgress
+ usage: Get the progress of the block synchronization, including the state download of fast sync
+ params:
+
+ return: sync mode, current phase (empty if not syncing, "state", "blocks" or "full"), block heights and downloaded state nodes
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"blockmgr_syncProgress","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"mode":"fast","phase":"state","startingBlock":0,"currentBlock":0,"highestBlock":10064,"pivotBlock":10000,"pulledStates":5120,"pendingStates":1930,"stateBytes":1048576}}
+*/
+func (blockMgrApi *BlockMgrAPI) SyncProgress() SyncProgress {
+	return blockMgrApi.blockMgr.Progress()
+}
+
+/*
+ name: syncing
+ usage: Tell whether the node is caught up with its peers
+ params:
+
+ return: false if the node is in sync, otherwise the height the synchronization started from, the current height, the highest height known from the peers and the blocks imported per second
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"blockmgr_syncing","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"startingBlock":1200,"currentBlock":5310,"highestBlock":10064,"downloadRate":137.5}}
+*/
+func (blockMgrApi *BlockMgrAPI) Syncing() interface{} {
+	status := blockMgrApi.blockMgr.SyncStatus()
+	if status == nil {
+		return false
+	}
+	return status
+}
+
+//SyncState is the notification of the syncState subscription
+type SyncState struct {
+	Syncing bool        `json:"syncing"`
+	Status  *SyncStatus `json:"status,omitempty"`
+}
+
+/*
+ name: syncState
+ usage: Subscribe to the synchronization state through websocket, a notification is sent each time a synchronization starts or stops
+ params:
+	1. "syncState", the name of the subscription passed to blockmgr_subscribe
+ return: subscription id, then notifications with the syncing flag and the same status as blockmgr_syncing
+ example: wscat -c ws://localhost:10084 -x '{"jsonrpc":"2.0","method":"blockmgr_subscribe","params":["syncState"], "id": 3}'
+ response:
+	{"jsonrpc":"2.0","id":3,"result":"0x9ce59a13059e417087c02d3236a0b1cc"}
+	{"jsonrpc":"2.0","method":"blockmgr_subscription","params":{"subscription":"0x9ce59a13059e417087c02d3236a0b1cc","result":{"syncing":true,"status":{"startingBlock":1200,"currentBlock":1200,"highestBlock":10064,"downloadRate":0}}}}
+*/
+func (blockMgrApi *BlockMgrAPI) SyncState(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	events := make(chan event.SyncBlockEvent, 16)
+	sub := blockMgrApi.blockMgr.SubscribeSyncBlockEvent(events)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-events:
+				status := blockMgrApi.blockMgr.SyncStatus()
+				notifier.Notify(rpcSub.ID, &SyncState{Syncing: status != nil, Status: status})
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+/*
+ name: poolStats
+ usage: Get how many transactions the pool refused as underpriced or for being full, and how many it evicted to stay within its limits
+ params:
+
+ return: rejected and evicted transaction counts
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"blockmgr_poolStats","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"rejected":12,"evicted":40}}
+*/
+func (blockMgrApi *BlockMgrAPI) PoolStats() txpool.PoolStats {
+	return blockMgrApi.blockMgr.transactionPool.Stats()
+}
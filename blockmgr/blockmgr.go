@@ -1,10 +1,12 @@
 package blockmgr
 
 import (
+	"math"
 	"math/big"
 	"math/rand"
 	"path"
 	"sync"
+	"time"
 
 	chainStore "github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common/trie"
@@ -23,8 +25,6 @@ import (
 	p2pService "github.com/drep-project/DREP-Chain/network/service"
 	"github.com/drep-project/DREP-Chain/types"
 
-	"time"
-
 	rpc2 "github.com/drep-project/DREP-Chain/pkgs/rpc"
 )
 
@@ -40,6 +40,7 @@ var (
 	DefaultChainConfig = &BlockMgrConfig{
 		GasPrice:    DefaultOracleConfig,
 		JournalFile: "txpool/txs",
+		TxPool:      txpool.DefaultTxPoolConfig,
 	}
 	span = uint64(params.MaxGasLimit / 360)
 	_    = IBlockMgr((*BlockMgr)(nil)) //compile check
@@ -104,22 +105,41 @@ type BlockMgr struct {
 
 	//Events related to sync blocks
 	syncBlockEvent event.Feed
-	syncMut        sync.Mutex
 
 	//Receive the bulk hash group from the remote
 	headerHashCh chan []*syncHeaderHash
 
 	//The block is received from the far end
-	blocksCh chan []*types.Block
+	blocksCh chan *blockPack
+
+	//State trie nodes received from the far end during fast sync
+	nodeDataCh chan *nodeDataPack
+
+	//Id of the last node data req sent
+	nodeDataReqId uint64
+
+	//Node data reqs of the peers being served
+	nodeDataReqs *reqLimiter
 
-	//List of all tasks that need to be synchronized
-	allTasks *heightSortedMap
+	//Announced transactions requested from the peers
+	txFetcher *txFetcher
 
-	//The list of tasks being synchronized will be reissued if the corresponding block does not arrive
-	pendingSyncTasks sync.Map //map[*time.Timer]map[crypto.Hash]uint64
-	taskTxsCh        chan tasksTxsSync
-	syncTimerCh      chan *time.Timer
-	state            event.EventType
+	//Tx announcements and pooled txs reqs of the peers being handled
+	txReqs *reqLimiter
+
+	//Hashes of the broadcast transactions waiting to be announced
+	txAnnouncer *txAnnouncer
+
+	//Whether the next synchronization downloads the state at a pivot block instead of executing all blocks
+	fastSync     int32
+	progressLock sync.RWMutex
+	progress     SyncProgress
+
+	//Fast syncs failed in a row, fast sync is given up for full sync after maxFastSyncFailures
+	fastSyncFailures int
+
+	taskTxsCh chan tasksTxsSync
+	state     event.EventType
 
 	//All peers that communicate with this module
 	//peersInfo map[string]types.PeerInfoInterface
@@ -143,6 +163,7 @@ func getPeersCount(peerInfos sync.Map) int {
 type syncHeaderHash struct {
 	headerHash *crypto.Hash
 	height     uint64
+	stateRoot  []byte
 }
 
 // Name return package name
@@ -157,7 +178,7 @@ func (blockMgr *BlockMgr) Api() []app.API {
 
 // CommandFlags return an array interface of flag
 func (blockMgr *BlockMgr) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{}
+	return nil, []cli.Flag{SyncModeFlag}
 }
 
 // NewBlockMgr init all need of block management
@@ -168,22 +189,24 @@ func NewBlockMgr(config *BlockMgrConfig, homeDir string, cs chain.ChainServiceIn
 	blockMgr.P2pServer = p2pservice
 
 	blockMgr.headerHashCh = make(chan []*syncHeaderHash)
-	blockMgr.blocksCh = make(chan []*types.Block)
-	blockMgr.allTasks = newHeightSortedMap()
-	//blockMgr.pendingSyncTasks = make(map[*time.Timer]map[crypto.Hash]uint64)
+	blockMgr.blocksCh = make(chan *blockPack)
+	blockMgr.nodeDataCh = make(chan *nodeDataPack)
+	blockMgr.nodeDataReqs = newReqLimiter(maxNodeDataReqInFlight)
+	blockMgr.txFetcher = newTxFetcher()
+	blockMgr.txReqs = newReqLimiter(maxTxReqInFlight)
+	blockMgr.txAnnouncer = newTxAnnouncer()
 	blockMgr.state = event.StopSyncBlock
-	blockMgr.syncTimerCh = make(chan *time.Timer, pendingTimerCount)
 	//blockMgr.peersInfo = sync.Map{} //make(map[string]types.PeerInfoInterface)
 	blockMgr.newPeerCh = make(chan *types.PeerInfo, maxLivePeer)
 	blockMgr.taskTxsCh = make(chan tasksTxsSync, maxLivePeer)
 
 	blockMgr.gpo = NewOracle(blockMgr.ChainService, blockMgr.Config.GasPrice)
 
-	store, err := chainStore.TrieStoreFromStore(blockMgr.DatabaseService.LevelDb(), trie.EmptyRoot[:])
+	store, err := chainStore.TrieStoreFromDatabase(blockMgr.DatabaseService.LevelDb(), blockMgr.DatabaseService.StateDb(), trie.EmptyRoot[:])
 	if err != nil {
 		return nil
 	}
-	blockMgr.transactionPool = txpool.NewTransactionPool(store, path.Join(homeDir, blockMgr.Config.JournalFile))
+	blockMgr.transactionPool = txpool.NewTransactionPool(blockMgr.Config.TxPool, store, path.Join(homeDir, blockMgr.Config.JournalFile))
 
 	blockMgr.P2pServer.AddProtocols([]p2p.Protocol{
 		p2p.Protocol{
@@ -212,6 +235,18 @@ func NewBlockMgr(config *BlockMgrConfig, homeDir string, cs chain.ChainServiceIn
 			},
 			Public: true,
 		},
+		app.API{
+			Namespace: "txpool",
+			Version:   "1.0",
+			Service:   &TxPoolAPI{blockMgr: blockMgr},
+			Public:    true,
+		},
+		app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   &TxPoolAdminAPI{blockMgr: blockMgr},
+			Public:    false,
+		},
 	}
 	return blockMgr
 }
@@ -219,9 +254,12 @@ func NewBlockMgr(config *BlockMgrConfig, homeDir string, cs chain.ChainServiceIn
 // Init function init block from initial config.
 func (blockMgr *BlockMgr) Init(executeContext *app.ExecuteContext) error {
 	blockMgr.headerHashCh = make(chan []*syncHeaderHash)
-	blockMgr.blocksCh = make(chan []*types.Block)
-	blockMgr.allTasks = newHeightSortedMap()
-	blockMgr.syncTimerCh = make(chan *time.Timer, 1)
+	blockMgr.blocksCh = make(chan *blockPack)
+	blockMgr.nodeDataCh = make(chan *nodeDataPack)
+	blockMgr.nodeDataReqs = newReqLimiter(maxNodeDataReqInFlight)
+	blockMgr.txFetcher = newTxFetcher()
+	blockMgr.txReqs = newReqLimiter(maxTxReqInFlight)
+	blockMgr.txAnnouncer = newTxAnnouncer()
 	blockMgr.state = event.StopSyncBlock
 	//blockMgr.peersInfo = make(map[string]types.PeerInfoInterface)
 	blockMgr.newPeerCh = make(chan *types.PeerInfo, maxLivePeer)
@@ -229,11 +267,19 @@ func (blockMgr *BlockMgr) Init(executeContext *app.ExecuteContext) error {
 
 	blockMgr.gpo = NewOracle(blockMgr.ChainService, blockMgr.Config.GasPrice)
 
-	store, err := chainStore.TrieStoreFromStore(blockMgr.DatabaseService.LevelDb(), trie.EmptyRoot[:])
+	if executeContext.Cli != nil && executeContext.Cli.GlobalIsSet(SyncModeFlag.Name) {
+		blockMgr.Config.SyncMode = executeContext.Cli.GlobalString(SyncModeFlag.Name)
+	}
+	err := blockMgr.initSyncMode()
 	if err != nil {
 		return err
 	}
-	blockMgr.transactionPool = txpool.NewTransactionPool(store, path.Join(executeContext.CommonConfig.HomeDir, blockMgr.Config.JournalFile))
+
+	store, err := chainStore.TrieStoreFromDatabase(blockMgr.DatabaseService.LevelDb(), blockMgr.DatabaseService.StateDb(), trie.EmptyRoot[:])
+	if err != nil {
+		return err
+	}
+	blockMgr.transactionPool = txpool.NewTransactionPool(blockMgr.Config.TxPool, store, path.Join(executeContext.CommonConfig.HomeDir, blockMgr.Config.JournalFile))
 	blockMgr.chainStore = &chainStore.ChainStore{blockMgr.DatabaseService.LevelDb()}
 	blockMgr.P2pServer.AddProtocols([]p2p.Protocol{
 		p2p.Protocol{
@@ -266,18 +312,36 @@ func (blockMgr *BlockMgr) Init(executeContext *app.ExecuteContext) error {
 			},
 			Public: true,
 		},
+		app.API{
+			Namespace: "txpool",
+			Version:   "1.0",
+			Service:   &TxPoolAPI{blockMgr: blockMgr},
+			Public:    true,
+		},
+		app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   &TxPoolAdminAPI{blockMgr: blockMgr},
+			Public:    false,
+		},
 	}
 	return nil
 }
 
 // Start syn block and transactions.
 func (blockMgr *BlockMgr) Start(executeContext *app.ExecuteContext) error {
-	blockMgr.transactionPool.Start(blockMgr.ChainService.NewBlockFeed(), blockMgr.ChainService.BestChain().Tip().StateRoot)
+	blockMgr.transactionPool.Start(blockMgr.ChainService.NewBlockFeed(), blockMgr.ChainService.DetachBlockFeed(), func() []byte {
+		return blockMgr.ChainService.BestChain().Tip().StateRoot
+	})
 	go blockMgr.synchronise()
 	go blockMgr.syncTxs()
+	go blockMgr.announceTxs()
 	return nil
 }
 
+//Online marks the service as a network service, it syncs and relays blocks and txs with peers
+func (blockMgr *BlockMgr) Online() {}
+
 // Stop blockchain.
 func (blockMgr *BlockMgr) Stop(executeContext *app.ExecuteContext) error {
 	if blockMgr.quit != nil {
@@ -332,30 +396,57 @@ func (blockMgr *BlockMgr) BroadcastBlock(msgType int32, block *types.Block, isLo
 	})
 }
 
-// BroadcastTx broadcasts transaction until receive more than 2/3 of peers.
+// BroadcastTx sends the transaction to a square root subset of the peers which do not know it yet and announces
+// its hash to the others, they request the transaction if it is not in their pool. The hashes announced to a peer
+// are batched by announceTxs.
 func (blockMgr *BlockMgr) BroadcastTx(msgType int32, tx *types.Transaction, isLocal bool) {
 	go func() {
-
+		var peers []types.PeerInfoInterface
 		blockMgr.peersInfo.Range(func(key, value interface{}) bool {
 			peer := value.(types.PeerInfoInterface)
-			b := peer.KnownTx(tx)
-			if !b {
-				if !isLocal {
-					//Receive a message from the remote end and only broadcast it to 2/3 of peers
-					rd := rand.Intn(broadcastRatio)
-					if rd > 2 {
-						return false
-					}
-				}
+			if !peer.KnownTx(tx) {
+				peers = append(peers, peer)
+			}
+			return true
+		})
 
+		rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+		direct := int(math.Sqrt(float64(len(peers))))
+		hash := tx.TxHash()
+		for i, peer := range peers {
+			if i < direct {
 				peer.MarkTx(tx)
 				blockMgr.P2pServer.Send(peer.GetMsgRW(), uint64(msgType), []*types.Transaction{tx})
+			} else {
+				peer.MarkTxHash(hash)
+				if hashes := blockMgr.txAnnouncer.queue(peer, hash); hashes != nil {
+					blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeTxHashes, &types.TxHashes{Hashes: hashes})
+				}
 			}
-			return true
-		})
+		}
 	}()
 }
 
+// announceTxs sends the batched hashes of the broadcast transactions every txAnnounceInterval.
+func (blockMgr *BlockMgr) announceTxs() {
+	ticker := time.NewTicker(time.Millisecond * txAnnounceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			blockMgr.flushTxAnnounces()
+		case <-blockMgr.quit:
+			return
+		}
+	}
+}
+
+func (blockMgr *BlockMgr) flushTxAnnounces() {
+	for peer, hashes := range blockMgr.txAnnouncer.flush() {
+		blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeTxHashes, &types.TxHashes{Hashes: hashes})
+	}
+}
+
 // GetPoolTransactions gets all the trades in the current pool.
 func (blockMgr *BlockMgr) GetPoolTransactions(addr *crypto.CommonAddress) []types.Transactions {
 	return blockMgr.transactionPool.GetTransactions(addr)
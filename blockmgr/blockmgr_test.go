@@ -1,3 +1,8 @@
+//generatorChain builds blocks through NewChainService and database.Database, which this tree does not have,
+//the file is only built with the legacy tag until the helper is ported. The sync tests use linkedBlocks.
+
+// +build legacy
+
 package blockmgr
 
 import (
@@ -0,0 +1,274 @@
+package blockmgr
+
+import (
+	"sort"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+//blockPack is a block response together with the peer which sent it
+type blockPack struct {
+	peer   types.PeerInfoInterface
+	blocks []*types.Block
+}
+
+//bodyRequest is a batch of blocks of consecutive heights requested from one peer
+type bodyRequest struct {
+	peer     types.PeerInfoInterface
+	count    int
+	pending  map[uint64]struct{} //heights not delivered yet
+	sent     time.Time
+	deadline time.Time
+}
+
+//fetchResult is a downloaded block and the peer which sent it
+type fetchResult struct {
+	block *types.Block
+	peer  types.PeerInfoInterface
+}
+
+//bodyPeer is what the fetcher learned about a peer during one synchronization
+type bodyPeer struct {
+	throughput float64 //blocks per second, 0 until a request of the peer completes
+	failures   int     //requests in a row the peer did not answer in time
+	invalid    bool    //the peer sent an invalid block, it is neither asked nor listened to anymore
+}
+
+//bodyFetcher downloads the blocks of a verified header skeleton. The requests are spread over all the peers high
+//enough to serve them and sized by the throughput measured for each peer, a request not answered in time is given
+//to another peer. The blocks are imported in height order whatever the order they arrive in.
+type bodyFetcher struct {
+	blockMgr *BlockMgr
+	skeleton map[uint64]*syncHeaderHash //headers whose block is not imported yet
+	tasks    *heightSortedMap           //headers whose block is neither requested nor downloaded
+	active   map[types.PeerInfoInterface]*bodyRequest
+	peers    map[types.PeerInfoInterface]*bodyPeer
+	results  map[uint64]*fetchResult //downloaded blocks waiting for their parent to be imported
+	next     uint64                  //height of the next block to import
+}
+
+func newBodyFetcher(blockMgr *BlockMgr, from uint64) *bodyFetcher {
+	return &bodyFetcher{
+		blockMgr: blockMgr,
+		skeleton: make(map[uint64]*syncHeaderHash),
+		tasks:    newHeightSortedMap(),
+		active:   make(map[types.PeerInfoInterface]*bodyRequest),
+		peers:    make(map[types.PeerInfoInterface]*bodyPeer),
+		results:  make(map[uint64]*fetchResult),
+		next:     from,
+	}
+}
+
+//enqueue schedules the blocks of verified headers
+func (fetcher *bodyFetcher) enqueue(headers []*syncHeaderHash) {
+	for _, header := range headers {
+		fetcher.skeleton[header.height] = header
+		fetcher.tasks.Put(header)
+	}
+}
+
+//queued returns the number of headers whose block is not imported yet
+func (fetcher *bodyFetcher) queued() int {
+	return len(fetcher.skeleton)
+}
+
+//done reports whether all the enqueued blocks are imported
+func (fetcher *bodyFetcher) done() bool {
+	return len(fetcher.skeleton) == 0
+}
+
+func (fetcher *bodyFetcher) peer(peer types.PeerInfoInterface) *bodyPeer {
+	stats, ok := fetcher.peers[peer]
+	if !ok {
+		stats = &bodyPeer{}
+		fetcher.peers[peer] = stats
+	}
+	return stats
+}
+
+//idlePeers returns the peers without a request in flight which did not fail too often, the fastest first
+func (fetcher *bodyFetcher) idlePeers() []types.PeerInfoInterface {
+	var idles []types.PeerInfoInterface
+	fetcher.blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+		pi := value.(types.PeerInfoInterface)
+		stats := fetcher.peer(pi)
+		if _, ok := fetcher.active[pi]; !ok && !stats.invalid && stats.failures < maxBodyReqFailures {
+			idles = append(idles, pi)
+		}
+		return true
+	})
+	sort.Slice(idles, func(i, j int) bool {
+		ti, tj := fetcher.peers[idles[i]].throughput, fetcher.peers[idles[j]].throughput
+		if ti != tj {
+			return ti > tj
+		}
+		return idles[i].AverageRtt() < idles[j].AverageRtt()
+	})
+	return idles
+}
+
+//batchSize returns the number of blocks the peer should deliver in bodyReqTargetTime
+func (fetcher *bodyFetcher) batchSize(peer types.PeerInfoInterface) int {
+	throughput := fetcher.peer(peer).throughput
+	if throughput == 0 {
+		return maxBlockCountReq
+	}
+	size := int(throughput * bodyReqTargetTime)
+	if size < 1 {
+		return 1
+	}
+	if size > maxBlockBatchReq {
+		return maxBlockBatchReq
+	}
+	return size
+}
+
+//assign requests the lowest pending blocks from the idle peers, a peer only gets blocks up to its height
+func (fetcher *bodyFetcher) assign(now time.Time) error {
+	for _, peer := range fetcher.idlePeers() {
+		if fetcher.tasks.Len() == 0 {
+			return nil
+		}
+		headers := fetcher.tasks.PopContinuous(fetcher.batchSize(peer), peer.GetHeight())
+		if len(headers) == 0 {
+			continue
+		}
+
+		first, last := headers[0], headers[len(headers)-1]
+		req := &types.BlockReq{BlockHashs: []crypto.Hash{*first.headerHash, *last.headerHash}}
+		peer.SetReqTime(now)
+		err := fetcher.blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeBlockReq, req)
+		if err != nil {
+			log.WithField("ip", peer.GetAddr()).WithField("Reason", err).Warn("req block body")
+			fetcher.peer(peer).failures = maxBodyReqFailures
+			for _, header := range headers {
+				fetcher.tasks.Put(header)
+			}
+			continue
+		}
+
+		pending := make(map[uint64]struct{}, len(headers))
+		for _, header := range headers {
+			pending[header.height] = struct{}{}
+		}
+		fetcher.active[peer] = &bodyRequest{
+			peer:     peer,
+			count:    len(headers),
+			pending:  pending,
+			sent:     now,
+			deadline: now.Add(time.Second * bodyReqTimeout),
+		}
+		log.WithField("from", first.height).WithField("to", last.height).WithField("destIp", peer.GetAddr()).Trace("req block body")
+	}
+
+	if fetcher.tasks.Len() > 0 && len(fetcher.active) == 0 {
+		return ErrNoBodyPeer
+	}
+	return nil
+}
+
+//deliver keeps the blocks matching the skeleton whoever sent them, a late answer to an expired request is still
+//useful. The request of the sender completes once all its blocks arrived.
+func (fetcher *bodyFetcher) deliver(pack *blockPack, now time.Time) {
+	if fetcher.peer(pack.peer).invalid {
+		return
+	}
+	for _, block := range pack.blocks {
+		height := block.Header.Height
+		header, ok := fetcher.skeleton[height]
+		if !ok || fetcher.results[height] != nil || !header.headerHash.IsEqual(block.Header.Hash()) {
+			continue
+		}
+		fetcher.results[height] = &fetchResult{block: block, peer: pack.peer}
+		fetcher.tasks.Remove(height)
+		for _, req := range fetcher.active {
+			delete(req.pending, height)
+		}
+	}
+
+	for peer, req := range fetcher.active {
+		if len(req.pending) > 0 {
+			continue
+		}
+		delete(fetcher.active, peer)
+		if peer != pack.peer {
+			//the blocks came from another peer, the request tells nothing about this one
+			continue
+		}
+		stats := fetcher.peer(peer)
+		stats.failures = 0
+		measured := float64(req.count) / now.Sub(req.sent).Seconds()
+		if stats.throughput == 0 {
+			stats.throughput = measured
+		} else {
+			stats.throughput = stats.throughput*0.7 + measured*0.3
+		}
+		log.WithField("ip", peer.GetAddr()).WithField("throughput", stats.throughput).Trace("block body rsp")
+	}
+}
+
+//expire gives the missing blocks of the requests past their deadline back to the queue, the throughput of the
+//peer is halved so that it gets smaller batches
+func (fetcher *bodyFetcher) expire(now time.Time) {
+	for peer, req := range fetcher.active {
+		if now.Before(req.deadline) {
+			continue
+		}
+		delete(fetcher.active, peer)
+		for height := range req.pending {
+			fetcher.tasks.Put(fetcher.skeleton[height])
+		}
+		stats := fetcher.peer(peer)
+		stats.failures++
+		stats.throughput /= 2
+		fetcher.blockMgr.penalize(peer, p2p.FaultTimeout)
+		log.WithField("ip", peer.GetAddr()).WithField("missing", len(req.pending)).WithField("failures", stats.failures).Warn("req block body time out")
+	}
+}
+
+//exclude leaves a peer which sent an invalid block out of the synchronization, the blocks it still has to deliver
+//and the ones it delivered which are not imported yet are given back to the queue
+func (fetcher *bodyFetcher) exclude(peer types.PeerInfoInterface) {
+	fetcher.peer(peer).invalid = true
+	for height, result := range fetcher.results {
+		if result.peer == peer {
+			delete(fetcher.results, height)
+			fetcher.tasks.Put(fetcher.skeleton[height])
+		}
+	}
+	if req, ok := fetcher.active[peer]; ok {
+		delete(fetcher.active, peer)
+		for height := range req.pending {
+			fetcher.tasks.Put(fetcher.skeleton[height])
+		}
+	}
+	fetcher.blockMgr.penalize(peer, p2p.FaultInvalidBlock)
+}
+
+//importBlocks processes the downloaded blocks which directly follow the last imported one. The header of a block
+//is part of the verified skeleton so an invalid block is blamed on the peer which sent its body, the block is
+//dropped and requested again from another peer.
+func (fetcher *bodyFetcher) importBlocks() error {
+	for {
+		result, ok := fetcher.results[fetcher.next]
+		if !ok {
+			return nil
+		}
+		_, _, err := fetcher.blockMgr.ChainService.ProcessBlock(result.block)
+		if err != nil && err != chain.ErrBlockExsist && err != chain.ErrOrphanBlockExsist {
+			log.WithField("Reason", err).WithField("height", fetcher.next).WithField("ip", result.peer.GetAddr()).Error("deal sync block")
+			if _, invalid := err.(*chain.InvalidBlockError); !invalid {
+				return err
+			}
+			fetcher.exclude(result.peer)
+			return nil
+		}
+		delete(fetcher.results, fetcher.next)
+		delete(fetcher.skeleton, fetcher.next)
+		fetcher.next++
+	}
+}
@@ -0,0 +1,208 @@
+package blockmgr
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+//skeletonOf returns the verified headers of the blocks
+func skeletonOf(blks []*types.Block) []*syncHeaderHash {
+	headers := make([]*syncHeaderHash, 0, len(blks))
+	for _, blk := range blks {
+		headers = append(headers, &syncHeaderHash{headerHash: blk.Header.Hash(), height: blk.Header.Height, stateRoot: blk.Header.StateRoot})
+	}
+	return headers
+}
+
+//invalidBody returns a block with the header of blk and a body which does not match its tx root
+func invalidBody(blk *types.Block) *types.Block {
+	tx := types.NewTransaction(crypto.CommonAddress{}, big.NewInt(1), big.NewInt(1), big.NewInt(1), 0)
+	return &types.Block{
+		Header: blk.Header,
+		Data:   &types.BlockData{TxCount: 1, TxList: []*types.Transaction{tx}},
+	}
+}
+
+func TestBodyFetcherAssign(t *testing.T) {
+	blks := linkedBlocks(20)
+	bm, p2pServer := newTestBlockMgr(newChainServiceMock(blks[:1]))
+	low, high := newPeerInfoMock(1, blks[:5]), newPeerInfoMock(2, blks)
+	low.silent, high.silent = true, true
+	bm.peersInfo.Store(low.GetAddr(), low)
+
+	fetcher := newBodyFetcher(bm, 1)
+	fetcher.enqueue(skeletonOf(blks[1:]))
+	if err := fetcher.assign(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(fetcher.active[low].pending) != 4 {
+		t.Fatalf("expect the blocks up to the height of the peer requested, got %d", len(fetcher.active[low].pending))
+	}
+	for height := range fetcher.active[low].pending {
+		if height > low.GetHeight() {
+			t.Fatalf("expect no block above the height of the peer, got %d", height)
+		}
+	}
+
+	bm.peersInfo.Store(high.GetAddr(), high)
+	if err := fetcher.assign(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(fetcher.active) != 2 || p2pServer.sentCount(types.MsgTypeBlockReq) != 2 {
+		t.Fatalf("expect a req to each peer, got %d", len(fetcher.active))
+	}
+	if len(fetcher.active[high].pending) != maxBlockCountReq {
+		t.Fatalf("expect %d blocks requested from an unmeasured peer, got %d", maxBlockCountReq, len(fetcher.active[high].pending))
+	}
+
+	//a busy peer gets no other request
+	if err := fetcher.assign(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if p2pServer.sentCount(types.MsgTypeBlockReq) != 2 {
+		t.Fatal("expect no req to a peer with a req in flight")
+	}
+}
+
+func TestBodyFetcherImportInOrder(t *testing.T) {
+	blks := linkedBlocks(10)
+	cs := newChainServiceMock(blks[:1])
+	bm, _ := newTestBlockMgr(cs)
+	peer := newPeerInfoMock(1, blks)
+
+	fetcher := newBodyFetcher(bm, 1)
+	fetcher.enqueue(skeletonOf(blks[1:]))
+	fetcher.deliver(&blockPack{peer: peer, blocks: blks[6:]}, time.Now())
+	if err := fetcher.importBlocks(); err != nil {
+		t.Fatal(err)
+	}
+	if cs.BestChain().Height() != 0 {
+		t.Fatalf("expect no block imported before its parent, got %d", cs.BestChain().Height())
+	}
+
+	fetcher.deliver(&blockPack{peer: peer, blocks: blks[1:6]}, time.Now())
+	if err := fetcher.importBlocks(); err != nil {
+		t.Fatal(err)
+	}
+	if cs.BestChain().Height() != 10 || !fetcher.done() {
+		t.Fatalf("expect all the blocks imported, got %d", cs.BestChain().Height())
+	}
+}
+
+func TestBodyFetcherExpire(t *testing.T) {
+	blks := linkedBlocks(10)
+	bm, p2pServer := newTestBlockMgr(newChainServiceMock(blks[:1]))
+	peer := newPeerInfoMock(1, blks)
+	peer.silent = true
+	bm.peersInfo.Store(peer.GetAddr(), peer)
+
+	fetcher := newBodyFetcher(bm, 1)
+	fetcher.enqueue(skeletonOf(blks[1:]))
+	now := time.Now()
+	if err := fetcher.assign(now); err != nil {
+		t.Fatal(err)
+	}
+	fetcher.expire(now.Add(time.Second * bodyReqTimeout))
+	if len(fetcher.active) != 0 || fetcher.tasks.Len() != 10 {
+		t.Fatalf("expect the blocks of the expired req queued again, got %d", fetcher.tasks.Len())
+	}
+	if fetcher.peer(peer).failures != 1 {
+		t.Fatalf("expect a failure of the peer, got %d", fetcher.peer(peer).failures)
+	}
+	faults := p2pServer.faults(peer.GetPeer())
+	if len(faults) != 1 || faults[0] != p2p.FaultTimeout {
+		t.Fatalf("expect the peer penalized for the time out, got %v", faults)
+	}
+
+	//a peer failing too often is left out
+	fetcher.peer(peer).failures = maxBodyReqFailures
+	if err := fetcher.assign(now); err != ErrNoBodyPeer {
+		t.Fatalf("expect no peer left, got %v", err)
+	}
+}
+
+func TestBodyFetcherInvalidBody(t *testing.T) {
+	blks := linkedBlocks(10)
+	cs := newChainServiceMock(blks[:1])
+	bm, p2pServer := newTestBlockMgr(cs)
+	bad, good := newPeerInfoMock(1, blks), newPeerInfoMock(2, blks)
+	bad.silent, good.silent = true, true
+	bm.peersInfo.Store(bad.GetAddr(), bad)
+
+	fetcher := newBodyFetcher(bm, 1)
+	fetcher.enqueue(skeletonOf(blks[1:]))
+	if err := fetcher.assign(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	delivered := []*types.Block{blks[1], blks[2], invalidBody(blks[3]), blks[4]}
+	fetcher.deliver(&blockPack{peer: bad, blocks: delivered}, time.Now())
+	if err := fetcher.importBlocks(); err != nil {
+		t.Fatalf("expect the sync to go on after an invalid body, got %v", err)
+	}
+	if cs.BestChain().Height() != 2 {
+		t.Fatalf("expect the blocks below the invalid one imported, got %d", cs.BestChain().Height())
+	}
+	faults := p2pServer.faults(bad.GetPeer())
+	if len(faults) != 1 || faults[0] != p2p.FaultInvalidBlock {
+		t.Fatalf("expect the sender of the invalid block penalized, got %v", faults)
+	}
+	if fetcher.tasks.Get(3) == nil {
+		t.Fatal("expect the height of the invalid block queued again")
+	}
+	//the blocks the excluded peer delivered above the invalid one are not trusted either
+	if _, ok := fetcher.active[bad]; ok || fetcher.results[4] != nil || fetcher.tasks.Len() != 8 {
+		t.Fatalf("expect the blocks of the excluded peer queued again, got %d", fetcher.tasks.Len())
+	}
+
+	//the excluded peer is neither asked nor listened to
+	fetcher.deliver(&blockPack{peer: bad, blocks: blks[3:]}, time.Now())
+	if fetcher.results[3] != nil {
+		t.Fatal("expect the blocks of the excluded peer ignored")
+	}
+	if err := fetcher.assign(time.Now()); err != ErrNoBodyPeer {
+		t.Fatalf("expect the excluded peer not asked, got %v", err)
+	}
+
+	bm.peersInfo.Store(good.GetAddr(), good)
+	if err := fetcher.assign(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fetcher.active[good]; !ok {
+		t.Fatal("expect the blocks requested from another peer")
+	}
+	fetcher.deliver(&blockPack{peer: good, blocks: blks[3:]}, time.Now())
+	if err := fetcher.importBlocks(); err != nil {
+		t.Fatal(err)
+	}
+	if cs.BestChain().Height() != 10 || !fetcher.done() {
+		t.Fatalf("expect the chain synchronized to 10, got %d", cs.BestChain().Height())
+	}
+}
+
+func TestFetchBlocksInvalidBody(t *testing.T) {
+	blks := linkedBlocks(50)
+	cs := newChainServiceMock(blks[:4])
+	bm, p2pServer := newTestBlockMgr(cs)
+
+	bad, good := newPeerInfoMock(1, blks), newPeerInfoMock(2, blks)
+	for _, blk := range blks {
+		bad.bodies[blk.Header.Height] = invalidBody(blk)
+	}
+	bm.peersInfo.Store(bad.GetAddr(), bad)
+	bm.peersInfo.Store(good.GetAddr(), good)
+	if err := bm.fetchBlocks(good); err != nil {
+		t.Fatal(err)
+	}
+	if cs.BestChain().Height() != 50 {
+		t.Fatalf("expect the chain synchronized to 50, got %d", cs.BestChain().Height())
+	}
+	faults := p2pServer.faults(bad.GetPeer())
+	if len(faults) != 1 || faults[0] != p2p.FaultInvalidBlock {
+		t.Fatalf("expect the sender of the invalid blocks penalized once, got %v", faults)
+	}
+}
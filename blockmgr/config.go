@@ -1,9 +1,15 @@
 package blockmgr
 
+import "github.com/drep-project/DREP-Chain/blockmgr/txpool"
+
 // BlockMgrConfig defines gasprice & journal file type.
 type BlockMgrConfig struct {
 	GasPrice    OracleConfig `json:"gasprice"`
 	JournalFile string       `json:"journalFile"`
+	//limits of the transaction pool
+	TxPool txpool.TxPoolConfig `json:"txpool"`
+	//"full" executes every block from genesis, "fast" downloads the state at a recent pivot block first
+	SyncMode string `json:"syncMode,omitempty"`
 }
 
 // OracleConfig manages gas price of block.
@@ -39,4 +39,12 @@ var (
 	ErrNotSupportRenameAlias = errors.New("not suppport rename alias")
 	// ErrNoCommonAncesstor print error message.
 	ErrNoCommonAncesstor = errors.New("no common ancesstor")
+	// ErrSyncMode print error message.
+	ErrSyncMode = errors.New("unknown sync mode, expect full or fast")
+	// ErrUnexpectedHeaders print error message.
+	ErrUnexpectedHeaders = errors.New("unexpected headers")
+	// ErrStateSync print error message.
+	ErrStateSync = errors.New("state sync fail")
+	// ErrNoBodyPeer print error message.
+	ErrNoBodyPeer = errors.New("no peer to fetch block bodies from")
 )
@@ -0,0 +1,487 @@
+package blockmgr
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	//FullSync downloads and executes every block from genesis
+	FullSync = "full"
+	//FastSync downloads the state at a pivot block, only the blocks after the pivot are executed
+	FastSync = "fast"
+
+	fastSyncPivotGap     = 64              //Distance of the pivot block below the height of the peer
+	maxNodeDataCountReq  = 384             //The maximum number of trie nodes in one request
+	maxNodeDataRspSize   = 2 * 1024 * 1024 //A node data rsp is cut short once it reaches this size
+	maxStateSyncFailures = 5               //Rounds in a row without any useful node before the state sync gives up
+	maxStateSyncPeers    = 4               //The maximum number of peers the state is downloaded from
+	storageTrieDepth     = 64              //Depth of a storage trie root below its parent, prioritises the fetch order
+
+	maxFastSyncFailures    = 3 //Fast syncs failed in a row before the node falls back to full sync
+	maxNodeDataReqInFlight = 2 //Node data reqs of a peer served at the same time
+)
+
+const (
+	syncPhaseIdle   = ""
+	syncPhaseState  = "state"
+	syncPhaseBlocks = "blocks"
+	syncPhaseFull   = "full"
+)
+
+type nodeDataPack struct {
+	peer  types.PeerInfoInterface
+	reqId uint64
+	nodes [][]byte
+}
+
+//SyncProgress describes the progress of the block synchronization
+type SyncProgress struct {
+	Mode          string `json:"mode"`          //sync mode of the node, full or fast
+	Phase         string `json:"phase"`         //empty if not syncing, otherwise state, blocks or full
+	StartingBlock uint64 `json:"startingBlock"` //local height when the synchronization started
+	CurrentBlock  uint64 `json:"currentBlock"`  //current local height
+	HighestBlock  uint64 `json:"highestBlock"`  //height of the peer synchronized from
+	PivotBlock    uint64 `json:"pivotBlock"`    //block whose state is downloaded by fast sync
+	PulledStates  uint64 `json:"pulledStates"`  //trie nodes downloaded by fast sync
+	PendingStates uint64 `json:"pendingStates"` //trie nodes known to be still missing
+	StateBytes    uint64 `json:"stateBytes"`    //size of the downloaded trie nodes
+
+	started time.Time //when the synchronization started
+}
+
+//SyncStatus is what a client needs to know about a node which is not caught up with its peers
+type SyncStatus struct {
+	StartingBlock uint64  `json:"startingBlock"` //local height when the synchronization started
+	CurrentBlock  uint64  `json:"currentBlock"`  //current local height
+	HighestBlock  uint64  `json:"highestBlock"`  //highest height known from the peers
+	DownloadRate  float64 `json:"downloadRate"`  //blocks imported per second since the synchronization started
+}
+
+func (blockMgr *BlockMgr) initSyncMode() error {
+	switch blockMgr.Config.SyncMode {
+	case "", FullSync:
+		blockMgr.Config.SyncMode = FullSync
+	case FastSync:
+		//fast sync only makes sense for an empty chain, a node that already has blocks just executes the rest
+		if blockMgr.ChainService.BestChain().Height() == 0 {
+			atomic.StoreInt32(&blockMgr.fastSync, 1)
+		} else {
+			log.WithField("height", blockMgr.ChainService.BestChain().Height()).Info("chain not empty, fast sync disabled")
+		}
+	default:
+		return errors.Wrapf(ErrSyncMode, "%s", blockMgr.Config.SyncMode)
+	}
+	blockMgr.progress.Mode = blockMgr.Config.SyncMode
+	return nil
+}
+
+//Progress returns a copy of the synchronization progress
+func (blockMgr *BlockMgr) Progress() SyncProgress {
+	blockMgr.progressLock.RLock()
+	defer blockMgr.progressLock.RUnlock()
+	progress := blockMgr.progress
+	progress.CurrentBlock = blockMgr.ChainService.BestChain().Height()
+	return progress
+}
+
+//SyncStatus returns nil if the node is in sync, that is neither synchronizing nor behind the best peer
+func (blockMgr *BlockMgr) SyncStatus() *SyncStatus {
+	progress := blockMgr.Progress()
+	highest := progress.HighestBlock
+	if peer := blockMgr.GetBestPeerInfo(); peer != nil && peer.GetHeight() > highest {
+		highest = peer.GetHeight()
+	}
+	if progress.Phase == syncPhaseIdle {
+		if highest <= progress.CurrentBlock {
+			return nil
+		}
+		//behind the peers, the next round of synchronise starts from here
+		return &SyncStatus{
+			StartingBlock: progress.CurrentBlock,
+			CurrentBlock:  progress.CurrentBlock,
+			HighestBlock:  highest,
+		}
+	}
+
+	status := &SyncStatus{
+		StartingBlock: progress.StartingBlock,
+		CurrentBlock:  progress.CurrentBlock,
+		HighestBlock:  highest,
+	}
+	elapsed := time.Since(progress.started).Seconds()
+	if progress.CurrentBlock > progress.StartingBlock && elapsed > 0 {
+		status.DownloadRate = float64(progress.CurrentBlock-progress.StartingBlock) / elapsed
+	}
+	return status
+}
+
+func (blockMgr *BlockMgr) updateProgress(update func(progress *SyncProgress)) {
+	blockMgr.progressLock.Lock()
+	defer blockMgr.progressLock.Unlock()
+	update(&blockMgr.progress)
+}
+
+//fastSyncBlocks downloads the state of a pivot block below the height of the peer, then stores the blocks up to
+//the pivot without executing them and makes the pivot the tip of the chain. The blocks after the pivot are left
+//to the full synchronization.
+func (blockMgr *BlockMgr) fastSyncBlocks(peer types.PeerInfoInterface) error {
+	if atomic.LoadInt32(&blockMgr.fastSync) == 0 || peer.GetHeight() <= fastSyncPivotGap {
+		return nil
+	}
+	if blockMgr.state == event.StartSyncBlock {
+		log.Info("have fetch blocks")
+		return nil
+	}
+	blockMgr.state = event.StartSyncBlock
+	pivotHeight := peer.GetHeight() - fastSyncPivotGap
+	blockMgr.updateProgress(func(progress *SyncProgress) {
+		progress.Phase = syncPhaseState
+		progress.StartingBlock = blockMgr.ChainService.BestChain().Height()
+		progress.HighestBlock = peer.GetHeight()
+		progress.started = time.Now()
+		progress.PivotBlock = pivotHeight
+		progress.PulledStates = 0
+		progress.PendingStates = 0
+		progress.StateBytes = 0
+	})
+	//the subscribers read the progress when notified, so it is updated first
+	blockMgr.syncBlockEvent.Send(event.SyncBlockEvent{EventType: event.StartSyncBlock})
+	defer func() {
+		blockMgr.updateProgress(func(progress *SyncProgress) {
+			progress.Phase = syncPhaseIdle
+		})
+		blockMgr.state = event.StopSyncBlock
+		blockMgr.syncBlockEvent.Send(event.SyncBlockEvent{EventType: event.StopSyncBlock})
+	}()
+	blockMgr.clearSyncCh()
+
+	//1 The pivot header, its state root is the state to download
+	pivot, err := blockMgr.fetchHeaders(peer, pivotHeight, 1)
+	if err != nil {
+		return err
+	}
+	log.WithField("pivot", pivotHeight).WithField("hash", pivot[0].headerHash).WithField("ip", peer.GetAddr()).Info("fast sync start")
+
+	//2 The state trie at the pivot, from the peer and the other peers having the pivot
+	err = blockMgr.syncState(peer, pivotHeight, crypto.Bytes2Hash(pivot[0].stateRoot))
+	if err != nil {
+		return err
+	}
+
+	//3 The blocks up to the pivot, they are only checked against their parent
+	blockMgr.updateProgress(func(progress *SyncProgress) {
+		progress.Phase = syncPhaseBlocks
+	})
+	err = blockMgr.fetchBlocksWithoutState(peer, pivot[0])
+	if err != nil {
+		return err
+	}
+
+	//4 The block after the pivot, its consensus proof vouches for the pivot and the downloaded state
+	next, err := blockMgr.fetchHeaders(peer, pivotHeight+1, 1)
+	if err != nil {
+		return err
+	}
+	nextBlock, err := blockMgr.fetchBodies(peer, next)
+	if err != nil {
+		return err
+	}
+	err = blockMgr.ChainService.SetPivotBlock(pivot[0].headerHash, nextBlock[0])
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&blockMgr.fastSync, 0)
+	blockMgr.fastSyncFailures = 0
+	log.WithField("pivot", pivotHeight).Info("fast sync done, switch to full sync")
+	return nil
+}
+
+//fetchHeaders requests count headers from height, the response is checked to be a linked chain
+func (blockMgr *BlockMgr) fetchHeaders(peer types.PeerInfoInterface, from, count uint64) ([]*syncHeaderHash, error) {
+	err := blockMgr.requestHeaders(peer, from, count)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case headers := <-blockMgr.headerHashCh:
+		if len(headers) == 0 || headers[0].height != from || uint64(len(headers)) > count {
+			return nil, errors.Wrapf(ErrUnexpectedHeaders, "req from %d count %d", from, count)
+		}
+		return headers, nil
+	case <-time.After(time.Second * maxNetworkTimeout):
+		return nil, ErrGetHeaderHashTimeout
+	}
+}
+
+//fetchBlocksWithoutState downloads the blocks after the local tip up to the pivot in batches and stores them in order
+func (blockMgr *BlockMgr) fetchBlocksWithoutState(peer types.PeerInfoInterface, pivot *syncHeaderHash) error {
+	from := blockMgr.ChainService.BestChain().Height() + 1
+	for from <= pivot.height {
+		count := pivot.height - from + 1
+		if count > maxHeaderHashCountReq {
+			count = maxHeaderHashCountReq
+		}
+		headers, err := blockMgr.fetchHeaders(peer, from, count)
+		if err != nil {
+			return err
+		}
+		last := headers[len(headers)-1]
+		if last.height == pivot.height && !last.headerHash.IsEqual(pivot.headerHash) {
+			return errors.Wrapf(ErrUnexpectedHeaders, "pivot %d changed from %s to %s", pivot.height, pivot.headerHash, last.headerHash)
+		}
+
+		blocks, err := blockMgr.fetchBodies(peer, headers)
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			err = blockMgr.ChainService.InsertBlockWithoutState(block)
+			if err != nil && err != chain.ErrBlockExsist {
+				return err
+			}
+		}
+		from = last.height + 1
+		log.WithField("height", last.height).WithField("pivot", pivot.height).Info("fast sync blocks")
+	}
+	return nil
+}
+
+//fetchBodies downloads the blocks of a run of linked headers from the peer, the blocks are returned in the
+//order of the headers
+func (blockMgr *BlockMgr) fetchBodies(peer types.PeerInfoInterface, headers []*syncHeaderHash) ([]*types.Block, error) {
+	wanted := make(map[crypto.Hash]*types.Block, len(headers))
+	for _, header := range headers {
+		wanted[*header.headerHash] = nil
+	}
+	req := &types.BlockReq{BlockHashs: []crypto.Hash{*headers[0].headerHash, *headers[len(headers)-1].headerHash}}
+	peer.SetReqTime(time.Now())
+	err := blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeBlockReq, req)
+	if err != nil {
+		return nil, err
+	}
+
+	received := 0
+	timeout := time.After(time.Second * maxNetworkTimeout)
+	for received < len(headers) {
+		select {
+		case pack := <-blockMgr.blocksCh:
+			for _, block := range pack.blocks {
+				hash := *block.Header.Hash()
+				if b, ok := wanted[hash]; ok && b == nil {
+					wanted[hash] = block
+					received++
+				}
+			}
+		case <-timeout:
+			return nil, ErrGetBlockTimeout
+		}
+	}
+
+	blocks := make([]*types.Block, 0, len(headers))
+	for _, header := range headers {
+		blocks = append(blocks, wanted[*header.headerHash])
+	}
+	return blocks, nil
+}
+
+//fallBackToFullSync is called after a failed fast sync and tells whether the blocks are executed from the local
+//tip instead. An invalid pivot gives fast sync up at once, other failures are retried up to maxFastSyncFailures
+//times in a row.
+func (blockMgr *BlockMgr) fallBackToFullSync(err error) bool {
+	blockMgr.fastSyncFailures++
+	if errors.Cause(err) != chain.ErrInvalidPivot && blockMgr.fastSyncFailures < maxFastSyncFailures {
+		return false
+	}
+	atomic.StoreInt32(&blockMgr.fastSync, 0)
+	log.WithField("Reason", err).WithField("failures", blockMgr.fastSyncFailures).Warn("fast sync given up, switch to full sync")
+	return true
+}
+
+//nodeDataFetcher retrieves trie nodes by hash from one of the state peers, the result is laid out as
+//types.NodeDataRsp
+type nodeDataFetcher func(peer int, hashes []crypto.Hash) ([][]byte, error)
+
+//stateSyncPeers returns the peers to download the state at the pivot from, the sync peer first
+func (blockMgr *BlockMgr) stateSyncPeers(peer types.PeerInfoInterface, pivot uint64) []types.PeerInfoInterface {
+	peers := []types.PeerInfoInterface{peer}
+	blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+		pi := value.(types.PeerInfoInterface)
+		if pi != peer && pi.GetHeight() >= pivot {
+			peers = append(peers, pi)
+		}
+		return len(peers) < maxStateSyncPeers
+	})
+	return peers
+}
+
+//syncState downloads the state trie at root, the root of the pivot block, from the peer and the other peers
+//having the pivot
+func (blockMgr *BlockMgr) syncState(peer types.PeerInfoInterface, pivot uint64, root crypto.Hash) error {
+	peers := blockMgr.stateSyncPeers(peer, pivot)
+	fetch := func(i int, hashes []crypto.Hash) ([][]byte, error) {
+		return blockMgr.requestNodeData(peers[i], hashes)
+	}
+	report := func(pulled, size, pending int) {
+		blockMgr.updateProgress(func(progress *SyncProgress) {
+			progress.PulledStates += uint64(pulled)
+			progress.StateBytes += uint64(size)
+			progress.PendingStates = uint64(pending)
+		})
+	}
+	err := syncStateTrie(blockMgr.DatabaseService.LevelDb(), root, len(peers), fetch, report)
+	if err != nil {
+		return err
+	}
+	progress := blockMgr.Progress()
+	log.WithField("root", root).WithField("nodes", progress.PulledStates).WithField("bytes", progress.StateBytes).Info("fast sync state done")
+	return nil
+}
+
+//syncStateTrie downloads the state trie with the given root and all contract storage tries below it from a number
+//of peers. Every node is checked against the hash it was requested by, nodes are written to the disk once all
+//their children are there. A node a peer failed to deliver is asked from the next peer, it is only given up once
+//every peer has answered that it does not have it.
+func syncStateTrie(diskDb dbinterface.KeyValueStore, root crypto.Hash, peers int, fetch nodeDataFetcher, report func(pulled, size, pending int)) error {
+	//The storage trie root of a contract is carried by the storage of its account in the state trie
+	var sched *trie.Sync
+	sched = trie.NewSync(root, diskDb, func(leaf []byte, parent crypto.Hash) error {
+		if storageRoot, ok := types.StorageRootOf(leaf); ok {
+			sched.AddSubTrie(storageRoot, storageTrieDepth, parent, nil)
+		}
+		return nil
+	})
+
+	batch := diskDb.NewBatch()
+	tries := make(map[crypto.Hash]int)   //failed requests of a node, the next one goes to the next peer
+	empties := make(map[crypto.Hash]int) //peers that answered they do not have a node
+	var retry []crypto.Hash
+	failures := 0
+	for sched.Pending() > 0 {
+		hashes := retry
+		if len(hashes) < maxNodeDataCountReq {
+			hashes = append(hashes, sched.Missing(maxNodeDataCountReq-len(hashes))...)
+		}
+		retry = nil
+		if len(hashes) > maxNodeDataCountReq {
+			retry = append(retry, hashes[maxNodeDataCountReq:]...)
+			hashes = hashes[:maxNodeDataCountReq]
+		}
+		if len(hashes) == 0 {
+			return errors.Wrapf(ErrStateSync, "%d nodes pending but none requestable", sched.Pending())
+		}
+
+		reqs := make([][]crypto.Hash, peers)
+		for _, hash := range hashes {
+			peer := tries[hash] % peers
+			reqs[peer] = append(reqs[peer], hash)
+		}
+
+		pulled, size := 0, 0
+		for peer, req := range reqs {
+			if len(req) == 0 {
+				continue
+			}
+			nodes, err := fetch(peer, req)
+			if err != nil {
+				log.WithField("peer", peer).WithField("Reason", err).Warn("node data req")
+				for _, hash := range req {
+					tries[hash]++
+				}
+				retry = append(retry, req...)
+				continue
+			}
+
+			for i, hash := range req {
+				if i >= len(nodes) {
+					//the rsp has been cut short, ask again
+					retry = append(retry, hash)
+					continue
+				}
+				if len(nodes[i]) == 0 {
+					tries[hash]++
+					empties[hash]++
+					if empties[hash] < peers {
+						retry = append(retry, hash)
+						continue
+					}
+					return errors.Wrapf(ErrStateSync, "node %s not available from any of %d peers", hash, peers)
+				}
+				if crypto.Keccak256Hash(nodes[i]) != hash {
+					log.WithField("hash", hash).WithField("peer", peer).Warn("node data not match its hash")
+					tries[hash]++
+					retry = append(retry, hash)
+					continue
+				}
+				delete(tries, hash)
+				delete(empties, hash)
+				_, _, err = sched.Process([]trie.SyncResult{{Hash: hash, Data: nodes[i]}})
+				if err != nil {
+					return errors.Wrapf(ErrStateSync, "process node %s: %v", hash, err)
+				}
+				pulled++
+				size += len(nodes[i])
+			}
+		}
+
+		if pulled == 0 {
+			failures++
+			if failures >= maxStateSyncFailures {
+				return errors.Wrapf(ErrStateSync, "no node received in %d rounds, %d pending", failures, sched.Pending())
+			}
+		} else {
+			failures = 0
+		}
+
+		_, err := sched.Commit(batch)
+		if err != nil {
+			return err
+		}
+		if batch.ValueSize() >= dbinterface.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		report(pulled, size, sched.Pending())
+	}
+
+	_, err := sched.Commit(batch)
+	if err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+//requestNodeData sends a node data req for hashes to the peer and waits for its rsp
+func (blockMgr *BlockMgr) requestNodeData(peer types.PeerInfoInterface, hashes []crypto.Hash) ([][]byte, error) {
+	peer.SetReqTime(time.Now())
+	reqId := atomic.AddUint64(&blockMgr.nodeDataReqId, 1)
+	err := blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeNodeDataReq, &types.NodeDataReq{ReqId: reqId, Hashes: hashes})
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.After(time.Second * maxNetworkTimeout)
+	for {
+		select {
+		case pack := <-blockMgr.nodeDataCh:
+			if pack.peer != peer || pack.reqId != reqId {
+				//a late rsp to a req given up before
+				continue
+			}
+			return pack.nodes, nil
+		case <-timeout:
+			return nil, errors.Wrapf(ErrStateSync, "node data req to %s timeout", peer.GetAddr())
+		case <-blockMgr.quit:
+			return nil, errors.Wrapf(ErrStateSync, "quit")
+		}
+	}
+}
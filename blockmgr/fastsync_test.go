@@ -0,0 +1,177 @@
+package blockmgr
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+//newTestStateTrie stores a state trie with one contract account carrying the root of its storage trie and a 32 byte
+//value which is no storage root
+func newTestStateTrie(t *testing.T) (*memorydb.Database, crypto.Hash, crypto.Hash) {
+	diskDb := memorydb.New()
+	triedb := trie.NewDatabase(diskDb)
+
+	storage, err := trie.New(trie.EmptyRoot, triedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(0); i < 20; i++ {
+		storage.Update([]byte{i}, bytes.Repeat([]byte{i + 1}, 40))
+	}
+	storageRoot, err := storage.Commit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := trie.New(trie.EmptyRoot, triedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract, err := types.EncodeStorage(&types.Storage{ByteCode: []byte{1, 2, 3}, StorageRoot: storageRoot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Update([]byte("contract"), contract)
+	state.Update([]byte("account"), sha3.Keccak256([]byte("not a trie node")))
+	for i := byte(0); i < 20; i++ {
+		state.Update([]byte{'a', i}, bytes.Repeat([]byte{i}, 40))
+	}
+	root, err := state.Commit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := triedb.Commit(storageRoot, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := triedb.Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	return diskDb, root, storageRoot
+}
+
+func TestSyncStateTrie(t *testing.T) {
+	srcDb, root, storageRoot := newTestStateTrie(t)
+	//peer 0 has pruned the storage trie, peer 1 has the whole state
+	fetch := func(peer int, hashes []crypto.Hash) ([][]byte, error) {
+		nodes := make([][]byte, 0, len(hashes))
+		for _, hash := range hashes {
+			blob, _ := srcDb.Get(hash[:])
+			if peer == 0 && hash == storageRoot {
+				blob = nil
+			}
+			nodes = append(nodes, blob)
+		}
+		return nodes, nil
+	}
+
+	dstDb := memorydb.New()
+	err := syncStateTrie(dstDb, root, 2, fetch, func(pulled, size, pending int) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, err := trie.New(storageRoot, trie.NewDatabase(dstDb))
+	if err != nil {
+		t.Fatalf("expect the storage trie retried from the other peer, got %v", err)
+	}
+	for i := byte(0); i < 20; i++ {
+		value, err := storage.TryGet([]byte{i})
+		if err != nil || !bytes.Equal(value, bytes.Repeat([]byte{i + 1}, 40)) {
+			t.Fatalf("expect the storage value %d, got %v %v", i, value, err)
+		}
+	}
+	state, err := trie.New(root, trie.NewDatabase(dstDb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for it := state.NodeIterator(nil); it.Next(true); {
+		if it.Error() != nil {
+			t.Fatal(it.Error())
+		}
+	}
+
+	//a trie node no peer has fails the sync
+	dstDb = memorydb.New()
+	err = syncStateTrie(dstDb, root, 2, func(peer int, hashes []crypto.Hash) ([][]byte, error) {
+		return make([][]byte, len(hashes)), nil
+	}, func(pulled, size, pending int) {})
+	if errors.Cause(err) != ErrStateSync {
+		t.Fatalf("expect the state sync failed, got %v", err)
+	}
+}
+
+func TestFallBackToFullSync(t *testing.T) {
+	bm, _ := newTestBlockMgr(newChainServiceMock(linkedBlocks(0)))
+	bm.fastSync = 1
+	for i := 1; i < maxFastSyncFailures; i++ {
+		if bm.fallBackToFullSync(ErrStateSync) {
+			t.Fatalf("expect fast sync retried after %d failures", i)
+		}
+	}
+	if !bm.fallBackToFullSync(ErrStateSync) || bm.fastSync != 0 {
+		t.Fatal("expect full sync after too many failures")
+	}
+
+	bm.fastSync, bm.fastSyncFailures = 1, 0
+	if !bm.fallBackToFullSync(chain.ErrInvalidPivot) || bm.fastSync != 0 {
+		t.Fatal("expect full sync at once for an invalid pivot")
+	}
+}
+
+func TestRequestNodeDataSkipsLateRsp(t *testing.T) {
+	bm, p2pServer := newTestBlockMgr(newChainServiceMock(linkedBlocks(0)))
+	peer := newPeerInfoMock(1, nil)
+	peer.silent = true
+
+	type result struct {
+		nodes [][]byte
+		err   error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		nodes, err := bm.requestNodeData(peer, []crypto.Hash{{1}})
+		resultCh <- result{nodes, err}
+	}()
+	for p2pServer.sentCount(types.MsgTypeNodeDataReq) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	reqId := atomic.LoadUint64(&bm.nodeDataReqId)
+	//the rsp to an earlier req arrives first and must not be taken for the answer
+	bm.handleNodeDataRsp(peer, &types.NodeDataRsp{ReqId: reqId - 1, Nodes: [][]byte{[]byte("late")}})
+	bm.handleNodeDataRsp(peer, &types.NodeDataRsp{ReqId: reqId, Nodes: [][]byte{[]byte("node")}})
+
+	res := <-resultCh
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if len(res.nodes) != 1 || string(res.nodes[0]) != "node" {
+		t.Fatalf("expect the rsp of the req, got %q", res.nodes)
+	}
+}
+
+func TestReqLimiter(t *testing.T) {
+	limiter := newReqLimiter(2)
+	peer, other := newPeerInfoMock(1, nil), newPeerInfoMock(2, nil)
+	if !limiter.acquire(peer) || !limiter.acquire(peer) {
+		t.Fatal("expect the reqs within the limit served")
+	}
+	if limiter.acquire(peer) {
+		t.Fatal("expect the req above the limit refused")
+	}
+	if !limiter.acquire(other) {
+		t.Fatal("expect the limit kept per peer")
+	}
+	limiter.release(peer)
+	if !limiter.acquire(peer) {
+		t.Fatal("expect a slot freed by a served req")
+	}
+}
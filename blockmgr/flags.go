@@ -0,0 +1,13 @@
+package blockmgr
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	SyncModeFlag = cli.StringFlag{
+		Name:  "syncmode",
+		Usage: `blockchain sync mode, "full" executes every block, "fast" downloads the state at a recent block`,
+		Value: FullSync,
+	}
+)
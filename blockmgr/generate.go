@@ -29,7 +29,7 @@ func (blockMgr *BlockMgr) GenerateTemplate(trieStore chainStore.StoreInterface,
 	blockHeader := &types.BlockHeader{
 		Version:      common.Version,
 		PreviousHash: *previousHash,
-		ChainId:      blockMgr.ChainService.ChainID(),
+		ChainId:      blockMgr.ChainService.GetConfig().BlockChainId(height),
 		GasLimit:     *newGasLimit,
 		Timestamp:    timestamp,
 		Height:       height,
@@ -49,7 +49,7 @@ func (blockMgr *BlockMgr) GenerateTemplate(trieStore chainStore.StoreInterface,
 	gp := new(utils.GasPool).AddGas(newGasLimit.Uint64())
 	//process transaction
 	chainStore := &chainStore.ChainStore{blockMgr.DatabaseService.LevelDb()}
-	context := chainBlock.NewBlockExecuteContext(trieStore, gp, chainStore, block)
+	context := chainBlock.NewBlockExecuteContext(trieStore, gp, chainStore, block, blockMgr.ChainService.GetConfig().Rules(block.Header.Height))
 
 	templateValidator := NewTemplateBlockValidator(blockMgr.ChainService)
 	err = templateValidator.ExecuteBlock(context, blockInterval)
@@ -12,7 +12,20 @@ const (
 	maxLivePeer           = 50
 	broadcastRatio        = 3    //BroadcastRatio broadcasts one third as many non-local messages
 	maxTxsCount           = 1024 //The maximum number of transmission transactions
-	pendingTimerCount     = 2    //When synchronizing blocks, the maximum number of concurrent coroutines of fetch block requests
+	maxBlockBatchReq      = 64   //The maximum number of blocks in one body request to a fast peer
+	maxQueuedBlocks       = 1024 //Headers downloaded ahead of the imported blocks
+	bodyReqTargetTime     = 2    //Seconds a body request should take at the measured throughput of the peer
+	bodyReqTimeout        = 10   //Seconds before an unanswered body request is given to another peer
+	maxBodyReqFailures    = 3    //Body requests in a row a peer may fail before it is left out of the synchronization
+
+	//Transactions are relayed in full to a few peers and announced by hash to the others
+	maxTxAnnounceCount  = 4096            //The maximum number of hashes in one transaction announcement
+	maxTxFetchPerPeer   = 256             //Announced transactions requested from one peer and not delivered yet
+	maxTxRetrievalCount = 256             //The maximum number of transactions in one pooled txs request
+	maxPooledTxsRspSize = 2 * 1024 * 1024 //A pooled txs rsp is cut short once it reaches this size
+	txFetchTimeout      = 5               //Seconds before an undelivered announced transaction can be requested from another peer
+	txAnnounceInterval  = 500             //Milliseconds the hashes of the broadcast transactions are batched before they are announced
+	maxTxReqInFlight    = 4               //Tx announcements and pooled txs reqs of a peer handled at the same time
 
 	MODULENAME = "blockmgr"
 )
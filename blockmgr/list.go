@@ -87,3 +87,19 @@ func (m *heightSortedMap) GetSortedHashs(count int) ([]crypto.Hash, map[crypto.H
 
 	return hashs, syncHeaderHashs
 }
+
+// PopContinuous removes up to count syncHeaderHash of consecutive heights starting with
+// the lowest one, none of them above maxHeight.
+func (m *heightSortedMap) PopContinuous(count int, maxHeight uint64) []*syncHeaderHash {
+	shhs := make([]*syncHeaderHash, 0, count)
+	for len(shhs) < count && m.Len() > 0 {
+		h := (*m.index)[0]
+		if h > maxHeight || (len(shhs) > 0 && h != shhs[len(shhs)-1].height+1) {
+			break
+		}
+		heap.Pop(m.index)
+		shhs = append(shhs, m.items[h])
+		delete(m.items, h)
+	}
+	return shhs
+}
@@ -3,6 +3,7 @@ package blockmgr
 import (
 	"time"
 
+	"github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/network/p2p"
 	"github.com/drep-project/DREP-Chain/types"
@@ -10,43 +11,29 @@ import (
 )
 
 func (blockMgr *BlockMgr) receiveMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter) error {
-	//1 Synchronize the state with the peer
-	timeout := time.After(time.Second * maxNetworkTimeout)
-	errCh := make(chan error)
-	msgCh := make(chan p2p.Msg)
-	go func() {
-		blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypePeerStateReq, &types.PeerState{Height: uint64(blockMgr.ChainService.BestChain().Height())})
-		msg, err := rw.ReadMsg()
-		if err != nil {
-			errCh <- err
-			return
-		}
-		msgCh <- msg
-	}()
-
-	select {
-	case err := <-errCh:
-		return err
-	case <-timeout:
-		return ErrReqStateTimeout
-	case msg := <-msgCh:
-		switch msg.Code {
-		case types.MsgTypePeerStateReq:
-			var req types.PeerStateReq
-			if err := msg.Decode(&req); err != nil {
-				return errors.Wrapf(ErrDecodeMsg, "PeerStateReq msg:%v err:%v", msg, err)
-			}
-			blockMgr.handleReqPeerState(peer, &req)
-		default:
-			return errors.Wrapf(ErrMsgType, "expected type:%d, receive type:%d", types.MsgTypePeerStateReq, msg.Code)
-		}
+	//1 Exchange the status with the peer, a peer of another chain or protocol version is disconnected
+	remote, err := types.Handshake(rw, blockMgr.status(), &blockMgr.ChainService.GetConfig().ForkConfig, time.Second*maxNetworkTimeout)
+	if err != nil {
+		log.WithField("Reason", err).WithField("ip", peer.GetAddr()).Info("status handshake fail")
+		return errors.Cause(err)
 	}
+	peer.SetHeight(remote.Height)
 
 	//Notify the synchronization coroutine
 	blockMgr.newPeerCh <- peer
 
 	//2 Process all messages
-	return blockMgr.dealMsg(peer, rw)
+	err = blockMgr.dealMsg(peer, rw)
+	if cause := errors.Cause(err); cause == ErrDecodeMsg || cause == ErrOverFlowMaxMsgSize {
+		blockMgr.penalize(peer, p2p.FaultBadMessage)
+	}
+	return err
+}
+
+//penalize reports a misbehaviour of the peer, the p2p service bans the peers which misbehave too often
+func (blockMgr *BlockMgr) penalize(peer types.PeerInfoInterface, fault p2p.Fault) {
+	log.WithField("ip", peer.GetAddr()).WithField("fault", fault).Info("penalize peer")
+	blockMgr.P2pServer.Penalize(peer.GetPeer(), fault)
 }
 
 func (blockMgr *BlockMgr) dealMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter) error {
@@ -82,16 +69,36 @@ func (blockMgr *BlockMgr) dealMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter) er
 			if err := msg.Decode(&txs); err != nil {
 				return errors.Wrapf(ErrDecodeMsg, "Transactions msg:%v err:%v", msg, err)
 			}
-
-			// TODO backup nodes should not add
-			for _, tx := range txs {
-				from, _ := tx.From()
-				log.WithField("transaction", tx.Nonce()).WithField("from", from.String()).Trace("comming transaction")
-				tx := tx
-				peer.MarkTx(tx)
-				blockMgr.SendTransaction(tx, false)
+			blockMgr.handleTransactions(peer, txs)
+		case types.MsgTypeTxHashes:
+			var ann types.TxHashes
+			if err := msg.Decode(&ann); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "TxHashes msg:%v err:%v", msg, err)
 			}
-
+			if !blockMgr.txReqs.acquire(peer) {
+				//the announcements of a peer are batched
+				blockMgr.penalize(peer, p2p.FaultSpam)
+				continue
+			}
+			go blockMgr.handleTxHashes(peer, &ann)
+		case types.MsgTypeGetPooledTxs:
+			var req types.GetPooledTxs
+			if err := msg.Decode(&req); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "GetPooledTxs msg:%v err:%v", msg, err)
+			}
+			if !blockMgr.txReqs.acquire(peer) {
+				//a peer only requests the transactions it was announced
+				blockMgr.penalize(peer, p2p.FaultSpam)
+				continue
+			}
+			go blockMgr.handleGetPooledTxs(peer, &req)
+		case types.MsgTypePooledTxs:
+			var rsp types.PooledTxs
+			if err := msg.Decode(&rsp); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "PooledTxs msg:%v err:%v", msg, err)
+			}
+			blockMgr.txFetcher.deliver(rsp.Txs)
+			blockMgr.handleTransactions(peer, rsp.Txs)
 		case types.MsgTypeBlock:
 			var newBlock types.Block
 			if err := msg.Decode(&newBlock); err != nil {
@@ -99,8 +106,13 @@ func (blockMgr *BlockMgr) dealMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter) er
 			}
 
 			_, isOrPhan, err := blockMgr.ChainService.ProcessBlock(&newBlock)
-			if err == nil {
-				//return err
+			if err != nil && err != chain.ErrBlockExsist && err != chain.ErrOrphanBlockExsist {
+				log.WithField("Reason", err).WithField("ip", peer.GetAddr()).Info("process new block")
+				//a failure of the local database or of the orphans after the block is not the fault of the peer
+				if _, invalid := err.(*chain.InvalidBlockError); invalid {
+					blockMgr.penalize(peer, p2p.FaultInvalidBlock)
+				}
+				continue
 			}
 
 			peer.MarkBlock(&newBlock)
@@ -133,12 +145,98 @@ func (blockMgr *BlockMgr) dealMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter) er
 				return errors.Wrapf(ErrDecodeMsg, "HeaderRsp msg:%v err:%v", msg, err)
 			}
 			go blockMgr.handleHeaderRsp(peer, &resp)
+		case types.MsgTypeNodeDataReq:
+			var req types.NodeDataReq
+			if err := msg.Decode(&req); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "NodeDataReq msg:%v err:%v", msg, err)
+			}
+			if !blockMgr.nodeDataReqs.acquire(peer) {
+				//a peer syncing the state waits for each rsp
+				blockMgr.penalize(peer, p2p.FaultSpam)
+				continue
+			}
+			go blockMgr.handleNodeDataReq(peer, &req)
+		case types.MsgTypeNodeDataRsp:
+			var resp types.NodeDataRsp
+			if err := msg.Decode(&resp); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "NodeDataRsp msg:%v err:%v", msg, err)
+			}
+			go blockMgr.handleNodeDataRsp(peer, &resp)
 		}
 	}
 
 	return nil
 }
 
+func (blockMgr *BlockMgr) handleTransactions(peer types.PeerInfoInterface, txs []*types.Transaction) {
+	// TODO backup nodes should not add
+	for _, tx := range txs {
+		from, err := tx.ClaimedFrom()
+		if err != nil {
+			//a transaction without a valid signature can only be spam
+			blockMgr.penalize(peer, p2p.FaultSpam)
+			continue
+		}
+		log.WithField("transaction", tx.Nonce()).WithField("from", from.String()).Trace("comming transaction")
+		tx := tx
+		peer.MarkTx(tx)
+		if err := blockMgr.SendTransaction(tx, false); err == ErrNegativeAmount {
+			blockMgr.penalize(peer, p2p.FaultSpam)
+		}
+	}
+}
+
+//handleTxHashes requests the announced transactions which are not in the pool
+func (blockMgr *BlockMgr) handleTxHashes(peer types.PeerInfoInterface, ann *types.TxHashes) {
+	defer blockMgr.txReqs.release(peer)
+	hashes := ann.Hashes
+	if len(hashes) > maxTxAnnounceCount {
+		blockMgr.penalize(peer, p2p.FaultSpam)
+		hashes = hashes[:maxTxAnnounceCount]
+	}
+
+	unknown := make([]crypto.Hash, 0, len(hashes))
+	for i := range hashes {
+		peer.MarkTxHash(&hashes[i])
+		if _, err := blockMgr.transactionPool.GetTxInPool(hashes[i].String()); err != nil {
+			unknown = append(unknown, hashes[i])
+		}
+	}
+	fetch := blockMgr.txFetcher.schedule(peer, unknown, time.Now())
+	if len(fetch) == 0 {
+		return
+	}
+	log.WithField("announced", len(hashes)).WithField("req", len(fetch)).WithField("ip", peer.GetAddr()).Trace("req pooled txs")
+	blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeGetPooledTxs, &types.GetPooledTxs{Hashes: fetch})
+}
+
+//handleGetPooledTxs answers with the requested transactions still in the pool
+func (blockMgr *BlockMgr) handleGetPooledTxs(peer types.PeerInfoInterface, req *types.GetPooledTxs) {
+	defer blockMgr.txReqs.release(peer)
+	hashes := req.Hashes
+	if len(hashes) > maxTxRetrievalCount {
+		blockMgr.penalize(peer, p2p.FaultSpam)
+		hashes = hashes[:maxTxRetrievalCount]
+	}
+
+	txs := make([]*types.Transaction, 0, len(hashes))
+	size := 0
+	for _, hash := range hashes {
+		if size >= maxPooledTxsRspSize {
+			break
+		}
+		tx, err := blockMgr.transactionPool.GetTxInPool(hash.String())
+		if err != nil {
+			continue
+		}
+		peer.MarkTx(tx)
+		txs = append(txs, tx)
+		size += len(tx.AsPersistentMessage())
+	}
+	log.WithField("req", len(req.Hashes)).WithField("rsp", len(txs)).Trace("pooled txs req")
+	blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypePooledTxs, &types.PooledTxs{Txs: txs})
+}
+
 func (blockMgr *BlockMgr) handleHeaderReq(peer types.PeerInfoInterface, req *types.HeaderReq) {
 	headers := make([]types.BlockHeader, 0, req.ToHeight-req.FromHeight+1)
 	for i := req.FromHeight; i <= req.ToHeight; i++ {
@@ -156,13 +254,14 @@ func (blockMgr *BlockMgr) handleHeaderRsp(peer types.PeerInfoInterface, rsp *typ
 	peer.CalcAverageRtt()
 	headerHashs := make([]*syncHeaderHash, 0, len(rsp.Headers))
 	for _, h := range rsp.Headers {
-		headerHashs = append(headerHashs, &syncHeaderHash{headerHash: h.Hash(), height: h.Height})
+		headerHashs = append(headerHashs, &syncHeaderHash{headerHash: h.Hash(), height: h.Height, stateRoot: h.StateRoot})
 	}
 
 	//The requested associated coroutine is closed
 	err := blockMgr.checkHeaderChain(rsp.Headers)
 	if err != nil {
 		log.WithField("Reason", err).Info("checkHeaderChain fail")
+		blockMgr.penalize(peer, p2p.FaultBadHeaderChain)
 		return
 	}
 
@@ -230,5 +329,40 @@ func (blockMgr *BlockMgr) HandleBlockReqMsg(peer types.PeerInfoInterface, req *t
 
 func (blockMgr *BlockMgr) HandleBlockRespMsg(peer types.PeerInfoInterface, rsp *types.BlockResp) {
 	peer.CalcAverageRtt()
-	blockMgr.blocksCh <- rsp.Blocks
+	//nobody waits for the blocks if the sync has timed out
+	select {
+	case blockMgr.blocksCh <- &blockPack{peer: peer, blocks: rsp.Blocks}:
+	case <-time.After(time.Second * maxNetworkTimeout):
+		log.WithField("ip", peer.GetAddr()).Warn("drop block rsp")
+	}
+}
+
+func (blockMgr *BlockMgr) handleNodeDataReq(peer types.PeerInfoInterface, req *types.NodeDataReq) {
+	defer blockMgr.nodeDataReqs.release(peer)
+	nodes := make([][]byte, 0, len(req.Hashes))
+	size := 0
+	for _, hash := range req.Hashes {
+		if len(nodes) >= maxNodeDataCountReq || size >= maxNodeDataRspSize {
+			break
+		}
+		//a node which is not available is answered with an empty entry
+		blob, err := blockMgr.DatabaseService.StateDb().Node(hash)
+		if err != nil {
+			blob = nil
+		}
+		nodes = append(nodes, blob)
+		size += len(blob)
+	}
+	log.WithField("req", len(req.Hashes)).WithField("rsp", len(nodes)).Trace("node data req")
+	blockMgr.P2pServer.Send(peer.GetMsgRW(), uint64(types.MsgTypeNodeDataRsp), &types.NodeDataRsp{ReqId: req.ReqId, Nodes: nodes})
+}
+
+func (blockMgr *BlockMgr) handleNodeDataRsp(peer types.PeerInfoInterface, rsp *types.NodeDataRsp) {
+	peer.CalcAverageRtt()
+	//nobody waits for the nodes if the state sync has timed out
+	select {
+	case blockMgr.nodeDataCh <- &nodeDataPack{peer: peer, reqId: rsp.ReqId, nodes: rsp.Nodes}:
+	case <-time.After(time.Second * maxNetworkTimeout):
+		log.WithField("ip", peer.GetAddr()).Warn("drop node data rsp")
+	}
 }
@@ -0,0 +1,57 @@
+package blockmgr
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+//faultRecorder records the faults reported to the p2p service instead of scoring the peers
+type faultRecorder struct {
+	lock      sync.Mutex
+	penalties map[*p2p.Peer][]p2p.Fault
+}
+
+func (recorder *faultRecorder) record(peer *p2p.Peer, fault p2p.Fault) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	if recorder.penalties == nil {
+		recorder.penalties = make(map[*p2p.Peer][]p2p.Fault)
+	}
+	recorder.penalties[peer] = append(recorder.penalties[peer], fault)
+}
+
+func (recorder *faultRecorder) faults(peer *p2p.Peer) []p2p.Fault {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	return recorder.penalties[peer]
+}
+
+func (ps *p2pServiceMock) Penalize(peer *p2p.Peer, fault p2p.Fault) {
+	ps.faultRecorder.record(peer, fault)
+}
+
+func TestPenalizeBadHeaderChain(t *testing.T) {
+	blks := linkedBlocks(3)
+	bm, p2pServer := newTestBlockMgr(newChainServiceMock(blks[:1]))
+	peer := newPeerInfoMock(1, blks)
+
+	//the second header does not follow the first one
+	headers := []types.BlockHeader{*blks[1].Header, *blks[3].Header}
+	bm.handleHeaderRsp(peer, &types.HeaderRsp{Headers: headers})
+	faults := p2pServer.faults(peer.GetPeer())
+	if len(faults) != 1 || faults[0] != p2p.FaultBadHeaderChain {
+		t.Fatalf("expect the peer penalized for a broken header chain, got %v", faults)
+	}
+
+	//a linked header chain is not a fault of the peer
+	go bm.handleHeaderRsp(peer, &types.HeaderRsp{Headers: []types.BlockHeader{*blks[1].Header, *blks[2].Header}})
+	if hashes := <-bm.headerHashCh; len(hashes) != 2 {
+		t.Fatalf("expect the 2 headers passed to the synchronization, got %d", len(hashes))
+	}
+	if faults := p2pServer.faults(peer.GetPeer()); len(faults) != 1 {
+		t.Fatalf("expect no penalty for a linked header chain, got %v", faults)
+	}
+}
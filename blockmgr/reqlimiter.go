@@ -0,0 +1,45 @@
+package blockmgr
+
+import (
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+//reqLimiter bounds the number of requests of a kind that each peer may have served at the same time, a peer
+//sending more before its previous requests are answered is spamming
+type reqLimiter struct {
+	lock     sync.Mutex
+	limit    int
+	inFlight map[types.PeerInfoInterface]int //requests being served, by peer
+}
+
+func newReqLimiter(limit int) *reqLimiter {
+	return &reqLimiter{
+		limit:    limit,
+		inFlight: make(map[types.PeerInfoInterface]int),
+	}
+}
+
+//acquire reserves a slot for a request of the peer, it returns false if the peer has no slot left
+func (limiter *reqLimiter) acquire(peer types.PeerInfoInterface) bool {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	if limiter.inFlight[peer] >= limiter.limit {
+		return false
+	}
+	limiter.inFlight[peer]++
+	return true
+}
+
+//release frees the slot of a request of the peer once it has been served
+func (limiter *reqLimiter) release(peer types.PeerInfoInterface) {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	limiter.inFlight[peer]--
+	if limiter.inFlight[peer] <= 0 {
+		delete(limiter.inFlight, peer)
+	}
+}
@@ -3,15 +3,15 @@ package blockmgr
 import (
 	"bytes"
 	"encoding/hex"
-	"fmt"
 	"math/big"
 	"time"
 
-	"github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
 
 	"github.com/drep-project/DREP-Chain/common/event"
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
 )
 
 type tasksTxsSync struct {
@@ -50,7 +50,14 @@ func (blockMgr *BlockMgr) synchronise() {
 		currentHeight := blockMgr.ChainService.BestChain().Height()
 		if pi.GetHeight() > currentHeight {
 			log.Info("need sync  ", pi.GetHeight(), ">", currentHeight)
-			err := blockMgr.fetchBlocks(pi)
+			err := blockMgr.fastSyncBlocks(pi)
+			if err != nil {
+				log.WithField("Reason", err).Warn("fast sync from peer")
+				if !blockMgr.fallBackToFullSync(err) {
+					return
+				}
+			}
+			err = blockMgr.fetchBlocks(pi)
 			if err != nil {
 				log.WithField("Reason", err).Warn("sync block from peer")
 			}
@@ -177,58 +184,56 @@ func (blockMgr *BlockMgr) clearSyncCh() {
 	case <-blockMgr.blocksCh:
 	default:
 	}
-
-	select {
-	case <-blockMgr.syncTimerCh:
-	default:
-	}
-
-	blockMgr.allTasks = newHeightSortedMap()
-
-	blockMgr.pendingSyncTasks.Range(func(key, value interface{}) bool {
-		blockMgr.pendingSyncTasks.Delete(key)
-		return true
-	})
 }
 
-func (blockMgr *BlockMgr) batchReqBlocks(hashs []crypto.Hash, mapHeightHash map[crypto.Hash]uint64, errCh chan error) {
-	req := &types.BlockReq{BlockHashs: hashs}
-
-	var maxHeight uint64 = 0
-	var minHeight uint64 = 0xffffffffffffffff
-	for _, value := range mapHeightHash {
-		if value > maxHeight {
-			maxHeight = value
+//fetchSkeleton downloads the headers after the common ancestor up to height from the peer. Each request starts
+//with the last header already known, checkHeaderChain then verifies that the batches are linked to each other.
+func (blockMgr *BlockMgr) fetchSkeleton(peer types.PeerInfoInterface, ancestor, height uint64, headerCh chan<- []*syncHeaderHash, errCh chan<- error, quit <-chan struct{}) {
+	node := blockMgr.ChainService.BestChain().NodeByHeight(ancestor)
+	if node == nil {
+		errCh <- errors.Wrapf(ErrNoCommonAncesstor, "ancestor %d", ancestor)
+		return
+	}
+	last := node.Hash
+	for from := ancestor + 1; from <= height; {
+		count := height - from + 1
+		if count > maxHeaderHashCountReq-1 {
+			count = maxHeaderHashCountReq - 1
 		}
-		if value < minHeight {
-			minHeight = value
+		headers, err := blockMgr.fetchHeaders(peer, from-1, count+1)
+		if err != nil {
+			if err == ErrGetHeaderHashTimeout {
+				blockMgr.penalize(peer, p2p.FaultTimeout)
+			}
+			errCh <- err
+			return
+		}
+		if !headers[0].headerHash.IsEqual(last) {
+			blockMgr.penalize(peer, p2p.FaultBadHeaderChain)
+			errCh <- errors.Wrapf(ErrUnexpectedHeaders, "header %d is %s, expect %s", from-1, headers[0].headerHash, last)
+			return
+		}
+		headers = headers[1:]
+		if len(headers) == 0 {
+			errCh <- errors.Wrapf(ErrUnexpectedHeaders, "no header after %d", from-1)
+			return
 		}
-	}
-
-	bestPeer := blockMgr.GetBestPeerInfo()
-	if bestPeer != nil {
-		blockMgr.syncMut.Lock()
-		log.WithField("len", len(hashs)).WithField("from", minHeight).WithField("to:", maxHeight).WithField("destIp", bestPeer.GetAddr()).Info("req block body")
-		err := blockMgr.P2pServer.Send(bestPeer.GetMsgRW(), types.MsgTypeBlockReq, req)
-		bestPeer.SetReqTime(time.Now())
-		blockMgr.syncMut.Unlock()
 
-		if err == nil {
+		select {
+		case headerCh <- headers:
+		case <-quit:
 			return
 		}
+		last = headers[len(headers)-1].headerHash
+		from += uint64(len(headers))
+		log.WithField("height", from-1).WithField("ip", peer.GetAddr()).Info("get headers")
 	}
-
-	errCh <- fmt.Errorf("p2p no peers")
-	return
+	close(headerCh)
 }
 
+//fetchBlocks synchronizes the chain up to the height of the peer. The headers are only downloaded from this peer,
+//the blocks are downloaded from all the peers by a bodyFetcher.
 func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
-	blockMgr.syncBlockEvent.Send(event.SyncBlockEvent{EventType: event.StartSyncBlock})
-	defer func() {
-		blockMgr.state = event.StopSyncBlock
-		blockMgr.syncBlockEvent.Send(event.SyncBlockEvent{EventType: event.StopSyncBlock})
-	}()
-
 	if blockMgr.state == event.StartSyncBlock {
 		log.Info("have fetch blocks")
 		return nil
@@ -237,8 +242,22 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 	blockMgr.state = event.StartSyncBlock
 
 	height := peer.GetHeight()
+	blockMgr.updateProgress(func(progress *SyncProgress) {
+		progress.Phase = syncPhaseFull
+		progress.StartingBlock = blockMgr.ChainService.BestChain().Height()
+		progress.HighestBlock = height
+		progress.started = time.Now()
+	})
+	//the subscribers read the progress when notified, so it is updated first
+	blockMgr.syncBlockEvent.Send(event.SyncBlockEvent{EventType: event.StartSyncBlock})
+	defer func() {
+		blockMgr.updateProgress(func(progress *SyncProgress) {
+			progress.Phase = syncPhaseIdle
+		})
+		blockMgr.state = event.StopSyncBlock
+		blockMgr.syncBlockEvent.Send(event.SyncBlockEvent{EventType: event.StopSyncBlock})
+	}()
 	blockMgr.clearSyncCh()
-	headerRoutineExit := false
 
 	//1 Acquisition of common ancestor
 	commonAncestor, err := blockMgr.findAncestor(peer)
@@ -248,225 +267,54 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 
 	log.Info("commonAncestor=", commonAncestor)
 
-	errCh := make(chan error)
+	//2 The header skeleton, it is verified before any block is requested
+	headerCh := make(chan []*syncHeaderHash)
+	errCh := make(chan error, 1)
 	quit := make(chan struct{})
-	//2 Gets the hash of all blocks that need to be synchronized; It then notifies the coroutine to get the BODY
-	go func() {
-		commonAncestor++
-		timer := time.NewTimer(time.Second * maxNetworkTimeout)
-		defer timer.Stop()
-
-		for height >= commonAncestor {
-			select {
-			case <-quit:
-				log.Info("fetch headers goroutine quit")
-				return
-			default:
-				timer.Reset(time.Second * maxNetworkTimeout)
-
-				blockMgr.syncMut.Lock()
-				taskLen := blockMgr.allTasks.Len()
-				blockMgr.syncMut.Unlock()
-				if taskLen >= pendingTimerCount {
-					time.Sleep(maxSyncSleepTime)
-					continue
-				}
-
-				blockMgr.syncMut.Lock()
-				err := blockMgr.requestHeaders(peer, commonAncestor, maxHeaderHashCountReq)
-				log.WithField("commonAncestor", commonAncestor).Info("req header")
-				blockMgr.syncMut.Unlock()
-				if err != nil {
-					errCh <- err
-					return
-				}
-
-				select {
-				//Each headerhash is a task
-				case tasks := <-blockMgr.headerHashCh:
-					for _, task := range tasks {
-						blockMgr.syncMut.Lock()
-						blockMgr.allTasks.Put(task)
-						blockMgr.syncMut.Unlock()
-					}
-					commonAncestor += uint64(len(tasks))
-					log.WithField("tasks len", blockMgr.allTasks.Len()).WithField("newtasks", len(tasks)).Info("get headers")
-				case <-timer.C:
-					errCh <- ErrGetHeaderHashTimeout
-					return
+	defer close(quit)
+	go blockMgr.fetchSkeleton(peer, commonAncestor, height, headerCh, errCh, quit)
+
+	//3 The blocks of the skeleton from all the peers, imported in order
+	fetcher := newBodyFetcher(blockMgr, commonAncestor+1)
+	ticker := time.NewTicker(time.Millisecond * maxSyncSleepTime)
+	defer ticker.Stop()
+	headersDone := false
+	for {
+		//no more headers are accepted while too many blocks wait to be imported
+		in := headerCh
+		if headersDone || fetcher.queued() >= maxQueuedBlocks {
+			in = nil
+		}
 
-				}
+		select {
+		case headers, ok := <-in:
+			if !ok {
+				headersDone = true
+			} else {
+				fetcher.enqueue(headers)
 			}
+		case err := <-errCh:
+			return err
+		case pack := <-blockMgr.blocksCh:
+			fetcher.deliver(pack, time.Now())
+		case <-ticker.C:
+			fetcher.expire(time.Now())
+		case <-blockMgr.quit:
+			return nil
 		}
-		log.Info("fetch all headers end ****************************")
-		blockMgr.syncMut.Lock()
-		headerRoutineExit = true
-		blockMgr.syncMut.Unlock()
-	}()
 
-	//When the request is sent, set a timeout timer
-	//After the request has timed out, the pendingSyncTasks are placed into allTasks and requested again
-	//The request to the block has arrived, and the corresponding task is removed from pendingSyncTasks
-
-	//Receive block
-	go func() {
-		delHash := func(b *types.Block) {
-			blockMgr.pendingSyncTasks.Range(func(key, value interface{}) bool {
-				blockMgr.syncMut.Lock()
-				defer blockMgr.syncMut.Unlock()
-				hashs := value.(map[crypto.Hash]uint64)
-				timer := key.(*time.Timer)
-
-				if _, ok := hashs[*b.Header.Hash()]; ok {
-					delete(hashs, *b.Header.Hash())
-					if len(hashs) == 0 {
-						//all the blocks have arrived, stop the timeout timer
-						blockMgr.syncTimerCh <- timer
-					}
-
-					return false
-				}
-				return true
-			})
+		err := fetcher.importBlocks()
+		if err != nil {
+			return err
 		}
-
-		checkExist := func(h crypto.Hash) bool {
-			found := false
-			blockMgr.pendingSyncTasks.Range(func(key, value interface{}) bool {
-				blockMgr.syncMut.Lock()
-				defer blockMgr.syncMut.Unlock()
-				hashs := value.(map[crypto.Hash]uint64)
-
-				if _, ok := hashs[h]; ok {
-					found = true
-					return false
-				}
-				return true
-			})
-
-			return found
+		if headersDone && fetcher.done() {
+			log.WithField("height", blockMgr.ChainService.BestChain().Height()).Info("all block sync ok")
+			return nil
 		}
-
-		for {
-			select {
-			case blocks := <-blockMgr.blocksCh:
-				for _, b := range blocks {
-
-					if !checkExist(*b.Header.Hash()) {
-						continue
-					}
-
-					//log.WithField("height", b.Header.Height).WithField("blk num", len(blocks)).Info("sync block recv block")
-					_, _, err := blockMgr.ChainService.ProcessBlock(b)
-					if err != nil {
-						switch err {
-						case chain.ErrBlockExsist, chain.ErrOrphanBlockExsist:
-							//Delete the task corresponding to the block height
-							delHash(b)
-							continue
-
-						default:
-							log.WithField("Reason", err).Error("deal sync block")
-							errCh <- err
-							return
-						}
-					} else {
-						//Delete the task corresponding to the block height
-						delHash(b)
-					}
-				}
-
-			case <-quit:
-				log.Info("fetch blocks routine quit")
-				return
-			}
-		}
-	}()
-
-	//3Get the corresponding body
-	go func() {
-		for {
-			select {
-			case <-quit:
-				return
-			default:
-				//The request was sent too fast and had to wait
-				count := 0
-				blockMgr.pendingSyncTasks.Range(func(key, value interface{}) bool {
-					count++
-					if count >= pendingTimerCount {
-						return false
-					}
-					return true
-				})
-				if count >= pendingTimerCount {
-					log.Info("req block body routine wait.......")
-					time.Sleep(time.Millisecond * maxSyncSleepTime)
-					continue
-				}
-
-				blockMgr.syncMut.Lock()
-				hashs, headerHashs := blockMgr.allTasks.GetSortedHashs(maxBlockCountReq)
-				taskLen := blockMgr.allTasks.Len()
-				blockMgr.syncMut.Unlock()
-				if len(hashs) == 0 {
-					blockMgr.syncMut.Lock()
-					if headerRoutineExit && count == 0 {
-						//Task completed, trigger exit
-						log.WithField("tasks len", taskLen).Info("all block sync ok")
-						close(errCh)
-						blockMgr.syncMut.Unlock()
-						return
-					}
-					blockMgr.syncMut.Unlock()
-					time.Sleep(time.Millisecond * maxSyncSleepTime)
-					continue
-				}
-
-				reqTimer := time.NewTimer(time.Second * maxNetworkTimeout)
-				blockMgr.pendingSyncTasks.Store(reqTimer, headerHashs)
-
-				go func() {
-					defer reqTimer.Stop()
-
-					select {
-					case <-reqTimer.C:
-						//Get the hash and add it to allTasks
-						value, ok := blockMgr.pendingSyncTasks.Load(reqTimer)
-						if !ok {
-							errCh <- fmt.Errorf("timer not in pending task, exception")
-							return
-						}
-						hashs := value.(map[crypto.Hash]uint64)
-						for k, v := range hashs {
-							blockMgr.syncMut.Lock()
-
-							k := k
-							blockMgr.allTasks.Put(&syncHeaderHash{headerHash: &k, height: v})
-							blockMgr.syncMut.Unlock()
-						}
-
-						blockMgr.pendingSyncTasks.Delete(reqTimer)
-						log.Errorf("req block body time out time：%p\n", reqTimer)
-
-					case timer := <-blockMgr.syncTimerCh:
-						//timer.Stop()
-						//Stop the timer when all requests to the block have arrived
-						blockMgr.pendingSyncTasks.Delete(timer)
-					case <-quit:
-						log.Info("fetch block timer goroutine quit")
-						return
-					}
-				}()
-				blockMgr.batchReqBlocks(hashs, headerHashs, errCh)
-			}
+		err = fetcher.assign(time.Now())
+		if err != nil {
+			return err
 		}
-	}()
-
-	select {
-	case err := <-errCh:
-		close(quit)
-		return err
 	}
 }
 
@@ -549,3 +397,17 @@ func (blockMgr *BlockMgr) checkHeaderChain(chain []types.BlockHeader) error {
 	}
 	return nil
 }
+
+//status returns the status announced to the peers in the handshake of the block protocol
+func (blockMgr *BlockMgr) status() *types.Status {
+	bestChain := blockMgr.ChainService.BestChain()
+	genesis, tip := bestChain.Genesis(), bestChain.Tip()
+	return &types.Status{
+		ProtocolVersion: types.BlockProtocolVersion,
+		ChainId:         blockMgr.ChainService.ChainID(),
+		Genesis:         *genesis.Hash,
+		BestHash:        *tip.Hash,
+		Height:          tip.Height,
+		ForkID:          blockMgr.ChainService.GetConfig().ForkConfig.ForkID(genesis.Hash.Bytes(), tip.Height),
+	}
+}
@@ -1,66 +1,119 @@
 package blockmgr
 
 import (
-	"math/big"
-	"os"
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/chain/block"
 	"github.com/drep-project/DREP-Chain/common/event"
 	"github.com/drep-project/DREP-Chain/crypto"
-	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
 	"github.com/drep-project/DREP-Chain/network/p2p"
+	p2pService "github.com/drep-project/DREP-Chain/network/service"
 	"github.com/drep-project/DREP-Chain/types"
-	"gopkg.in/urfave/cli.v1"
 )
 
+var errMockTxRoot = errors.New("tx root mismatch")
+
+//p2pServiceMock records the penalties and passes the messages sent to a peerInfoMock on to the peer
 type p2pServiceMock struct {
-	app.Service
+	p2pService.P2P
+	faultRecorder
+	blockMgr *BlockMgr
+
+	lock sync.Mutex
+	sent map[uint64]int
 }
 
 func (ps *p2pServiceMock) SendAsync(w p2p.MsgWriter, msgType uint64, msg interface{}) chan error {
-	return nil
+	errCh := make(chan error, 1)
+	errCh <- ps.Send(w, msgType, msg)
+	return errCh
 }
+
 func (ps *p2pServiceMock) Send(w p2p.MsgWriter, msgType uint64, msg interface{}) error {
+	ps.lock.Lock()
+	ps.sent[msgType]++
+	ps.lock.Unlock()
+	if peer, ok := w.(*peerInfoMock); ok && !peer.silent {
+		go peer.answer(ps.blockMgr, msgType, msg)
+	}
 	return nil
 }
-func (ps *p2pServiceMock) Peers() []*p2p.Peer {
-	return nil
-}
-func (ps *p2pServiceMock) AddPeer(nodeUrl string) error {
-	return nil
-}
-func (ps *p2pServiceMock) RemovePeer(url string) {
-}
-func (ps *p2pServiceMock) AddProtocols(protocols []p2p.Protocol) {
-}
-func (ps *p2pServiceMock) Name() string {
-	return ""
-} // service  name must be unique
-func (ps *p2pServiceMock) Api() []app.API {
-	return nil
-} // Interfaces required for services
-func (ps *p2pServiceMock) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, nil
-}
-func (ps *p2pServiceMock) Init(executeContext *app.ExecuteContext) error {
-	return nil
-}
-func (ps *p2pServiceMock) Start(executeContext *app.ExecuteContext) error {
-	return nil
-}
-func (ps *p2pServiceMock) Stop(executeContext *app.ExecuteContext) error {
-	return nil
+
+func (ps *p2pServiceMock) sentCount(msgType uint64) int {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	return ps.sent[msgType]
 }
 
+//peerInfoMock is a remote peer serving its chain, the requests sent to it are answered from the chain
 type peerInfoMock struct {
+	p2p.MsgReadWriter
+	peer   *p2p.Peer
+	addr   string
 	height uint64
+	blocks []*types.Block          //the chain of the peer by height
+	bodies map[uint64]*types.Block //blocks answered instead of the ones of the chain
+	silent bool                    //requests are not answered
+	served int32                   //block requests answered
+	knownTxSet
+}
+
+func newPeerInfoMock(id byte, blocks []*types.Block) *peerInfoMock {
+	return &peerInfoMock{
+		peer:   new(p2p.Peer),
+		addr:   fmt.Sprintf("127.0.0.%d", id),
+		height: uint64(len(blocks) - 1),
+		blocks: blocks,
+		bodies: make(map[uint64]*types.Block),
+	}
+}
+
+func (p *peerInfoMock) answer(blockMgr *BlockMgr, msgType uint64, msg interface{}) {
+	switch msgType {
+	case types.MsgTypeHeaderReq:
+		req := msg.(*types.HeaderReq)
+		var headers []types.BlockHeader
+		for height := req.FromHeight; height <= req.ToHeight && height < uint64(len(p.blocks)); height++ {
+			headers = append(headers, *p.blocks[height].Header)
+		}
+		blockMgr.handleHeaderRsp(p, &types.HeaderRsp{Headers: headers})
+	case types.MsgTypeBlockReq:
+		req := msg.(*types.BlockReq)
+		var blocks []*types.Block
+		for _, blk := range p.blocks {
+			if blk.Header.Hash().IsEqual(&req.BlockHashs[0]) {
+				blocks = append(blocks, blk)
+			} else if len(blocks) > 0 {
+				blocks = append(blocks, blk)
+			}
+			if blk.Header.Hash().IsEqual(&req.BlockHashs[1]) {
+				break
+			}
+		}
+		for i, blk := range blocks {
+			if body, ok := p.bodies[blk.Header.Height]; ok {
+				blocks[i] = body
+			}
+		}
+		atomic.AddInt32(&p.served, 1)
+		blockMgr.HandleBlockRespMsg(p, &types.BlockResp{Blocks: blocks})
+	}
+}
+
+func (p *peerInfoMock) GetPeer() *p2p.Peer {
+	return p.peer
 }
 
 func (p *peerInfoMock) GetMsgRW() p2p.MsgReadWriter {
-	return nil
+	return p
 }
 
 func (p *peerInfoMock) GetHeight() uint64 {
@@ -68,214 +121,209 @@ func (p *peerInfoMock) GetHeight() uint64 {
 }
 
 func (p *peerInfoMock) GetAddr() string {
-	return "127.0.0.1"
+	return p.addr
 }
 
 func (p *peerInfoMock) SetHeight(height uint64) {
 	p.height = height
-}
-func (p *peerInfoMock) KnownTx(tx *types.Transaction) bool {
-	return true
-}
-func (p *peerInfoMock) MarkTx(tx *types.Transaction) {
-
 }
 func (p *peerInfoMock) KnownBlock(blk *types.Block) bool {
 	return true
 }
 func (p *peerInfoMock) MarkBlock(blk *types.Block) {}
 
-//var pi types.PeerInfoInterface = &peerInfoMock{}
+func (p *peerInfoMock) SetReqTime(t time.Time) {}
 
-type chainServiceMock struct {
-}
+func (p *peerInfoMock) CalcAverageRtt() {}
 
-func (ps *chainServiceMock) Name() string {
-	return ""
-} // service  name must be unique
-func (ps *chainServiceMock) Api() []app.API {
-	return nil
-} // Interfaces required for services
-func (ps *chainServiceMock) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, nil
-}
-func (ps *chainServiceMock) Init(executeContext *app.ExecuteContext) error {
-	return nil
-}
-func (ps *chainServiceMock) Start(executeContext *app.ExecuteContext) error {
-	return nil
-}
-func (ps *chainServiceMock) Stop(executeContext *app.ExecuteContext) error {
-	return nil
+func (p *peerInfoMock) AverageRtt() time.Duration {
+	return 0
 }
 
-func (ps *chainServiceMock) ChainID() app.ChainIdType {
-	return [64]byte{}
-}
+var _ types.PeerInfoInterface = &peerInfoMock{}
 
-func (ps *chainServiceMock) DeriveMerkleRoot(txs []*types.Transaction) []byte {
-	return nil
-}
-func (ps *chainServiceMock) GetBlockByHash(hash *crypto.Hash) (*types.Block, error) {
-	return nil, nil
-}
-func (ps *chainServiceMock) GetBlockByHeight(number uint64) (*types.Block, error) {
-	return nil, nil
-}
+//chainServiceMock keeps a chain of blocks in memory, a block is valid if it follows the tip and matches its tx root
+type chainServiceMock struct {
+	chain.ChainServiceInterface
 
-//DefaultChainConfig
-func (ps *chainServiceMock) GetBlockHeaderByHash(hash *crypto.Hash) (*types.BlockHeader, error) {
-	return nil, nil
-}
-func (ps *chainServiceMock) GetBlockHeaderByHeight(number uint64) (*types.BlockHeader, error) {
-	return nil, nil
+	lock      sync.Mutex
+	index     *block.BlockIndex
+	bestChain *chain.ChainView
 }
 
-func (ps *chainServiceMock) GetCurrentState() *database.Database {
-	return nil
-}
-func (ps *chainServiceMock) GetHeader(hash crypto.Hash, number uint64) *types.BlockHeader {
-	return nil
-}
-func (ps *chainServiceMock) GetHighestBlock() (*types.Block, error) {
-	return nil, nil
-}
-func (ps *chainServiceMock) RootChain() app.ChainIdType {
-	return [64]byte{}
+func newChainServiceMock(blocks []*types.Block) *chainServiceMock {
+	index := block.NewBlockIndex()
+	var tip *types.BlockNode
+	for _, blk := range blocks {
+		tip = types.NewBlockNode(blk.Header, tip)
+		index.AddNode(tip)
+	}
+	return &chainServiceMock{
+		index:     index,
+		bestChain: chain.NewChainView(tip),
+	}
 }
 
-func (ps *chainServiceMock) BestChain() *chain.ChainView {
-	cv := &chain.ChainView{}
-	return cv
-}
-func (ps *chainServiceMock) CalcGasLimit(parent *types.BlockHeader, gasFloor, gasCeil uint64) *big.Int {
-	return nil
+func deriveMockMerkleRoot(txs []*types.Transaction) []byte {
+	var data []byte
+	for _, tx := range txs {
+		data = append(data, tx.TxHash().Bytes()...)
+	}
+	return sha3.Keccak256(data)
+}
+
+//linkedBlocks returns a genesis block followed by count linked blocks without transactions
+func linkedBlocks(count int) []*types.Block {
+	blks := make([]*types.Block, 0, count+1)
+	prev := crypto.Hash{}
+	for i := 0; i <= count; i++ {
+		block := &types.Block{
+			Header: &types.BlockHeader{
+				PreviousHash: prev,
+				Height:       uint64(i),
+				Timestamp:    uint64(1000 + i),
+				StateRoot:    []byte{byte(i)},
+				TxRoot:       deriveMockMerkleRoot(nil),
+			},
+			Data: &types.BlockData{},
+		}
+		prev = *block.Header.Hash()
+		blks = append(blks, block)
+	}
+	return blks
 }
 
-func (ps *chainServiceMock) ProcessBlock(block *types.Block) (bool, bool, error) {
-	return true, true, nil
+func (cs *chainServiceMock) DeriveMerkleRoot(txs []*types.Transaction) []byte {
+	return deriveMockMerkleRoot(txs)
 }
 
-func (ps *chainServiceMock) NewBlockFeed() *event.Feed {
-	return nil
-}
-func (ps *chainServiceMock) BlockExists(blockHash *crypto.Hash) bool {
-	return true
-}
-func (ps *chainServiceMock) TransactionValidator() chain.ITransactionValidator {
-	return nil
-}
-func (ps *chainServiceMock) GetDatabaseService() *database.DatabaseService {
-	return nil
-}
-func (ps *chainServiceMock) Index() *chain.BlockIndex {
-	return nil
-}
-func (ps *chainServiceMock) BlockValidator() chain.IBlockValidator {
-	return nil
+func (cs *chainServiceMock) DeriveReceiptRoot(receipts []*types.Receipt) crypto.Hash {
+	return crypto.Hash{}
 }
-func (ps *chainServiceMock) Config() *types.ChainConfig {
-	return nil
+
+func (cs *chainServiceMock) BestChain() *chain.ChainView {
+	return cs.bestChain
 }
-func (ps *chainServiceMock) AccumulateRewards(db *database.Database, b *types.Block, totalGasBalance *big.Int) error {
-	return nil
+
+func (cs *chainServiceMock) Index() *block.BlockIndex {
+	return cs.index
 }
-func (ps *chainServiceMock) DetachBlockFeed() *event.Feed {
+
+func (cs *chainServiceMock) BlockValidator() chain.BlockValidators {
 	return nil
 }
 
-//var bm *BlockMgr
+func (cs *chainServiceMock) ProcessBlock(blk *types.Block) (bool, bool, error) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
 
-func prepareBase(t *testing.T) (*BlockMgr, []*types.Block) {
-	db, err := database.NewDatabase("./test/")
-	if err != nil {
-		t.Fatal(err)
+	if cs.index.HaveBlock(blk.Header.Hash()) {
+		return false, false, chain.ErrBlockExsist
 	}
-	blks := generatorChain(t, db)
-
-	ds := database.NewDatabaseService(db)
-	cs := chain.NewChainService(chain.DefaultChainConfig, ds)
-
-	bm := NewBlockMgr(DefaultChainConfig, "./", cs, &p2pServiceMock{})
+	tip := cs.bestChain.Tip()
+	if !blk.Header.PreviousHash.IsEqual(tip.Hash) {
+		return false, true, nil
+	}
+	if !bytes.Equal(deriveMockMerkleRoot(blk.Data.TxList), blk.Header.TxRoot) {
+		return false, false, &chain.InvalidBlockError{Height: blk.Header.Height, Hash: *blk.Header.Hash(), Err: errMockTxRoot}
+	}
+	node := types.NewBlockNode(blk.Header, tip)
+	cs.index.AddNode(node)
+	cs.bestChain.SetTip(node)
+	return true, false, nil
+}
 
-	return bm, blks
+func newTestBlockMgr(cs chain.ChainServiceInterface) (*BlockMgr, *p2pServiceMock) {
+	p2pServer := &p2pServiceMock{
+		sent: make(map[uint64]int),
+	}
+	blockMgr := &BlockMgr{
+		ChainService: cs,
+		P2pServer:    p2pServer,
+		Config:       DefaultChainConfig,
+		headerHashCh: make(chan []*syncHeaderHash),
+		blocksCh:     make(chan *blockPack),
+		nodeDataCh:   make(chan *nodeDataPack),
+		nodeDataReqs: newReqLimiter(maxNodeDataReqInFlight),
+		txFetcher:    newTxFetcher(),
+		txReqs:       newReqLimiter(maxTxReqInFlight),
+		txAnnouncer:  newTxAnnouncer(),
+		state:        event.StopSyncBlock,
+		quit:         make(chan struct{}),
+	}
+	p2pServer.blockMgr = blockMgr
+	return blockMgr, p2pServer
 }
 
 func TestFindAncestor(t *testing.T) {
-	bm, blks := prepareBase(t)
-
-	peerInfo := &peerInfoMock{}
-	headerHashs := []*syncHeaderHash{}
-
-	blks = blks[9:]
-	for _, b := range blks {
-		headerHashs = append(headerHashs, &syncHeaderHash{headerHash: b.Header.Hash(), height: b.Header.Height})
+	blks := linkedBlocks(10)
+	for _, local := range []int{10, 4} {
+		bm, _ := newTestBlockMgr(newChainServiceMock(blks[:local+1]))
+		ancestor, err := bm.findAncestor(newPeerInfoMock(1, blks))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ancestor != uint64(local) {
+			t.Fatalf("expect the ancestor %d, got %d", local, ancestor)
+		}
 	}
+}
 
-	//time out
-	ancestor, err := bm.findAncestor(peerInfo)
-	if err == nil {
-		t.Fatal(err)
+func TestFetchBlocks(t *testing.T) {
+	blks := linkedBlocks(100)
+	cs := newChainServiceMock(blks[:4])
+	bm, _ := newTestBlockMgr(cs)
+
+	//the skeleton comes from the first peer, the blocks from all the peers high enough to serve them
+	peers := []*peerInfoMock{newPeerInfoMock(1, blks), newPeerInfoMock(2, blks), newPeerInfoMock(3, blks[:3])}
+	for _, peer := range peers {
+		bm.peersInfo.Store(peer.GetAddr(), peer)
 	}
-
-	go func() {
-		bm.headerHashCh <- headerHashs
-	}()
-	ancestor, err = bm.findAncestor(peerInfo)
+	err := bm.fetchBlocks(peers[0])
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	if ancestor != 10 {
-		t.Fatal("get ancestor err", "need:", 0, "ancestor：", ancestor)
+	if cs.BestChain().Height() != 100 || !cs.BestChain().Tip().Hash.IsEqual(blks[100].Header.Hash()) {
+		t.Fatalf("expect the chain synchronized to 100, got %d", cs.BestChain().Height())
+	}
+	if atomic.LoadInt32(&peers[0].served) == 0 || atomic.LoadInt32(&peers[1].served) == 0 {
+		t.Fatal("expect the blocks requested from both peers at the tip")
+	}
+	if served := atomic.LoadInt32(&peers[2].served); served != 0 {
+		t.Fatalf("expect no block requested from the peer below the local chain, got %d requests", served)
 	}
-
-	os.RemoveAll("./test")
 }
 
-func TestFetchBlocks(t *testing.T) {
-
-	defer os.RemoveAll("./test")
+func TestFetchBlocksSyncEvents(t *testing.T) {
+	blks := linkedBlocks(10)
+	cs := newChainServiceMock(blks[:4])
+	bm, _ := newTestBlockMgr(cs)
+	peer := newPeerInfoMock(1, blks)
+	bm.peersInfo.Store(peer.GetAddr(), peer)
 
-	peer := &peerInfoMock{}
-	headerHashs1 := []*syncHeaderHash{}
-	headerHashs2 := []*syncHeaderHash{}
+	events := make(chan event.SyncBlockEvent, 16)
+	sub := bm.SubscribeSyncBlockEvent(events)
+	defer sub.Unsubscribe()
 
-	bm, blks := prepareBase(t)
-
-	blks1 := blks[1:2]
-	for _, b := range blks1 {
-		headerHashs1 = append(headerHashs1, &syncHeaderHash{headerHash: b.Header.Hash(), height: b.Header.Height})
+	//a synchronization already running is neither notified nor stopped again
+	bm.state = event.StartSyncBlock
+	if err := bm.fetchBlocks(peer); err != nil {
+		t.Fatal(err)
 	}
-
-	go func() {
-		time.Sleep(time.Second * 4)
-		bm.headerHashCh <- headerHashs1
-	}()
-
-	//fake header hash
-	blks2 := blks[2:4]
-	for _, b := range blks2 {
-		headerHashs2 = append(headerHashs2, &syncHeaderHash{headerHash: b.Header.Hash(), height: b.Header.Height})
+	if len(events) != 0 || bm.state != event.StartSyncBlock {
+		t.Fatalf("expect no event for a synchronization already running, got %d", len(events))
 	}
 
-	go func() {
-		time.Sleep(time.Second * 8)
-		bm.headerHashCh <- headerHashs2
-	}()
-
-	//fake block body
-	go func() {
-		time.Sleep(time.Second * 12)
-		bm.blocksCh <- blks[2:4]
-	}()
-
-	peer.height = 4
-	bm.peersInfo["127.0.0.1"] = peer
-	err := bm.fetchBlocks(peer)
-	if err != nil {
+	bm.state = event.StopSyncBlock
+	if err := bm.fetchBlocks(peer); err != nil {
 		t.Fatal(err)
 	}
+	if len(events) != 2 || (<-events).EventType != event.StartSyncBlock || (<-events).EventType != event.StopSyncBlock {
+		t.Fatal("expect the synchronization started and stopped once")
+	}
+	if bm.SyncStatus() != nil {
+		t.Fatalf("expect the node in sync once stopped, got %v", bm.SyncStatus())
+	}
 }
 
 func TestClearSyncCh(t *testing.T) {
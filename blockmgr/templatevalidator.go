@@ -83,8 +83,8 @@ SELECT_TX:
 				context.Gp = &backGp
 				return nil
 			} else {
-				from, _ := t.From()
-				log.WithField("err", err).WithField("from", from.String()).WithField("tx nonce", t.Nonce()).Info("route tx")
+				from, _ := t.ClaimedFrom()
+				log.WithField("err", err).WithField("from", from).WithField("tx nonce", t.Nonce()).Info("route tx")
 				//skip wrong tx
 				context.TrieStore.RevertState(snap)
 				context.Gp = &backGp
@@ -97,7 +97,11 @@ SELECT_TX:
 }
 
 func (chainBlockValidator *TemplateBlockValidator) RouteTransaction(context *block.BlockExecuteContext, gasPool *utils.GasPool, tx *types.Transaction) (*types.Receipt, uint64, error) {
-	//init transaction tx
+	//init transaction tx, the sender of a multisig transaction is known once its proof is verified
+	err := transactions.VerifyMultiSig(tx, context.TrieStore, context.Rules)
+	if err != nil {
+		return nil, 0, err
+	}
 	from, err := tx.From()
 	if err != nil {
 		return nil, 0, err
@@ -109,7 +113,7 @@ func (chainBlockValidator *TemplateBlockValidator) RouteTransaction(context *blo
 	}
 
 	// Pay intrinsic gastx
-	gas, err := tx.IntrinsicGas()
+	gas, err := transactions.IntrinsicGas(tx)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -139,43 +143,11 @@ func (chainBlockValidator *TemplateBlockValidator) RouteTransaction(context *blo
 	}
 	// Set the receipt logs and create a bloom for filtering
 	receipt.Logs = ret.ContractTxLog
+	if sponsorLog := txContext.SponsorLog(); sponsorLog != nil {
+		receipt.Logs = append(receipt.Logs, sponsorLog)
+	}
 	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
 	//receipt.BlockHash = *header.Hash()
 	receipt.BlockNumber = context.Block.Header.Height
 	return receipt, txContext.GasUsed(), nil
-
-	//exit := false
-	//for selector, txValidator := range chainBlockValidator.chain.TransactionValidators() {
-	//	if selector.Select(tx) {
-	//		exit = true
-	//		ret := txValidator.ExecuteTransaction(txContext)
-	//		if ret.Txerror != nil {
-	//			return nil, 0, ret.Txerror
-	//		}
-	//		err = txContext.RefundCoin()
-	//		if err != nil {
-	//			return nil, 0, err
-	//		}
-	//		// Create a new receipt for the transaction, storing the intermediate root and gasRemained used by the tx
-	//		// based on the eip phase, we're passing whether the root touch-delete accounts.
-	//		receipt := types.NewReceipt(crypto.ZeroHash[:], ret.ContractTxExecuteFail, txContext.GasUsed())
-	//		receipt.TxHash = *tx.TxHash()
-	//		receipt.GasUsed = txContext.GasUsed()
-	//		// if the transaction created a contract, store the creation address in the receipt.
-	//		if (tx.To() == nil || tx.To().IsEmpty()) && tx.Type() == types.CreateContractType {
-	//			receipt.ContractAddress = crypto.CreateAddress(*from, tx.Nonce())
-	//			log.WithField("contractAddr:", receipt.ContractAddress).Info("execute contract")
-	//		}
-	//		// Set the receipt logs and create a bloom for filtering
-	//		receipt.Logs = ret.ContractTxLog
-	//		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
-	//		//receipt.BlockHash = *header.Hash()
-	//		receipt.BlockNumber = context.Block.Header.Height
-	//		return receipt, txContext.GasUsed(), nil
-	//	}
-	//}
-	//if !exit {
-	//	return nil, 0, chain.ErrUnsupportTxType
-	//}
-	panic("never come here")
 }
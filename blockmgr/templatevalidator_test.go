@@ -0,0 +1,134 @@
+package blockmgr
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/chain/block"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/chain/transactions"
+	"github.com/drep-project/DREP-Chain/chain/utils"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const storageTestType = types.TxType(201)
+
+var (
+	errStorageTest      = errors.New("storage test failure")
+	storageTestContract = crypto.CommonAddress{0xc0}
+	storageTestSlot     = []byte("slot")
+)
+
+//storageTestExecutor writes the data of the transaction to a slot of the contract and fails after the write
+//when the amount of the transaction is zero
+type storageTestExecutor struct{}
+
+func (executor *storageTestExecutor) ExecuteTransaction(context *transactions.ExecuteTransactionContext) *types.ExecuteTransactionResult {
+	err := context.TrieStore().PutState(&storageTestContract, storageTestSlot, context.Data())
+	if err == nil && context.Value().Sign() == 0 {
+		err = errStorageTest
+	}
+	return &types.ExecuteTransactionResult{Txerror: err}
+}
+
+//templateChainMock derives the roots of the template, the template validator needs nothing else from the chain
+type templateChainMock struct {
+	chain.ChainServiceInterface
+}
+
+func (cs *templateChainMock) DeriveMerkleRoot(txs []*types.Transaction) []byte {
+	return nil
+}
+
+func (cs *templateChainMock) DeriveReceiptRoot(receipts []*types.Receipt) crypto.Hash {
+	return crypto.Hash{}
+}
+
+func init() {
+	transactions.RegisterTransactionType(storageTestType, &transactions.TransactionType{
+		Name:     "storage test",
+		Executor: &storageTestExecutor{},
+	})
+}
+
+func newStorageTestTx(t *testing.T, key *secp256k1.PrivateKey, nonce uint64, amount int64, data byte) *types.Transaction {
+	tx := types.NewTransaction(storageTestContract, big.NewInt(amount), big.NewInt(1), big.NewInt(100000), nonce)
+	tx.Data.Type = storageTestType
+	tx.Data.Data = []byte{data}
+	sig, err := secp256k1.SignCompact(key, tx.TxHash().Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sig = sig
+	return tx
+}
+
+//executeTemplate runs the transactions through the template validator and returns the included ones and the
+//flushed state
+func executeTemplate(t *testing.T, from *crypto.CommonAddress, txs ...*types.Transaction) ([]*types.Transaction, store.StoreInterface) {
+	db := memorydb.New()
+	changeInterval := make([]byte, 8)
+	binary.BigEndian.PutUint64(changeInterval, 100)
+	db.Put([]byte(store.ChangeInterval), changeInterval)
+	trieStore, err := store.TrieStoreFromStore(db, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	trieStore.PutBalance(from, 0, big.NewInt(10000000))
+
+	blk := &types.Block{
+		Header: &types.BlockHeader{Height: 1, GasLimit: *big.NewInt(10000000)},
+		Data:   &types.BlockData{TxCount: uint64(len(txs)), TxList: txs},
+	}
+	gp := new(utils.GasPool).AddGas(blk.Header.GasLimit.Uint64())
+	context := block.NewBlockExecuteContext(trieStore, gp, nil, blk, (&params.ForkConfig{}).Rules(1))
+	validator := NewTemplateBlockValidator(&templateChainMock{})
+	if err := validator.ExecuteBlock(context, 10); err != nil {
+		t.Fatal(err)
+	}
+	trieStore.GetStateRoot()
+	return blk.Data.TxList, trieStore
+}
+
+func TestTemplateRevertsContractStorage(t *testing.T) {
+	key, err := secp256k1.GeneratePrivateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PubKey())
+	succeed := newStorageTestTx(t, key, 0, 1, 1)
+	fail := newStorageTestTx(t, key, 1, 0, 2)
+
+	//the storage is opened by the reverted transaction
+	included, trieStore := executeTemplate(t, &from, newStorageTestTx(t, key, 0, 0, 2))
+	if len(included) != 0 {
+		t.Fatalf("expect the failed tx skipped, got %d txs", len(included))
+	}
+	if root, err := trieStore.GetStorageRoot(&storageTestContract); err != nil || root != trie.EmptyRoot {
+		t.Fatalf("expect the storage write of the skipped tx reverted, got root %v %v", root, err)
+	}
+
+	//the storage is opened before the snapshot of the reverted transaction
+	included, trieStore = executeTemplate(t, &from, succeed, fail)
+	if len(included) != 1 || included[0] != succeed {
+		t.Fatalf("expect the first tx only, got %d txs", len(included))
+	}
+	value, err := trieStore.GetState(&storageTestContract, storageTestSlot)
+	if err != nil || !bytes.Equal(value, []byte{1}) {
+		t.Fatalf("expect the value of the included tx, got %v %v", value, err)
+	}
+	_, expect := executeTemplate(t, &from, succeed)
+	if !bytes.Equal(trieStore.GetStateRoot(), expect.GetStateRoot()) {
+		t.Fatal("expect the state root of the block without the skipped tx")
+	}
+}
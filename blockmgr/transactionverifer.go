@@ -1,10 +1,12 @@
 package blockmgr
 
 import (
-	"fmt"
+	"math/big"
+	"time"
 
-	"github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/chain/transactions"
+	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
@@ -25,20 +27,46 @@ func (blockMgr *BlockMgr) verifyTransaction(tx *types.Transaction) error {
 	}
 
 	//Transactor should have enough funds to cover the costs
-	trieStore, err := store.TrieStoreFromStore(blockMgr.DatabaseService.LevelDb(), blockMgr.ChainService.BestChain().Tip().StateRoot)
+	trieStore, err := store.TrieStoreFromDatabase(blockMgr.DatabaseService.LevelDb(), blockMgr.DatabaseService.StateDb(), blockMgr.ChainService.BestChain().Tip().StateRoot)
 	if err != nil {
 		log.WithField("err", err).Trace("verifyTransaction")
 		return err
 	}
 
+	//the sender of a multisig transaction is known once its proof is verified
+	rules := blockMgr.ChainService.GetConfig().Rules(tip.Height + 1)
+	err = transactions.VerifyMultiSig(tx, trieStore, rules)
+	if err != nil {
+		return err
+	}
 	from, err := tx.From()
+	if err != nil {
+		return err
+	}
+	payer, err := tx.Payer()
+	if err != nil {
+		return err
+	}
+	//the amount of a batch transfer is the sum of its payouts, so the cost covers all of them
 	originBalance := trieStore.GetBalance(from, blockMgr.ChainService.BestChain().Height())
-	if originBalance.Cmp(tx.Cost()) < 0 {
-		return ErrBalance
+	if *payer == *from {
+		if originBalance.Cmp(tx.Cost()) < 0 {
+			return ErrBalance
+		}
+	} else {
+		//the sponsor pays the gas, the sender the amount
+		if originBalance.Cmp(tx.Amount()) < 0 {
+			return ErrBalance
+		}
+		//the sponsor also pays for its transactions waiting in the pool
+		gasCost := sponsoredGas(tx, from, blockMgr.transactionPool.Sponsored(payer))
+		if trieStore.GetBalance(payer, blockMgr.ChainService.BestChain().Height()).Cmp(gasCost) < 0 {
+			return transactions.ErrSponsorBalance
+		}
 	}
 
 	// Should supply enough intrinsic gas
-	gas, err := tx.IntrinsicGas()
+	gas, err := transactions.IntrinsicGas(tx)
 	if err != nil {
 		return err
 	}
@@ -47,63 +75,33 @@ func (blockMgr *BlockMgr) verifyTransaction(tx *types.Transaction) error {
 		return ErrReachGasLimit
 	}
 
-	return blockMgr.checkByTxType(tx)
-}
-
-func (blockMgr *BlockMgr) checkByTxType(tx *types.Transaction) error {
-	from, _ := tx.From()
-	trieQuery, err := chain.NewTrieQuery(blockMgr.DatabaseService.LevelDb(),
-		blockMgr.ChainService.BestChain().Tip().StateRoot)
+	// The type must be active in the next block and admit the transaction against the tip state
+	err = transactions.ValidateTransaction(tx, rules)
 	if err != nil {
 		return err
 	}
-	switch tx.Type() {
-	case types.SetAliasType:
-		newAlias := tx.GetData()
-		if newAlias == nil {
-			return chain.ErrUnsupportAliasChar
-		}
-
-		trieStore, err := store.TrieStoreFromStore(blockMgr.DatabaseService.LevelDb(),
-			blockMgr.ChainService.BestChain().Tip().StateRoot)
-		if err != nil {
-			log.WithField("err", err).Trace("check byt tx type")
-			return err
-		}
-		drepFee, err := types.CheckAlias(newAlias)
-		if err != nil {
-			return err
-		}
-		balBefore := trieStore.GetBalance(from, blockMgr.ChainService.BestChain().Height())
-		balAfter := balBefore.Sub(balBefore, drepFee)
-		if balAfter.Sign() < 0 {
-			return chain.ErrBalance
-		}
-		alias := trieQuery.GetStorageAlias(from)
-		if alias != "" {
-			return ErrNotSupportRenameAlias
-		}
-		return nil
-	case types.CancelCandidateType, types.CancelVoteCreditType:
-		if err = trieQuery.CheckCancelCandidateType(tx); err != nil {
-			return err
-		}
-		return nil
-	//case types.CandidateType:
-	//	if from.String() == tx.To().String() {
-	//		return fmt.Errorf("from euqal to addr")
-	//	}
-	//	return nil
+	err = transactions.VerifySponsor(tx, rules)
+	if err != nil {
+		return err
+	}
+	err = transactions.VerifyValidity(tx, blockMgr.ChainService.ChainID(), tip.Height+1, time.Now().Unix(), rules)
+	if err != nil {
+		return err
+	}
+	return transactions.CheckPool(tx, from, blockMgr.transactionPool.Sent(from), trieStore, blockMgr.ChainService.BestChain().Height())
+}
 
-	case types.VoteCreditType:
-		if from.String() == tx.To().String() {
-			return fmt.Errorf("from euqal to addr")
+//sponsoredGas returns the gas a sponsor pays for tx from the sender from and for pooled, the transactions of the pool
+//it sponsors. A pooled transaction replaced by tx is not counted
+func sponsoredGas(tx *types.Transaction, from *crypto.CommonAddress, pooled []*types.Transaction) *big.Int {
+	gasCost := new(big.Int).Sub(tx.Cost(), tx.Amount())
+	for _, pooledTx := range pooled {
+		if pooledTx.Nonce() == tx.Nonce() {
+			if sender, err := pooledTx.From(); err == nil && *sender == *from {
+				continue
+			}
 		}
-		return nil
-	case types.TransferType, types.CreateContractType,
-		types.CallContractType, types.CandidateType:
-		return nil
+		gasCost.Add(gasCost, new(big.Int).Sub(pooledTx.Cost(), pooledTx.Amount()))
 	}
-
-	return fmt.Errorf("checkByTxType err type:%d", tx.Type())
+	return gasCost
 }
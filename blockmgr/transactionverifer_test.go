@@ -0,0 +1,60 @@
+package blockmgr
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func newSponsoredTestTx(t *testing.T, sender, sponsor *secp256k1.PrivateKey, nonce uint64, price int64) *types.Transaction {
+	tx := types.NewTransaction(crypto.CommonAddress{9}, big.NewInt(10), big.NewInt(price), big.NewInt(100), nonce)
+	sig, err := secp256k1.SignCompact(sender, tx.TxHash().Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.SetSenderSig(sig)
+	from := crypto.PubkeyToAddress(sender.PubKey())
+	sig, err = secp256k1.SignCompact(sponsor, tx.SponsorHash(&from), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.SetSponsorSig(sig)
+	return tx
+}
+
+func TestSponsoredGas(t *testing.T) {
+	var keys [3]*secp256k1.PrivateKey
+	for i := range keys {
+		key, err := secp256k1.GeneratePrivateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+	sender, other, sponsor := keys[0], keys[1], keys[2]
+	from := crypto.PubkeyToAddress(sender.PubKey())
+
+	tx := newSponsoredTestTx(t, sender, sponsor, 1, 1)
+	if gas := sponsoredGas(tx, &from, nil); gas.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("expect the gas of the tx, got %v", gas)
+	}
+
+	//the pooled transactions of every sender add up
+	pooled := []*types.Transaction{
+		newSponsoredTestTx(t, sender, sponsor, 0, 2),
+		newSponsoredTestTx(t, other, sponsor, 1, 3),
+	}
+	if gas := sponsoredGas(tx, &from, pooled); gas.Cmp(big.NewInt(600)) != 0 {
+		t.Fatalf("expect the gas of the pooled txs added, got %v", gas)
+	}
+
+	//a pooled transaction replaced by tx is paid once
+	pooled = append(pooled, newSponsoredTestTx(t, sender, sponsor, 1, 4))
+	if gas := sponsoredGas(tx, &from, pooled); gas.Cmp(big.NewInt(600)) != 0 {
+		t.Fatalf("expect the replaced tx not counted, got %v", gas)
+	}
+}
@@ -0,0 +1,121 @@
+package blockmgr
+
+import (
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+//txRequest is an announced transaction requested from a peer
+type txRequest struct {
+	peer types.PeerInfoInterface
+	sent time.Time
+}
+
+//txFetcher keeps track of the announced transactions requested from the peers. A transaction is only requested
+//from one peer at a time and each peer has a bounded number of requested transactions not delivered yet, a
+//transaction not delivered in time can be requested again from the next peer announcing it.
+type txFetcher struct {
+	lock      sync.Mutex
+	requested map[crypto.Hash]*txRequest
+	inFlight  map[types.PeerInfoInterface]int //requested transactions not delivered yet, by peer
+}
+
+func newTxFetcher() *txFetcher {
+	return &txFetcher{
+		requested: make(map[crypto.Hash]*txRequest),
+		inFlight:  make(map[types.PeerInfoInterface]int),
+	}
+}
+
+//schedule returns the announced hashes to request from the peer, the ones already requested from another peer
+//are skipped and the peer is asked for no more than maxTxFetchPerPeer transactions at once
+func (fetcher *txFetcher) schedule(peer types.PeerInfoInterface, hashes []crypto.Hash, now time.Time) []crypto.Hash {
+	fetcher.lock.Lock()
+	defer fetcher.lock.Unlock()
+
+	fetcher.expire(now)
+	var fetch []crypto.Hash
+	for _, hash := range hashes {
+		if fetcher.inFlight[peer] >= maxTxFetchPerPeer {
+			break
+		}
+		if _, ok := fetcher.requested[hash]; ok {
+			continue
+		}
+		fetcher.requested[hash] = &txRequest{peer: peer, sent: now}
+		fetcher.inFlight[peer]++
+		fetch = append(fetch, hash)
+	}
+	return fetch
+}
+
+//deliver marks the transactions as delivered whichever peer sent them
+func (fetcher *txFetcher) deliver(txs []*types.Transaction) {
+	fetcher.lock.Lock()
+	defer fetcher.lock.Unlock()
+
+	for _, tx := range txs {
+		hash := *tx.TxHash()
+		if req, ok := fetcher.requested[hash]; ok {
+			fetcher.forget(hash, req)
+		}
+	}
+}
+
+//expire forgets the requests older than txFetchTimeout, a peer which does not answer frees its slots this way
+func (fetcher *txFetcher) expire(now time.Time) {
+	for hash, req := range fetcher.requested {
+		if now.Sub(req.sent) > time.Second*txFetchTimeout {
+			fetcher.forget(hash, req)
+		}
+	}
+}
+
+func (fetcher *txFetcher) forget(hash crypto.Hash, req *txRequest) {
+	delete(fetcher.requested, hash)
+	fetcher.inFlight[req.peer]--
+	if fetcher.inFlight[req.peer] <= 0 {
+		delete(fetcher.inFlight, req.peer)
+	}
+}
+
+//txAnnouncer batches the hashes of the transactions announced to each peer, so that a peer gets one announcement
+//for all the transactions broadcast in a txAnnounceInterval instead of one for each transaction
+type txAnnouncer struct {
+	lock    sync.Mutex
+	pending map[types.PeerInfoInterface][]crypto.Hash
+}
+
+func newTxAnnouncer() *txAnnouncer {
+	return &txAnnouncer{
+		pending: make(map[types.PeerInfoInterface][]crypto.Hash),
+	}
+}
+
+//queue adds the hash to the next announcement to the peer, the announcement is returned to be sent at once when it
+//is full
+func (announcer *txAnnouncer) queue(peer types.PeerInfoInterface, hash *crypto.Hash) []crypto.Hash {
+	announcer.lock.Lock()
+	defer announcer.lock.Unlock()
+
+	hashes := append(announcer.pending[peer], *hash)
+	if len(hashes) < maxTxAnnounceCount {
+		announcer.pending[peer] = hashes
+		return nil
+	}
+	delete(announcer.pending, peer)
+	return hashes
+}
+
+//flush returns the announcements of all the peers and starts new ones
+func (announcer *txAnnouncer) flush() map[types.PeerInfoInterface][]crypto.Hash {
+	announcer.lock.Lock()
+	defer announcer.lock.Unlock()
+
+	pending := announcer.pending
+	announcer.pending = make(map[types.PeerInfoInterface][]crypto.Hash)
+	return pending
+}
@@ -0,0 +1,156 @@
+package blockmgr
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+//knownTxSet keeps the transactions a peer mock knows
+type knownTxSet struct {
+	lock     sync.Mutex
+	knownTxs map[crypto.Hash]struct{}
+}
+
+func (set *knownTxSet) KnownTx(tx *types.Transaction) bool {
+	return set.KnownTxHash(tx.TxHash())
+}
+
+func (set *knownTxSet) MarkTx(tx *types.Transaction) {
+	set.MarkTxHash(tx.TxHash())
+}
+
+func (set *knownTxSet) KnownTxHash(hash *crypto.Hash) bool {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+	_, ok := set.knownTxs[*hash]
+	return ok
+}
+
+func (set *knownTxSet) MarkTxHash(hash *crypto.Hash) {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+	if set.knownTxs == nil {
+		set.knownTxs = make(map[crypto.Hash]struct{})
+	}
+	set.knownTxs[*hash] = struct{}{}
+}
+
+func newTestTxs(count int) []*types.Transaction {
+	txs := make([]*types.Transaction, 0, count)
+	for i := 0; i < count; i++ {
+		txs = append(txs, types.NewTransaction(crypto.CommonAddress{}, big.NewInt(1), big.NewInt(1), big.NewInt(1), uint64(i)))
+	}
+	return txs
+}
+
+func txHashes(txs []*types.Transaction) []crypto.Hash {
+	hashes := make([]crypto.Hash, 0, len(txs))
+	for _, tx := range txs {
+		hashes = append(hashes, *tx.TxHash())
+	}
+	return hashes
+}
+
+func TestTxFetcherSchedule(t *testing.T) {
+	fetcher := newTxFetcher()
+	peer, other := newPeerInfoMock(1, nil), newPeerInfoMock(2, nil)
+	hashes := txHashes(newTestTxs(maxTxFetchPerPeer + 10))
+	now := time.Now()
+
+	fetch := fetcher.schedule(peer, hashes[:10], now)
+	if len(fetch) != 10 {
+		t.Fatalf("expect the announced txs requested, got %d", len(fetch))
+	}
+	if fetch = fetcher.schedule(other, hashes[:10], now); len(fetch) != 0 {
+		t.Fatalf("expect no tx requested from two peers at once, got %d", len(fetch))
+	}
+	if fetch = fetcher.schedule(peer, hashes, now); len(fetch) != maxTxFetchPerPeer-10 {
+		t.Fatalf("expect %d txs in flight from a peer at most, got %d", maxTxFetchPerPeer, len(fetch)+10)
+	}
+}
+
+func TestTxFetcherDeliver(t *testing.T) {
+	fetcher := newTxFetcher()
+	peer, other := newPeerInfoMock(1, nil), newPeerInfoMock(2, nil)
+	txs := newTestTxs(4)
+	now := time.Now()
+
+	fetcher.schedule(peer, txHashes(txs), now)
+	fetcher.deliver(txs[:2])
+	if fetcher.inFlight[peer] != 2 || fetcher.requested[*txs[0].TxHash()] != nil {
+		t.Fatalf("expect the delivered txs forgotten, got %d in flight", fetcher.inFlight[peer])
+	}
+
+	//an undelivered tx is requested from the next peer announcing it once the request expired
+	if fetch := fetcher.schedule(other, txHashes(txs[2:]), now); len(fetch) != 0 {
+		t.Fatalf("expect no tx requested again before the time out, got %d", len(fetch))
+	}
+	later := now.Add(time.Second*txFetchTimeout + time.Second)
+	if fetch := fetcher.schedule(other, txHashes(txs[2:]), later); len(fetch) != 2 {
+		t.Fatalf("expect the expired txs requested from another peer, got %d", len(fetch))
+	}
+	if _, ok := fetcher.inFlight[peer]; ok {
+		t.Fatal("expect the slots of a peer not answering freed")
+	}
+}
+
+func TestTxAnnouncer(t *testing.T) {
+	announcer := newTxAnnouncer()
+	peer, other := newPeerInfoMock(1, nil), newPeerInfoMock(2, nil)
+	hashes := txHashes(newTestTxs(3))
+	for i := range hashes {
+		if full := announcer.queue(peer, &hashes[i]); full != nil {
+			t.Fatal("expect the hashes batched")
+		}
+	}
+	announcer.queue(other, &hashes[0])
+	pending := announcer.flush()
+	if len(pending[peer]) != 3 || len(pending[other]) != 1 {
+		t.Fatalf("expect one announcement per peer, got %d %d", len(pending[peer]), len(pending[other]))
+	}
+	if len(announcer.flush()) != 0 {
+		t.Fatal("expect the announcements sent once")
+	}
+
+	//a full announcement is sent at once
+	var full []crypto.Hash
+	for i := 0; i < maxTxAnnounceCount && full == nil; i++ {
+		full = announcer.queue(peer, &hashes[0])
+	}
+	if len(full) != maxTxAnnounceCount || len(announcer.flush()) != 0 {
+		t.Fatalf("expect a full announcement of %d hashes, got %d", maxTxAnnounceCount, len(full))
+	}
+}
+
+func TestBroadcastTxBatchesAnnouncements(t *testing.T) {
+	bm, p2pServer := newTestBlockMgr(newChainServiceMock(linkedBlocks(0)))
+	for id := byte(1); id <= 4; id++ {
+		peer := newPeerInfoMock(id, nil)
+		peer.silent = true
+		bm.peersInfo.Store(peer.GetAddr(), peer)
+	}
+	txs := newTestTxs(10)
+	for _, tx := range txs {
+		bm.BroadcastTx(types.MsgTypeTransaction, tx, true)
+	}
+	//each tx is sent to the square root of the peers and announced to the others
+	deadline := time.Now().Add(time.Second * 5)
+	for p2pServer.sentCount(types.MsgTypeTransaction) < 2*len(txs) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expect each tx sent to 2 peers, got %d sends", p2pServer.sentCount(types.MsgTypeTransaction))
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if p2pServer.sentCount(types.MsgTypeTxHashes) != 0 {
+		t.Fatal("expect the announcements batched")
+	}
+	bm.flushTxAnnounces()
+	if sent := p2pServer.sentCount(types.MsgTypeTxHashes); sent == 0 || sent > 4 {
+		t.Fatalf("expect one announcement per peer, got %d", sent)
+	}
+}
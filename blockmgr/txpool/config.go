@@ -0,0 +1,36 @@
+package txpool
+
+//TxPoolConfig limits the transactions kept by the pool, the limits do not apply to the transactions of local accounts
+type TxPoolConfig struct {
+	PriceLimit   uint64 `json:"priceLimit"`   //minimum gas price of the remote transactions accepted
+	AccountSlots uint64 `json:"accountSlots"` //executable transactions kept for a single account
+	AccountQueue uint64 `json:"accountQueue"` //transactions waiting for a nonce gap kept for a single account
+	GlobalSlots  uint64 `json:"globalSlots"`  //executable transactions kept for all the accounts
+	GlobalQueue  uint64 `json:"globalQueue"`  //transactions waiting for a nonce gap kept for all the accounts
+}
+
+//DefaultTxPoolConfig define default limits of the transaction pool
+var DefaultTxPoolConfig = TxPoolConfig{
+	PriceLimit:   1,
+	AccountSlots: maxTxsOfPending,
+	AccountQueue: maxTxsOfQueue,
+	GlobalSlots:  8192,
+	GlobalQueue:  2048,
+}
+
+//sanitize replaces the unset limits with the default ones
+func (config TxPoolConfig) sanitize() TxPoolConfig {
+	if config.AccountSlots == 0 {
+		config.AccountSlots = DefaultTxPoolConfig.AccountSlots
+	}
+	if config.AccountQueue == 0 {
+		config.AccountQueue = DefaultTxPoolConfig.AccountQueue
+	}
+	if config.GlobalSlots == 0 {
+		config.GlobalSlots = DefaultTxPoolConfig.GlobalSlots
+	}
+	if config.GlobalQueue == 0 {
+		config.GlobalQueue = DefaultTxPoolConfig.GlobalQueue
+	}
+	return config
+}
@@ -3,7 +3,10 @@ package txpool
 import "errors"
 
 var (
-	ErrQueueFull  = errors.New("queue full")
-	ErrTxExist    = errors.New("transaction exists")
-	ErrTxPoolFull = errors.New("transaction pool full")
+	ErrQueueFull   = errors.New("queue full")
+	ErrTxExist     = errors.New("transaction exists")
+	ErrTxPoolFull  = errors.New("transaction pool full")
+	ErrUnderpriced = errors.New("transaction underpriced")
+	ErrTxNotExist  = errors.New("transaction not in pool")
+	ErrNotLocalTx  = errors.New("transaction not sent by a local account")
 )
@@ -9,6 +9,8 @@ import (
 
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -22,7 +24,7 @@ func rotateTx(t *testing.T, txs map[crypto.CommonAddress][]*types.Transaction) {
 }
 
 func loadTx(t *testing.T, maxNonce uint64) {
-	j = newTxJournal("./txpool/txs")
+	j = newTxJournal(filepath.Join(os.TempDir(), "txpool/txs"))
 	err := j.load(func(txs []types.Transaction) []error {
 		if txs[len(txs)-1].Nonce() != maxNonce {
 			return []error{fmt.Errorf("maybe nonce lost,%d != %d", txs[len(txs)-1].Nonce(), maxNonce)}
@@ -39,7 +41,7 @@ var generateMaxNonce uint64 = 10
 
 func generateTxs() []*types.Transaction {
 	privKey, _ := crypto.GenerateKey(rand.Reader)
-	addr := crypto.PubKey2Address(privKey.PubKey())
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 
 	txs := make([]*types.Transaction, 0)
 
@@ -68,7 +70,7 @@ func TestLoadAndRotate(t *testing.T) {
 		txs := generateTxs()
 		privateKey, _ := crypto.GenerateKey(rand.Reader)
 		pubkey := privateKey.PubKey()
-		addr := crypto.PubKey2Address(pubkey)
+		addr := crypto.PubkeyToAddress(pubkey)
 		all[addr] = txs
 	}
 
@@ -79,7 +81,7 @@ var insertTxNum uint64 = 10
 
 func insertTx(t *testing.T) {
 	privKey, _ := crypto.GenerateKey(rand.Reader)
-	addr := crypto.PubKey2Address(privKey.PubKey())
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 
 	for i := generateMaxNonce; i <= generateMaxNonce+insertTxNum; i++ {
 		tx := types.NewTransaction(addr, new(big.Int).SetUint64(100000000), new(big.Int).SetUint64(100000000), new(big.Int).SetUint64(100000000), uint64(i))
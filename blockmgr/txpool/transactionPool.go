@@ -1,10 +1,11 @@
 package txpool
 
 import (
-	"container/heap"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,7 +17,6 @@ import (
 )
 
 const (
-	maxAllTxsCount  = 100000           //The total number of trades held in a trading pool
 	maxTxsOfQueue   = 5                //The maximum number of transactions in an out-of-order queue corresponding to a single address
 	maxTxsOfPending = 20               //The maximum number of transactions in an ordered queue corresponding to a single address
 	expireTimeTx    = 60 * 60 * 24 * 3 //The transaction is discarded if it is not packaged within three days
@@ -41,6 +41,10 @@ type TransactionPool struct {
 	pendingNonce     map[crypto.CommonAddress]uint64
 	eventNewBlockSub event.Subscription
 	newBlockChan     chan *types.ChainEvent
+	detachBlockSub   event.Subscription
+	detachBlockChan  chan *types.Block
+	tipRoot          func() []byte //state root of the current tip, read when blocks are detached
+	tipHeight        uint64        //height of the last block adjusted to, bounds the validity windows
 	quit             chan struct{}
 
 	//Provide pending transaction subscriptions
@@ -48,11 +52,27 @@ type TransactionPool struct {
 
 	journal *txJournal
 	locals  map[crypto.CommonAddress]struct{} //The address that the local node contains
+
+	config   TxPoolConfig
+	rejected uint64 //remote transactions refused as underpriced or for a full pool
+	evicted  uint64 //transactions dropped to keep the pool within its limits
+}
+
+//PoolStatus counts the executable transactions and the ones waiting for a nonce gap
+type PoolStatus struct {
+	Pending int `json:"pending"`
+	Queued  int `json:"queued"`
+}
+
+//PoolStats counts the transactions the pool turned away or dropped to stay within its limits
+type PoolStats struct {
+	Rejected uint64 `json:"rejected"`
+	Evicted  uint64 `json:"evicted"`
 }
 
 //NewTransactionPool Create a trading pool
-func NewTransactionPool(chainStore store.StoreInterface, journalPath string) *TransactionPool {
-	pool := &TransactionPool{chainStore: chainStore}
+func NewTransactionPool(config TxPoolConfig, chainStore store.StoreInterface, journalPath string) *TransactionPool {
+	pool := &TransactionPool{chainStore: chainStore, config: config.sanitize()}
 	pool.nonceCp = func(a interface{}, b interface{}) int {
 		ta, oka := a.(*types.Transaction)
 		tb, okb := b.(*types.Transaction)
@@ -81,6 +101,7 @@ func NewTransactionPool(chainStore store.StoreInterface, journalPath string) *Tr
 	pool.queue = make(map[crypto.CommonAddress]*txList)
 	pool.pending = make(map[crypto.CommonAddress]*txList)
 	pool.newBlockChan = make(chan *types.ChainEvent)
+	pool.detachBlockChan = make(chan *types.Block)
 	pool.pendingNonce = make(map[crypto.CommonAddress]uint64)
 
 	pool.allTxs = make(map[string]*types.Transaction)
@@ -120,11 +141,7 @@ func (pool *TransactionPool) local() map[crypto.CommonAddress][]*types.Transacti
 	for addr, list := range pool.pending {
 		if !list.Empty() && isLocalAddr(addr) {
 			txs := list.Flatten()
-			if _, ok := all[addr]; ok {
-				txs = append(txs, all[addr]...)
-			} else {
-				all[addr] = txs
-			}
+			all[addr] = append(txs, all[addr]...)
 		}
 	}
 
@@ -135,7 +152,12 @@ func (pool *TransactionPool) addTxs(txs []types.Transaction) []error {
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
 		tx := tx
-		from, _ := tx.From()
+		from, err := pool.verifySender(&tx)
+		if err != nil {
+			errs[i] = err
+			log.WithField("Reason", err).Error("recover tx from journal err")
+			continue
+		}
 		if tx.Nonce() < pool.getTransactionCount(from) {
 			continue
 		}
@@ -148,6 +170,31 @@ func (pool *TransactionPool) addTxs(txs []types.Transaction) []error {
 	return errs
 }
 
+//verifySender returns the sender of tx, a multisig transaction is verified against the keys of its account at the
+//tip as the transactions of the journal and of detached blocks come without a verified sender
+func (pool *TransactionPool) verifySender(tx *types.Transaction) (*crypto.CommonAddress, error) {
+	from, err := tx.From()
+	if err != types.ErrUnverifiedSender {
+		return from, err
+	}
+	proof, err := tx.MultiSigProof()
+	if err != nil {
+		return nil, err
+	}
+	account, err := pool.chainStore.GetMultiSigAccount(&proof.Account)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, types.ErrMultiSigAccount
+	}
+	err = account.VerifyTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	return tx.From()
+}
+
 //AddTransaction ransaction put in txpool
 func (pool *TransactionPool) AddTransaction(tx *types.Transaction, isLocal bool) error {
 	pool.mu.Lock()
@@ -168,6 +215,11 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 		return err
 	}
 
+	if !isLocal && tx.GasPrice().Cmp(new(big.Int).SetUint64(pool.config.PriceLimit)) < 0 {
+		pool.rejected++
+		return ErrUnderpriced
+	}
+
 	// pending Queue transaction substitution
 	if list, ok := pool.pending[*addr]; ok {
 		if list.Overlaps(tx) {
@@ -218,48 +270,32 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 		return fmt.Errorf("SendTransaction local nonce:%d , comming tx nonce:%d too small", nonce, tx.Nonce())
 	}
 
-	//A new transaction is coming, let's see if the pool is full; When full, delete some of the cheaper tx's
-	miniPrice := new(big.Int)
-	if len(pool.allTxs) >= maxAllTxsCount {
-		//Cheaper deals will be discarded
-		txs := pool.allPricedTxs.Discard(1, pool.locals)
-		for _, t := range txs {
-			if t.GasPrice().Cmp(miniPrice) < 0 || miniPrice.Cmp(new(big.Int)) == 0 {
-				miniPrice = t.GasPrice()
-			}
-			delAddr, _ := tx.From()
-			delete(pool.allTxs, t.TxHash().String())
-
-			remove := func(list *txList, pending bool) bool {
-				//going to delete all the tx's in the queue /pending
-				removeSuccess, deleteTxs := list.Remove(&t)
-				if removeSuccess {
-					for _, delTx := range deleteTxs {
-						if pending {
-							pool.pendingNonce[*delAddr]--
-						}
-
-						delete(pool.allTxs, delTx.TxHash().String())
-						pool.allPricedTxs.Remove(delTx)
-					}
-				}
-				return removeSuccess
-			}
-
-			for i, maplist := range []map[crypto.CommonAddress]*txList{pool.pending, pool.queue} {
-				if list, ok := maplist[*delAddr]; ok {
-					b := remove(list, i == 0)
-					if b {
-						break
-					}
-				}
+	//A remote account with a full queue only gets a tx filling a nonce gap below its queued ones, those above it
+	//make room for it. A tx beyond all of them would not be executable before them
+	if list, ok := pool.queue[*addr]; ok && uint64(list.Len()) >= pool.config.AccountQueue {
+		if _, local := pool.locals[*addr]; !local && !isLocal {
+			queued := list.Flatten()
+			if tx.Nonce() > queued[len(queued)-1].Nonce() {
+				pool.rejected++
+				return ErrQueueFull
 			}
 		}
 	}
 
-	//If the price of the new transaction is low and not local. So return an error (todo need to optimize)
-	if miniPrice.Cmp(new(big.Int)) != 0 && tx.GasPrice().Cmp(miniPrice) < 0 && !isLocal {
-		return fmt.Errorf("new tx gasprice is too low")
+	//A new transaction is coming, let's see if the pool is full; When full, the cheapest remote tx's make room for it
+	if capacity := int(pool.config.GlobalSlots + pool.config.GlobalQueue); len(pool.allTxs) >= capacity {
+		if !isLocal && pool.allPricedTxs.Underpriced(tx, pool.locals) {
+			pool.rejected++
+			return ErrUnderpriced
+		}
+		drops := pool.allPricedTxs.Discard(len(pool.allTxs)-capacity+1, pool.locals)
+		if len(drops) == 0 && !isLocal {
+			pool.rejected++
+			return ErrTxPoolFull
+		}
+		for _, drop := range drops {
+			pool.evict(drop)
+		}
 	}
 
 	if isLocal {
@@ -271,12 +307,13 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 	//add to queue
 	if list, ok := pool.queue[*addr]; ok {
 		//Whether the queue space corresponding to the address is full,drop old tx
-		if list.Len() > maxTxsOfQueue {
-			//Blocking here may be nonce discontinuity, so discard the old transaction, add new transaction, and realize nonce continuity
-			txs := list.Cap(list.Len())
+		if _, ok := pool.locals[*addr]; !ok && uint64(list.Len()) >= pool.config.AccountQueue {
+			//Blocking here may be nonce discontinuity, so discard the highest nonces, add new transaction, and realize nonce continuity
+			txs := list.Cap(int(pool.config.AccountQueue) - 1)
 			for _, delTx := range txs {
 				delete(pool.allTxs, delTx.TxHash().String())
 				pool.allPricedTxs.Remove(delTx)
+				pool.evicted++
 				log.WithField("oldtx", delTx.TxHash()).WithField("newTx", tx.TxHash()).Info("old tx been replaced")
 			}
 		}
@@ -291,24 +328,119 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 	pool.allTxs[id.String()] = tx
 	pool.allPricedTxs.Put(tx)
 	pool.syncToPending(addr)
+	pool.truncateQueue()
+	if _, ok := pool.allTxs[id.String()]; !ok {
+		//the new tx was the cheapest one waiting in the full queue
+		return ErrQueueFull
+	}
 	return nil
 }
 
+//evict drops a transaction to keep the pool within its limits
+func (pool *TransactionPool) evict(tx *types.Transaction) {
+	removed := pool.removeTx(tx)
+	pool.evicted += uint64(len(removed))
+	for _, t := range removed {
+		log.WithField("tx", t.TxHash()).WithField("price", t.GasPrice()).Debug("evict tx")
+	}
+}
+
+//removeTx deletes tx from the pool, a pending tx takes its nonce successors along as they can't be executed
+//anymore. It returns all the transactions removed
+func (pool *TransactionPool) removeTx(tx *types.Transaction) []*types.Transaction {
+	from, err := tx.From()
+	if err != nil {
+		return nil
+	}
+	holds := func(list *txList) bool {
+		old := list.txs.Get(tx.Nonce())
+		return old != nil && *old.TxHash() == *tx.TxHash()
+	}
+
+	var removed []*types.Transaction
+	if list, ok := pool.pending[*from]; ok && holds(list) {
+		_, invalids := list.Remove(tx)
+		removed = append([]*types.Transaction{tx}, invalids...)
+		pool.pendingNonce[*from] = tx.Nonce()
+	} else if list, ok := pool.queue[*from]; ok && holds(list) {
+		list.Remove(tx)
+		removed = []*types.Transaction{tx}
+	}
+
+	for _, t := range removed {
+		delete(pool.allTxs, t.TxHash().String())
+		pool.allPricedTxs.Remove(t)
+	}
+	return removed
+}
+
+//truncateQueue evicts the cheapest remote transactions waiting for a nonce gap while the queue holds more than its global slots
+func (pool *TransactionPool) truncateQueue() {
+	_, queued := pool.count()
+	if uint64(queued) <= pool.config.GlobalQueue {
+		return
+	}
+
+	var remotes []*types.Transaction
+	for addr, list := range pool.queue {
+		if _, ok := pool.locals[addr]; !ok {
+			remotes = append(remotes, list.Flatten()...)
+		}
+	}
+	sort.Sort(priceHeap(remotes))
+	for _, tx := range remotes {
+		if uint64(queued) <= pool.config.GlobalQueue {
+			return
+		}
+		pool.evict(tx)
+		queued--
+	}
+}
+
+//count returns the number of executable and of waiting transactions in the pool
+func (pool *TransactionPool) count() (int, int) {
+	pending, queued := 0, 0
+	for _, list := range pool.pending {
+		pending += list.Len()
+	}
+	for _, list := range pool.queue {
+		queued += list.Len()
+	}
+	return pending, queued
+}
+
 func (pool *TransactionPool) syncToPending(address *crypto.CommonAddress) {
 	if _, ok := pool.pending[*address]; !ok {
 		pool.pending[*address] = newTxList(true)
 	}
 	listPending := pool.pending[*address]
-	if listPending.Len() > maxTxsOfPending {
-		return
-	}
 
 	//and put it into pending
 	addrList := pool.queue[*address]
 	if addrList == nil {
 		return
 	}
+
+	//remote accounts are promoted only while they and the pool have executable slots left
+	slots := math.MaxInt32
+	if _, ok := pool.locals[*address]; !ok {
+		pending, _ := pool.count()
+		slots = int(pool.config.AccountSlots) - listPending.Len()
+		if free := int(pool.config.GlobalSlots) - pending; free < slots {
+			slots = free
+		}
+	}
+	if slots <= 0 {
+		return
+	}
+
 	list := addrList.Ready(pool.getTransactionCount(address))
+	if len(list) > slots {
+		for _, tx := range list[slots:] {
+			addrList.Add(tx)
+		}
+		list = list[:slots]
+	}
 	var nonce uint64
 	if len(list) > 0 {
 		for _, tx := range list {
@@ -321,6 +453,104 @@ func (pool *TransactionPool) syncToPending(address *crypto.CommonAddress) {
 	}
 }
 
+//Stats returns how many transactions the pool rejected and evicted
+func (pool *TransactionPool) Stats() PoolStats {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return PoolStats{Rejected: pool.rejected, Evicted: pool.evicted}
+}
+
+//Status returns the number of pending and queued transactions
+func (pool *TransactionPool) Status() PoolStatus {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pending, queued := pool.count()
+	return PoolStatus{Pending: pending, Queued: queued}
+}
+
+//Content returns the pending and the queued transactions grouped by account, sorted by nonce
+func (pool *TransactionPool) Content() (map[crypto.CommonAddress][]*types.Transaction, map[crypto.CommonAddress][]*types.Transaction) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	flatten := func(lists map[crypto.CommonAddress]*txList) map[crypto.CommonAddress][]*types.Transaction {
+		content := make(map[crypto.CommonAddress][]*types.Transaction, len(lists))
+		for addr, list := range lists {
+			if !list.Empty() {
+				content[addr] = list.Flatten()
+			}
+		}
+		return content
+	}
+	return flatten(pool.pending), flatten(pool.queue)
+}
+
+//Sent returns the pending and the queued transactions of sender, sorted by nonce
+func (pool *TransactionPool) Sent(sender *crypto.CommonAddress) []*types.Transaction {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var txs []*types.Transaction
+	if list, ok := pool.pending[*sender]; ok {
+		txs = append(txs, list.Flatten()...)
+	}
+	if list, ok := pool.queue[*sender]; ok {
+		txs = append(txs, list.Flatten()...)
+	}
+	return txs
+}
+
+//Sponsored returns the transactions in the pool whose gas is paid by sponsor
+func (pool *TransactionPool) Sponsored(sponsor *crypto.CommonAddress) []*types.Transaction {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var txs []*types.Transaction
+	for _, tx := range pool.allTxs {
+		if !tx.IsSponsored() {
+			continue
+		}
+		if payer, err := tx.Payer(); err == nil && *payer == *sponsor {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+//DropTransaction removes a transaction of a local account from the pool and the journal, together with the
+//transactions of the account following it. It returns all the transactions removed
+func (pool *TransactionPool) DropTransaction(hash *crypto.Hash) ([]*types.Transaction, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	tx, ok := pool.allTxs[hash.String()]
+	if !ok {
+		return nil, ErrTxNotExist
+	}
+	from, err := tx.From()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := pool.locals[*from]; !ok {
+		return nil, ErrNotLocalTx
+	}
+
+	removed := pool.removeTx(tx)
+	if list, ok := pool.queue[*from]; ok {
+		for _, queued := range list.Flatten() {
+			if queued.Nonce() > tx.Nonce() {
+				removed = append(removed, pool.removeTx(queued)...)
+			}
+		}
+	}
+	if pool.journal != nil {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.WithField("Reason", err).Warn("Failed to rotate local transaction journal")
+		}
+	}
+	return removed, nil
+}
+
 //GetQueue Gets all transactions in the non-strictly sorted queue in the transaction pool
 func (pool *TransactionPool) GetQueue() []*types.Transaction {
 	var retrunTxs []*types.Transaction
@@ -337,54 +567,29 @@ func (pool *TransactionPool) GetQueue() []*types.Transaction {
 	return retrunTxs
 }
 
-//GetPending The packaging process takes the transaction and packages it
+//GetPending returns the pending transactions to pack into a block with GasLimit, the best paying first. An account
+//offers its transactions by nonce, one that does not fit in the remaining gas drops the rest of its account.
 func (pool *TransactionPool) GetPending(GasLimit *big.Int) []*types.Transaction {
 	pool.mu.Lock()
-	gasCount := new(big.Int)
-
-	//change Data structure
-	hbn := make(map[crypto.CommonAddress]*nonceTxsHeap)
-
+	pending := make(map[crypto.CommonAddress][]*types.Transaction, len(pool.pending))
 	for addr, list := range pool.pending {
 		if !list.Empty() {
-			txs := list.Flatten()
-			newList := &nonceTxsHeap{}
-			for _, tx := range txs {
-				newList.Push(tx)
-			}
-			hbn[addr] = newList
+			pending[addr] = list.Flatten()
 		}
 	}
-
 	pool.mu.Unlock()
 
-	var retrunTxs []*types.Transaction
-	for {
-		for addr, list := range hbn {
-			tx := heap.Pop(list).(*types.Transaction)
-			if GasLimit.Cmp(new(big.Int).Add(tx.GasLimit(), gasCount)) >= 0 {
-				retrunTxs = append(retrunTxs, tx)
-			} else {
-				goto END
-			}
-
-			if list.Len() == 0 {
-				delete(hbn, addr)
-			}
-		}
-
-		if len(hbn) <= 0 {
-			goto END
-		}
+	gasLimit := uint64(math.MaxUint64)
+	if GasLimit.IsUint64() {
+		gasLimit = GasLimit.Uint64()
 	}
-
-END:
-	return retrunTxs
+	return types.NewTransactionsByPriceAndNonce(pending).Pack(gasLimit)
 }
 
 //Start start transaction pool
-func (pool *TransactionPool) Start(feed *event.Feed, tipRoot []byte) {
-	b := pool.chainStore.RecoverTrie(tipRoot)
+func (pool *TransactionPool) Start(feed, detachFeed *event.Feed, tipRoot func() []byte) {
+	pool.tipRoot = tipRoot
+	b := pool.chainStore.RecoverTrie(tipRoot())
 	if !b {
 		log.WithField("recoverRet", b).Error("tx pool")
 	}
@@ -394,21 +599,32 @@ func (pool *TransactionPool) Start(feed *event.Feed, tipRoot []byte) {
 
 	go pool.checkUpdate()
 	pool.eventNewBlockSub = feed.Subscribe(pool.newBlockChan)
+	pool.detachBlockSub = detachFeed.Subscribe(pool.detachBlockChan)
 }
 
 //Stop transaction pool work
 func (pool *TransactionPool) Stop() {
 	close(pool.quit)
 	pool.eventNewBlockSub.Unsubscribe()
+	pool.detachBlockSub.Unsubscribe()
 	pool.journal.close()
 }
 
+//expired reports whether tx stayed in the pool too long or no following block may include it anymore
+func (pool *TransactionPool) expired(tx *types.Transaction, now int64) bool {
+	if tx.Time()+expireTimeTx <= now {
+		return true
+	}
+	window := tx.ValidityWindow()
+	return window != nil && window.Expired(pool.tipHeight+1, now)
+}
+
 func (pool *TransactionPool) eliminateExpiredTxs() {
 	for _, list := range pool.queue {
 		if !list.Empty() {
 			txs := list.Flatten()
 			for _, tx := range txs {
-				if tx.Time()+expireTimeTx <= time.Now().Unix() {
+				if pool.expired(tx, time.Now().Unix()) {
 					from, _ := tx.From()
 					log.WithField("tx time", tx.Time()).WithField("tx nonce", tx.Nonce()).WithField("from", from.String()).Info("tx expire")
 					delete(pool.allTxs, tx.TxHash().String())
@@ -423,7 +639,7 @@ func (pool *TransactionPool) eliminateExpiredTxs() {
 		if !list.Empty() {
 			txs := list.Flatten()
 			for _, tx := range txs {
-				if tx.Time()+expireTimeTx <= time.Now().Unix() {
+				if pool.expired(tx, time.Now().Unix()) {
 					from, _ := tx.From()
 					log.WithField("tx time", tx.Time()).WithField("tx nonce", tx.Nonce()).WithField("from", from.String()).Info("tx expire")
 					delete(pool.allTxs, tx.TxHash().String())
@@ -453,6 +669,8 @@ func (pool *TransactionPool) checkUpdate() {
 			pool.mu.Unlock()
 		case block := <-pool.newBlockChan:
 			pool.adjust(block.Block)
+		case block := <-pool.detachBlockChan:
+			pool.detach(block)
 		case <-pool.quit:
 			return
 		}
@@ -468,11 +686,15 @@ func (pool *TransactionPool) adjust(block *types.Block) {
 	if !b {
 		log.WithField("recoverRet", b).WithField("h:", block.Header.Height).Error("RecoverTrie")
 	}
+	pool.tipHeight = block.Header.Height
 
 	addrMap := make(map[crypto.CommonAddress]struct{})
 	var addrs []*crypto.CommonAddress
 	for _, tx := range block.Data.TxList {
-		addr, _ := tx.From()
+		addr, err := tx.ClaimedFrom()
+		if err != nil {
+			continue
+		}
 		if _, ok := addrMap[*addr]; !ok {
 			addrMap[*addr] = struct{}{}
 			addrs = append(addrs, addr)
@@ -496,6 +718,7 @@ func (pool *TransactionPool) adjust(block *types.Block) {
 				for _, tx := range txs {
 					id := tx.TxHash()
 					delete(pool.allTxs, id.String())
+					pool.allPricedTxs.Remove(tx)
 				}
 			}
 
@@ -505,6 +728,67 @@ func (pool *TransactionPool) adjust(block *types.Block) {
 	}
 }
 
+//detach returns the transactions of a block removed from the main chain to the pool, the nonces of their
+//senders are recomputed from the state of the new tip
+func (pool *TransactionPool) detach(block *types.Block) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	b := pool.chainStore.RecoverTrie(pool.tipRoot())
+	if !b {
+		log.WithField("recoverRet", b).WithField("h:", block.Header.Height).Error("RecoverTrie")
+	}
+	pool.tipHeight = block.Header.Height - 1
+
+	addrMap := make(map[crypto.CommonAddress]struct{})
+	for _, tx := range block.Data.TxList {
+		addr, err := tx.ClaimedFrom()
+		if err != nil {
+			continue
+		}
+		addrMap[*addr] = struct{}{}
+	}
+
+	queue := func(addr crypto.CommonAddress) *txList {
+		if _, ok := pool.queue[addr]; !ok {
+			pool.queue[addr] = newTxList(false)
+		}
+		return pool.queue[addr]
+	}
+	for addr := range addrMap {
+		//pending transactions go back to the queue, they are promoted again from the nonce of the new tip
+		if list, ok := pool.pending[addr]; ok {
+			for _, tx := range list.Flatten() {
+				queue(addr).Add(tx)
+			}
+			delete(pool.pending, addr)
+		}
+		delete(pool.pendingNonce, addr)
+	}
+
+	for _, tx := range block.Data.TxList {
+		id := tx.TxHash()
+		if _, ok := pool.allTxs[id.String()]; ok {
+			continue
+		}
+		addr, err := pool.verifySender(tx)
+		if err != nil {
+			continue
+		}
+		if !queue(*addr).Add(tx) {
+			continue
+		}
+		pool.allTxs[id.String()] = tx
+		pool.allPricedTxs.Put(tx)
+	}
+
+	for addr := range addrMap {
+		addr := addr
+		pool.syncToPending(&addr)
+		log.WithField("addr", addr.Hex()).WithField("txpool tx count", len(pool.allTxs)).Trace("detach block")
+	}
+}
+
 //GetTransactionCount Gets the total number of transactions, that is, the nonce corresponding to the address
 func (pool *TransactionPool) GetTransactionCount(address *crypto.CommonAddress) uint64 {
 	pool.mu.Lock()
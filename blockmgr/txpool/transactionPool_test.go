@@ -1,10 +1,14 @@
 package txpool
 
 import (
+	"encoding/binary"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
-	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
 	"github.com/drep-project/DREP-Chain/types"
 
 	rand2 "math/rand"
@@ -21,33 +25,40 @@ import (
 var txNum1 uint64 = 10000
 var txNum2 int = 1000
 var txPool *TransactionPool
-var feed event.Feed
-
-func TestNewTransactions(t *testing.T) {
-	path := filepath.Join(os.TempDir(), fmt.Sprintf("./txpool/%d/data", rand2.Int63n(10000000)))
-	diskDb, err := database.NewDatabase(path)
+var feed, detachFeed event.Feed
+
+//newTestTxPool starts a pool on an empty state kept in memory
+func newTestTxPool(t *testing.T, config TxPoolConfig) *TransactionPool {
+	db := memorydb.New()
+	changeInterval := make([]byte, 8)
+	binary.BigEndian.PutUint64(changeInterval, 100)
+	db.Put([]byte(store.ChangeInterval), changeInterval)
+	chainStore, err := store.TrieStoreFromStore(db, trie.EmptyRoot[:])
 	if err != nil {
-		t.Error("db init err")
+		t.Fatal(err)
 	}
 
-	path = filepath.Join(os.TempDir(), fmt.Sprintf("./jounal/txs"))
-	txPool = NewTransactionPool(diskDb, path)
-	if txPool == nil {
-		t.Error("init chainStore service err")
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("./jounal/%d/txs", rand2.Int63n(10000000)))
+	pool := NewTransactionPool(config, chainStore, path)
+	if pool == nil {
+		t.Fatal("init chainStore service err")
 	}
+	pool.Start(&feed, &detachFeed, func() []byte { return trie.EmptyRoot[:] })
+	return pool
+}
 
-	txPool.Start(&feed)
+func TestNewTransactions(t *testing.T) {
+	txPool = newTestTxPool(t, DefaultTxPoolConfig)
 }
 
 func addTx(t *testing.T, num uint64) error {
 	privKey, _ := crypto.GenerateKey(rand.Reader)
 
-	addr := crypto.PubKey2Address(privKey.PubKey())
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 	fmt.Println(string(addr.Hex()))
-	txPool.chainStore.BeginTransaction()
 
 	var amount uint64 = 0xefffffffffffffff
-	txPool.chainStore.PutBalance(&addr, new(big.Int).SetUint64(amount))
+	txPool.chainStore.PutBalance(&addr, 0, new(big.Int).SetUint64(amount))
 
 	nonce := txPool.chainStore.GetNonce(&addr)
 	for i := 0; uint64(i) < num; i++ {
@@ -82,7 +93,7 @@ func TestAddTX(t *testing.T) {
 
 func TestAddIntevalTX(t *testing.T) {
 	privKey, _ := crypto.GenerateKey(rand.Reader)
-	addr := crypto.PubKey2Address(privKey.PubKey())
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 	for i := 0; i < txNum2; i++ {
 		if i != 0 && i%100 == 0 {
 			continue
@@ -96,53 +107,21 @@ func TestAddIntevalTX(t *testing.T) {
 //The pool is full of unprocessed transactions
 func TestGetPendingTxs(t *testing.T) {
 	TestNewTransactions(t)
-	ch := make(chan uint64)
-	go func() {
-		err := addTx(t, txNum1)
-		if err != nil {
-			return
-		}
+	err := addTx(t, uint64(txNum2))
+	if err != nil {
+		t.Fatal(err)
+	}
 
-		time.Sleep(time.Second * 1)
-		ch <- txNum1
-	}()
-
-	func() {
-		var nonce uint64
-		for {
-			select {
-			case num := <-ch:
-				if nonce != num {
-					t.Fatalf("recv nonce:%d sendTxNum:%d", nonce, num)
-				}
-				return
-			default:
-				gasLimit := new(big.Int).SetInt64(10000000)
-				mapTxs := txPool.GetPending(gasLimit)
-				fmt.Println("pending tx len:", len(mapTxs))
-				if len(mapTxs) == 0 {
-					time.Sleep(time.Second * 1)
-					continue
-				}
-
-				var addrs []*crypto.CommonAddress
-				mapAddr := make(map[crypto.CommonAddress]struct{})
-				for _, tx := range mapTxs {
-					from := &crypto.CommonAddress{}
-					if _, ok := mapAddr[*from]; !ok {
-						mapAddr[*from] = struct{}{}
-						addrs = append(addrs, from)
-					}
-					nonce = tx.Nonce()
-				}
-				fmt.Println("recv nonce:", nonce)
-				txPool.chainStore.BeginTransaction()
-
-				feed.Send(addrs)
-				time.Sleep(time.Second * 1)
-			}
+	gasLimit := new(big.Int).SetInt64(10000000)
+	txs := txPool.GetPending(gasLimit)
+	if len(txs) != txNum2 {
+		t.Fatalf("pending tx len:%d sendTxNum:%d", len(txs), txNum2)
+	}
+	for i, tx := range txs {
+		if tx.Nonce() != uint64(i) {
+			t.Fatalf("recv nonce:%d expect:%d", tx.Nonce(), i)
 		}
-	}()
+	}
 }
 
 //The tx in the test queue is deleted
@@ -150,11 +129,10 @@ func TestReplace(t *testing.T) {
 	TestNewTransactions(t)
 
 	privKey, _ := crypto.GenerateKey(rand.Reader)
-	addr := crypto.PubKey2Address(privKey.PubKey())
-	txPool.chainStore.BeginTransaction()
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 
 	var amount uint64 = 0xefffffffffffffff
-	txPool.chainStore.PutBalance(&addr, new(big.Int).SetUint64(amount))
+	txPool.chainStore.PutBalance(&addr, 0, new(big.Int).SetUint64(amount))
 
 	nonce := txPool.chainStore.GetNonce(&addr)
 	for i := 0; uint64(i) < maxTxsOfPending; i++ {
@@ -213,7 +191,7 @@ func TestDelTx(t *testing.T) {
 	//txPool.chainStore.BeginTransaction()
 
 	var amount uint64 = 0xefffffffffffffff
-	txPool.chainStore.PutBalance(&addr, new(big.Int).SetUint64(amount))
+	txPool.chainStore.PutBalance(&addr, 0, new(big.Int).SetUint64(amount))
 
 	nonce := txPool.getTransactionCount(&addr)
 	for i := 0; uint64(i) < maxTxsOfQueue+maxTxsOfPending; i++ {
@@ -227,14 +205,109 @@ func TestDelTx(t *testing.T) {
 	}
 
 	nonce += maxTxsOfQueue + maxTxsOfPending
-	//delete in pending
+	//the queue is full, txs beyond it are refused and the queued ones kept
 	for i := 0; uint64(i) < 20; i++ {
 		tx := types.NewTransaction(addr, new(big.Int).SetInt64(100), new(big.Int).SetInt64(int64(100*5)), new(big.Int).SetInt64(100), nonce+uint64(i))
 		sig, err := secp256k1.SignCompact(privKey, tx.TxHash().Bytes(), true)
 		tx.Sig = sig
 		err = txPool.AddTransaction(tx, false)
-		if err != nil {
+		if err != ErrQueueFull {
+			t.Fatalf("expect the tx beyond the queue refused, got %v", err)
+		}
+	}
+	if status := txPool.Status(); status.Pending != maxTxsOfPending || status.Queued != maxTxsOfQueue {
+		t.Fatalf("expect the pending and queued txs kept, got %v", status)
+	}
+}
+
+func newTestTx(t *testing.T, privKey *secp256k1.PrivateKey, nonce uint64, price int64) *types.Transaction {
+	tx := types.NewTransaction(crypto.CommonAddress{}, new(big.Int).SetInt64(100), new(big.Int).SetInt64(price), new(big.Int).SetInt64(100), nonce)
+	sig, err := secp256k1.SignCompact(privKey, tx.TxHash().Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sig = sig
+	return tx
+}
+
+//A full account queue makes room for a tx filling a nonce gap by dropping its highest nonces only
+func TestAccountQueueFull(t *testing.T) {
+	pool := newTestTxPool(t, DefaultTxPoolConfig)
+	privKey, _ := crypto.GenerateKey(rand.Reader)
+
+	for nonce := uint64(2); nonce < 2+maxTxsOfQueue; nonce++ {
+		if err := pool.AddTransaction(newTestTx(t, privKey, nonce, 100), false); err != nil {
 			t.Fatal(err)
 		}
 	}
+	if err := pool.AddTransaction(newTestTx(t, privKey, 2+maxTxsOfQueue, 100), false); err != ErrQueueFull {
+		t.Fatalf("expect the tx beyond the full queue refused, got %v", err)
+	}
+	if stats := pool.Stats(); stats.Rejected != 1 || stats.Evicted != 0 {
+		t.Fatalf("expect one tx rejected, got %v", stats)
+	}
+
+	if err := pool.AddTransaction(newTestTx(t, privKey, 1, 100), false); err != nil {
+		t.Fatal(err)
+	}
+	if status := pool.Status(); status.Queued != maxTxsOfQueue {
+		t.Fatalf("expect the queue kept full, got %v", status)
+	}
+	if stats := pool.Stats(); stats.Evicted != 1 {
+		t.Fatalf("expect the highest nonce evicted, got %v", stats)
+	}
+
+	//the gap is filled, the queue is executable
+	if err := pool.AddTransaction(newTestTx(t, privKey, 0, 100), false); err != nil {
+		t.Fatal(err)
+	}
+	if status := pool.Status(); status.Pending != maxTxsOfQueue || status.Queued != 0 {
+		t.Fatalf("expect the txs up to the queue size pending, got %v", status)
+	}
+	for i, tx := range pool.GetPending(new(big.Int).SetInt64(10000000)) {
+		if tx.Nonce() != uint64(i) {
+			t.Fatalf("expect the lowest nonces kept, got nonce %d at %d", tx.Nonce(), i)
+		}
+	}
+}
+
+//A remote tx has to pay the price limit and, in a full pool, more than the cheapest remote tx
+func TestUnderpriced(t *testing.T) {
+	pool := newTestTxPool(t, TxPoolConfig{PriceLimit: 10, GlobalSlots: 2, GlobalQueue: 1})
+	var keys [4]*secp256k1.PrivateKey
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey(rand.Reader)
+	}
+
+	if err := pool.AddTransaction(newTestTx(t, keys[0], 0, 5), false); err != ErrUnderpriced {
+		t.Fatalf("expect the tx below the price limit refused, got %v", err)
+	}
+	local := newTestTx(t, keys[0], 0, 5)
+	if err := pool.AddTransaction(local, true); err != nil {
+		t.Fatalf("expect the price limit not applied to a local tx, got %v", err)
+	}
+	cheapest := newTestTx(t, keys[1], 0, 20)
+	if err := pool.AddTransaction(cheapest, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.AddTransaction(newTestTx(t, keys[2], 0, 30), false); err != nil {
+		t.Fatal(err)
+	}
+
+	//the pool is full, the cheapest remote tx pays 20
+	if err := pool.AddTransaction(newTestTx(t, keys[3], 0, 19), false); err != ErrUnderpriced {
+		t.Fatalf("expect the tx not beating the cheapest one refused, got %v", err)
+	}
+	if err := pool.AddTransaction(newTestTx(t, keys[3], 0, 25), false); err != nil {
+		t.Fatal(err)
+	}
+	if stats := pool.Stats(); stats.Rejected != 2 || stats.Evicted != 1 {
+		t.Fatalf("expect two txs rejected and one evicted, got %v", stats)
+	}
+	if _, err := pool.GetTxInPool(cheapest.TxHash().String()); err == nil {
+		t.Fatal("expect the cheapest remote tx evicted")
+	}
+	if _, err := pool.GetTxInPool(local.TxHash().String()); err != nil {
+		t.Fatal("expect the local tx kept")
+	}
 }
@@ -397,24 +397,37 @@ func (l *txPricedList) Put(tx *types.Transaction) {
 	heap.Push(l.items, tx)
 }
 
+//Remove deletes a transaction from the heap.
 func (l *txPricedList) Remove(tx *types.Transaction) {
-	all := new(priceHeap)
-	for len(*l.items) > 0 {
-		// Discard stale transactions if found during cleanup
-		temp := heap.Pop(l.items).(*types.Transaction)
-		if tx.TxHash() != temp.TxHash() {
-			*all = append(*all, temp)
+	for i, t := range *l.items {
+		if *t.TxHash() == *tx.TxHash() {
+			heap.Remove(l.items, i)
+			return
 		}
 	}
+}
 
-	heap.Init(all)
+//Underpriced checks whether tx pays no more than the cheapest remote transaction in the heap,
+//the floor a remote transaction has to beat to enter a full pool.
+func (l *txPricedList) Underpriced(tx *types.Transaction, local map[crypto.CommonAddress]struct{}) bool {
+	var cheapest *types.Transaction
+	for _, t := range *l.items {
+		from, _ := t.From()
+		if _, ok := local[*from]; ok {
+			continue
+		}
+		if cheapest == nil || t.GasPrice().Cmp(cheapest.GasPrice()) < 0 {
+			cheapest = t
+		}
+	}
+	return cheapest != nil && cheapest.GasPrice().Cmp(tx.GasPrice()) >= 0
 }
 
 // Discard finds a number of most underpriced transactions, removes them from the
 // priced list and returns them for further removal from the entire pool.
-func (l *txPricedList) Discard(count int, local map[crypto.CommonAddress]struct{}) types.Transactions {
-	drop := make(types.Transactions, 0, count) // Remote underpriced transactions to drop
-	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
+func (l *txPricedList) Discard(count int, local map[crypto.CommonAddress]struct{}) []*types.Transaction {
+	drop := make([]*types.Transaction, 0, count) // Remote underpriced transactions to drop
+	save := make([]*types.Transaction, 0, 64)    // Local underpriced transactions to keep
 
 	for len(*l.items) > 0 && count > 0 {
 		// Discard stale transactions if found during cleanup
@@ -422,14 +435,14 @@ func (l *txPricedList) Discard(count int, local map[crypto.CommonAddress]struct{
 		from, _ := tx.From()
 		// Non stale transaction found, discard unless local
 		if _, ok := local[*from]; ok {
-			save = append(save, *tx)
+			save = append(save, tx)
 		} else {
-			drop = append(drop, *tx)
+			drop = append(drop, tx)
 			count--
 		}
 	}
 	for _, tx := range save {
-		heap.Push(l.items, &tx)
+		heap.Push(l.items, tx)
 	}
 	return drop
 }
@@ -0,0 +1,157 @@
+package blockmgr
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/drep-project/DREP-Chain/blockmgr/txpool"
+	"github.com/drep-project/DREP-Chain/chain/transactions"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+/*
+name: Transaction pool
+usage: Inspect the transactions waiting in the pool
+prefix:txpool
+*/
+type TxPoolAPI struct {
+	blockMgr *BlockMgr
+}
+
+/*
+ name: status
+ usage: Get the number of transactions in the pool
+ params:
+
+ return: number of executable (pending) transactions and of transactions waiting for a nonce gap (queued)
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"txpool_status","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"pending":12,"queued":3}}
+*/
+func (txPoolApi *TxPoolAPI) Status() txpool.PoolStatus {
+	return txPoolApi.blockMgr.transactionPool.Status()
+}
+
+/*
+ name: content
+ usage: Get all the transactions in the pool, grouped by sender and nonce
+ params:
+
+ return: pending and queued transactions
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"txpool_content","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"pending":{"0x8a8e541ddd1272d53729164c70197221a3c27486":{"10":{"Data":{"Version":1,"Nonce":10,"Type":0,"To":"0x3296d3336895b5baaa0eca3df911741bd0681c3f","ChainId":0,"Amount":"0x64","GasPrice":"0x1e8480","GasLimit":"0x7530","Timestamp":1590999424,"Data":null},"Sig":"IPJbhsS/c6pPoLywHi9XMd46ORfIhh0c4FdKjYMxrtzwAeZ4AA9q/JXTWlPvYjogVfzmh/hcL9dS3EVattuAKx8="}}},"queued":{}}}
+*/
+func (txPoolApi *TxPoolAPI) Content() map[string]map[string]map[string]*types.Transaction {
+	pending, queued := txPoolApi.blockMgr.transactionPool.Content()
+	return map[string]map[string]map[string]*types.Transaction{
+		"pending": contentByNonce(pending),
+		"queued":  contentByNonce(queued),
+	}
+}
+
+/*
+ name: contentFrom
+ usage: Get the transactions of an account in the pool, by nonce
+ params:
+	1. account address
+ return: pending and queued transactions of the account
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"txpool_contentFrom","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"pending":{"10":{"Data":{"Version":1,"Nonce":10,"Type":0,"To":"0x3296d3336895b5baaa0eca3df911741bd0681c3f","ChainId":0,"Amount":"0x64","GasPrice":"0x1e8480","GasLimit":"0x7530","Timestamp":1590999424,"Data":null},"Sig":"IPJbhsS/c6pPoLywHi9XMd46ORfIhh0c4FdKjYMxrtzwAeZ4AA9q/JXTWlPvYjogVfzmh/hcL9dS3EVattuAKx8="}},"queued":{}}}
+*/
+func (txPoolApi *TxPoolAPI) ContentFrom(addr *crypto.CommonAddress) map[string]map[string]*types.Transaction {
+	pending, queued := txPoolApi.blockMgr.transactionPool.Content()
+	content := map[string]map[string]*types.Transaction{
+		"pending": {},
+		"queued":  {},
+	}
+	for _, tx := range pending[*addr] {
+		content["pending"][strconv.FormatUint(tx.Nonce(), 10)] = tx
+	}
+	for _, tx := range queued[*addr] {
+		content["queued"][strconv.FormatUint(tx.Nonce(), 10)] = tx
+	}
+	return content
+}
+
+/*
+ name: inspect
+ usage: Get a one line summary of every transaction in the pool, grouped by sender and nonce
+ params:
+
+ return: pending and queued transaction summaries, type, recipient, amount, gas limit and gas price
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"txpool_inspect","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"pending":{"0x8a8e541ddd1272d53729164c70197221a3c27486":{"10":"transfer to 0x3296d3336895b5baaa0eca3df911741bd0681c3f: 100 + 30000 gas × 2000000"}},"queued":{}}}
+*/
+func (txPoolApi *TxPoolAPI) Inspect() map[string]map[string]map[string]string {
+	pending, queued := txPoolApi.blockMgr.transactionPool.Content()
+	inspect := func(content map[crypto.CommonAddress][]*types.Transaction) map[string]map[string]string {
+		summaries := make(map[string]map[string]string, len(content))
+		for addr, txs := range content {
+			byNonce := make(map[string]string, len(txs))
+			for _, tx := range txs {
+				byNonce[strconv.FormatUint(tx.Nonce(), 10)] = inspectTx(tx)
+			}
+			summaries[addr.Hex()] = byNonce
+		}
+		return summaries
+	}
+	return map[string]map[string]map[string]string{
+		"pending": inspect(pending),
+		"queued":  inspect(queued),
+	}
+}
+
+func contentByNonce(content map[crypto.CommonAddress][]*types.Transaction) map[string]map[string]*types.Transaction {
+	byAddr := make(map[string]map[string]*types.Transaction, len(content))
+	for addr, txs := range content {
+		byNonce := make(map[string]*types.Transaction, len(txs))
+		for _, tx := range txs {
+			byNonce[strconv.FormatUint(tx.Nonce(), 10)] = tx
+		}
+		byAddr[addr.Hex()] = byNonce
+	}
+	return byAddr
+}
+
+func inspectTx(tx *types.Transaction) string {
+	name := fmt.Sprintf("type %d", tx.Type())
+	if txType, err := transactions.GetTransactionType(tx.Type()); err == nil {
+		name = txType.Name
+	}
+	return fmt.Sprintf("%s to %s: %v + %v gas × %v", name, tx.To().Hex(), tx.Amount(), tx.GasLimit(), tx.GasPrice())
+}
+
+/*
+name: Admin RPC interface
+usage: Node maintenance, not public, only reachable through ipc or a whitelisted admin module
+prefix:admin
+*/
+type TxPoolAdminAPI struct {
+	blockMgr *BlockMgr
+}
+
+/*
+ name: dropTransaction
+ usage: Remove a transaction sent by a local account from the pool and the journal, the following transactions of the account are removed too
+ params:
+	1. transaction hash
+ return: hashes of the removed transactions
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_dropTransaction","params":["0xe0c2e5b2a3d2ce0fb6d9c7cba4a02e8b7e4b1d6a5fd7b0bd5eb77e68e9c1f2a5"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":["0xe0c2e5b2a3d2ce0fb6d9c7cba4a02e8b7e4b1d6a5fd7b0bd5eb77e68e9c1f2a5","0x5d1e4c33b1f4a6b92c1d0d9f7c3e8a2b6f0e4d7c9a1b3e5f7092a4c6e8b0d2f4"]}
+*/
+func (adminApi *TxPoolAdminAPI) DropTransaction(hash *crypto.Hash) ([]*crypto.Hash, error) {
+	removed, err := adminApi.blockMgr.transactionPool.DropTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]*crypto.Hash, 0, len(removed))
+	for _, tx := range removed {
+		hashes = append(hashes, tx.TxHash())
+	}
+	return hashes, nil
+}
@@ -0,0 +1,28 @@
+package chain
+
+/*
+name: Admin RPC interface
+usage: Node maintenance, not public, only reachable through ipc or a whitelisted admin module
+prefix:admin
+*/
+type AdminApi struct {
+	chainService *ChainService
+}
+
+func NewAdminApi(chainService *ChainService) *AdminApi {
+	return &AdminApi{chainService}
+}
+
+/*
+ name: rewind
+ usage: Rewind the chain, the block at the given height becomes the head. Blocks above it are removed with their receipts and trace records
+ params:
+	1. height of the new head
+ return: error if the height is above the head or the state of the block is no longer available
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_rewind","params":[100], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *AdminApi) Rewind(height uint64) error {
+	return adminApi.chainService.Rewind(height)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/drep-project/DREP-Chain/chain/utils"
 
 	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
@@ -14,18 +15,20 @@ type BlockExecuteContext struct {
 	Gp        *utils.GasPool
 	DbStore   *store.ChainStore
 	Block     *types.Block
+	Rules     *params.Rules
 	GasUsed   *big.Int
 	GasFee    *big.Int
 	Logs      []*types.Log
 	Receipts  types.Receipts
 }
 
-func NewBlockExecuteContext(trieStore store.StoreInterface, gp *utils.GasPool, dbStore *store.ChainStore, block *types.Block) *BlockExecuteContext {
+func NewBlockExecuteContext(trieStore store.StoreInterface, gp *utils.GasPool, dbStore *store.ChainStore, block *types.Block, rules *params.Rules) *BlockExecuteContext {
 	return &BlockExecuteContext{
 		TrieStore: trieStore,
 		Gp:        gp,
 		DbStore:   dbStore,
 		Block:     block,
+		Rules:     rules,
 		GasUsed:   new(big.Int),
 		GasFee:    new(big.Int),
 		Logs:      []*types.Log{},
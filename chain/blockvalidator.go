@@ -48,7 +48,7 @@ func NewChainBlockValidator(chainService *ChainService) *ChainBlockValidator {
 
 func (chainBlockValidator *ChainBlockValidator) VerifyHeader(header, parent *types.BlockHeader) error {
 	// Verify chainID  matched
-	if header.ChainId != chainBlockValidator.chain.ChainID() {
+	if header.ChainId != chainBlockValidator.chain.Config.BlockChainId(header.Height) {
 		return ErrChainId
 	}
 	// Verify version  matched
@@ -143,7 +143,11 @@ func (chainBlockValidator *ChainBlockValidator) ExecuteBlock(context *block.Bloc
 }
 
 func (chainBlockValidator *ChainBlockValidator) RouteTransaction(context *block.BlockExecuteContext, gasPool *utils.GasPool, tx *types.Transaction) (*types.Receipt, uint64, error) {
-	//init transaction tx
+	//init transaction tx, the sender of a multisig transaction is known once its proof is verified
+	err := transactions.VerifyMultiSig(tx, context.TrieStore, context.Rules)
+	if err != nil {
+		return nil, 0, err
+	}
 	from, err := tx.From()
 	if err != nil {
 		return nil, 0, err
@@ -155,7 +159,7 @@ func (chainBlockValidator *ChainBlockValidator) RouteTransaction(context *block.
 	}
 
 	// Pay intrinsic gastx
-	gas, err := tx.IntrinsicGas()
+	gas, err := transactions.IntrinsicGas(tx)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -185,6 +189,9 @@ func (chainBlockValidator *ChainBlockValidator) RouteTransaction(context *block.
 	}
 	// Set the receipt logs and create a bloom for filtering
 	receipt.Logs = ret.ContractTxLog
+	if sponsorLog := txContext.SponsorLog(); sponsorLog != nil {
+		receipt.Logs = append(receipt.Logs, sponsorLog)
+	}
 	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
 	//receipt.BlockHash = *header.Hash()
 	receipt.BlockNumber = context.Block.Header.Height
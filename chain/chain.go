@@ -20,6 +20,7 @@ import (
 	"github.com/drep-project/DREP-Chain/crypto/sha3"
 	"github.com/drep-project/DREP-Chain/database"
 	"github.com/drep-project/binary"
+	"github.com/pkg/errors"
 
 	rpc2 "github.com/drep-project/DREP-Chain/pkgs/rpc"
 	"github.com/drep-project/DREP-Chain/types"
@@ -28,11 +29,20 @@ import (
 var (
 	DefaultChainConfigMainnet = &ChainConfig{
 		RemotePort:  params.RemotePortMainnet,
-		ChainId:     types.ChainIdType(params.RootChain),
+		ChainId:     types.ChainIdType(params.MainnetChainId),
 		GenesisAddr: params.HoleAddress,
+		ForkConfig:  params.MainnetForks,
 	}
 
 	DefaultChainConfigTestnet = &ChainConfig{
+		RemotePort:  params.RemotePortTestnet,
+		ChainId:     types.ChainIdType(params.TestnetChainId),
+		GenesisAddr: params.HoleAddress,
+		ForkConfig:  params.TestnetForks,
+	}
+
+	//DefaultChainConfigSolonet starts a new develop chain, all forks are active from genesis
+	DefaultChainConfigSolonet = &ChainConfig{
 		RemotePort:  params.RemotePortTestnet,
 		ChainId:     types.ChainIdType(params.RootChain),
 		GenesisAddr: params.HoleAddress,
@@ -59,6 +69,8 @@ type ChainServiceInterface interface {
 	BestChain() *ChainView
 	CalcGasLimit(parent *types.BlockHeader, gasFloor, gasCeil uint64) *big.Int
 	ProcessBlock(block *types.Block) (bool, bool, error)
+	InsertBlockWithoutState(block *types.Block) error
+	SetPivotBlock(hash *crypto.Hash, next *types.Block) error
 	NewBlockFeed() *event.Feed
 	GetLogsFeed() *event.Feed
 	GetRMLogsFeed() *event.Feed
@@ -66,8 +78,6 @@ type ChainServiceInterface interface {
 	Index() *block.BlockIndex
 	BlockValidator() BlockValidators
 	AddBlockValidator(validator IBlockValidator)
-	//TransactionValidators() map[ITransactionSelector]ITransactionValidator
-	//AddTransactionValidator(selector ITransactionSelector, validator ITransactionValidator)
 	AddGenesisProcess(validator IGenesisProcess)
 	GetConfig() *ChainConfig
 	DetachBlockFeed() *event.Feed
@@ -106,10 +116,10 @@ type ChainService struct {
 	rmLogsFeed      event.Feed
 
 	blockValidator BlockValidators
-	//transactionValidator map[ITransactionSelector]ITransactionValidator
 	genesisProcess []IGenesisProcess
 	chainStore     *store.ChainStore
 	genesisConfig  json.RawMessage
+	stateGC        *stateGC
 }
 
 type ChainState struct {
@@ -124,16 +134,23 @@ func (chainService *ChainService) Init(executeContext *app.ExecuteContext) error
 	chainService.orphans = make(map[crypto.Hash]*types.OrphanBlock)
 	chainService.prevOrphans = make(map[crypto.Hash][]*types.OrphanBlock)
 
+	if executeContext.Cli != nil && executeContext.Cli.GlobalIsSet(GCModeFlag.Name) {
+		chainService.Config.GCMode = executeContext.Cli.GlobalString(GCModeFlag.Name)
+	}
+	if executeContext.Cli != nil && executeContext.Cli.GlobalIsSet(StateRetainFlag.Name) {
+		chainService.Config.StateRetain = executeContext.Cli.GlobalUint64(StateRetainFlag.Name)
+	}
+	if chainService.Config.GCMode == "" {
+		chainService.Config.GCMode = GCModeArchive
+	}
+	var err error
+	chainService.stateGC, err = newStateGC(chainService.DatabaseService.StateDb(), chainService.Config.GCMode, chainService.Config.StateRetain)
+	if err != nil {
+		return err
+	}
+	log.WithField("gcmode", chainService.Config.GCMode).WithField("retain", chainService.stateGC.retain).Info("state garbage collection")
 	chainService.blockValidator = []IBlockValidator{NewChainBlockValidator(chainService)}
 	chainService.genesisProcess = []IGenesisProcess{NewPreminerGenesisProcessor()}
-	//chainService.transactionValidator = map[ITransactionSelector]ITransactionValidator{
-	//&TransferTxSelector{}:        &TransferTransactionProcessor{},
-	//&AliasTxSelector{}:           &AliasTransactionProcessor{},
-	//&StakeTxSelector{}:           &StakeTransactionProcessor{},
-	//&CancelVoteTxSelector{}:      &CancelVoteTransactionProcessor{},
-	//&CandidateTxSelector{}:       &CandidateTransactionProcessor{},
-	//&CancelCandidateTxSelector{}: &CancelCandidateTransactionProcessor{},
-	//}
 
 	if _, ok := executeContext.PhaseConfig["genesis"]; ok {
 		chainService.genesisConfig = executeContext.PhaseConfig["genesis"]
@@ -145,7 +162,6 @@ func (chainService *ChainService) Init(executeContext *app.ExecuteContext) error
 		return fmt.Errorf("net type err,type:%s", executeContext.NetConfigType)
 	}
 
-	var err error
 	chainService.genesisBlock, err = chainService.GetGenisiBlock(chainService.Config.GenesisAddr)
 	if err != nil {
 		return err
@@ -158,7 +174,7 @@ func (chainService *ChainService) Init(executeContext *app.ExecuteContext) error
 			log.Error("createChainState err", err)
 			return err
 		}
-		_, err = store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), chainService.genesisBlock.Header.StateRoot)
+		_, err = store.TrieStoreFromDatabase(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), chainService.genesisBlock.Header.StateRoot)
 		if err != nil {
 			return err
 		}
@@ -168,13 +184,30 @@ func (chainService *ChainService) Init(executeContext *app.ExecuteContext) error
 		log.Error("InitStates err:", err)
 		return err
 	}
+	//rewind must be able to run on a chain which conflicts with the schedule to bring it back below the fork
+	if executeContext.Cli == nil || executeContext.Cli.Command.Name != rewindCommandName {
+		err = chainService.checkForkConfig()
+		if err != nil {
+			return err
+		}
+	}
+	err = chainService.indexTxLookups()
+	if err != nil {
+		return err
+	}
 	chainService.apis = []app.API{
 		{
 			Namespace: MODULENAME,
 			Version:   "1.0",
-			Service:   NewChainApi(chainService.DatabaseService.LevelDb(), chainService.BestChain(), chainService.chainStore),
+			Service:   NewChainApi(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), chainService.stateGC, chainService.BestChain(), chainService.blockIndex, chainService.chainStore, &chainService.Config.ForkConfig),
 			Public:    true,
 		},
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewAdminApi(chainService),
+			Public:    false,
+		},
 	}
 	return nil
 }
@@ -184,7 +217,12 @@ func (chainService *ChainService) Start(executeContext *app.ExecuteContext) erro
 }
 
 func (chainService *ChainService) Stop(executeContext *app.ExecuteContext) error {
-	return nil
+	if chainService.stateGC == nil {
+		return nil
+	}
+	chainService.addBlockSync.Lock()
+	defer chainService.addBlockSync.Unlock()
+	return chainService.stateGC.flush(chainService.bestChain.Tip())
 }
 
 func (chainService *ChainService) BlockExists(blockHash *crypto.Hash) bool {
@@ -294,7 +332,7 @@ func (chainService *ChainService) DeriveReceiptRoot(receipts []*types.Receipt) c
 func (chainService *ChainService) createChainState() error {
 	node := types.NewBlockNode(chainService.genesisBlock.Header, nil)
 	node.Status = types.StatusDataStored | types.StatusValid
-	chainService.bestChain.SetTip(node)
+	chainService.setTip(node)
 
 	// Add the new node to the index which is used for faster lookups.
 	chainService.blockIndex.AddNode(node)
@@ -331,18 +369,10 @@ func (chainService *ChainService) BlockValidator() BlockValidators {
 	return chainService.blockValidator
 }
 
-//func (chainService *ChainService) TransactionValidators() map[ITransactionSelector]ITransactionValidator {
-//	return chainService.transactionValidator
-//}
-
 func (chainService *ChainService) AddBlockValidator(validator IBlockValidator) {
 	chainService.blockValidator = append(chainService.blockValidator, validator)
 }
 
-//func (chainService *ChainService) AddTransactionValidator(selector ITransactionSelector, validator ITransactionValidator) {
-//	chainService.transactionValidator[selector] = validator
-//}
-
 func (chainService *ChainService) AddGenesisProcess(validator IGenesisProcess) {
 	chainService.genesisProcess = append(chainService.genesisProcess, validator)
 }
@@ -380,7 +410,7 @@ func (chainService *ChainService) Api() []app.API {
 }
 
 func (chainService *ChainService) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{}
+	return []cli.Command{exportCommand, importCommand, rewindCommand}, []cli.Flag{GCModeFlag, StateRetainFlag}
 }
 
 // DefaultConfig -> config
@@ -388,7 +418,53 @@ func (chainService *ChainService) DefaultConfig(netType params.NetType) *ChainCo
 	switch netType {
 	case params.MainnetType:
 		return DefaultChainConfigMainnet
+	case params.SolonetType:
+		return DefaultChainConfigSolonet
 	default:
 		return DefaultChainConfigTestnet
 	}
 }
+
+//checkForkConfig refuses a fork schedule which gives different rules to blocks already in the chain,
+//a compatible schedule replaces the stored one. A chain without a stored schedule was built before the
+//forks were introduced, none of them was active for its blocks.
+func (chainService *ChainService) checkForkConfig() error {
+	stored, err := chainService.chainStore.GetForkConfig()
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		stored = map[string]uint64{}
+	}
+	compatErr := chainService.Config.CheckCompatible(stored, chainService.bestChain.Height())
+	if compatErr != nil {
+		return errors.Wrapf(ErrForkConfig, "%s, run drep rewind --height %d", compatErr.Error(), compatErr.RewindTo)
+	}
+	return chainService.chainStore.PutForkConfig(chainService.Config.Heights())
+}
+
+//indexTxLookups indexes the transactions of the main chain blocks stored before the tx lookup index existed, the
+//blocks connected later are indexed as they are connected
+func (chainService *ChainService) indexTxLookups() error {
+	indexed, err := chainService.chainStore.HasTxLookupIndex()
+	if err != nil || indexed {
+		return err
+	}
+	height := chainService.bestChain.Height()
+	log.WithField("height", height).Info("index the transactions of the chain")
+	for i := uint64(0); i <= height; i++ {
+		node := chainService.bestChain.NodeByHeight(i)
+		block, err := chainService.chainStore.GetBlock(node.Hash)
+		if err != nil {
+			return errors.Wrapf(err, "index the transactions of block %d", i)
+		}
+		err = chainService.chainStore.PutTxLookupEntries(block)
+		if err != nil {
+			return err
+		}
+		if i > 0 && i%10000 == 0 {
+			log.WithField("height", i).Info("index the transactions of the chain")
+		}
+	}
+	return chainService.chainStore.PutTxLookupIndex()
+}
@@ -0,0 +1,129 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func newForkTestService(height uint64, forks params.ForkConfig) *ChainService {
+	var tip *types.BlockNode
+	for i := uint64(0); i <= height; i++ {
+		tip = types.NewBlockNode(&types.BlockHeader{Height: i, Timestamp: i}, tip)
+	}
+	return &ChainService{
+		Config:     &ChainConfig{ForkConfig: forks},
+		bestChain:  NewChainView(tip),
+		chainStore: &store.ChainStore{KeyValueStore: memorydb.New()},
+	}
+}
+
+func TestCheckForkConfig(t *testing.T) {
+	//a new chain takes the schedule
+	chainService := newForkTestService(0, params.ForkConfig{})
+	if err := chainService.checkForkConfig(); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := chainService.chainStore.GetForkConfig()
+	if err != nil || len(stored) != len(params.MainnetForks.Forks()) {
+		t.Fatalf("expect the schedule stored, got %v %v", stored, err)
+	}
+
+	//a chain built before the forks were introduced had none of them active
+	chainService = newForkTestService(10, params.ForkConfig{})
+	if err := chainService.checkForkConfig(); errors.Cause(err) != ErrForkConfig {
+		t.Fatalf("expect forks active from genesis refused on an old chain, got %v", err)
+	}
+	if stored, err := chainService.chainStore.GetForkConfig(); err != nil || stored != nil {
+		t.Fatalf("expect nothing stored for a refused schedule, got %v %v", stored, err)
+	}
+	chainService.Config.ForkConfig = params.MainnetForks
+	if err := chainService.checkForkConfig(); err != nil {
+		t.Fatalf("expect forks above the head accepted on an old chain, got %v", err)
+	}
+}
+
+func TestGetForkConfigError(t *testing.T) {
+	db := memorydb.New()
+	chainStore := &store.ChainStore{KeyValueStore: db}
+	if stored, err := chainStore.GetForkConfig(); err != nil || stored != nil {
+		t.Fatalf("expect no schedule, got %v %v", stored, err)
+	}
+	db.Close()
+	if _, err := chainStore.GetForkConfig(); err == nil {
+		t.Fatal("expect the error of the database returned")
+	}
+}
+
+func TestBlockChainId(t *testing.T) {
+	config := &ChainConfig{ChainId: types.ChainIdType(params.MainnetChainId)}
+	config.ReplayProtectionHeight = 10
+	if chainId := config.BlockChainId(9); chainId != types.ChainIdType(params.RootChain) {
+		t.Fatalf("expect the root chain id before replay protection, got %d", chainId)
+	}
+	if chainId := config.BlockChainId(10); chainId != types.ChainIdType(params.MainnetChainId) {
+		t.Fatalf("expect the chain id of the network with replay protection, got %d", chainId)
+	}
+	if DefaultChainConfigMainnet.ChainId == DefaultChainConfigTestnet.ChainId {
+		t.Fatal("expect the networks to have their own chain id")
+	}
+}
+
+func TestIndexTxLookups(t *testing.T) {
+	blocks := exportTestBlocks(10)
+	chainStore := &store.ChainStore{KeyValueStore: memorydb.New()}
+	var tip *types.BlockNode
+	for _, block := range blocks {
+		tip = types.NewBlockNode(block.Header, tip)
+		if err := chainStore.PutBlock(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chainService := &ChainService{bestChain: NewChainView(tip), chainStore: chainStore}
+
+	//a chain stored before the index existed is indexed on startup
+	if err := chainService.indexTxLookups(); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		entry, err := chainStore.GetTxLookupEntry(block.Data.TxList[0].TxHash())
+		if err != nil || entry.BlockHash != *block.Header.Hash() || entry.Height != block.Header.Height || entry.Index != 0 {
+			t.Fatalf("expect the tx of block %d indexed, got %v %v", block.Header.Height, entry, err)
+		}
+	}
+	if indexed, err := chainStore.HasTxLookupIndex(); err != nil || !indexed {
+		t.Fatalf("expect the index recorded as complete, got %v %v", indexed, err)
+	}
+
+	//an indexed chain is not scanned again
+	if err := chainStore.DeleteTxLookupEntries(blocks[3]); err != nil {
+		t.Fatal(err)
+	}
+	if err := chainService.indexTxLookups(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chainStore.GetTxLookupEntry(blocks[3].Data.TxList[0].TxHash()); err == nil {
+		t.Fatal("expect the chain indexed once")
+	}
+}
+
+func TestIndexTxLookupsMissingBlock(t *testing.T) {
+	blocks := exportTestBlocks(3)
+	chainStore := &store.ChainStore{KeyValueStore: memorydb.New()}
+	var tip *types.BlockNode
+	for _, block := range blocks {
+		tip = types.NewBlockNode(block.Header, tip)
+	}
+	chainStore.PutBlock(blocks[0])
+	chainService := &ChainService{bestChain: NewChainView(tip), chainStore: chainStore}
+	if err := chainService.indexTxLookups(); err == nil {
+		t.Fatal("expect a missing block to fail the index")
+	}
+	if indexed, _ := chainStore.HasTxLookupIndex(); indexed {
+		t.Fatal("expect an incomplete index not recorded as complete")
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"math/big"
 
+	"github.com/drep-project/DREP-Chain/chain/block"
+	"github.com/drep-project/DREP-Chain/chain/proof"
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/common/hexutil"
@@ -25,16 +27,24 @@ prefix:chain
 
 */
 type ChainApi struct {
-	store     dbinterface.KeyValueStore
-	chainView *ChainView
-	dbQuery   *store.ChainStore
+	store      dbinterface.KeyValueStore
+	stateDb    *trie.Database
+	stateGC    *stateGC
+	chainView  *ChainView
+	blockIndex *block.BlockIndex
+	dbQuery    *store.ChainStore
+	forks      *params.ForkConfig
 }
 
-func NewChainApi(store dbinterface.KeyValueStore, chainView *ChainView, dbQuery *store.ChainStore) *ChainApi {
+func NewChainApi(store dbinterface.KeyValueStore, stateDb *trie.Database, stateGC *stateGC, chainView *ChainView, blockIndex *block.BlockIndex, dbQuery *store.ChainStore, forks *params.ForkConfig) *ChainApi {
 	return &ChainApi{
-		store:     store,
-		chainView: chainView,
-		dbQuery:   dbQuery,
+		store:      store,
+		stateDb:    stateDb,
+		stateGC:    stateGC,
+		chainView:  chainView,
+		blockIndex: blockIndex,
+		dbQuery:    dbQuery,
+		forks:      forks,
 	}
 }
 
@@ -142,19 +152,33 @@ func (chain *ChainApi) GetBlockGasInfo() string {
  usage: Query address balance
  params:
 	1. Query address
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return: The account balance in the address
- example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBalance","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBalance","params":["0x8a8e541ddd1272d53729164c70197221a3c27486", "latest"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":9987999999999984000000}
 */
-func (chain *ChainApi) GetBalance(addr crypto.CommonAddress) string {
-	store, err := store.TrieStoreFromStore(chain.store, chain.chainView.Tip().StateRoot)
+func (chain *ChainApi) GetBalance(addr crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (string, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
 	if err != nil {
-		return ""
+		return "", err
 	}
-	big := store.GetBalance(&addr, chain.chainView.tip().Height)
-
-	return big.String()
+	storage, err := trieQuery.GetStorage(&addr)
+	if err != nil {
+		return "", chain.stateError(node, err)
+	}
+	//the cancelled credit whose refund is due counts to the balance like in the store
+	stakeStorage, err := trieQuery.getStakeStorage(&addr)
+	if err != nil {
+		return "", chain.stateError(node, err)
+	}
+	changeInterval, err := store.ReadChangeInterval(trieQuery.KeyValueStore)
+	if err != nil {
+		return "", err
+	}
+	balance := store.MaturedCancelCredit(stakeStorage, node.Height, changeInterval)
+	balance.Add(balance, &storage.Balance)
+	return balance.String(), nil
 }
 
 /*
@@ -162,14 +186,18 @@ func (chain *ChainApi) GetBalance(addr crypto.CommonAddress) string {
  usage: Query the nonce whose address is on the chain
  params:
 	1. Query address
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return: nonce
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getNonce","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":0}
 */
-func (chain *ChainApi) GetNonce(addr crypto.CommonAddress) uint64 {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.GetNonce(&addr)
+func (chain *ChainApi) GetNonce(addr crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (uint64, error) {
+	storage, err := chain.getStorage(&addr, blockNrOrHash)
+	if err != nil {
+		return 0, err
+	}
+	return storage.Nonce, nil
 }
 
 /*
@@ -177,14 +205,18 @@ func (chain *ChainApi) GetNonce(addr crypto.CommonAddress) uint64 {
  usage: Query the reputation value of the address
  params:
 	1.  Query address
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return: The reputation value corresponding to the address
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getReputation","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":1}
 */
-func (chain *ChainApi) GetReputation(addr crypto.CommonAddress) *big.Int {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.GetReputation(&addr)
+func (chain *ChainApi) GetReputation(addr crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (*big.Int, error) {
+	storage, err := chain.getStorage(&addr, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.Reputation, nil
 }
 
 /*
@@ -227,19 +259,206 @@ func (chain *ChainApi) GetTransactionByBlockHeightAndIndex(height uint64, index
 	return block.Data.TxList[index], nil
 }
 
+/*
+ name: getTransactionByHash
+ usage: Gets a transaction of the main chain by its hash, together with the block that includes it. Works without the trace module
+ params:
+	1. transaction hash
+ return: transaction with blockHash, blockHeight and index of the transaction in the block
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getTransactionByHash","params":["0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040"], "id": 3}' -H "Content-Type:application/json"
+ response:
+{
+  "jsonrpc": "2.0",
+  "id": 3,
+  "result": {
+    "Hash": "0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040",
+    "From": "0x7923a30bbfbcb998a6534d56b313e68c8e0c594a",
+    "Version": 1,
+    "Nonce": 15632,
+    "Type": 0,
+    "To": "0x7923a30bbfbcb998a6534d56b313e68c8e0c594a",
+    "ChainId": 0,
+    "Amount": "0x111",
+    "GasPrice": "0x110",
+    "GasLimit": "0x30000",
+    "Timestamp": 1559322808,
+    "Data": null,
+    "Sig": "0x20f25b86c4bf73aa4fa0bcb01e2f5731de3a3917c8861d1ce0574a8d8331aedcf001e678000f6afc95d35a53ef623a2055fce687f85c2fd752dc455ab6db802b1f",
+    "BlockHash": "0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6",
+    "BlockHeight": 1024,
+    "Index": 0
+  }
+}
+*/
+func (chain *ChainApi) GetTransactionByHash(txHash crypto.Hash) (*RpcTransaction, error) {
+	entry, err := chain.dbQuery.GetTxLookupEntry(&txHash)
+	if err != nil {
+		return nil, ErrTxNotFound
+	}
+	node := chain.blockIndex.LookupNode(&entry.BlockHash)
+	if node == nil || !chain.chainView.Contains(node) {
+		return nil, ErrTxNotFound
+	}
+	block, err := chain.dbQuery.GetBlock(&entry.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Index >= uint64(len(block.Data.TxList)) {
+		return nil, ErrTxIndexOutOfRange
+	}
+	rpcTx := new(RpcTransaction).FromTx(block.Data.TxList[entry.Index])
+	rpcTx.BlockHash = entry.BlockHash
+	rpcTx.BlockHeight = entry.Height
+	rpcTx.Index = entry.Index
+	return rpcTx, nil
+}
+
+/*
+ name: getBlockByHash
+ usage: Used to obtain block information by block hash
+ params:
+	1. block hash
+ return: Block detail information, the same as getBlock
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBlockByHash","params":["0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"Header":{"ChainId":0,"Version":1,"PreviousHash":"0x...","GasLimit":18000000,"GasUsed":0,"Height":1,"Timestamp":1592365562,"StateRoot":"...","TxRoot":null,"ReceiptRoot":"0x...","Bloom":"0x..."},"Data":{"TxCount":0,"TxList":null},"Proof":{"Type":0,"Evidence":"..."}}}
+*/
+func (chain *ChainApi) GetBlockByHash(hash crypto.Hash) (*types.Block, error) {
+	if chain.blockIndex.LookupNode(&hash) == nil {
+		return nil, ErrBlockNotFound
+	}
+	return chain.dbQuery.GetBlock(&hash)
+}
+
+/*
+ name: getBlockReceipts
+ usage: Gets the receipts of all transactions in a block
+ params:
+	1. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
+ return: receipts in the order of the transactions of the block, an error for a block stored by fast sync at or below its pivot
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBlockReceipts","params":[1024], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":[{"PostState":"...","Status":1,"CumulativeGasUsed":30000,"Logs":[],"Bloom":"0x...","TxHash":"0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040","ContractAddress":"0x0000000000000000000000000000000000000000","GasUsed":30000,"BlockHash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","BlockNumber":1024}]}
+*/
+func (chain *ChainApi) GetBlockReceipts(blockNrOrHash *types.BlockNumberOrHash) ([]*types.Receipt, error) {
+	node, err := chain.blockNode(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	err = chain.dbQuery.CheckReceipts(node.Height)
+	if err != nil {
+		return nil, err
+	}
+	return chain.dbQuery.GetReceipts(*node.Hash), nil
+}
+
+/*
+ name: getMultiSigAccount
+ usage: Get the threshold and the keys of a multisig account
+ params:
+	1. address of the multisig account
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
+ return: threshold and public keys, null if the address is no multisig account
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getMultiSigAccount","params":["0x9c5f7e4a5b0e7b5c9d0a9cdb0cb0f3d2e1b5a4c7"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"Threshold":2,"Pubkeys":["0x03177b8e4ef31f4f801ce00260db1b04cc501287e828692a404fdbc46c7ad6ff26","0x02e8a4b8f9d5e3a0f5c3c06e5ed4a31b4ab1e1a6a5a3d0b0e2a6a4f6f1c0b5a9e3","0x0350a6a3ee7f9a6c6a5cd63ee6c9c3d6f6a0b5d4e86c3f63ccd2e6f0a3a2b1c0d9"]}}
+*/
+func (chain *ChainApi) GetMultiSigAccount(addr *crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (*types.MultiSigAccount, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	account, err := trieQuery.GetMultiSigAccount(addr)
+	if err != nil {
+		return nil, chain.stateError(node, err)
+	}
+	return account, nil
+}
+
+/*
+ name: getAsset
+ usage: Get the issuer, supply and mint authority of a native asset
+ params:
+	1. symbol of the asset
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
+ return: the asset, null if it was not issued
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getAsset","params":["POINT", "latest"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"symbol":"POINT","issuer":"0x8a8e541ddd1272d53729164c70197221a3c27486","supply":"0xf4240","mintAuthority":"0x8a8e541ddd1272d53729164c70197221a3c27486"}}
+*/
+func (chain *ChainApi) GetAsset(symbol string, blockNrOrHash *types.BlockNumberOrHash) (*types.Asset, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := trieQuery.GetAsset(symbol)
+	if err != nil {
+		return nil, chain.stateError(node, err)
+	}
+	return asset, nil
+}
+
+/*
+ name: getAssetBalance
+ usage: Query the balance of a native asset of an address
+ params:
+	1. Query address
+	2. symbol of the asset
+	3. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
+ return: The balance of the asset
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getAssetBalance","params":["0x8a8e541ddd1272d53729164c70197221a3c27486", "POINT", "latest"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":"1000000"}
+*/
+func (chain *ChainApi) GetAssetBalance(addr crypto.CommonAddress, symbol string, blockNrOrHash *types.BlockNumberOrHash) (string, error) {
+	storage, err := chain.getStorage(&addr, blockNrOrHash)
+	if err != nil {
+		return "", err
+	}
+	balance := storage.BalanceMap[symbol]
+	return balance.String(), nil
+}
+
+/*
+ name: getAssetBalances
+ usage: Query the balances of all native assets of an address
+ params:
+	1. Query address
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
+ return: the balances by symbol, assets with a zero balance are left out
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getAssetBalances","params":["0x8a8e541ddd1272d53729164c70197221a3c27486", "latest"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"GOLD":"25","POINT":"1000000"}}
+*/
+func (chain *ChainApi) GetAssetBalances(addr crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (map[string]string, error) {
+	storage, err := chain.getStorage(&addr, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	balances := map[string]string{}
+	for symbol, balance := range storage.BalanceMap {
+		balances[symbol] = balance.String()
+	}
+	return balances, nil
+}
+
 /*
  name: getAliasByAddress
  usage: Gets the alias corresponding to the address according to the address
  params:
 	1. address
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return: Address the alias
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getAliasByAddress","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
  response:
 	{"jsonrpc":"2.0","id":3,"result":"tom"}
 */
-func (chain *ChainApi) GetAliasByAddress(addr *crypto.CommonAddress) string {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.GetStorageAlias(addr)
+func (chain *ChainApi) GetAliasByAddress(addr *crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (string, error) {
+	storage, err := chain.getStorage(addr, blockNrOrHash)
+	if err != nil {
+		return "", err
+	}
+	return storage.Alias, nil
 }
 
 /*
@@ -247,14 +466,22 @@ func (chain *ChainApi) GetAliasByAddress(addr *crypto.CommonAddress) string {
  usage: Gets the address corresponding to the alias based on the alias
  params:
 	1. Alias to be queried
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return: The address corresponding to the alias
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getAddressByAlias","params":["tom"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":"0x8a8e541ddd1272d53729164c70197221a3c27486"}
 */
-func (chain *ChainApi) GetAddressByAlias(alias string) (*crypto.CommonAddress, error) {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.AliasGet(alias)
+func (chain *ChainApi) GetAddressByAlias(alias string, blockNrOrHash *types.BlockNumberOrHash) (*crypto.CommonAddress, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := trieQuery.AliasGet(alias)
+	if err != nil {
+		return nil, chain.stateError(node, err)
+	}
+	return addr, nil
 }
 
 /*
@@ -262,26 +489,34 @@ func (chain *ChainApi) GetAddressByAlias(alias string) (*crypto.CommonAddress, e
  usage: Get the receipt information based on txhash
  params:
 	1. txhash
- return: receipt
+ return: receipt, an error for a transaction of a block stored by fast sync at or below its pivot
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getReceipt","params":["0x7d9dd32ca192e765ff2abd7c5f8931cc3f77f8f47d2d52170c7804c2ca2c5dd9"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":""}
 */
-func (chain *ChainApi) GetReceipt(txHash crypto.Hash) *types.Receipt {
-	return chain.dbQuery.GetReceipt(txHash)
+func (chain *ChainApi) GetReceipt(txHash crypto.Hash) (*types.Receipt, error) {
+	err := chain.dbQuery.CheckTxReceipt(&txHash)
+	if err != nil {
+		return nil, err
+	}
+	return chain.dbQuery.GetReceipt(txHash), nil
 }
 
 /*
  name: getLogs
- usage: Get the transaction log information based on txhash
+ usage: Get the transaction log information based on txhash, transfer, stake and alias transactions log an event at the system address 0x00000000000000000000000000000000000000ff
  params:
 	1. txhash
- return: []log
+ return: []log, an error for a transaction of a block stored by fast sync at or below its pivot
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getLogs","params":["0x7d9dd32ca192e765ff2abd7c5f8931cc3f77f8f47d2d52170c7804c2ca2c5dd9"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":""}
 */
-func (chain *ChainApi) GetLogs(txHash crypto.Hash) []*types.Log {
+func (chain *ChainApi) GetLogs(txHash crypto.Hash) ([]*types.Log, error) {
+	err := chain.dbQuery.CheckTxReceipt(&txHash)
+	if err != nil {
+		return nil, err
+	}
 	//return chain.chainService.chainStore.GetLogs(txHash)
 	rt := chain.dbQuery.GetReceipt(txHash)
 	if rt != nil {
@@ -295,10 +530,10 @@ func (chain *ChainApi) GetLogs(txHash crypto.Hash) []*types.Log {
 		//	}
 		//}
 
-		return rt.Logs
+		return rt.Logs, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
 /*
@@ -306,12 +541,16 @@ func (chain *ChainApi) GetLogs(txHash crypto.Hash) []*types.Log {
  usage: Get the back pledge or back vote information according to txhash
  params:
 	1. txhash
- return: {}
+ return: {}, an error for a transaction of a block stored by fast sync at or below its pivot
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getCancelCreditDetailByTXHash","params":["0x7d9dd32ca192e765ff2abd7c5f8931cc3f77f8f47d2d52170c7804c2ca2c5dd9"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":[]}
 */
-func (chain *ChainApi) GetCancelCreditDetailByTXHash(txHash crypto.Hash) []*types.CancelCreditDetail {
+func (chain *ChainApi) GetCancelCreditDetailByTXHash(txHash crypto.Hash) ([]*types.CancelCreditDetail, error) {
+	err := chain.dbQuery.CheckTxReceipt(&txHash)
+	if err != nil {
+		return nil, err
+	}
 	//return chain.chainService.chainStore.GetLogs(txHash)
 	ids := make([]*types.CancelCreditDetail, 0)
 	rt := chain.dbQuery.GetReceipt(txHash)
@@ -326,10 +565,10 @@ func (chain *ChainApi) GetCancelCreditDetailByTXHash(txHash crypto.Hash) []*type
 			}
 		}
 
-		return ids
+		return ids, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
 /*
@@ -337,14 +576,18 @@ func (chain *ChainApi) GetCancelCreditDetailByTXHash(txHash crypto.Hash) []*type
  usage: Get bytecode by address
  params:
 	1. address
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return: bytecode
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getByteCode","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":"0x00"}
 */
-func (chain *ChainApi) GetByteCode(addr *crypto.CommonAddress) hexutil.Bytes {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.GetByteCode(addr)
+func (chain *ChainApi) GetByteCode(addr *crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (hexutil.Bytes, error) {
+	storage, err := chain.getStorage(addr, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(storage.ByteCode), nil
 }
 
 /*
@@ -352,14 +595,22 @@ func (chain *ChainApi) GetByteCode(addr *crypto.CommonAddress) hexutil.Bytes {
  usage: Get all the details of the stake according to the address
  params:
 	1. address
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return: bytecode
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getCreditDetails","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":"[{\"Addr\":\"DREPd05d5f324ada3c418e14cd6b497f2f36d60ba607\",\"HeightValues\":[{\"CreditHeight\":1329,\"CreditValue\":\"0x11135\"}]}]"}
 */
-func (chain *ChainApi) GetCreditDetails(addr *crypto.CommonAddress) string {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.GetVoteCreditDetails(addr)
+func (chain *ChainApi) GetCreditDetails(addr *crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (string, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return "", err
+	}
+	details, err := trieQuery.GetVoteCreditDetails(addr)
+	if err != nil {
+		return "", chain.stateError(node, err)
+	}
+	return details, nil
 }
 
 /*
@@ -367,29 +618,44 @@ func (chain *ChainApi) GetCreditDetails(addr *crypto.CommonAddress) string {
  usage: Get the details of all refund requests
  params:
 	1. address
+	2. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return: bytecode
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getCancelCreditDetails","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":"{\"DREP300fc5a14e578be28c64627c0e7e321771c58cd4\":\"0x3641100\"}"}
 */
-func (chain *ChainApi) GetCancelCreditDetails(addr *crypto.CommonAddress) string {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.GetCancelCreditDetails(addr)
+func (chain *ChainApi) GetCancelCreditDetails(addr *crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (string, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return "", err
+	}
+	details, err := trieQuery.GetCancelCreditDetails(addr)
+	if err != nil {
+		return "", chain.stateError(node, err)
+	}
+	return details, nil
 }
 
 /*
  name: GetCandidateAddrs
  usage: Gets the addresses of all candidate nodes and the corresponding trust values
  params:
-	1. address
+	1. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return:  []
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getCandidateAddrs","params":[""], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":"{\"DREP300fc5a14e578be28c64627c0e7e321771c58cd4\":\"0x3641100\"}"}
 */
-func (chain *ChainApi) GetCandidateAddrs() string {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.GetCandidateAddrs()
+func (chain *ChainApi) GetCandidateAddrs(blockNrOrHash *types.BlockNumberOrHash) (string, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return "", err
+	}
+	addrs, err := trieQuery.GetCandidateAddrs()
+	if err != nil {
+		return "", chain.stateError(node, err)
+	}
+	return addrs, nil
 }
 
 ///*
@@ -431,14 +697,14 @@ func (chain *ChainApi) GetCandidateAddrs() string {
  name: getChangeCycle
  usage: Gets the transition period of the out - of - block node
  params:
-	none
+	1. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
  return:  Transition period
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getChangeCycle","params":"", "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":"{100}"}
 */
-func (chain *ChainApi) GetChangeCycle() (int, error) {
-	store, err := store.TrieStoreFromStore(chain.store, chain.chainView.Tip().StateRoot)
+func (chain *ChainApi) GetChangeCycle(blockNrOrHash *types.BlockNumberOrHash) (int, error) {
+	store, _, err := chain.trieStore(blockNrOrHash)
 	if err != nil {
 		return 0, err
 	}
@@ -457,14 +723,22 @@ func (chain *ChainApi) GetChangeCycle() (int, error) {
  response:
    {"jsonrpc":"2.0","id":3,"result":"{100}"}
 */
-func (chain *ChainApi) GetReward(addr *crypto.CommonAddress) (int, error) {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	supporters := trieQuery.GetVoteCreditDetails(addr)
-	var rec []types.ReceivedCredit
-	err := json.Unmarshal([]byte(supporters), &rec)
+func (chain *ChainApi) GetReward(addr *crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (int, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
 	if err != nil {
 		return -1, err
 	}
+	supporters, err := trieQuery.GetVoteCreditDetails(addr)
+	if err != nil {
+		return -1, chain.stateError(node, err)
+	}
+	var rec []types.ReceivedCredit
+	if supporters != "" {
+		err = json.Unmarshal([]byte(supporters), &rec)
+		if err != nil {
+			return -1, err
+		}
+	}
 	for _, v := range rec {
 		if v.Addr != *addr {
 			return params.Rewards * 0.8, nil
@@ -473,6 +747,125 @@ func (chain *ChainApi) GetReward(addr *crypto.CommonAddress) (int, error) {
 	return params.Rewards, nil
 }
 
+/*
+ name: getProof
+ usage: Get the merkle proof of an account and its storage slots
+ params:
+	1. address
+	2. storage keys
+	3. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
+ return: account storage, which carries the contract storage root, and the trie nodes from the state root of the block to the account and from the storage root to each storage key, a slot not written since the contract storage fork is proven by its legacy key
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getProof","params":["0x8a8e541ddd1272d53729164c70197221a3c27486", [], "latest"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"address":"0x8a8e541ddd1272d53729164c70197221a3c27486","blockHash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","height":1,"stateRoot":"0x529327...","storage":"0x...","accountProof":["0x..."],"storageProof":[]}}
+*/
+func (chain *ChainApi) GetProof(addr crypto.CommonAddress, storageKeys []crypto.Hash, blockNrOrHash *types.BlockNumberOrHash) (*proof.AccountResult, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+
+	accountKey := proof.AccountKey(&addr)
+	storage, err := trieQuery.Get(accountKey)
+	if err != nil {
+		return nil, chain.stateError(node, err)
+	}
+	accountProof := trie.ProofList{}
+	if err := trieQuery.Prove(accountKey, &accountProof); err != nil {
+		return nil, chain.stateError(node, err)
+	}
+
+	result := &proof.AccountResult{
+		Address:      addr,
+		BlockHash:    *node.Hash,
+		Height:       node.Height,
+		StateRoot:    node.StateRoot,
+		Storage:      storage,
+		AccountProof: proof.ToProof(accountProof),
+		StorageProof: make([]proof.StorageResult, len(storageKeys)),
+	}
+
+	if !chain.forks.Rules(node.Height).IsContractStorage {
+		for i, key := range storageKeys {
+			value, slotProof, err := trieQuery.legacySlotProof(&addr, key)
+			if err != nil {
+				return nil, chain.stateError(node, err)
+			}
+			result.StorageProof[i] = proof.StorageResult{Key: key, Value: value, Proof: slotProof}
+		}
+		return result, nil
+	}
+
+	storageTrie, err := trieQuery.StorageTrie(&addr)
+	if err != nil {
+		return nil, chain.stateError(node, err)
+	}
+	for i, key := range storageKeys {
+		value, err := storageTrie.TryGet(key[:])
+		if err != nil {
+			return nil, chain.stateError(node, err)
+		}
+		slotProof := trie.ProofList{}
+		if err := storageTrie.Prove(key[:], 0, &slotProof); err != nil {
+			return nil, chain.stateError(node, err)
+		}
+		result.StorageProof[i] = proof.StorageResult{Key: key, Value: value, Proof: proof.ToProof(slotProof)}
+		if len(value) == 0 {
+			//the slot was not written since the fork, it is still read from its legacy key
+			result.StorageProof[i].Value, result.StorageProof[i].LegacyProof, err = trieQuery.legacySlotProof(&addr, key)
+			if err != nil {
+				return nil, chain.stateError(node, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+/*
+ name: getStorageAt
+ usage: Get the value of a contract storage slot
+ params:
+	1. contract address
+	2. storage slot
+	3. block height, block hash or "latest"/"earliest"/"pending" (optional, default latest)
+ return: value of the slot, 32 bytes
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getStorageAt","params":["0x8a8e541ddd1272d53729164c70197221a3c27486", "0x0000000000000000000000000000000000000000000000000000000000000000", "latest"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":"0x0000000000000000000000000000000000000000000000000000000000000001"}
+*/
+func (chain *ChainApi) GetStorageAt(addr crypto.CommonAddress, slot crypto.Hash, blockNrOrHash *types.BlockNumberOrHash) (hexutil.Bytes, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	if !chain.forks.Rules(node.Height).IsContractStorage {
+		value, err = trieQuery.Get(proof.LegacySlotKey(&addr, slot))
+	} else {
+		var storageTrie *trie.SecureTrie
+		storageTrie, err = trieQuery.StorageTrie(&addr)
+		if err == nil {
+			value, err = storageTrie.TryGet(slot[:])
+		}
+		if err == nil && len(value) == 0 {
+			value, err = trieQuery.Get(proof.LegacySlotKey(&addr, slot))
+		}
+	}
+	if err != nil {
+		return nil, chain.stateError(node, err)
+	}
+	return crypto.BytesToHash(value).Bytes(), nil
+}
+
+// stateError reports trie nodes missing below the state root of node as a MissingStateError
+func (chain *ChainApi) stateError(node *types.BlockNode, err error) error {
+	if _, ok := err.(*trie.MissingNodeError); ok {
+		return chain.missingState(node)
+	}
+	return err
+}
+
 func (chain *ChainApi) GetAvgPrice(height uint64) (*big.Int, error) {
 	block, err := chain.GetBlock(height)
 	if err != nil {
@@ -486,15 +879,101 @@ func (chain *ChainApi) GetAvgPrice(height uint64) (*big.Int, error) {
 
 }
 
+// blockNode resolves the block selected by blockNrOrHash. The current tip is
+// used when no selector is given, there is no pending state so "pending" also
+// resolves to the tip.
+func (chain *ChainApi) blockNode(blockNrOrHash *types.BlockNumberOrHash) (*types.BlockNode, error) {
+	if blockNrOrHash == nil {
+		return chain.chainView.Tip(), nil
+	}
+	if blockNrOrHash.BlockHash != nil {
+		node := chain.blockIndex.LookupNode(blockNrOrHash.BlockHash)
+		if node == nil {
+			return nil, ErrBlockNotFound
+		}
+		return node, nil
+	}
+	if blockNrOrHash.BlockNumber != nil {
+		switch number := *blockNrOrHash.BlockNumber; number {
+		case common.LatestBlockNumber, common.PendingBlockNumber:
+			return chain.chainView.Tip(), nil
+		default:
+			if number < 0 {
+				return nil, ErrInvalidBlockSelector
+			}
+			node := chain.chainView.NodeByHeight(uint64(number))
+			if node == nil {
+				return nil, ErrBlockNotFound
+			}
+			return node, nil
+		}
+	}
+	return nil, ErrInvalidBlockSelector
+}
+
+func (chain *ChainApi) missingState(node *types.BlockNode) error {
+	err := &MissingStateError{
+		Height: node.Height,
+		Hash:   *node.Hash,
+		Root:   crypto.Bytes2Hash(node.StateRoot),
+	}
+	if tip := chain.chainView.Tip(); tip != nil && chain.stateGC != nil && chain.stateGC.pruned(node, tip) {
+		err.Pruned = true
+		err.Retain = chain.stateGC.retain
+	}
+	return err
+}
+
+// trieQuery opens a read only view of the state of the selected block
+func (chain *ChainApi) trieQuery(blockNrOrHash *types.BlockNumberOrHash) (*TrieQuery, *types.BlockNode, error) {
+	node, err := chain.blockNode(blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	trieQuery, err := NewTrieQuery(chain.store, chain.stateDb, node.StateRoot)
+	if err != nil {
+		return nil, nil, chain.missingState(node)
+	}
+	return trieQuery, node, nil
+}
+
+// trieStore opens the state of the selected block through the store, which also
+// accounts for stake refunds that matured at the block height
+func (chain *ChainApi) trieStore(blockNrOrHash *types.BlockNumberOrHash) (store.StoreInterface, *types.BlockNode, error) {
+	node, err := chain.blockNode(blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	trieStore, err := store.TrieStoreFromDatabase(chain.store, chain.stateDb, node.StateRoot)
+	if err != nil {
+		return nil, nil, chain.missingState(node)
+	}
+	return trieStore, node, nil
+}
+
+func (chain *ChainApi) getStorage(addr *crypto.CommonAddress, blockNrOrHash *types.BlockNumberOrHash) (*types.Storage, error) {
+	trieQuery, node, err := chain.trieQuery(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := trieQuery.GetStorage(addr)
+	if err != nil {
+		return nil, chain.stateError(node, err)
+	}
+	return &storage, nil
+}
+
 type TrieQuery struct {
 	dbinterface.KeyValueStore
-	trie *trie.SecureTrie
-	root []byte
+	trieDb *trie.Database
+	trie   *trie.SecureTrie
+	root   []byte
 }
 
-func NewTrieQuery(store dbinterface.KeyValueStore, root []byte) (*TrieQuery, error) {
-	trieQuery := &TrieQuery{store, nil, root}
-	trieDb := trie.NewDatabaseWithCache(store, 0)
+//NewTrieQuery opens the state at root, trieDb should be the state database shared with the chain,
+//the recent states of a node running with gcmode=full are only kept in its memory
+func NewTrieQuery(store dbinterface.KeyValueStore, trieDb *trie.Database, root []byte) (*TrieQuery, error) {
+	trieQuery := &TrieQuery{store, trieDb, nil, root}
 	var err error
 	trieQuery.trie, err = trie.NewSecure(crypto.Bytes2Hash(root), trieDb)
 	if err != nil {
@@ -507,21 +986,82 @@ func (trieQuery *TrieQuery) Get(key []byte) ([]byte, error) {
 	return trieQuery.trie.TryGet(key)
 }
 
+// Prove writes the merkle proof of key to proofDb
+func (trieQuery *TrieQuery) Prove(key []byte, proofDb dbinterface.KeyValueWriter) error {
+	return trieQuery.trie.Prove(key, 0, proofDb)
+}
+
+// legacySlotProof returns the value of a contract storage slot under its legacy key in the state trie and its proof
+func (trieQuery *TrieQuery) legacySlotProof(addr *crypto.CommonAddress, slot crypto.Hash) ([]byte, []hexutil.Bytes, error) {
+	slotKey := proof.LegacySlotKey(addr, slot)
+	value, err := trieQuery.Get(slotKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	slotProof := trie.ProofList{}
+	if err := trieQuery.Prove(slotKey, &slotProof); err != nil {
+		return nil, nil, err
+	}
+	return value, proof.ToProof(slotProof), nil
+}
+
+// StorageTrie opens the storage trie of the contract at addr
+func (trieQuery *TrieQuery) StorageTrie(addr *crypto.CommonAddress) (*trie.SecureTrie, error) {
+	storage, err := trieQuery.GetStorage(addr)
+	if err != nil {
+		return nil, err
+	}
+	return trie.NewSecure(storage.StorageRoot, trieQuery.trieDb)
+}
+
 func (trieQuery *TrieQuery) GetStorage(addr *crypto.CommonAddress) (types.Storage, error) {
-	key := sha3.Keccak256([]byte(store.AddressStorage + addr.Hex()))
+	key := types.AccountKey(addr)
 	value, err := trieQuery.trie.TryGet(key)
 	storage := types.Storage{}
+	if err != nil {
+		return storage, err
+	}
 	if value == nil {
 		return storage, nil
 	} else {
-		err = binary.Unmarshal(value, &storage)
+		decoded, err := types.DecodeStorage(value)
 		if err != nil {
 			return storage, err
 		}
+		storage = *decoded
+	}
+	if len(storage.ByteCode) > 0 && !storage.HasStorageRoot() {
+		storage.StorageRoot = trie.EmptyRoot
 	}
 	return storage, nil
 }
 
+func (trieQuery *TrieQuery) GetMultiSigAccount(addr *crypto.CommonAddress) (*types.MultiSigAccount, error) {
+	value, err := trieQuery.trie.TryGet(store.MultiSigAccountKey(addr))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	account := &types.MultiSigAccount{}
+	err = binary.Unmarshal(value, account)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (trieQuery *TrieQuery) GetAsset(symbol string) (*types.Asset, error) {
+	value, err := trieQuery.trie.TryGet(store.AssetKey(symbol))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	asset := &types.Asset{}
+	err = binary.Unmarshal(value, asset)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
 func (trieQuery *TrieQuery) GetStorageAlias(addr *crypto.CommonAddress) string {
 	storage, _ := trieQuery.GetStorage(addr)
 	return storage.Alias
@@ -573,53 +1113,42 @@ func (trieQuery *TrieQuery) GetReputation(addr *crypto.CommonAddress) *big.Int {
 	return &storage.Reputation
 }
 
-func (trieQuery *TrieQuery) GetVoteCreditDetails(addr *crypto.CommonAddress) string {
-	key := sha3.Keccak256([]byte(store.StakeStorage + addr.Hex()))
-
-	storage := &types.StakeStorage{}
+func (trieQuery *TrieQuery) GetVoteCreditDetails(addr *crypto.CommonAddress) (string, error) {
+	storage, err := trieQuery.getStakeStorage(addr)
+	if err != nil || storage == nil || len(storage.RC) == 0 {
+		return "", err
+	}
+	b, err := json.Marshal(storage.RC)
+	return string(b), err
+}
 
+//getStakeStorage returns the stake storage of addr, nil if the address has none
+func (trieQuery *TrieQuery) getStakeStorage(addr *crypto.CommonAddress) (*types.StakeStorage, error) {
+	key := sha3.Keccak256([]byte(store.StakeStorage + addr.Hex()))
 	value, err := trieQuery.trie.TryGet(key)
-	if err != nil {
-		log.Errorf("get storage err:%v", err)
-		return ""
-	}
-	if value == nil {
-		return ""
-	} else {
-		err = binary.Unmarshal(value, storage)
-		if err != nil {
-			return ""
-		}
+	if err != nil || value == nil {
+		return nil, err
 	}
-
-	if len(storage.RC) == 0 {
-		return ""
+	storage := &types.StakeStorage{}
+	err = binary.Unmarshal(value, storage)
+	if err != nil {
+		return nil, err
 	}
-	b, _ := json.Marshal(storage.RC)
-	return string(b)
+	return storage, nil
 }
 
-func (trieQuery *TrieQuery) GetCandidateAddrs() string {
-	var addrsBuf []byte
-	var err error
-
+func (trieQuery *TrieQuery) GetCandidateAddrs() (string, error) {
 	key := []byte(store.CandidateAddrs)
 
 	addrs := []crypto.CommonAddress{}
-	addrsBuf = trieQuery.trie.Get(key)
-	if err != nil {
-		log.Errorf("GetCandidateAddrs:%v", err)
-		return ""
-	}
-
-	if addrsBuf == nil {
-		return ""
+	addrsBuf, err := trieQuery.trie.TryGet(key)
+	if err != nil || addrsBuf == nil {
+		return "", err
 	}
 
 	err = binary.Unmarshal(addrsBuf, &addrs)
 	if err != nil {
-		log.Errorf("GetCandidateAddrs, Unmarshal:%v", err)
-		return ""
+		return "", err
 	}
 
 	type AddrAndCrit struct {
@@ -630,17 +1159,9 @@ func (trieQuery *TrieQuery) GetCandidateAddrs() string {
 	ac := make([]AddrAndCrit, 0)
 	for _, addr := range addrs {
 		addr := addr
-		storage := &types.StakeStorage{}
-		key := sha3.Keccak256([]byte(store.StakeStorage + addr.Hex()))
-
-		value, _ := trieQuery.trie.TryGet(key)
-		if value == nil {
-			return ""
-		} else {
-			err = binary.Unmarshal(value, storage)
-			if err != nil {
-				return ""
-			}
+		storage, err := trieQuery.getStakeStorage(&addr)
+		if err != nil || storage == nil {
+			return "", err
 		}
 
 		total := new(big.Int)
@@ -657,33 +1178,16 @@ func (trieQuery *TrieQuery) GetCandidateAddrs() string {
 	}
 
 	b, err := json.Marshal(ac)
-	return string(b)
+	return string(b), err
 }
 
-func (trieQuery *TrieQuery) GetCancelCreditDetails(addr *crypto.CommonAddress) string {
-	key := sha3.Keccak256([]byte(store.StakeStorage + addr.Hex()))
-
-	storage := &types.StakeStorage{}
-	value, err := trieQuery.trie.TryGet(key)
-	if err != nil {
-		log.Errorf("get storage err:%v", err)
-		return ""
+func (trieQuery *TrieQuery) GetCancelCreditDetails(addr *crypto.CommonAddress) (string, error) {
+	storage, err := trieQuery.getStakeStorage(addr)
+	if err != nil || storage == nil || len(storage.CC) == 0 {
+		return "", err
 	}
-	if value == nil {
-		return ""
-	} else {
-		err = binary.Unmarshal(value, storage)
-		if err != nil {
-			return ""
-		}
-	}
-
-	if len(storage.CC) == 0 {
-		return ""
-	}
-
-	b, _ := json.Marshal(storage.CC)
-	return string(b)
+	b, err := json.Marshal(storage.CC)
+	return string(b), err
 }
 
 func (trieQuery *TrieQuery) CheckCancelCandidateType(tx *types.Transaction) error {
@@ -0,0 +1,205 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/block"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+var apiTestAddr = crypto.CommonAddress{1}
+
+//newApiTestChain connects count blocks, the nonce of apiTestAddr is height+1 in the state of each block
+//and its balance is height*10
+func newApiTestChain(t *testing.T, count uint64, gcMode string, retain uint64) (*ChainApi, []*types.BlockNode, *memorydb.Database) {
+	diskDb := memorydb.New()
+	changeInterval := make([]byte, 8)
+	binary.BigEndian.PutUint64(changeInterval, params.ChangeInterval)
+	diskDb.Put([]byte(store.ChangeInterval), changeInterval)
+	trieDb := trie.NewDatabase(diskDb)
+	gc, err := newStateGC(trieDb, gcMode, retain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockIndex := block.NewBlockIndex()
+	root := trie.EmptyRoot[:]
+	var tip *types.BlockNode
+	nodes := make([]*types.BlockNode, 0, count)
+	for height := uint64(0); height < count; height++ {
+		trieStore, err := store.TrieStoreFromDatabase(diskDb, trieDb, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		//other accounts give the state trie some depth
+		for i := byte(0); i < 16; i++ {
+			trieStore.PutNonce(&crypto.CommonAddress{2, i}, height)
+		}
+		if err := trieStore.PutNonce(&apiTestAddr, height+1); err != nil {
+			t.Fatal(err)
+		}
+		if err := trieStore.PutBalance(&apiTestAddr, 0, new(big.Int).SetUint64(height*10)); err != nil {
+			t.Fatal(err)
+		}
+		root = trieStore.GetStateRoot()
+		tip = types.NewBlockNode(&types.BlockHeader{Height: height, Timestamp: height, StateRoot: root}, tip)
+		if err := gc.markState(tip); err != nil {
+			t.Fatal(err)
+		}
+		blockIndex.AddNode(tip)
+		nodes = append(nodes, tip)
+	}
+	return NewChainApi(diskDb, trieDb, gc, NewChainView(tip), blockIndex, nil, &params.ForkConfig{}), nodes, diskDb
+}
+
+func TestChainApiBlockSelector(t *testing.T) {
+	chainApi, nodes, _ := newApiTestChain(t, 10, GCModeFull, 4)
+	tip := nodes[len(nodes)-1]
+
+	tests := []struct {
+		selector *types.BlockNumberOrHash
+		nonce    uint64
+	}{
+		{nil, tip.Height + 1},
+		{types.BlockNumberOrHashWithNumber(common.LatestBlockNumber), tip.Height + 1},
+		{types.BlockNumberOrHashWithNumber(common.PendingBlockNumber), tip.Height + 1},
+		{types.BlockNumberOrHashWithNumber(common.BlockNumber(7)), 8},
+		{types.BlockNumberOrHashWithHash(*nodes[8].Hash), 9},
+	}
+	for i, test := range tests {
+		nonce, err := chainApi.GetNonce(apiTestAddr, test.selector)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if nonce != test.nonce {
+			t.Fatalf("case %d: expect nonce %d, got %d", i, test.nonce, nonce)
+		}
+	}
+
+	balance, err := chainApi.GetBalance(apiTestAddr, types.BlockNumberOrHashWithNumber(common.BlockNumber(7)))
+	if err != nil || balance != "70" {
+		t.Fatalf("expect balance 70 at block 7, got %s %v", balance, err)
+	}
+
+	if _, err := chainApi.GetNonce(apiTestAddr, types.BlockNumberOrHashWithNumber(common.BlockNumber(tip.Height+1))); err != ErrBlockNotFound {
+		t.Fatalf("expect a height above the tip not found, got %v", err)
+	}
+	if _, err := chainApi.GetNonce(apiTestAddr, types.BlockNumberOrHashWithHash(crypto.RandomHash())); err != ErrBlockNotFound {
+		t.Fatalf("expect an unknown hash not found, got %v", err)
+	}
+	if _, err := chainApi.GetNonce(apiTestAddr, types.BlockNumberOrHashWithNumber(common.BlockNumber(-3))); err != ErrInvalidBlockSelector {
+		t.Fatalf("expect a negative height refused, got %v", err)
+	}
+
+	//the state of a block out of the retained window has been dropped
+	_, err = chainApi.GetNonce(apiTestAddr, types.BlockNumberOrHashWithNumber(common.BlockNumber(2)))
+	missing, ok := err.(*MissingStateError)
+	if !ok || !missing.Pruned || missing.Height != 2 || missing.Retain != 4 {
+		t.Fatalf("expect the state of block 2 reported as pruned, got %v", err)
+	}
+}
+
+func TestChainApiMissingStateNode(t *testing.T) {
+	chainApi, nodes, diskDb := newApiTestChain(t, 3, GCModeArchive, 0)
+	node := nodes[1]
+
+	//keep the root of the state but lose the nodes below it
+	it := diskDb.NewIterator()
+	var keys [][]byte
+	for it.Next() {
+		if !bytes.Equal(it.Key(), node.StateRoot) {
+			keys = append(keys, common.CopyBytes(it.Key()))
+		}
+	}
+	it.Release()
+	for _, key := range keys {
+		diskDb.Delete(key)
+	}
+	chainApi.stateDb = trie.NewDatabase(diskDb)
+
+	selector := types.BlockNumberOrHashWithNumber(common.BlockNumber(node.Height))
+	queries := map[string]func() error{
+		"balance": func() error {
+			_, err := chainApi.GetBalance(apiTestAddr, selector)
+			return err
+		},
+		"nonce": func() error {
+			_, err := chainApi.GetNonce(apiTestAddr, selector)
+			return err
+		},
+		"credit details": func() error {
+			_, err := chainApi.GetCreditDetails(&apiTestAddr, selector)
+			return err
+		},
+		"cancel credit details": func() error {
+			_, err := chainApi.GetCancelCreditDetails(&apiTestAddr, selector)
+			return err
+		},
+		"candidate addrs": func() error {
+			_, err := chainApi.GetCandidateAddrs(selector)
+			return err
+		},
+		"reward": func() error {
+			_, err := chainApi.GetReward(&apiTestAddr, selector)
+			return err
+		},
+	}
+	for name, query := range queries {
+		err := query()
+		missing, ok := err.(*MissingStateError)
+		if !ok || missing.Pruned || missing.Height != node.Height {
+			t.Fatalf("%s: expect the missing state of block %d reported, got %v", name, node.Height, err)
+		}
+	}
+}
+
+func TestChainApiEmptyStake(t *testing.T) {
+	chainApi, _, _ := newApiTestChain(t, 3, GCModeArchive, 0)
+	details, err := chainApi.GetCreditDetails(&apiTestAddr, nil)
+	if err != nil || details != "" {
+		t.Fatalf("expect no credit details, got %q %v", details, err)
+	}
+	reward, err := chainApi.GetReward(&apiTestAddr, nil)
+	if err != nil || reward != params.Rewards {
+		t.Fatalf("expect the full reward without supporters, got %d %v", reward, err)
+	}
+}
+
+func TestChainApiReceiptsBelowPivot(t *testing.T) {
+	chainApi, nodes, diskDb := newApiTestChain(t, 5, GCModeArchive, 0)
+	chainStore := &store.ChainStore{KeyValueStore: diskDb}
+	chainApi.dbQuery = chainStore
+	tx := types.NewTransaction(crypto.CommonAddress{3}, big.NewInt(1), big.NewInt(1), big.NewInt(21000), 0)
+	header := nodes[1].Header()
+	if err := chainStore.PutTxLookupEntries(&types.Block{Header: &header, Data: &types.BlockData{TxCount: 1, TxList: []*types.Transaction{tx}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chainApi.GetReceipt(*tx.TxHash()); err != nil {
+		t.Fatalf("expect the receipts of a node without fast sync available, got %v", err)
+	}
+
+	if err := chainStore.PutFastSyncPivot(nodes[2].Height); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range nodes {
+		_, err := chainApi.GetBlockReceipts(types.BlockNumberOrHashWithHash(*node.Hash))
+		if below := node.Height <= nodes[2].Height; below != (errors.Cause(err) == store.ErrBelowFastSyncPivot) {
+			t.Fatalf("block %d: expect the receipts unavailable %v, got %v", node.Height, below, err)
+		}
+	}
+	if _, err := chainApi.GetReceipt(*tx.TxHash()); errors.Cause(err) != store.ErrBelowFastSyncPivot {
+		t.Fatalf("expect the receipt of a tx below the pivot unavailable, got %v", err)
+	}
+	if _, err := chainApi.GetLogs(*tx.TxHash()); errors.Cause(err) != store.ErrBelowFastSyncPivot {
+		t.Fatalf("expect the logs of a tx below the pivot unavailable, got %v", err)
+	}
+}
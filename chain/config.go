@@ -2,6 +2,7 @@ package chain
 
 import (
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
@@ -10,4 +11,19 @@ type ChainConfig struct {
 	RootChain   types.ChainIdType    `json:"rootChain,omitempty"`
 	ChainId     types.ChainIdType    `json:"chainID,omitempty"`
 	GenesisAddr crypto.CommonAddress `json:"genesisaddr"`
+	//Activation heights of the rule changes, see params.ForkConfig
+	params.ForkConfig
+	//State garbage collection mode, "full" drops the state of old blocks, "archive" (default) keeps all of them
+	GCMode string `json:"gcmode,omitempty"`
+	//Number of recent block states kept in gcmode=full
+	StateRetain uint64 `json:"stateRetain,omitempty"`
+}
+
+//BlockChainId returns the chain id in the header of the block at height. The networks got their own chain id
+//with replay protection, the blocks before carry the id of the root chain.
+func (config *ChainConfig) BlockChainId(height uint64) types.ChainIdType {
+	if !config.Rules(height).IsReplayProtection {
+		return types.ChainIdType(params.RootChain)
+	}
+	return config.ChainId
 }
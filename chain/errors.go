@@ -2,6 +2,10 @@ package chain
 
 import (
 	"errors"
+	"fmt"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
 )
 
 var (
@@ -9,6 +13,7 @@ var (
 	ErrInvalidateBlockNumber     = errors.New("invalid block number")
 	ErrBlockNotFound             = errors.New("block not exist")
 	ErrTxIndexOutOfRange         = errors.New("tx index out of range")
+	ErrTxNotFound                = errors.New("transaction not found in the main chain")
 	ErrReachGasLimit             = errors.New("gasRemained limit reached")
 	ErrInvalidateBlockMultisig   = errors.New("verify multisig error")
 	ErrUnsupportTxType           = errors.New("not support transaction type")
@@ -35,6 +40,15 @@ var (
 	ErrTooLongAlias              = errors.New("alias too long")
 	ErrUnsupportAliasChar        = errors.New("alias only support number and letter")
 	ErrReceiptRoot               = errors.New("receipt root not match")
+	ErrInvalidBlockSelector      = errors.New("invalid block number or hash")
+	ErrUnknownGCMode             = errors.New("unknown gcmode, expect full or archive")
+	ErrInvalidPivot              = errors.New("invalid fast sync pivot")
+	ErrCommandArgs               = errors.New("invalid command arguments")
+	ErrExportRange               = errors.New("invalid export range")
+	ErrImportFormat              = errors.New("invalid block in import file")
+	ErrImportMissingParent       = errors.New("parent of imported block not found")
+	ErrRewindHeight              = errors.New("invalid rewind height")
+	ErrForkConfig                = errors.New("fork config conflicts with the stored chain")
 
 	ErrNoStorage   = errors.New("no account storage found")
 	ErrKeyNotFound = errors.New("key not found")
@@ -42,3 +56,44 @@ var (
 	ErrOutOfGas    = errors.New("out gas of block")
 	ErrTxUnSupport = errors.New("unsupported transaction type")
 )
+
+// MissingStateError is returned by the state queries when the state trie of the
+// selected block is not in the database, either because it has been pruned or
+// because the block was never connected to the main chain.
+type MissingStateError struct {
+	Height uint64
+	Hash   crypto.Hash
+	Root   crypto.Hash
+	Pruned bool   //the block is older than the states kept by a node running with gcmode=full
+	Retain uint64 //number of recent states kept by the node, only set if Pruned
+}
+
+func (err *MissingStateError) Error() string {
+	if err.Pruned {
+		return fmt.Sprintf("state of block %d (%s) has been pruned, root %s is gone (gcmode=full keeps the state of the last %d blocks)", err.Height, err.Hash.String(), err.Root.String(), err.Retain)
+	}
+	return fmt.Sprintf("state of block %d (%s) not available, root %s is missing", err.Height, err.Hash.String(), err.Root.String())
+}
+
+// InvalidBlockError is returned by ProcessBlock when the block itself fails its
+// validation or its execution. The other errors come from the local database or
+// from the orphans connected after the block, the sender of the block is not to
+// blame for them.
+type InvalidBlockError struct {
+	Height uint64
+	Hash   crypto.Hash
+	Err    error
+}
+
+func (err *InvalidBlockError) Error() string {
+	return fmt.Sprintf("invalid block %d (%s): %v", err.Height, err.Hash.String(), err.Err)
+}
+
+// Cause returns the validation error, see github.com/pkg/errors.Cause
+func (err *InvalidBlockError) Cause() error {
+	return err.Err
+}
+
+func invalidBlock(header *types.BlockHeader, err error) error {
+	return &InvalidBlockError{Height: header.Height, Hash: *header.Hash(), Err: err}
+}
@@ -0,0 +1,234 @@
+package chain
+
+import (
+	"bufio"
+	"compress/gzip"
+	encbinary "encoding/binary"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	exportCommandName = "export"
+	importCommandName = "import"
+
+	//maxExportedBlockSize bounds the length prefix read back from an export file
+	maxExportedBlockSize = 64 * 1024 * 1024
+	//progressInterval is how often export and import report their progress
+	progressInterval = 8 * time.Second
+)
+
+var (
+	exportCommand = cli.Command{
+		Name:      exportCommandName,
+		Usage:     "Export the blockchain into a file",
+		ArgsUsage: "<file> [from] [to]",
+		Flags:     []cli.Flag{},
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+Writes the blocks of the main chain from height [from] (default genesis) to
+height [to] (default current head) into <file>. Every block is binary encoded
+and prefixed with its length. The file is gzip compressed if it ends with ".gz".`,
+	}
+	importCommand = cli.Command{
+		Name:      importCommandName,
+		Usage:     "Import a blockchain file",
+		ArgsUsage: "<file>",
+		Flags:     []cli.Flag{},
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+Reads blocks written by the export command and processes them one by one with
+full validation. Blocks already in the chain are skipped. Files ending with
+".gz" are decompressed.`,
+	}
+)
+
+//RunCommand executes the export, import and rewind commands. The app runs them offline, once the services which
+//do not talk to the network, like the trace, filter and chain indexer services, are started.
+func (chainService *ChainService) RunCommand(executeContext *app.ExecuteContext) error {
+	args := executeContext.Cli.Args()
+	switch executeContext.Cli.Command.Name {
+	case exportCommandName:
+		if len(args) < 1 || len(args) > 3 {
+			return errors.Wrap(ErrCommandArgs, "usage: drep export <file> [from] [to]")
+		}
+		from, to := uint64(0), chainService.bestChain.Height()
+		var err error
+		if len(args) > 1 {
+			if from, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+				return errors.Wrapf(ErrCommandArgs, "from %s: %v", args[1], err)
+			}
+		}
+		if len(args) > 2 {
+			if to, err = strconv.ParseUint(args[2], 10, 64); err != nil {
+				return errors.Wrapf(ErrCommandArgs, "to %s: %v", args[2], err)
+			}
+		}
+		return chainService.ExportChain(args[0], from, to)
+	case importCommandName:
+		if len(args) != 1 {
+			return errors.Wrap(ErrCommandArgs, "usage: drep import <file>")
+		}
+		return chainService.ImportChain(args[0])
+	case rewindCommandName:
+		return chainService.runRewindCommand(executeContext)
+	}
+	return nil
+}
+
+//ExportChain writes the main chain blocks in [from, to] into fn, gzip compressed if fn ends with ".gz"
+func (chainService *ChainService) ExportChain(fn string, from, to uint64) error {
+	if from > to || to > chainService.bestChain.Height() {
+		return errors.Wrapf(ErrExportRange, "from %d to %d, head %d", from, to, chainService.bestChain.Height())
+	}
+	file, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var writer io.Writer = file
+	var gzWriter *gzip.Writer
+	if strings.HasSuffix(fn, ".gz") {
+		gzWriter = gzip.NewWriter(file)
+		writer = gzWriter
+	}
+	bufWriter := bufio.NewWriter(writer)
+
+	log.WithField("file", fn).WithField("from", from).WithField("to", to).Info("Exporting blockchain")
+	start, reported := time.Now(), time.Now()
+	for height := from; height <= to; height++ {
+		node := chainService.bestChain.NodeByHeight(height)
+		if node == nil {
+			return errors.Wrapf(ErrBlockNotFound, "height %d", height)
+		}
+		block, err := chainService.chainStore.GetBlock(node.Hash)
+		if err != nil {
+			return errors.Wrapf(err, "read block %d", height)
+		}
+		if err := writeBlock(bufWriter, block); err != nil {
+			return errors.Wrapf(err, "write block %d", height)
+		}
+		if time.Since(reported) > progressInterval {
+			log.WithField("exported", height-from+1).WithField("height", height).WithField("to", to).Info("Exporting blockchain")
+			reported = time.Now()
+		}
+	}
+	if err := bufWriter.Flush(); err != nil {
+		return err
+	}
+	//the gzip footer is only written on close
+	if gzWriter != nil {
+		if err := gzWriter.Close(); err != nil {
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	log.WithField("file", fn).WithField("blocks", to-from+1).WithField("elapsed", time.Since(start)).Info("Exported blockchain")
+	return nil
+}
+
+//ImportChain processes every block of an exported file with full validation, blocks already known are skipped
+func (chainService *ChainService) ImportChain(fn string) error {
+	file, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(fn, ".gz") {
+		gzReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+	bufReader := bufio.NewReader(reader)
+
+	log.WithField("file", fn).WithField("head", chainService.bestChain.Height()).Info("Importing blockchain")
+	imported, skipped := 0, 0
+	start, reported := time.Now(), time.Now()
+	for {
+		block, err := readBlock(bufReader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "read block %d of file", imported+skipped)
+		}
+		if chainService.BlockExists(block.Header.Hash()) {
+			skipped++
+			continue
+		}
+		_, isOrphan, err := chainService.ProcessBlock(block)
+		if err == ErrBlockExsist {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "import block %d (%s)", block.Header.Height, block.Header.Hash().String())
+		}
+		if isOrphan {
+			return errors.Wrapf(ErrImportMissingParent, "block %d (%s), parent %s", block.Header.Height, block.Header.Hash().String(), block.Header.PreviousHash.String())
+		}
+		imported++
+		if time.Since(reported) > progressInterval {
+			log.WithField("imported", imported).WithField("skipped", skipped).WithField("height", block.Header.Height).Info("Importing blockchain")
+			reported = time.Now()
+		}
+	}
+	log.WithField("imported", imported).WithField("skipped", skipped).WithField("head", chainService.bestChain.Height()).WithField("elapsed", time.Since(start)).Info("Imported blockchain")
+	return nil
+}
+
+//writeBlock writes a block with a 4 byte big endian length prefix
+func writeBlock(w io.Writer, block *types.Block) error {
+	data, err := binary.Marshal(block)
+	if err != nil {
+		return err
+	}
+	var size [4]byte
+	encbinary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+//readBlock reads a block written by writeBlock, io.EOF is returned at a clean end of the stream
+func readBlock(r io.Reader) (*types.Block, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.Wrap(ErrImportFormat, "truncated length prefix")
+		}
+		return nil, err
+	}
+	length := encbinary.BigEndian.Uint32(size[:])
+	if length == 0 || length > maxExportedBlockSize {
+		return nil, errors.Wrapf(ErrImportFormat, "block size %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errors.Wrapf(ErrImportFormat, "truncated block: %v", err)
+	}
+	block := &types.Block{}
+	if err := binary.Unmarshal(data, block); err != nil {
+		return nil, errors.Wrapf(ErrImportFormat, "decode block: %v", err)
+	}
+	return block, nil
+}
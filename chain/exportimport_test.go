@@ -0,0 +1,93 @@
+package chain
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func exportTestBlocks(count int) []*types.Block {
+	blocks := make([]*types.Block, 0, count)
+	prev := crypto.Hash{}
+	for i := 0; i < count; i++ {
+		tx := types.NewTransaction(crypto.CommonAddress{byte(i)}, big.NewInt(int64(i)), big.NewInt(1), big.NewInt(21000), uint64(i))
+		block := &types.Block{
+			Header: &types.BlockHeader{
+				PreviousHash: prev,
+				Height:       uint64(i),
+				Timestamp:    uint64(1000 + i),
+				StateRoot:    []byte{byte(i)},
+			},
+			Data:  &types.BlockData{TxCount: 1, TxList: []*types.Transaction{tx}},
+			Proof: types.Proof{Type: 1, Evidence: []byte{1, 2, 3}},
+		}
+		prev = *block.Header.Hash()
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestExportBlockStream(t *testing.T) {
+	blocks := exportTestBlocks(10)
+	for _, compress := range []bool{false, true} {
+		buf := new(bytes.Buffer)
+		var w io.Writer = buf
+		var gzWriter *gzip.Writer
+		if compress {
+			gzWriter = gzip.NewWriter(buf)
+			w = gzWriter
+		}
+		for _, block := range blocks {
+			if err := writeBlock(w, block); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if gzWriter != nil {
+			gzWriter.Close()
+		}
+
+		var r io.Reader = buf
+		if compress {
+			gzReader, err := gzip.NewReader(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r = gzReader
+		}
+		for i, want := range blocks {
+			got, err := readBlock(r)
+			if err != nil {
+				t.Fatalf("gzip %v, block %d: %v", compress, i, err)
+			}
+			if *got.Header.Hash() != *want.Header.Hash() {
+				t.Fatalf("gzip %v, block %d: hash mismatch", compress, i)
+			}
+			if len(got.Data.TxList) != 1 || *got.Data.TxList[0].TxHash() != *want.Data.TxList[0].TxHash() {
+				t.Fatalf("gzip %v, block %d: transactions mismatch", compress, i)
+			}
+		}
+		if _, err := readBlock(r); err != io.EOF {
+			t.Fatalf("gzip %v: expect EOF at the end of the stream, got %v", compress, err)
+		}
+	}
+}
+
+func TestImportTruncatedStream(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := writeBlock(buf, exportTestBlocks(1)[0]); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	for _, cut := range []int{2, 4, len(data) - 1} {
+		_, err := readBlock(bytes.NewReader(data[:cut]))
+		if errors.Cause(err) != ErrImportFormat {
+			t.Fatalf("cut at %d: expect ErrImportFormat, got %v", cut, err)
+		}
+	}
+}
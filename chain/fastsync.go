@@ -0,0 +1,149 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+//InsertBlockWithoutState stores a block downloaded by fast sync before the pivot block. The block is linked to
+//its parent and its header and transaction root are checked, but it is not executed, the consensus proof is
+//not verified either because it needs the state of the parent. The tip of the chain does not move.
+func (chainService *ChainService) InsertBlockWithoutState(block *types.Block) error {
+	chainService.addBlockSync.Lock()
+	defer chainService.addBlockSync.Unlock()
+
+	if chainService.BlockExists(block.Header.Hash()) {
+		return ErrBlockExsist
+	}
+	prevNode := chainService.blockIndex.LookupNode(&block.Header.PreviousHash)
+	if prevNode == nil {
+		return errors.Wrapf(ErrBlockNotFound, "parent %s of block %d", block.Header.PreviousHash.String(), block.Header.Height)
+	}
+	preBlock := prevNode.Header()
+	validator := NewChainBlockValidator(chainService)
+	err := validator.VerifyHeader(block.Header, &preBlock)
+	if err != nil {
+		return err
+	}
+	err = validator.VerifyBody(block)
+	if err != nil {
+		return err
+	}
+
+	err = chainService.chainStore.PutBlock(block)
+	if err != nil {
+		return err
+	}
+	newNode := types.NewBlockNode(block.Header, prevNode)
+	newNode.Status = types.StatusDataStored
+	chainService.blockIndex.AddNode(newNode)
+	return chainService.blockIndex.FlushToDB(chainService.chainStore.PutBlockNode)
+}
+
+//SetPivotBlock makes the block whose state has been downloaded by fast sync the tip of the chain. The block
+//and all its ancestors must be stored already and the complete state at its root must be on disk. The pivot
+//itself is only trusted once next, the block after it, passes every validator on top of the downloaded state,
+//which checks the consensus proof of next against the producers of that state.
+func (chainService *ChainService) SetPivotBlock(hash *crypto.Hash, next *types.Block) error {
+	chainService.addBlockSync.Lock()
+	defer chainService.addBlockSync.Unlock()
+
+	pivot := chainService.blockIndex.LookupNode(hash)
+	if pivot == nil {
+		return errors.Wrapf(ErrBlockNotFound, "pivot %s", hash.String())
+	}
+	if pivot.Height <= chainService.bestChain.Height() {
+		return errors.Wrapf(ErrInvalidPivot, "pivot height %d is not above the tip %d", pivot.Height, chainService.bestChain.Height())
+	}
+	if !next.Header.PreviousHash.IsEqual(pivot.Hash) {
+		return errors.Wrapf(ErrInvalidPivot, "block %d does not follow the pivot %d", next.Header.Height, pivot.Height)
+	}
+	_, err := store.TrieStoreFromDatabase(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), pivot.StateRoot)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidPivot, "state of pivot %d not available: %v", pivot.Height, err)
+	}
+	block, err := chainService.chainStore.GetBlock(pivot.Hash)
+	if err != nil {
+		return err
+	}
+
+	//the validators look the producers up from the tip, move it in memory only until the pivot is verified
+	oldTip := chainService.bestChain.Tip()
+	chainService.bestChain.SetTip(pivot)
+	pivotHeader := pivot.Header()
+	for _, blockValidator := range chainService.BlockValidator() {
+		err = blockValidator.VerifyHeader(next.Header, &pivotHeader)
+		if err == nil {
+			err = blockValidator.VerifyBody(next)
+		}
+		if err != nil {
+			chainService.bestChain.SetTip(oldTip)
+			return errors.Wrapf(ErrInvalidPivot, "block %d after the pivot: %v", next.Header.Height, err)
+		}
+	}
+
+	for node := pivot; node != nil && !node.Status.KnownValid(); node = node.Parent {
+		ancestor, err := chainService.chainStore.GetBlock(node.Hash)
+		if err != nil {
+			chainService.bestChain.SetTip(oldTip)
+			return err
+		}
+		err = chainService.chainStore.PutTxLookupEntries(ancestor)
+		if err != nil {
+			chainService.bestChain.SetTip(oldTip)
+			return err
+		}
+		chainService.blockIndex.SetStatusFlags(node, types.StatusValid)
+	}
+	err = chainService.blockIndex.FlushToDB(chainService.chainStore.PutBlockNode)
+	if err != nil {
+		chainService.bestChain.SetTip(oldTip)
+		return err
+	}
+	//the receipt queries report the blocks up to the pivot as never executed
+	err = chainService.chainStore.PutFastSyncPivot(pivot.Height)
+	if err != nil {
+		chainService.bestChain.SetTip(oldTip)
+		return err
+	}
+
+	chainService.stateGC.lastCommit = pivot.Height
+	chainService.setTip(pivot)
+	log.WithField("Height", pivot.Height).WithField("Hash", pivot.Hash).Info("fast sync pivot committed")
+	chainService.notifyBlock(block, nil)
+	return nil
+}
+
+//connectStoredBlock executes a block stored without state by a fast sync that did not reach its pivot. The block
+//was only checked against its parent when it was stored, so it goes through every validator before it is
+//executed on top of the tip.
+func (chainService *ChainService) connectStoredBlock(block *types.Block, node *types.BlockNode) error {
+	preBlock := node.Parent.Header()
+	for _, blockValidator := range chainService.BlockValidator() {
+		err := blockValidator.VerifyHeader(block.Header, &preBlock)
+		if err != nil {
+			return invalidBlock(block.Header, err)
+		}
+		err = blockValidator.VerifyBody(block)
+		if err != nil {
+			return invalidBlock(block.Header, err)
+		}
+	}
+	trieStore, err := store.TrieStoreFromDatabase(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), node.Parent.StateRoot)
+	if err != nil {
+		return err
+	}
+	context, err := chainService.connectBlock(trieStore, block, node)
+	if err != nil {
+		return invalidBlock(block.Header, err)
+	}
+	err = chainService.chainStore.PutTxLookupEntries(block)
+	if err != nil {
+		return err
+	}
+	chainService.markState(trieStore, node)
+	chainService.notifyBlock(block, context.Logs)
+	return nil
+}
@@ -0,0 +1,18 @@
+package chain
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	GCModeFlag = cli.StringFlag{
+		Name:  "gcmode",
+		Usage: `state garbage collection mode, "full" keeps only recent states, "archive" keeps every state`,
+		Value: GCModeArchive,
+	}
+	StateRetainFlag = cli.Uint64Flag{
+		Name:  "stateretain",
+		Usage: "number of recent block states kept in gcmode=full",
+		Value: DefaultStateRetain,
+	}
+)
@@ -80,7 +80,7 @@ func (chainService *ChainService) ProcessGenesisBlock(biosAddr crypto.CommonAddr
 	var err error
 	var root []byte
 
-	chainStore, err := store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), trie.EmptyRoot[:])
+	chainStore, err := store.TrieStoreFromDatabase(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), trie.EmptyRoot[:])
 	if err != nil {
 		return nil, err
 	}
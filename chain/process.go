@@ -23,7 +23,20 @@ func (chainService *ChainService) ProcessBlock(block *types.Block) (bool, bool,
 	blockHash := block.Header.Hash()
 	exist := chainService.BlockExists(blockHash)
 	if exist {
-		return false, false, ErrBlockExsist
+		//a block stored without state by an unfinished fast sync is executed once its parent is the tip
+		node := chainService.blockIndex.LookupNode(blockHash)
+		if chainService.blockIndex.NodeStatus(node) != types.StatusDataStored || node.Parent != chainService.BestChain().Tip() {
+			return false, false, ErrBlockExsist
+		}
+		err := chainService.connectStoredBlock(block, node)
+		if err != nil {
+			return false, false, err
+		}
+		err = chainService.processOrphans(blockHash)
+		if err != nil {
+			return false, false, errors.Wrapf(err, "orphans of block %d", block.Header.Height)
+		}
+		return true, false, nil
 	}
 
 	// The block must not already exist as an orphan.
@@ -49,7 +62,7 @@ func (chainService *ChainService) ProcessBlock(block *types.Block) (bool, bool,
 	// there are no more.
 	err = chainService.processOrphans(blockHash)
 	if err != nil {
-		return false, false, err
+		return false, false, errors.Wrapf(err, "orphans of block %d", block.Header.Height)
 	}
 	return isMainChain, false, nil
 }
@@ -106,11 +119,11 @@ func (chainService *ChainService) acceptBlock(block *types.Block) (inMainChain b
 	for _, blockValidator := range chainService.BlockValidator() {
 		err = blockValidator.VerifyHeader(block.Header, &preBlock)
 		if err != nil {
-			return false, err
+			return false, invalidBlock(block.Header, err)
 		}
 		err = blockValidator.VerifyBody(block)
 		if err != nil {
-			return false, err
+			return false, invalidBlock(block.Header, err)
 		}
 	}
 
@@ -128,13 +141,18 @@ func (chainService *ChainService) acceptBlock(block *types.Block) (inMainChain b
 	if err != nil {
 		return false, err
 	}
-	trieStore, err := store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), prevNode.StateRoot)
+	//the state of a side chain block has not been computed, a reorganization starts from the state of the tip
+	trieStore, err := store.TrieStoreFromDatabase(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), chainService.BestChain().Tip().StateRoot)
 	if err != nil {
 		return false, err
 	}
 
 	if block.Header.PreviousHash.IsEqual(chainService.BestChain().Tip().Hash) {
 		context, err := chainService.connectBlock(trieStore, block, newNode)
+		if err != nil {
+			return false, invalidBlock(block.Header, err)
+		}
+		err = chainService.chainStore.PutTxLookupEntries(block)
 		if err != nil {
 			return false, err
 		}
@@ -165,13 +183,17 @@ func (chainService *ChainService) acceptBlock(block *types.Block) (inMainChain b
 		log.WithField("Reason", writeErr).Warn("Error flushing block index changes to disk")
 	}
 
+	if invalid, ok := err.(*InvalidBlockError); ok && !invalid.Hash.IsEqual(newNode.Hash) {
+		//a block of the side chain stored before failed, the block itself has not been executed
+		err = errors.Wrapf(err, "reorganize to block %d", newNode.Height)
+	}
 	return err == nil, err
 }
 
 func (chainService *ChainService) connectBlock(trieStore store.StoreInterface, blockType *types.Block, newNode *types.BlockNode) (context *block.BlockExecuteContext, err error) {
 	gp := new(utils.GasPool).AddGas(blockType.Header.GasLimit.Uint64())
 	//process transaction
-	context = block.NewBlockExecuteContext(trieStore, gp, chainService.chainStore, blockType)
+	context = block.NewBlockExecuteContext(trieStore, gp, chainService.chainStore, blockType, chainService.Config.Rules(blockType.Header.Height))
 	for _, blockValidator := range chainService.BlockValidator() {
 		err := blockValidator.ExecuteBlock(context)
 		if err != nil {
@@ -188,15 +210,17 @@ func (chainService *ChainService) connectBlock(trieStore store.StoreInterface, b
 
 	trieStore.Commit()
 
+	oldStateRoot := trieStore.GetStateRoot()
 	if blockType.Header.GasUsed.Cmp(context.GasUsed) == 0 {
-		oldStateRoot := trieStore.GetStateRoot()
 		if !bytes.Equal(blockType.Header.StateRoot, oldStateRoot) {
+			chainService.stateGC.discard(oldStateRoot)
 			if !trieStore.RecoverTrie(chainService.bestChain.tip().StateRoot) {
 				log.Fatal("root not equal and recover trie err")
 			}
 			err = errors.Wrapf(ErrNotMathcedStateRoot, "%s not matched %s", hex.EncodeToString(blockType.Header.StateRoot), hex.EncodeToString(oldStateRoot))
 		}
 	} else {
+		chainService.stateGC.discard(oldStateRoot)
 		err = errors.Wrapf(ErrGasUsed, "%d not matched %d", blockType.Header.GasUsed.Uint64(), context.GasUsed.Uint64())
 	}
 
@@ -284,7 +308,12 @@ func (chainService *ChainService) reorganizeChain(db store.StoreInterface, detac
 		//Consider rollback
 		//	db.Rollback2Block(height, lastBlock.Hash)
 		log.WithField("Height", height).Info("REORGANIZE:RollBack state root")
-		chainService.markState(db, lastBlock.Parent)
+		//the state of the fork point is still referenced, the new branch is executed on top of it
+		chainService.stateGC.detach(lastBlock.Parent)
+		chainService.setTip(lastBlock.Parent)
+		if !db.RecoverTrie(lastBlock.Parent.StateRoot) {
+			return errors.Wrapf(ErrNotMathcedStateRoot, "state of the fork point %d missing", lastBlock.Parent.Height)
+		}
 		elem = detachNodes.Front()
 		for elem != nil {
 			blockNode := elem.Value.(*types.BlockNode)
@@ -292,6 +321,10 @@ func (chainService *ChainService) reorganizeChain(db store.StoreInterface, detac
 			if err != nil {
 				return err
 			}
+			err = chainService.chainStore.DeleteTxLookupEntries(block)
+			if err != nil {
+				return err
+			}
 			chainService.notifyDetachBlock(block)
 			elem = elem.Next()
 		}
@@ -306,6 +339,10 @@ func (chainService *ChainService) reorganizeChain(db store.StoreInterface, detac
 				return err
 			}
 			context, err := chainService.connectBlock(db, block, blockNode)
+			if err != nil {
+				return invalidBlock(block.Header, err)
+			}
+			err = chainService.chainStore.PutTxLookupEntries(block)
 			if err != nil {
 				return err
 			}
@@ -348,8 +385,20 @@ func (chainService *ChainService) notifyDetachBlock(block *types.Block) {
 
 func (chainService *ChainService) markState(db store.StoreInterface, blockNode *types.BlockNode) {
 	db.Commit()
-	db.TrieDB().Commit(crypto.Bytes2Hash(blockNode.StateRoot), true)
+	err := chainService.stateGC.markState(blockNode)
+	if err != nil {
+		log.WithField("Height", blockNode.Height).WithField("Reason", err).Error("mark state")
+	}
+	chainService.setTip(blockNode)
+}
+
+//setTip moves the tip of the main chain and persists it as the best state
+func (chainService *ChainService) setTip(blockNode *types.BlockNode) {
 	chainService.BestChain().SetTip(blockNode)
+	err := chainService.chainStore.PutBestState(types.NewBestState(blockNode))
+	if err != nil {
+		log.WithField("Height", blockNode.Height).WithField("Reason", err).Error("persist best state")
+	}
 }
 
 //TODO improves the performan
@@ -396,14 +445,20 @@ func (chainService *ChainService) InitStates() error {
 		return err
 	}
 
+	//the persisted best state decides the tip, a fork at the same height must not replace it
 	tip := lastNode
+	if bestState := chainService.chainStore.GetBestState(); bestState != nil {
+		if node := chainService.blockIndex.LookupNode(&bestState.Hash); node != nil {
+			tip = node
+		}
+	}
 	for {
 		if tip.Height != 0 {
-			_, err := store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), lastNode.StateRoot)
+			_, err := store.TrieStoreFromDatabase(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), tip.StateRoot)
 			if err == nil {
-
 				break
 			}
+			log.WithField("height", tip.Height).WithField("root", hex.EncodeToString(tip.StateRoot)).Warn("state of tip is missing, rollback")
 
 			// commit fail and repaire here
 			//delete dirty data , and rollback state to journalHeight
@@ -417,7 +472,7 @@ func (chainService *ChainService) InitStates() error {
 			//Removes node information from memory
 			chainService.blockIndex.ClearNode(tip)
 		} else {
-			_, err := store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), lastNode.StateRoot)
+			_, err := store.TrieStoreFromDatabase(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), tip.StateRoot)
 			if err == nil {
 				break
 			}
@@ -429,7 +484,9 @@ func (chainService *ChainService) InitStates() error {
 	}
 
 	// Set the best chain view to the stored best state.
-	chainService.BestChain().SetTip(tip)
+	chainService.setTip(tip)
+	//the state of the tip is on disk, next checkpoint of gcmode=full is counted from it
+	chainService.stateGC.lastCommit = tip.Height
 
 	// Load the raw block bytes for the best block.
 	if !chainService.chainStore.HasBlock(tip.Hash) {
@@ -0,0 +1,171 @@
+// Package proof verifies the account and storage proofs returned by the
+// chain_getProof rpc. A client only needs a block header it trusts, the proof
+// nodes are checked against the state root of that header. Storage slots of
+// blocks from the contract storage fork are proven against the storage root of
+// the contract, which is part of the proven account storage. A slot absent
+// from the storage trie still holds the value of its legacy key in the state trie.
+package proof
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common/hexutil"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+var (
+	ErrBlockHashMismatch = errors.New("proof block hash not match header")
+	ErrStateRootMismatch = errors.New("proof state root not match header")
+	ErrStorageMismatch   = errors.New("proof storage value not match")
+	ErrForkMismatch      = errors.New("proof storage layout not match the fork of the header")
+)
+
+// AccountResult is the result of chain_getProof
+type AccountResult struct {
+	Address      crypto.CommonAddress `json:"address"`
+	BlockHash    crypto.Hash          `json:"blockHash"`
+	Height       uint64               `json:"height"`
+	StateRoot    hexutil.Bytes        `json:"stateRoot"`
+	Storage      hexutil.Bytes        `json:"storage"` //types.Storage encoded by types.EncodeStorage, empty if the account not exist
+	AccountProof []hexutil.Bytes      `json:"accountProof"`
+	StorageProof []StorageResult      `json:"storageProof"`
+}
+
+// StorageResult proves the value of one contract storage slot
+type StorageResult struct {
+	Key   crypto.Hash     `json:"key"`
+	Value hexutil.Bytes   `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+
+	//a slot absent from the contract storage trie is read from its legacy key in the state trie, this proves
+	//the legacy slot against the state root
+	LegacyProof []hexutil.Bytes `json:"legacyProof,omitempty"`
+}
+
+// AccountKey returns the key of the account storage in the state trie
+func AccountKey(addr *crypto.CommonAddress) []byte {
+	return types.AccountKey(addr)
+}
+
+// LegacySlotKey returns the key of a contract storage slot in the state trie,
+// used by blocks before the contract storage fork
+func LegacySlotKey(addr *crypto.CommonAddress, slot crypto.Hash) []byte {
+	return types.LegacyStorageKey(addr, new(big.Int).SetBytes(slot[:]).Bytes())
+}
+
+// Verify checks that result belongs to header and that the account and all storage
+// proofs are valid against the state root of header. The fork schedule of the chain
+// decides whether the storage slots are proven against the state root or the contract
+// storage root. It returns the proven account storage, nil is returned for an account
+// which not exist.
+func Verify(forks *params.ForkConfig, header *types.BlockHeader, result *AccountResult) (*types.Storage, error) {
+	if *header.Hash() != result.BlockHash {
+		return nil, ErrBlockHashMismatch
+	}
+	if !bytes.Equal(header.StateRoot, result.StateRoot) {
+		return nil, ErrStateRootMismatch
+	}
+	root := crypto.Bytes2Hash(header.StateRoot)
+	storage, err := VerifyAccount(root, result)
+	if err != nil {
+		return nil, err
+	}
+	if !forks.Rules(header.Height).IsContractStorage {
+		if storage != nil && storage.HasStorageRoot() {
+			return nil, ErrForkMismatch
+		}
+		for i := range result.StorageProof {
+			if len(result.StorageProof[i].LegacyProof) != 0 {
+				return nil, ErrForkMismatch
+			}
+			key := sha3.Keccak256(LegacySlotKey(&result.Address, result.StorageProof[i].Key))
+			if err := VerifyStorage(root, key, &result.StorageProof[i]); err != nil {
+				return nil, err
+			}
+		}
+		return storage, nil
+	}
+
+	storageRoot := trie.EmptyRoot
+	if storage != nil && storage.HasStorageRoot() {
+		storageRoot = storage.StorageRoot
+	}
+	for i := range result.StorageProof {
+		if err := VerifyContractStorage(root, storageRoot, &result.Address, &result.StorageProof[i]); err != nil {
+			return nil, err
+		}
+	}
+	return storage, nil
+}
+
+// VerifyAccount checks the account proof of result against root.
+func VerifyAccount(root crypto.Hash, result *AccountResult) (*types.Storage, error) {
+	value, _, err := trie.VerifyProof(root, sha3.Keccak256(AccountKey(&result.Address)), trie.NewProofSet(toNodes(result.AccountProof)))
+	if err != nil {
+		return nil, fmt.Errorf("account proof of %s: %v", result.Address.String(), err)
+	}
+	if !bytes.Equal(value, result.Storage) {
+		return nil, ErrStorageMismatch
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return types.DecodeStorage(value)
+}
+
+// VerifyStorage checks the proof of one storage slot against root, key is the
+// hashed key of the slot in the trie.
+func VerifyStorage(root crypto.Hash, key []byte, result *StorageResult) error {
+	value, _, err := trie.VerifyProof(root, key, trie.NewProofSet(toNodes(result.Proof)))
+	if err != nil {
+		return fmt.Errorf("storage proof of %s: %v", result.Key.String(), err)
+	}
+	if !bytes.Equal(value, result.Value) {
+		return ErrStorageMismatch
+	}
+	return nil
+}
+
+// VerifyContractStorage checks the proof of one storage slot of the contract at
+// addr against its storage root. A slot absent from the storage trie must come
+// with the proof of its legacy slot against the state root.
+func VerifyContractStorage(root, storageRoot crypto.Hash, addr *crypto.CommonAddress, result *StorageResult) error {
+	value, _, err := trie.VerifyProof(storageRoot, sha3.Keccak256(result.Key[:]), trie.NewProofSet(toNodes(result.Proof)))
+	if err != nil {
+		return fmt.Errorf("storage proof of %s: %v", result.Key.String(), err)
+	}
+	if len(value) == 0 {
+		value, _, err = trie.VerifyProof(root, sha3.Keccak256(LegacySlotKey(addr, result.Key)), trie.NewProofSet(toNodes(result.LegacyProof)))
+		if err != nil {
+			return fmt.Errorf("legacy storage proof of %s: %v", result.Key.String(), err)
+		}
+	}
+	if !bytes.Equal(value, result.Value) {
+		return ErrStorageMismatch
+	}
+	return nil
+}
+
+// ToProof converts a trie proof to its rpc representation
+func ToProof(nodes trie.ProofList) []hexutil.Bytes {
+	proof := make([]hexutil.Bytes, len(nodes))
+	for i, node := range nodes {
+		proof[i] = node
+	}
+	return proof
+}
+
+func toNodes(proof []hexutil.Bytes) [][]byte {
+	nodes := make([][]byte, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+	return nodes
+}
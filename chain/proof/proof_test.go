@@ -0,0 +1,231 @@
+package proof
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// forksAt returns a fork schedule under which the header of makeProof at height 1 is
+// after the contract storage fork or before it
+func forksAt(fork bool) *params.ForkConfig {
+	if fork {
+		return &params.ForkConfig{ContractStorageHeight: 1}
+	}
+	return &params.ForkConfig{ContractStorageHeight: 2}
+}
+
+// makeProof proves the slots of addr, legacy writes the slots under their legacy key and fork
+// builds the proof of a block after the contract storage fork
+func makeProof(t *testing.T, addr crypto.CommonAddress, exist bool, slots []crypto.Hash, legacy, fork bool) (*types.BlockHeader, *AccountResult) {
+	diskDB := memorydb.New()
+	trieStore, err := store.TrieStoreFromStore(diskDB, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		pri, _ := crypto.GenerateKey(rand.Reader)
+		other := crypto.PubkeyToAddress(pri.PubKey())
+		trieStore.PutBalance(&other, 0, big.NewInt(int64(i+1)))
+	}
+	if exist {
+		trieStore.PutBalance(&addr, 0, big.NewInt(100))
+	}
+	for i, slot := range slots {
+		if legacy {
+			trieStore.Put(LegacySlotKey(&addr, slot), []byte{byte(i + 1)})
+		} else {
+			trieStore.PutState(&addr, slot[:], []byte{byte(i + 1)})
+		}
+	}
+	root := trieStore.GetStateRoot()
+	trieStore.TrieDB().Commit(crypto.Bytes2Hash(root), false)
+
+	// read everything back from disk, the storage trie has to be committed together with the state root
+	trieQuery, err := trie.NewSecure(crypto.Bytes2Hash(root), trie.NewDatabase(diskDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &types.BlockHeader{Height: 1, StateRoot: root}
+	result := &AccountResult{
+		Address:   addr,
+		BlockHash: *header.Hash(),
+		Height:    header.Height,
+		StateRoot: root,
+	}
+	result.Storage, _ = trieQuery.TryGet(AccountKey(&addr))
+	accountProof := trie.ProofList{}
+	if err := trieQuery.Prove(AccountKey(&addr), 0, &accountProof); err != nil {
+		t.Fatal(err)
+	}
+	result.AccountProof = ToProof(accountProof)
+
+	slotTrie, slotKey := trieQuery, func(slot crypto.Hash) []byte { return LegacySlotKey(&addr, slot) }
+	if fork {
+		storageRoot := crypto.Hash{}
+		if result.Storage != nil {
+			storage, err := types.DecodeStorage(result.Storage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			storageRoot = storage.StorageRoot
+		}
+		slotTrie, err = trie.NewSecure(storageRoot, trie.NewDatabase(diskDB))
+		if err != nil {
+			t.Fatal(err)
+		}
+		slotKey = func(slot crypto.Hash) []byte { return slot[:] }
+	}
+	for _, slot := range slots {
+		value, err := slotTrie.TryGet(slotKey(slot))
+		if err != nil {
+			t.Fatal(err)
+		}
+		slotProof := trie.ProofList{}
+		if err := slotTrie.Prove(slotKey(slot), 0, &slotProof); err != nil {
+			t.Fatal(err)
+		}
+		storageResult := StorageResult{Key: slot, Value: value, Proof: ToProof(slotProof)}
+		if fork && len(value) == 0 {
+			storageResult.Value, _ = trieQuery.TryGet(LegacySlotKey(&addr, slot))
+			legacyProof := trie.ProofList{}
+			if err := trieQuery.Prove(LegacySlotKey(&addr, slot), 0, &legacyProof); err != nil {
+				t.Fatal(err)
+			}
+			storageResult.LegacyProof = ToProof(legacyProof)
+		}
+		result.StorageProof = append(result.StorageProof, storageResult)
+	}
+	return header, result
+}
+func TestVerify(t *testing.T) {
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	slots := []crypto.Hash{crypto.BigToHash(big.NewInt(1)), crypto.RandomHash()}
+	for _, legacy := range []bool{true, false} {
+		header, result := makeProof(t, addr, true, slots, legacy, !legacy)
+
+		storage, err := Verify(forksAt(!legacy), header, result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if storage == nil || storage.Balance.Cmp(big.NewInt(100)) != 0 {
+			t.Fatalf("unexpected account storage %v", storage)
+		}
+		for i, slot := range result.StorageProof {
+			if !bytes.Equal(slot.Value, []byte{byte(i + 1)}) {
+				t.Fatalf("unexpected value %x of slot %s", slot.Value, slot.Key.String())
+			}
+		}
+	}
+}
+
+func TestVerifyLegacySlotAfterFork(t *testing.T) {
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	header, result := makeProof(t, addr, true, []crypto.Hash{crypto.BigToHash(big.NewInt(3))}, true, true)
+	if len(result.StorageProof[0].LegacyProof) == 0 {
+		t.Fatal("expect a legacy proof of the slot not written since the fork")
+	}
+	if _, err := Verify(forksAt(true), header, result); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result.StorageProof[0].Value, []byte{1}) {
+		t.Fatalf("unexpected value %x of the legacy slot", result.StorageProof[0].Value)
+	}
+
+	// without the legacy proof the absence from the storage trie does not prove the slot empty
+	result.StorageProof[0].Value = nil
+	result.StorageProof[0].LegacyProof = nil
+	if _, err := Verify(forksAt(true), header, result); err == nil {
+		t.Fatal("expect the slot without its legacy proof to be rejected")
+	}
+}
+
+func TestVerifyForkMismatch(t *testing.T) {
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	slots := []crypto.Hash{crypto.BigToHash(big.NewInt(5))}
+
+	// after the fork a node must not prove the slots absent under their legacy key
+	header, result := makeProof(t, addr, true, slots, false, false)
+	if len(result.StorageProof[0].Value) != 0 {
+		t.Fatalf("expect the legacy key of the slot absent, got %x", result.StorageProof[0].Value)
+	}
+	if _, err := Verify(forksAt(true), header, result); err == nil {
+		t.Fatal("expect the legacy proof of a slot in the storage trie to be rejected")
+	}
+
+	// before the fork the storage root is not part of the state
+	header, result = makeProof(t, addr, true, slots, false, true)
+	if _, err := Verify(forksAt(false), header, result); err != ErrForkMismatch {
+		t.Fatalf("expect fork mismatch, got %v", err)
+	}
+}
+
+func TestVerifyEmptyContractStorage(t *testing.T) {
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	header, result := makeProof(t, addr, true, nil, false, true)
+	if result.Storage[0] == types.StorageRootMark {
+		t.Fatalf("expect no storage root, got %x", result.Storage)
+	}
+	if _, err := Verify(forksAt(true), header, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyAbsentAccount(t *testing.T) {
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	header, result := makeProof(t, addr, false, nil, false, true)
+
+	storage, err := Verify(forksAt(true), header, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storage != nil {
+		t.Fatalf("expect absent account, got %v", storage)
+	}
+
+	result.Storage = []byte{1}
+	if _, err := Verify(forksAt(true), header, result); err != ErrStorageMismatch {
+		t.Fatalf("expect storage mismatch, got %v", err)
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	header, result := makeProof(t, addr, true, []crypto.Hash{crypto.BigToHash(big.NewInt(7))}, false, true)
+
+	result.StorageProof[0].Value = []byte{0xff}
+	if _, err := Verify(forksAt(true), header, result); err != ErrStorageMismatch {
+		t.Fatalf("expect storage mismatch, got %v", err)
+	}
+
+	// the storage root is proven with the account, a storage root changed by the prover fails the account proof
+	header, result = makeProof(t, addr, true, []crypto.Hash{crypto.BigToHash(big.NewInt(7))}, false, true)
+	storage, err := types.DecodeStorage(result.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.StorageRoot = crypto.RandomHash()
+	result.Storage, _ = types.EncodeStorage(storage)
+	if _, err := Verify(forksAt(true), header, result); err != ErrStorageMismatch {
+		t.Fatalf("expect storage mismatch, got %v", err)
+	}
+
+	result.BlockHash = crypto.RandomHash()
+	if _, err := Verify(forksAt(true), header, result); err != ErrBlockHashMismatch {
+		t.Fatalf("expect block hash mismatch, got %v", err)
+	}
+}
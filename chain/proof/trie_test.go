@@ -0,0 +1,83 @@
+package proof
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+func randomProofTrie(n int) (*trie.SecureTrie, map[string][]byte) {
+	tr, _ := trie.NewSecure(crypto.Hash{}, trie.NewDatabase(memorydb.New()))
+	vals := make(map[string][]byte)
+	for i := byte(0); i < 100; i++ {
+		key, value := []byte{i, 1}, []byte{i}
+		tr.Update(key, value)
+		vals[string(key)] = value
+	}
+	for i := 0; i < n; i++ {
+		key, value := make([]byte, 32), make([]byte, 20)
+		rand.Read(key)
+		rand.Read(value)
+		tr.Update(key, value)
+		vals[string(key)] = value
+	}
+	return tr, vals
+}
+
+func TestProof(t *testing.T) {
+	tr, vals := randomProofTrie(500)
+	root := tr.Hash()
+	for key, want := range vals {
+		proof := trie.ProofList{}
+		if err := tr.Prove([]byte(key), 0, &proof); err != nil {
+			t.Fatalf("prove key %x: %v", key, err)
+		}
+		got, _, err := trie.VerifyProof(root, sha3.Keccak256([]byte(key)), trie.NewProofSet(proof))
+		if err != nil {
+			t.Fatalf("verify key %x: %v", key, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("verify key %x: value mismatch, got %x want %x", key, got, want)
+		}
+	}
+}
+
+func TestProofOfAbsence(t *testing.T) {
+	tr, _ := randomProofTrie(500)
+	root := tr.Hash()
+	for i := 0; i < 100; i++ {
+		key := make([]byte, 32)
+		rand.Read(key)
+		proof := trie.ProofList{}
+		if err := tr.Prove(key, 0, &proof); err != nil {
+			t.Fatalf("prove key %x: %v", key, err)
+		}
+		got, _, err := trie.VerifyProof(root, sha3.Keccak256(key), trie.NewProofSet(proof))
+		if err != nil {
+			t.Fatalf("verify key %x: %v", key, err)
+		}
+		if got != nil {
+			t.Fatalf("verify key %x: expected absence, got %x", key, got)
+		}
+	}
+}
+
+func TestBadProof(t *testing.T) {
+	tr, vals := randomProofTrie(800)
+	root := tr.Hash()
+	for key := range vals {
+		proof := trie.ProofList{}
+		if err := tr.Prove([]byte(key), 0, &proof); err != nil {
+			t.Fatalf("prove key %x: %v", key, err)
+		}
+		// drop the last node on the path, verification has to fail
+		if _, _, err := trie.VerifyProof(root, sha3.Keccak256([]byte(key)), trie.NewProofSet(proof[:len(proof)-1])); err == nil {
+			t.Fatalf("expected proof to fail for key %x", key)
+		}
+	}
+}
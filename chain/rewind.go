@@ -0,0 +1,128 @@
+package chain
+
+import (
+	"sort"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const rewindCommandName = "rewind"
+
+var (
+	RewindHeightFlag = cli.Uint64Flag{
+		Name:  "height",
+		Usage: "height of the block that becomes the head of the chain",
+	}
+
+	rewindCommand = cli.Command{
+		Name:     rewindCommandName,
+		Usage:    "Rewind the blockchain to a given height",
+		Flags:    []cli.Flag{RewindHeightFlag},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Detaches every block above --height, removes their receipts and block index
+entries and makes the block at --height the head of the chain. The state of
+that block must still be available (see --gcmode).`,
+	}
+)
+
+//runRewindCommand executes drep rewind --height N
+func (chainService *ChainService) runRewindCommand(executeContext *app.ExecuteContext) error {
+	if !executeContext.Cli.IsSet(RewindHeightFlag.Name) {
+		return errors.Wrap(ErrCommandArgs, "usage: drep rewind --height N")
+	}
+	return chainService.Rewind(executeContext.Cli.Uint64(RewindHeightFlag.Name))
+}
+
+//Rewind makes the main chain block at height the tip. The main chain blocks above it are detached, newest
+//first, and removed together with every side chain block above height. Subscribers of DetachBlockFeed and
+//of the removed logs are notified for the detached main chain blocks.
+func (chainService *ChainService) Rewind(height uint64) error {
+	chainService.addBlockSync.Lock()
+	defer chainService.addBlockSync.Unlock()
+
+	tip := chainService.bestChain.Tip()
+	if height > tip.Height {
+		return errors.Wrapf(ErrRewindHeight, "height %d is above the head %d", height, tip.Height)
+	}
+	if height == tip.Height {
+		return nil
+	}
+	target := chainService.bestChain.NodeByHeight(height)
+	_, err := store.TrieStoreFromDatabase(chainService.DatabaseService.LevelDb(), chainService.DatabaseService.StateDb(), target.StateRoot)
+	if err != nil {
+		return &MissingStateError{
+			Height: target.Height,
+			Hash:   *target.Hash,
+			Root:   crypto.Bytes2Hash(target.StateRoot),
+			Pruned: chainService.stateGC.pruned(target, tip),
+			Retain: chainService.stateGC.retain,
+		}
+	}
+
+	//side chain blocks above height go as well, otherwise a restart could pick them as the head
+	removed := []*types.BlockNode{}
+	chainService.blockIndex.RLock()
+	for _, node := range chainService.blockIndex.Index {
+		if node.Height > height {
+			removed = append(removed, node)
+		}
+	}
+	chainService.blockIndex.RUnlock()
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Height > removed[j].Height
+	})
+
+	detached := []*types.Block{}
+	for node := tip; node != target; node = node.Parent {
+		block, err := chainService.chainStore.GetBlock(node.Hash)
+		if err != nil {
+			return errors.Wrapf(err, "read block %d", node.Height)
+		}
+		detached = append(detached, block)
+	}
+
+	chainService.setTip(target)
+	err = chainService.stateGC.rewind(target)
+	if err != nil {
+		return err
+	}
+	//announce the detached main chain blocks, newest first, while their receipts are still stored
+	for _, block := range detached {
+		err = chainService.chainStore.DeleteTxLookupEntries(block)
+		if err != nil {
+			return err
+		}
+		chainService.notifyDetachBlock(block)
+		for _, tx := range block.Data.TxList {
+			err = chainService.chainStore.DeleteReceipt(*tx.TxHash())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, node := range removed {
+		block, err := chainService.chainStore.GetBlock(node.Hash)
+		if err == nil {
+			err = chainService.chainStore.RemoveBlock(block)
+		} else {
+			//only the index entry of a block downloaded without body, e.g. an invalid one
+			err, _ = chainService.chainStore.RollBack(node.Height, node.Hash)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "remove block %d (%s)", node.Height, node.Hash.String())
+		}
+		chainService.blockIndex.Lock()
+		chainService.blockIndex.ClearNode(node)
+		chainService.blockIndex.Unlock()
+	}
+
+	log.WithField("Height", target.Height).WithField("Hash", target.Hash).WithField("Removed", len(removed)).Info("rewind chain")
+	return nil
+}
@@ -0,0 +1,35 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+//RpcTransaction is a transaction of the main chain together with its position
+type RpcTransaction struct {
+	Hash crypto.Hash
+	From crypto.CommonAddress
+	types.TransactionData
+	Sig         common.Bytes
+	BlockHash   crypto.Hash
+	BlockHeight uint64
+	Index       uint64
+	Sponsor     *crypto.CommonAddress `json:",omitempty"` //pays the gas of a sponsored transaction
+	ValidUntil  *types.ValidityWindow `json:",omitempty"` //blocks which may include the transaction
+}
+
+func (rpcTransaction *RpcTransaction) FromTx(tx *types.Transaction) *RpcTransaction {
+	from, _ := tx.ClaimedFrom()
+	rpcTransaction.Hash = *tx.TxHash()
+	rpcTransaction.TransactionData = tx.Data
+	if from != nil {
+		rpcTransaction.From = *from
+	}
+	rpcTransaction.Sig = common.Bytes(tx.Sig)
+	if tx.IsSponsored() {
+		rpcTransaction.Sponsor, _ = tx.Sponsor()
+	}
+	rpcTransaction.ValidUntil = tx.ValidityWindow()
+	return rpcTransaction
+}
@@ -0,0 +1,155 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	//GCModeFull keeps the state of the recent blocks in memory and drops the nodes of older states
+	GCModeFull = "full"
+	//GCModeArchive writes the state of every block to disk
+	GCModeArchive = "archive"
+
+	//DefaultStateRetain number of recent states kept in gcmode=full
+	DefaultStateRetain = 128
+	//stateCacheLimit dirty state nodes are flushed to disk once the memory cache grows over it
+	stateCacheLimit = common.StorageSize(256 * 1024 * 1024)
+)
+
+type retainedRoot struct {
+	height uint64
+	root   crypto.Hash
+}
+
+//stateGC garbage collects the state trie nodes of the blocks connected to the main chain. In archive mode
+//every state is committed to disk, the nodes of states which are never committed like the ones of side chains are
+//flushed once the memory cache is full. In full mode the state of the last retain blocks is referenced in the
+//memory of the trie database, when a state falls out of this window it is dereferenced and its nodes that
+//no later state uses are dropped before they reach the disk, the states of the blocks a reorganization takes off
+//the main chain are dereferenced at once. Once every retain blocks one state is committed,
+//so that a restart or a deep reorg finds a state to continue from.
+type stateGC struct {
+	trieDb     *trie.Database
+	archive    bool
+	retain     uint64
+	cacheLimit common.StorageSize //dirty nodes are flushed to disk once the memory cache grows over it
+	roots      []retainedRoot     //referenced states, oldest first
+	lastCommit uint64             //height of the last state committed to disk
+}
+
+func newStateGC(trieDb *trie.Database, gcMode string, retain uint64) (*stateGC, error) {
+	if gcMode != GCModeFull && gcMode != GCModeArchive {
+		return nil, ErrUnknownGCMode
+	}
+	if retain == 0 {
+		retain = DefaultStateRetain
+	}
+	return &stateGC{
+		trieDb:     trieDb,
+		archive:    gcMode == GCModeArchive,
+		retain:     retain,
+		cacheLimit: stateCacheLimit,
+	}, nil
+}
+
+//markState is called when the state of blockNode has been written to the trie database
+func (gc *stateGC) markState(blockNode *types.BlockNode) error {
+	root := crypto.Bytes2Hash(blockNode.StateRoot)
+	if gc.archive {
+		err := gc.trieDb.Commit(root, true)
+		if err != nil {
+			return err
+		}
+		return gc.capCache()
+	}
+
+	gc.trieDb.Reference(root, crypto.Hash{})
+	gc.roots = append(gc.roots, retainedRoot{height: blockNode.Height, root: root})
+	err := gc.capCache()
+	if err != nil {
+		return err
+	}
+
+	for len(gc.roots) > 0 && gc.roots[0].height+gc.retain <= blockNode.Height {
+		old := gc.roots[0]
+		gc.roots = gc.roots[1:]
+		if old.height >= gc.lastCommit+gc.retain {
+			err := gc.trieDb.Commit(old.root, true)
+			if err != nil {
+				return err
+			}
+			gc.lastCommit = old.height
+			log.WithField("height", old.height).WithField("root", old.root).Debug("commit state")
+		}
+		gc.trieDb.Dereference(old.root)
+		log.WithField("height", old.height).WithField("root", old.root).Trace("dereference state")
+	}
+	return nil
+}
+
+//capCache flushes the oldest dirty nodes to disk when the memory cache grows over its limit
+func (gc *stateGC) capCache() error {
+	if nodes, _ := gc.trieDb.Size(); nodes > gc.cacheLimit {
+		return gc.trieDb.Cap(gc.cacheLimit - gc.cacheLimit/8)
+	}
+	return nil
+}
+
+//flush commits the state of the tip so that the node restarts from it
+func (gc *stateGC) flush(tip *types.BlockNode) error {
+	if gc.archive || tip == nil {
+		return nil
+	}
+	gc.lastCommit = tip.Height
+	return gc.trieDb.Commit(crypto.Bytes2Hash(tip.StateRoot), true)
+}
+
+//detach dereferences the states of the blocks above fork when a reorganization takes them off the main chain.
+//The state of fork stays referenced once, the side chain blocks connected instead are marked afterwards and leave
+//the retained window like any other block.
+func (gc *stateGC) detach(fork *types.BlockNode) {
+	if gc.archive {
+		return
+	}
+	kept := gc.roots[:0]
+	for _, retained := range gc.roots {
+		if retained.height > fork.Height {
+			gc.trieDb.Dereference(retained.root)
+			log.WithField("height", retained.height).WithField("root", retained.root).Trace("dereference detached state")
+			continue
+		}
+		kept = append(kept, retained)
+	}
+	gc.roots = kept
+	//a state committed above fork belongs to the old chain, the next checkpoint is counted from fork
+	if gc.lastCommit > fork.Height {
+		gc.lastCommit = fork.Height
+	}
+}
+
+//discard drops the state a block failed to execute to, its nodes have been written to the trie database but no
+//block refers to them. A root which is retained because a marked block has the same state is left alone.
+func (gc *stateGC) discard(stateRoot []byte) {
+	root := crypto.Bytes2Hash(stateRoot)
+	for _, retained := range gc.roots {
+		if retained.root == root {
+			return
+		}
+	}
+	gc.trieDb.Dereference(root)
+	log.WithField("root", root).Trace("discard state")
+}
+
+//rewind drops the states above the new tip and commits the state of the tip, it must still be available
+func (gc *stateGC) rewind(tip *types.BlockNode) error {
+	gc.detach(tip)
+	return gc.flush(tip)
+}
+
+//pruned reports whether the missing state of node is missing because it has been garbage collected
+func (gc *stateGC) pruned(node, tip *types.BlockNode) bool {
+	return !gc.archive && node.Height+gc.retain <= tip.Height
+}
@@ -0,0 +1,251 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+//writeStates writes count states, each one changing a single key of the previous one
+func writeStates(t *testing.T, gc *stateGC, trieDb *trie.Database, count uint64) []*types.BlockNode {
+	return extendStates(t, gc, trieDb, nil, count, 0)
+}
+
+//extendStates writes count states above the state of parent, the values are offset by salt so that the states
+//of two branches differ
+func extendStates(t *testing.T, gc *stateGC, trieDb *trie.Database, parent *types.BlockNode, count, salt uint64) []*types.BlockNode {
+	root, height := crypto.Hash{}, uint64(0)
+	if parent != nil {
+		root, height = crypto.Bytes2Hash(parent.StateRoot), parent.Height
+	}
+	tr, err := trie.NewSecure(root, trieDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := make([]*types.BlockNode, 0, count)
+	for i := height + 1; i <= height+count; i++ {
+		tr.Update(new(big.Int).SetUint64(i%4).Bytes(), new(big.Int).SetUint64(i+salt).Bytes())
+		root, err := tr.Commit(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node := &types.BlockNode{Height: i, StateRoot: root.Bytes()}
+		if err := gc.markState(node); err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func stateAvailable(diskDb *memorydb.Database, node *types.BlockNode) bool {
+	//open the state through a fresh database, so that only the nodes written to disk are visible
+	_, err := trie.NewSecure(crypto.Bytes2Hash(node.StateRoot), trie.NewDatabase(diskDb))
+	return err == nil
+}
+
+func TestStateGCArchive(t *testing.T) {
+	diskDb := memorydb.New()
+	trieDb := trie.NewDatabase(diskDb)
+	gc, err := newStateGC(trieDb, GCModeArchive, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range writeStates(t, gc, trieDb, 20) {
+		if !stateAvailable(diskDb, node) {
+			t.Fatalf("state of block %d missing in archive mode", node.Height)
+		}
+	}
+}
+
+func TestStateGCArchiveCap(t *testing.T) {
+	diskDb := memorydb.New()
+	trieDb := trie.NewDatabase(diskDb)
+	gc, err := newStateGC(trieDb, GCModeArchive, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gc.cacheLimit = 1024
+
+	//a side chain state is written to the trie database but never committed
+	side, err := trie.NewSecure(crypto.Hash{}, trieDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		side.Update(new(big.Int).SetUint64(uint64(i)).Bytes(), make([]byte, 32))
+	}
+	if _, err := side.Commit(nil); err != nil {
+		t.Fatal(err)
+	}
+	if nodes, _ := trieDb.Size(); nodes <= gc.cacheLimit {
+		t.Fatalf("expect the side chain state cached in memory, got %v", nodes)
+	}
+
+	writeStates(t, gc, trieDb, 1)
+	if nodes, _ := trieDb.Size(); nodes > gc.cacheLimit {
+		t.Fatalf("expect the memory cache capped to %v in archive mode, got %v", gc.cacheLimit, nodes)
+	}
+}
+
+func TestStateGCFull(t *testing.T) {
+	diskDb := memorydb.New()
+	trieDb := trie.NewDatabase(diskDb)
+	gc, err := newStateGC(trieDb, GCModeFull, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := writeStates(t, gc, trieDb, 20)
+	tip := nodes[len(nodes)-1]
+	for _, node := range nodes {
+		//recent states are kept in memory
+		if node.Height+gc.retain > tip.Height {
+			if _, err := trie.NewSecure(crypto.Bytes2Hash(node.StateRoot), trieDb); err != nil {
+				t.Fatalf("recent state of block %d missing: %v", node.Height, err)
+			}
+			if gc.pruned(node, tip) {
+				t.Fatalf("recent state of block %d reported as pruned", node.Height)
+			}
+			continue
+		}
+		if !gc.pruned(node, tip) {
+			t.Fatalf("old state of block %d not reported as pruned", node.Height)
+		}
+		//only checkpoints reach the disk
+		checkpoint := node.Height%gc.retain == 0
+		if stateAvailable(diskDb, node) != checkpoint {
+			t.Fatalf("state of block %d on disk %v, expect %v", node.Height, !checkpoint, checkpoint)
+		}
+	}
+
+	if err := gc.flush(tip); err != nil {
+		t.Fatal(err)
+	}
+	if !stateAvailable(diskDb, tip) {
+		t.Fatal("state of tip not written to disk on flush")
+	}
+}
+
+func TestStateGCRewind(t *testing.T) {
+	diskDb := memorydb.New()
+	trieDb := trie.NewDatabase(diskDb)
+	gc, err := newStateGC(trieDb, GCModeFull, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := writeStates(t, gc, trieDb, 20)
+	target := nodes[17]
+	if stateAvailable(diskDb, target) {
+		t.Fatalf("state of block %d on disk before the rewind", target.Height)
+	}
+	if err := gc.rewind(target); err != nil {
+		t.Fatal(err)
+	}
+	if !stateAvailable(diskDb, target) {
+		t.Fatal("state of the new tip not written to disk on rewind")
+	}
+	if gc.lastCommit != target.Height {
+		t.Fatalf("last commit %d, expect %d", gc.lastCommit, target.Height)
+	}
+	for _, retained := range gc.roots {
+		if retained.height > target.Height {
+			t.Fatalf("state of block %d above the new tip still referenced", retained.height)
+		}
+	}
+}
+
+func TestStateGCReorg(t *testing.T) {
+	diskDb := memorydb.New()
+	trieDb := trie.NewDatabase(diskDb)
+	gc, err := newStateGC(trieDb, GCModeFull, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := writeStates(t, gc, trieDb, 10)
+	fork := nodes[7]
+	detached := nodes[8:]
+
+	//the reorganization rolls back to the fork and connects the side chain, the fork stays referenced once
+	gc.detach(fork)
+	side := extendStates(t, gc, trieDb, fork, 3, 100)
+	marked := 0
+	for _, retained := range gc.roots {
+		if retained.root == crypto.Bytes2Hash(fork.StateRoot) {
+			marked++
+		}
+	}
+	if marked != 1 {
+		t.Fatalf("expect the state of the fork referenced once, got %d", marked)
+	}
+	for _, node := range detached {
+		if _, err := trie.NewSecure(crypto.Bytes2Hash(node.StateRoot), trieDb); err == nil {
+			t.Fatalf("state of the detached block %d still cached", node.Height)
+		}
+	}
+	for _, node := range append([]*types.BlockNode{fork}, side...) {
+		if _, err := trie.NewSecure(crypto.Bytes2Hash(node.StateRoot), trieDb); err != nil {
+			t.Fatalf("state of the main chain block %d missing: %v", node.Height, err)
+		}
+	}
+
+	//the states of the new main chain leave the retained window as the chain grows
+	tip := extendStates(t, gc, trieDb, side[len(side)-1], 6, 100)[5]
+	for _, retained := range gc.roots {
+		if retained.height+gc.retain <= tip.Height {
+			t.Fatalf("state of block %d out of the retained window still referenced", retained.height)
+		}
+	}
+	for _, node := range append([]*types.BlockNode{fork}, side...) {
+		if _, err := trie.NewSecure(crypto.Bytes2Hash(node.StateRoot), trieDb); err == nil && !stateAvailable(diskDb, node) {
+			t.Fatalf("state of block %d out of the retained window still cached", node.Height)
+		}
+	}
+}
+
+func TestStateGCDiscard(t *testing.T) {
+	diskDb := memorydb.New()
+	trieDb := trie.NewDatabase(diskDb)
+	gc, err := newStateGC(trieDb, GCModeFull, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := writeStates(t, gc, trieDb, 3)
+
+	//a block executed to a wrong state leaves its nodes in the trie database without a reference
+	tr, err := trie.NewSecure(crypto.Bytes2Hash(nodes[2].StateRoot), trieDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Update([]byte{1}, []byte{0xff})
+	failed, err := tr.Commit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gc.discard(failed.Bytes())
+	if _, err := trie.NewSecure(failed, trieDb); err == nil {
+		t.Fatal("discarded state still cached")
+	}
+	//a retained state is not discarded
+	gc.discard(nodes[2].StateRoot)
+	for _, node := range nodes {
+		if _, err := trie.NewSecure(crypto.Bytes2Hash(node.StateRoot), trieDb); err != nil {
+			t.Fatalf("state of block %d missing: %v", node.Height, err)
+		}
+	}
+	if err := trieDb.Commit(crypto.Bytes2Hash(nodes[2].StateRoot), false); err != nil {
+		t.Fatal(err)
+	}
+	if stateAvailable(diskDb, &types.BlockNode{StateRoot: failed.Bytes()}) {
+		t.Fatal("discarded state written to disk")
+	}
+}
+
+func TestStateGCUnknownMode(t *testing.T) {
+	if _, err := newStateGC(trie.NewDatabase(memorydb.New()), "light", 0); err != ErrUnknownGCMode {
+		t.Fatalf("expect %v, got %v", ErrUnknownGCMode, err)
+	}
+}
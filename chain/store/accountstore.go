@@ -15,7 +15,11 @@ const (
 	//AliasPrefix storage alias used prefix
 	AliasPrefix = "alias"
 	//AddressStorage Object stored with the address as the KEY
-	AddressStorage = "AddressStorage"
+	AddressStorage = types.AddressStorage
+	//MultiSigPrefix prefix of the keys of the multisig accounts
+	MultiSigPrefix = "multisig"
+	//AssetPrefix prefix of the keys of the native assets
+	AssetPrefix = "asset"
 )
 
 var (
@@ -25,6 +29,10 @@ var (
 	ErrUsedAlias = errors.New("the alias has been used")
 	//ErrInvalidateAlias set null string as alias
 	ErrInvalidateAlias = errors.New("set null string as alias")
+	//ErrNotAliasOwner the address does not own the alias
+	ErrNotAliasOwner = errors.New("the alias is not owned by the address")
+	//ErrAliasOwned the address owns an alias already
+	ErrAliasOwned = errors.New("the address owns an alias already")
 )
 
 type trieAccountStore struct {
@@ -61,8 +69,7 @@ func (trieStore *trieAccountStore) GetStorage(addr *crypto.CommonAddress) (*type
 	trieStore.lock.Lock()
 	defer trieStore.lock.Unlock()
 
-	storage := &types.Storage{}
-	key := sha3.Keccak256([]byte(AddressStorage + addr.Hex()))
+	key := types.AccountKey(addr)
 	value, err := trieStore.storeDB.Get(key)
 	if err != nil {
 		return nil, err
@@ -71,10 +78,16 @@ func (trieStore *trieAccountStore) GetStorage(addr *crypto.CommonAddress) (*type
 		return nil, nil
 	}
 
-	err = binary.Unmarshal(value, storage)
+	storage, err := types.DecodeStorage(value)
 	if err != nil {
 		return nil, err
 	}
+	if len(storage.ByteCode) > 0 {
+		storage.StorageRoot, err = trieStore.storeDB.GetStorageRoot(addr)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return storage, nil
 }
@@ -83,7 +96,7 @@ func (trieStore *trieAccountStore) DeleteStorage(addr *crypto.CommonAddress) err
 	trieStore.lock.Lock()
 	defer trieStore.lock.Unlock()
 
-	key := sha3.Keccak256([]byte(AddressStorage + addr.Hex()))
+	key := types.AccountKey(addr)
 
 	return trieStore.storeDB.Delete(key)
 }
@@ -92,8 +105,8 @@ func (trieStore *trieAccountStore) PutStorage(addr *crypto.CommonAddress, storag
 	trieStore.lock.Lock()
 	defer trieStore.lock.Unlock()
 
-	key := sha3.Keccak256([]byte(AddressStorage + addr.Hex()))
-	value, err := binary.Marshal(storage)
+	key := types.AccountKey(addr)
+	value, err := types.EncodeStorage(storage)
 	if err != nil {
 		return err
 	}
@@ -180,6 +193,53 @@ func (trieStore *trieAccountStore) AliasSet(addr *crypto.CommonAddress, alias st
 	return nil
 }
 
+//AliasTransfer gives the alias of from to to, which must own no alias, both keys of the alias are moved together.
+//The keys are written one after the other, an error after the first write leaves them half updated: only the failed
+//transaction invalidating the whole block keeps such a state out of the chain.
+func (trieStore *trieAccountStore) AliasTransfer(from, to *crypto.CommonAddress, alias string) error {
+	err := trieStore.checkAliasOwner(from, alias)
+	if err != nil {
+		return err
+	}
+	if trieStore.GetStorageAlias(to) != "" {
+		return ErrAliasOwned
+	}
+	err = trieStore.AliasPut(alias, to.Bytes())
+	if err != nil {
+		return err
+	}
+	err = trieStore.setStorageAlias(from, "")
+	if err != nil {
+		return err
+	}
+	return trieStore.setStorageAlias(to, alias)
+}
+
+//AliasRelease frees the alias of addr, anyone may set it afterwards
+func (trieStore *trieAccountStore) AliasRelease(addr *crypto.CommonAddress, alias string) error {
+	err := trieStore.checkAliasOwner(addr, alias)
+	if err != nil {
+		return err
+	}
+	err = trieStore.storeDB.Delete([]byte(AliasPrefix + alias))
+	if err != nil {
+		return err
+	}
+	return trieStore.setStorageAlias(addr, "")
+}
+
+//checkAliasOwner checks that alias resolves to addr and addr to alias
+func (trieStore *trieAccountStore) checkAliasOwner(addr *crypto.CommonAddress, alias string) error {
+	if alias == "" || trieStore.GetStorageAlias(addr) != alias {
+		return ErrNotAliasOwner
+	}
+	owner, err := trieStore.AliasGet(alias)
+	if err != nil || *owner != *addr {
+		return ErrNotAliasOwner
+	}
+	return nil
+}
+
 func (trieStore *trieAccountStore) AliasPut(alias string, value []byte) error {
 	return trieStore.storeDB.Put([]byte(AliasPrefix+alias), value)
 }
@@ -203,6 +263,93 @@ func (trieStore *trieAccountStore) AliasExist(alias string) bool {
 	return true
 }
 
+//MultiSigAccountKey returns the key of the multisig account at addr in the state trie
+func MultiSigAccountKey(addr *crypto.CommonAddress) []byte {
+	return sha3.Keccak256([]byte(MultiSigPrefix + addr.Hex()))
+}
+
+//GetMultiSigAccount returns the keys and threshold of the multisig account at addr, nil if addr is none
+func (trieStore *trieAccountStore) GetMultiSigAccount(addr *crypto.CommonAddress) (*types.MultiSigAccount, error) {
+	value, err := trieStore.storeDB.Get(MultiSigAccountKey(addr))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	account := &types.MultiSigAccount{}
+	err = binary.Unmarshal(value, account)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (trieStore *trieAccountStore) PutMultiSigAccount(addr *crypto.CommonAddress, account *types.MultiSigAccount) error {
+	value, err := binary.Marshal(account)
+	if err != nil {
+		return err
+	}
+	return trieStore.storeDB.Put(MultiSigAccountKey(addr), value)
+}
+
+//AssetKey returns the key of the native asset symbol in the state trie
+func AssetKey(symbol string) []byte {
+	return sha3.Keccak256([]byte(AssetPrefix + symbol))
+}
+
+//GetAsset returns the native asset symbol, nil if it was not issued
+func (trieStore *trieAccountStore) GetAsset(symbol string) (*types.Asset, error) {
+	value, err := trieStore.storeDB.Get(AssetKey(symbol))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	asset := &types.Asset{}
+	err = binary.Unmarshal(value, asset)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+func (trieStore *trieAccountStore) PutAsset(asset *types.Asset) error {
+	value, err := binary.Marshal(asset)
+	if err != nil {
+		return err
+	}
+	return trieStore.storeDB.Put(AssetKey(asset.Symbol), value)
+}
+
+func (trieStore *trieAccountStore) GetAssetBalance(addr *crypto.CommonAddress, symbol string) *big.Int {
+	storage, _ := trieStore.GetStorage(addr)
+	if storage == nil {
+		return new(big.Int)
+	}
+	balance := storage.BalanceMap[symbol]
+	return &balance
+}
+
+//PutAssetBalance sets the balance of the native asset symbol of addr, a zero balance removes the asset from the
+//storage of addr
+func (trieStore *trieAccountStore) PutAssetBalance(addr *crypto.CommonAddress, symbol string, balance *big.Int) error {
+	storage, _ := trieStore.GetStorage(addr)
+	if storage == nil {
+		storage = &types.Storage{}
+	}
+	if storage.BalanceMap == nil {
+		storage.BalanceMap = types.AssetBalances{}
+	}
+	if balance.Sign() == 0 {
+		delete(storage.BalanceMap, symbol)
+	} else {
+		storage.BalanceMap[symbol] = *new(big.Int).Set(balance)
+	}
+	return trieStore.PutStorage(addr, storage)
+}
+
 func (trieStore *trieAccountStore) GetByteCode(addr *crypto.CommonAddress) []byte {
 	storage, _ := trieStore.GetStorage(addr)
 	if storage == nil {
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+)
+
+//checkAlias checks that the alias resolves to owner and owner to the alias, a nil owner means the alias is free
+func checkAlias(t *testing.T, store StoreInterface, alias string, owner *crypto.CommonAddress, others ...*crypto.CommonAddress) {
+	t.Helper()
+	if owner == nil {
+		if store.AliasExist(alias) {
+			t.Fatalf("expect the alias %s free", alias)
+		}
+	} else {
+		addr, err := store.AliasGet(alias)
+		if err != nil || *addr != *owner {
+			t.Fatalf("expect the alias %s resolved to %s, got %v %v", alias, owner.String(), addr, err)
+		}
+		if got := store.GetStorageAlias(owner); got != alias {
+			t.Fatalf("expect %s to own the alias %s, got %q", owner.String(), alias, got)
+		}
+	}
+	for _, other := range others {
+		if got := store.GetStorageAlias(other); got != "" {
+			t.Fatalf("expect %s to own no alias, got %q", other.String(), got)
+		}
+	}
+}
+
+func TestAliasTransferAndRelease(t *testing.T) {
+	diskDB := memorydb.New()
+	changeInterval := make([]byte, 8)
+	binary.BigEndian.PutUint64(changeInterval, ChangeCycle)
+	diskDB.Put([]byte(ChangeInterval), changeInterval)
+	store, err := TrieStoreFromStore(diskDB, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob, carol := &crypto.CommonAddress{1}, &crypto.CommonAddress{2}, &crypto.CommonAddress{3}
+	balance := new(big.Int).Mul(big.NewInt(100000), new(big.Int).SetUint64(params.Coin))
+	for _, addr := range []*crypto.CommonAddress{alice, bob, carol} {
+		store.PutBalance(addr, 0, new(big.Int).Set(balance))
+	}
+	const alias, other = "alicealias", "carolalias"
+	if err := store.AliasSet(alice, alias, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AliasSet(carol, other, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.AliasTransfer(bob, carol, alias); err != ErrNotAliasOwner {
+		t.Fatalf("expect a transfer by another account refused, got %v", err)
+	}
+	if err := store.AliasTransfer(alice, carol, alias); err != ErrAliasOwned {
+		t.Fatalf("expect a transfer to an alias owner refused, got %v", err)
+	}
+	checkAlias(t, store, alias, alice, bob)
+
+	if err := store.AliasTransfer(alice, bob, alias); err != nil {
+		t.Fatal(err)
+	}
+	checkAlias(t, store, alias, bob, alice)
+	checkAlias(t, store, other, carol)
+
+	//both keys of the alias are in the state of the block
+	store, err = TrieStoreFromDatabase(diskDB, store.TrieDB(), store.GetStateRoot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAlias(t, store, alias, bob, alice)
+
+	if err := store.AliasRelease(alice, alias); err != ErrNotAliasOwner {
+		t.Fatalf("expect a release by the previous owner refused, got %v", err)
+	}
+	if err := store.AliasRelease(bob, alias); err != nil {
+		t.Fatal(err)
+	}
+	checkAlias(t, store, alias, nil, alice, bob)
+
+	store, err = TrieStoreFromDatabase(diskDB, store.TrieDB(), store.GetStateRoot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAlias(t, store, alias, nil, alice, bob)
+	checkAlias(t, store, other, carol)
+
+	//a released alias can be set again
+	if err := store.AliasSet(alice, alias, 0); err != nil {
+		t.Fatal(err)
+	}
+	checkAlias(t, store, alias, alice, bob)
+}
@@ -1,8 +1,11 @@
 package store
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"github.com/pkg/errors"
+
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/sha3"
 	"github.com/drep-project/DREP-Chain/database/dbinterface"
@@ -15,8 +18,22 @@ var (
 	ChainStatePrefix = []byte("chainState_")
 	BlockPrefix      = []byte("block_")
 	BlockNodePrefix  = []byte("blockNode_")
+	TxLookupPrefix   = []byte("txLookup_")
+	ForkConfigKey    = []byte("forkConfig")
+	TxLookupIndexKey = []byte("txLookupIndex")
+	FastSyncPivotKey = []byte("fastSyncPivot")
+
+	//ErrBelowFastSyncPivot is returned for the receipts and logs of a block stored by fast sync without being executed
+	ErrBelowFastSyncPivot = errors.New("not available below fast-sync pivot")
 )
 
+//TxLookupEntry is the position of a transaction in the main chain
+type TxLookupEntry struct {
+	BlockHash crypto.Hash
+	Height    uint64
+	Index     uint64
+}
+
 type ChainStore struct {
 	dbinterface.KeyValueStore
 }
@@ -45,6 +62,11 @@ func (chainStore *ChainStore) GetReceipt(txHash crypto.Hash) *types.Receipt {
 	return receipt
 }
 
+func (chainStore *ChainStore) DeleteReceipt(txHash crypto.Hash) error {
+	key := sha3.Keccak256([]byte("receipt_" + txHash.String()))
+	return chainStore.Delete(key)
+}
+
 func (chainStore *ChainStore) PutReceipts(blockHash crypto.Hash, receipts []*types.Receipt) error {
 	key := sha3.Keccak256([]byte("receipts_" + blockHash.String()))
 	value, err := binary.Marshal(receipts)
@@ -208,3 +230,174 @@ func (chainStore *ChainStore) RollBack(height uint64, hash *crypto.Hash) (error,
 
 	return nil, 0
 }
+
+//RemoveBlock deletes the block body, its block index entry and its receipts. The receipts stored by
+//transaction hash are left alone, the transaction may be included in another block as well
+func (chainStore *ChainStore) RemoveBlock(block *types.Block) error {
+	hash := block.Header.Hash()
+	err := chainStore.Delete(chainStore.blockIndexKey(hash, block.Header.Height))
+	if err != nil {
+		return err
+	}
+	err = chainStore.DeleteReceipts(*hash)
+	if err != nil {
+		return err
+	}
+	return chainStore.Delete(append(BlockPrefix, hash[:]...))
+}
+
+//PutBestState persists the tip of the main chain
+func (chainStore *ChainStore) PutBestState(state *types.BestState) error {
+	value, err := binary.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return chainStore.Put(ChainStatePrefix, value)
+}
+
+//GetBestState returns the persisted tip of the main chain, nil if it was never written
+func (chainStore *ChainStore) GetBestState() *types.BestState {
+	value, err := chainStore.Get(ChainStatePrefix)
+	if err != nil {
+		return nil
+	}
+	state := &types.BestState{}
+	err = binary.Unmarshal(value, state)
+	if err != nil {
+		return nil
+	}
+	return state
+}
+
+//PutForkConfig persists the fork heights, by fork name, the chain was built with
+func (chainStore *ChainStore) PutForkConfig(heights map[string]uint64) error {
+	value, err := json.Marshal(heights)
+	if err != nil {
+		return err
+	}
+	return chainStore.Put(ForkConfigKey, value)
+}
+
+//GetForkConfig returns the persisted fork heights, nil if they were never written
+func (chainStore *ChainStore) GetForkConfig() (map[string]uint64, error) {
+	ok, err := chainStore.Has(ForkConfigKey)
+	if err != nil || !ok {
+		return nil, err
+	}
+	value, err := chainStore.Get(ForkConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	heights := map[string]uint64{}
+	err = json.Unmarshal(value, &heights)
+	if err != nil {
+		return nil, err
+	}
+	return heights, nil
+}
+
+func (chainStore *ChainStore) txLookupKey(txHash *crypto.Hash) []byte {
+	return append(append([]byte{}, TxLookupPrefix...), txHash[:]...)
+}
+
+//PutTxLookupEntries indexes the transactions of a block connected to the main chain
+func (chainStore *ChainStore) PutTxLookupEntries(block *types.Block) error {
+	for i, tx := range block.Data.TxList {
+		value, err := binary.Marshal(&TxLookupEntry{
+			BlockHash: *block.Header.Hash(),
+			Height:    block.Header.Height,
+			Index:     uint64(i),
+		})
+		if err != nil {
+			return err
+		}
+		err = chainStore.Put(chainStore.txLookupKey(tx.TxHash()), value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//DeleteTxLookupEntries removes the lookup entries of a block detached from the main chain, entries that
+//already point to another block are kept
+func (chainStore *ChainStore) DeleteTxLookupEntries(block *types.Block) error {
+	for _, tx := range block.Data.TxList {
+		entry, err := chainStore.GetTxLookupEntry(tx.TxHash())
+		if err != nil || entry.BlockHash != *block.Header.Hash() {
+			continue
+		}
+		err = chainStore.Delete(chainStore.txLookupKey(tx.TxHash()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//HasTxLookupIndex reports whether the blocks stored before the tx lookup index existed have been indexed
+func (chainStore *ChainStore) HasTxLookupIndex() (bool, error) {
+	return chainStore.Has(TxLookupIndexKey)
+}
+
+//PutTxLookupIndex records that the tx lookup index covers all the blocks of the main chain
+func (chainStore *ChainStore) PutTxLookupIndex() error {
+	return chainStore.Put(TxLookupIndexKey, []byte{1})
+}
+
+//GetTxLookupEntry returns the position of a transaction in the main chain
+func (chainStore *ChainStore) GetTxLookupEntry(txHash *crypto.Hash) (*TxLookupEntry, error) {
+	value, err := chainStore.Get(chainStore.txLookupKey(txHash))
+	if err != nil {
+		return nil, err
+	}
+	entry := &TxLookupEntry{}
+	err = binary.Unmarshal(value, entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+//PutFastSyncPivot records the height of the pivot block of a fast sync. The pivot and the blocks before it were
+//stored without being executed, they have no receipts.
+func (chainStore *ChainStore) PutFastSyncPivot(height uint64) error {
+	value, err := binary.Marshal(height)
+	if err != nil {
+		return err
+	}
+	return chainStore.Put(FastSyncPivotKey, value)
+}
+
+//GetFastSyncPivot returns the height of the pivot block of the fast sync of the node, ok is false if the node
+//executed every block
+func (chainStore *ChainStore) GetFastSyncPivot() (height uint64, ok bool) {
+	value, err := chainStore.Get(FastSyncPivotKey)
+	if err != nil || len(value) == 0 {
+		return 0, false
+	}
+	err = binary.Unmarshal(value, &height)
+	if err != nil {
+		return 0, false
+	}
+	return height, true
+}
+
+//CheckReceipts returns ErrBelowFastSyncPivot if the block at height has no receipts because fast sync stored it
+//without executing it
+func (chainStore *ChainStore) CheckReceipts(height uint64) error {
+	pivot, ok := chainStore.GetFastSyncPivot()
+	if ok && height <= pivot {
+		return errors.Wrapf(ErrBelowFastSyncPivot, "block %d, pivot %d", height, pivot)
+	}
+	return nil
+}
+
+//CheckTxReceipt is CheckReceipts for the block of the transaction, a transaction not in the main chain passes
+func (chainStore *ChainStore) CheckTxReceipt(txHash *crypto.Hash) error {
+	entry, err := chainStore.GetTxLookupEntry(txHash)
+	if err != nil {
+		return nil
+	}
+	return chainStore.CheckReceipts(entry.Height)
+}
@@ -332,11 +332,16 @@ func (trieStore *trieStakeStore) CancelVoteCredit(fromAddr, toAddr *crypto.Commo
 //The mortgage cancellation cycle has come, and the cancelled currency can be added to the balance of the account
 func (trieStore *trieStakeStore) GetCancelCreditForBalance(addr *crypto.CommonAddress, height uint64, changeInterval uint64) *big.Int {
 	storage, _ := trieStore.getStakeStorage(addr)
-	if storage == nil {
-		return &big.Int{}
-	}
+	return MaturedCancelCredit(storage, height, changeInterval)
+}
 
+//MaturedCancelCredit sums the cancelled credit of storage whose refund is due at height,
+//storage may be nil
+func MaturedCancelCredit(storage *types.StakeStorage, height uint64, changeInterval uint64) *big.Int {
 	total := new(big.Int)
+	if storage == nil {
+		return total
+	}
 	for _, cc := range storage.CC {
 		if height >= cc.CancelCreditHeight+changeInterval {
 			for _, value := range cc.CancelCreditValue {
@@ -31,6 +31,8 @@ type StoreInterface interface {
 	AliasGet(alias string) (*crypto.CommonAddress, error)
 	AliasExist(alias string) bool
 	AliasSet(addr *crypto.CommonAddress, alias string, height uint64) (err error)
+	AliasTransfer(from, to *crypto.CommonAddress, alias string) error
+	AliasRelease(addr *crypto.CommonAddress, alias string) error
 
 	GetBalance(addr *crypto.CommonAddress, height uint64) *big.Int
 	PutBalance(addr *crypto.CommonAddress, height uint64, balance *big.Int) error
@@ -45,6 +47,15 @@ type StoreInterface interface {
 	PutByteCode(addr *crypto.CommonAddress, byteCode []byte) error
 
 	GetReputation(addr *crypto.CommonAddress) *big.Int
+
+	GetMultiSigAccount(addr *crypto.CommonAddress) (*types.MultiSigAccount, error)
+	PutMultiSigAccount(addr *crypto.CommonAddress, account *types.MultiSigAccount) error
+
+	GetAsset(symbol string) (*types.Asset, error)
+	PutAsset(asset *types.Asset) error
+	GetAssetBalance(addr *crypto.CommonAddress, symbol string) *big.Int
+	PutAssetBalance(addr *crypto.CommonAddress, symbol string, balance *big.Int) error
+
 	GetStateRoot() []byte
 	RecoverTrie(root []byte) bool
 
@@ -53,6 +64,12 @@ type StoreInterface interface {
 	Put(key []byte, value []byte) error
 	Commit()
 
+	//contract storage
+	GetState(addr *crypto.CommonAddress, key []byte) ([]byte, error)
+	PutState(addr *crypto.CommonAddress, key []byte, value []byte) error
+	ClearState(addr *crypto.CommonAddress) error
+	GetStorageRoot(addr *crypto.CommonAddress) (crypto.Hash, error)
+
 	CopyState() *database.SnapShot
 	RevertState(shot *database.SnapShot)
 
@@ -111,7 +128,12 @@ func (s Store) AddCandidateAddr(addr *crypto.CommonAddress) error {
 }
 
 func (s Store) GetChangeInterval() (uint64, error) {
-	value, err := s.db.store.Get([]byte(ChangeInterval))
+	return ReadChangeInterval(s.db.store)
+}
+
+//ReadChangeInterval reads the change interval the consensus stored in db, in blocks
+func ReadChangeInterval(db dbinterface.KeyValueReader) (uint64, error) {
+	value, err := db.Get([]byte(ChangeInterval))
 	if err != nil {
 		log.Error("CancelCandidateCredit get change interval ", "err", err)
 		return 0, err
@@ -148,7 +170,8 @@ func (s Store) GetVoteCreditCount(addr *crypto.CommonAddress) *big.Int {
 }
 
 func (s Store) Empty(addr *crypto.CommonAddress) bool {
-	return s.Empty(addr)
+	storage, _ := s.account.GetStorage(addr)
+	return storage == nil || storage.Empty()
 }
 
 func (s Store) GetStorageAlias(addr *crypto.CommonAddress) string {
@@ -159,6 +182,38 @@ func (s Store) DeleteStorage(addr *crypto.CommonAddress) error {
 	return s.account.DeleteStorage(addr)
 }
 
+func (s Store) GetMultiSigAccount(addr *crypto.CommonAddress) (*types.MultiSigAccount, error) {
+	return s.account.GetMultiSigAccount(addr)
+}
+
+func (s Store) PutMultiSigAccount(addr *crypto.CommonAddress, account *types.MultiSigAccount) error {
+	return s.account.PutMultiSigAccount(addr, account)
+}
+
+func (s Store) GetAsset(symbol string) (*types.Asset, error) {
+	return s.account.GetAsset(symbol)
+}
+
+func (s Store) PutAsset(asset *types.Asset) error {
+	return s.account.PutAsset(asset)
+}
+
+func (s Store) GetAssetBalance(addr *crypto.CommonAddress, symbol string) *big.Int {
+	return s.account.GetAssetBalance(addr, symbol)
+}
+
+func (s Store) PutAssetBalance(addr *crypto.CommonAddress, symbol string, balance *big.Int) error {
+	return s.account.PutAssetBalance(addr, symbol, balance)
+}
+
+func (s Store) AliasTransfer(from, to *crypto.CommonAddress, alias string) error {
+	return s.account.AliasTransfer(from, to, alias)
+}
+
+func (s Store) AliasRelease(addr *crypto.CommonAddress, alias string) error {
+	return s.account.AliasRelease(addr, alias)
+}
+
 func (s Store) AliasGet(alias string) (*crypto.CommonAddress, error) {
 	return s.account.AliasGet(alias)
 }
@@ -306,8 +361,30 @@ func (s Store) Put(key []byte, value []byte) error {
 	return s.db.Put(key, value)
 }
 
+func (s Store) GetState(addr *crypto.CommonAddress, key []byte) ([]byte, error) {
+	return s.db.GetState(addr, key)
+}
+
+func (s Store) PutState(addr *crypto.CommonAddress, key []byte, value []byte) error {
+	return s.db.PutState(addr, key, value)
+}
+
+func (s Store) ClearState(addr *crypto.CommonAddress) error {
+	return s.db.ClearState(addr)
+}
+
+func (s Store) GetStorageRoot(addr *crypto.CommonAddress) (crypto.Hash, error) {
+	return s.db.GetStorageRoot(addr)
+}
+
 func TrieStoreFromStore(diskDB dbinterface.KeyValueStore, stateRoot []byte) (StoreInterface, error) {
-	db := NewStoreDB(diskDB, nil, nil, trie.NewDatabaseWithCache(diskDB, 0))
+	return TrieStoreFromDatabase(diskDB, trie.NewDatabaseWithCache(diskDB, 0), stateRoot)
+}
+
+//TrieStoreFromDatabase opens the state at stateRoot on trieDb, the nodes written by the store are kept in
+//the memory of trieDb until they are committed
+func TrieStoreFromDatabase(diskDB dbinterface.KeyValueStore, trieDb *trie.Database, stateRoot []byte) (StoreInterface, error) {
+	db := NewStoreDB(diskDB, nil, nil, trieDb)
 
 	store := &Store{
 		stake:   newStakeStorage(db),
@@ -1,10 +1,14 @@
 package store
 
 import (
+	"math/big"
+	"sync"
+
 	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/database"
 	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/types"
 )
 
 type StoreDB struct {
@@ -12,14 +16,18 @@ type StoreDB struct {
 	cache  *database.TransactionStore //The data belongs to storage's cache and is written to diskDb by a call flush
 	trie   *trie.SecureTrie           //Global state tree temporary tree (temporary variable)
 	trieDb *trie.Database             //The db used when the state tree is stored to disk
+
+	storageLock sync.Mutex
+	storages    map[crypto.CommonAddress]*database.TransactionStore //Opened contract storage tries, their roots are written to the storages of the accounts by flush
 }
 
 func NewStoreDB(store dbinterface.KeyValueStore, cache *database.TransactionStore, trie *trie.SecureTrie, trieDb *trie.Database) *StoreDB {
 	return &StoreDB{
-		store:  store,
-		cache:  cache,
-		trie:   trie,
-		trieDb: trieDb,
+		store:    store,
+		cache:    cache,
+		trie:     trie,
+		trieDb:   trieDb,
+		storages: make(map[crypto.CommonAddress]*database.TransactionStore),
 	}
 }
 
@@ -69,17 +77,194 @@ func (s *StoreDB) Delete(key []byte) error {
 }
 
 func (s *StoreDB) Flush() {
+	s.storageLock.Lock()
+	for addr, storage := range s.storages {
+		addr := addr
+		storage.Flush()
+		err := s.putStorageRoot(&addr, storage.Hash())
+		if err != nil {
+			log.WithField("addr", addr.String()).WithField("err", err).Error("write storage root")
+		}
+	}
+	s.storageLock.Unlock()
+
 	if s.cache != nil {
 		s.cache.Flush()
 	}
 }
 
+//storedStorageRoot returns the root of the contract storage trie recorded in the storage of the account
+func (s *StoreDB) storedStorageRoot(addr *crypto.CommonAddress) (crypto.Hash, error) {
+	value, err := s.Get(types.AccountKey(addr))
+	if err != nil || len(value) == 0 {
+		return trie.EmptyRoot, err
+	}
+	storage, err := types.DecodeStorage(value)
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+	if !storage.HasStorageRoot() {
+		return trie.EmptyRoot, nil
+	}
+	return storage.StorageRoot, nil
+}
+
+//putStorageRoot records the root of the contract storage trie in the storage of the account
+func (s *StoreDB) putStorageRoot(addr *crypto.CommonAddress, root crypto.Hash) error {
+	key := types.AccountKey(addr)
+	value, err := s.Get(key)
+	if err != nil {
+		return err
+	}
+	storage := &types.Storage{}
+	if len(value) > 0 {
+		storage, err = types.DecodeStorage(value)
+		if err != nil {
+			return err
+		}
+	} else if root == trie.EmptyRoot {
+		return nil
+	}
+	if root == trie.EmptyRoot {
+		root = crypto.Hash{}
+	}
+	if len(value) > 0 && storage.StorageRoot == root {
+		return nil
+	}
+	storage.StorageRoot = root
+	value, err = types.EncodeStorage(storage)
+	if err != nil {
+		return err
+	}
+	return s.Put(key, value)
+}
+
+//contractStorage returns the storage trie of the contract, it is opened at the root recorded in the storage of the account
+func (s *StoreDB) contractStorage(addr *crypto.CommonAddress) (*database.TransactionStore, error) {
+	if storage, ok := s.storages[*addr]; ok {
+		return storage, nil
+	}
+	root, err := s.storedStorageRoot(addr)
+	if err != nil {
+		return nil, err
+	}
+	storageTrie, err := trie.NewSecure(root, s.trieDb)
+	if err != nil {
+		return nil, err
+	}
+	storage := database.NewTransactionStore(storageTrie, nil)
+	s.storages[*addr] = storage
+	return storage, nil
+}
+
+//GetState returns the value of a storage slot of the contract. Contracts deployed before the contract storage
+//fork keep their slots in the state trie under the legacy key, a slot not written since the fork is read from there.
+func (s *StoreDB) GetState(addr *crypto.CommonAddress, key []byte) ([]byte, error) {
+	s.storageLock.Lock()
+	defer s.storageLock.Unlock()
+
+	storage, err := s.contractStorage(addr)
+	if err != nil {
+		return nil, err
+	}
+	value, err := storage.Get(key)
+	if err != nil || len(value) > 0 {
+		return value, err
+	}
+	return s.Get(legacySlotKey(addr, key))
+}
+
+//PutState sets the value of a storage slot of the contract in its storage trie. The legacy slot is removed so
+//that the slot is migrated and a deleted value does not fall back to the legacy one.
+func (s *StoreDB) PutState(addr *crypto.CommonAddress, key []byte, value []byte) error {
+	s.storageLock.Lock()
+	defer s.storageLock.Unlock()
+
+	storage, err := s.contractStorage(addr)
+	if err != nil {
+		return err
+	}
+	legacyKey := legacySlotKey(addr, key)
+	legacyValue, err := s.Get(legacyKey)
+	if err != nil {
+		return err
+	}
+	if len(legacyValue) > 0 {
+		if err := s.Put(legacyKey, nil); err != nil {
+			return err
+		}
+	}
+	if len(value) == 0 {
+		return storage.Delete(key)
+	}
+	return storage.Put(key, value)
+}
+
+//legacySlotKey returns the legacy key of the slot, key is the 32 byte slot of the contract storage trie
+func legacySlotKey(addr *crypto.CommonAddress, key []byte) []byte {
+	return types.LegacyStorageKey(addr, new(big.Int).SetBytes(key).Bytes())
+}
+
+//ClearState drops all storage slots of the contract, the storage root is removed from the storage of the account by the next flush.
+//The legacy slots of a contract deployed before the fork stay in the state trie, they were never removed by a
+//self destruct and can not be read again as no contract is created at the address of a destructed one.
+func (s *StoreDB) ClearState(addr *crypto.CommonAddress) error {
+	s.storageLock.Lock()
+	defer s.storageLock.Unlock()
+
+	storageTrie, err := trie.NewSecure(crypto.Hash{}, s.trieDb)
+	if err != nil {
+		return err
+	}
+	s.storages[*addr] = database.NewTransactionStore(storageTrie, nil)
+	return nil
+}
+
+func (s *StoreDB) GetStorageRoot(addr *crypto.CommonAddress) (crypto.Hash, error) {
+	s.storageLock.Lock()
+	defer s.storageLock.Unlock()
+
+	if storage, ok := s.storages[*addr]; ok {
+		storage.Flush()
+		return storage.Hash(), nil
+	}
+	return s.storedStorageRoot(addr)
+}
+
+//referenceStorage is called for the leaves of the state trie when it is written to the trie database.
+//The root of a contract storage trie is referenced by the state trie node of the storage of its account, so that
+//committing a state root to disk also commits the storage tries of the contracts.
+func (s *StoreDB) referenceStorage(leaf []byte, parent crypto.Hash) error {
+	if root, ok := types.StorageRootOf(leaf); ok {
+		s.trieDb.Reference(root, parent)
+	}
+	return nil
+}
+
+//RevertState reverts the state and the contract storages to the snapshot, the storages opened after the snapshot
+//are dropped so that they are opened again at the reverted storage root
 func (s *StoreDB) RevertState(shot *database.SnapShot) {
 	s.cache.RevertState(shot)
+
+	s.storageLock.Lock()
+	defer s.storageLock.Unlock()
+	s.storages = make(map[crypto.CommonAddress]*database.TransactionStore, len(shot.Storages))
+	for addr, storageShot := range shot.Storages {
+		s.storages[addr] = storageShot.Revert()
+	}
 }
 
+//CopyState takes a snapshot of the state together with every opened contract storage
 func (s *StoreDB) CopyState() *database.SnapShot {
-	return s.cache.CopyState()
+	shot := s.cache.CopyState()
+
+	s.storageLock.Lock()
+	defer s.storageLock.Unlock()
+	shot.Storages = make(map[crypto.CommonAddress]*database.SnapShot, len(s.storages))
+	for addr, storage := range s.storages {
+		shot.Storages[addr] = storage.CopyState()
+	}
+	return shot
 }
 
 func (s *StoreDB) getStateRoot() []byte {
@@ -93,6 +278,9 @@ func (s *StoreDB) RecoverTrie(root []byte) bool {
 		log.WithField("err", err).Info("new secure")
 		return false
 	}
-	s.cache = database.NewTransactionStore(s.trie)
+	s.cache = database.NewTransactionStore(s.trie, s.referenceStorage)
+	s.storageLock.Lock()
+	s.storages = make(map[crypto.CommonAddress]*database.TransactionStore)
+	s.storageLock.Unlock()
 	return true
 }
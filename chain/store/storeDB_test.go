@@ -0,0 +1,140 @@
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func TestContractStorageFork(t *testing.T) {
+	diskDB := memorydb.New()
+	before, err := TrieStoreFromStore(diskDB, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	slots := []crypto.Hash{crypto.BigToHash(big.NewInt(0)), crypto.BigToHash(big.NewInt(1)), crypto.RandomHash()}
+
+	//the blocks before the fork keep the slots in the state trie under their legacy key
+	for i, slot := range slots {
+		if err := before.Put(types.LegacyStorageKey(&addr, new(big.Int).SetBytes(slot[:]).Bytes()), []byte{byte(i + 1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := before.GetStateRoot()
+
+	//the first block after the fork reads them through the contract storage
+	after, err := TrieStoreFromDatabase(diskDB, before.TrieDB(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, slot := range slots {
+		value, err := after.GetState(&addr, slot[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(value, []byte{byte(i + 1)}) {
+			t.Fatalf("slot %s: expect the legacy value %d, got %x", slot.String(), i+1, value)
+		}
+	}
+
+	//a written slot moves to the storage trie and a deleted one does not fall back to the legacy value
+	if err := after.PutState(&addr, slots[0][:], []byte{9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := after.PutState(&addr, slots[1][:], nil); err != nil {
+		t.Fatal(err)
+	}
+	root = after.GetStateRoot()
+
+	next, err := TrieStoreFromDatabase(diskDB, after.TrieDB(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expects := [][]byte{{9}, nil, {3}}
+	for i, slot := range slots {
+		value, err := next.GetState(&addr, slot[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(value, expects[i]) {
+			t.Fatalf("slot %s: expect %x, got %x", slot.String(), expects[i], value)
+		}
+	}
+	for _, slot := range slots[:2] {
+		legacy, err := next.Get(types.LegacyStorageKey(&addr, new(big.Int).SetBytes(slot[:]).Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(legacy) != 0 {
+			t.Fatalf("slot %s: expect the legacy value to be removed, got %x", slot.String(), legacy)
+		}
+	}
+}
+
+func TestStorageRootInAccount(t *testing.T) {
+	diskDB := memorydb.New()
+	trieStore, err := TrieStoreFromStore(diskDB, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	if err := trieStore.PutByteCode(&addr, []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	slot := crypto.BigToHash(big.NewInt(1))
+	if err := trieStore.PutState(&addr, slot[:], []byte{7}); err != nil {
+		t.Fatal(err)
+	}
+	//a 32 byte value in the state trie is no storage root
+	if err := trieStore.Put([]byte("other"), crypto.RandomHash().Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	root := crypto.Bytes2Hash(trieStore.GetStateRoot())
+
+	value, err := trieStore.Get(types.AccountKey(&addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	storageRoot, ok := types.StorageRootOf(value)
+	if !ok {
+		t.Fatalf("expect the storage root in the account, got %x", value)
+	}
+	if stored, err := trieStore.GetStorageRoot(&addr); err != nil || stored != storageRoot {
+		t.Fatalf("expect storage root %v, got %v %v", storageRoot, stored, err)
+	}
+
+	//committing the state root writes the storage trie it references
+	if err := trieStore.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	fromDisk, err := TrieStoreFromDatabase(diskDB, trie.NewDatabase(diskDB), root[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	slotValue, err := fromDisk.GetState(&addr, slot[:])
+	if err != nil || !bytes.Equal(slotValue, []byte{7}) {
+		t.Fatalf("expect the slot of the committed storage trie, got %x %v", slotValue, err)
+	}
+
+	//a cleared storage drops the root from the account
+	if err := fromDisk.ClearState(&addr); err != nil {
+		t.Fatal(err)
+	}
+	fromDisk.GetStateRoot()
+	value, err = fromDisk.Get(types.AccountKey(&addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := types.StorageRootOf(value); ok {
+		t.Fatalf("expect no storage root after clear, got %x", value)
+	}
+}
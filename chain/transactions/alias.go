@@ -0,0 +1,51 @@
+package transactions
+
+import (
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func isAliasTransfer(rules *params.Rules) bool {
+	return rules.IsAliasTransfer
+}
+
+func aliasView(tx *types.Transaction) interface{} {
+	return map[string]string{"alias": string(tx.GetData())}
+}
+
+func aliasTransferTopics(tx *types.Transaction) []crypto.Hash {
+	return append(aliasTopic(tx), types.AddressTopic(tx.To()))
+}
+
+func executeAliasTransfer(context *ExecuteTransactionContext) ([]byte, error) {
+	alias := context.Tx().GetData()
+	err := context.TrieStore().AliasTransfer(context.From(), context.Tx().To(), string(alias))
+	if err != nil {
+		return nil, errors.Wrapf(err, "transfer alias %s", alias)
+	}
+	return alias, nil
+}
+
+func executeAliasRelease(context *ExecuteTransactionContext) ([]byte, error) {
+	alias := context.Tx().GetData()
+	err := context.TrieStore().AliasRelease(context.From(), string(alias))
+	if err != nil {
+		return nil, errors.Wrapf(err, "release alias %s", alias)
+	}
+	return alias, nil
+}
+
+//checkAliasOwnerPool checks that the sender owns the alias and the recipient of a transfer owns none
+func checkAliasOwnerPool(tx *types.Transaction, from *crypto.CommonAddress, pooled []*types.Transaction, trieStore store.StoreInterface, height uint64) error {
+	alias := string(tx.GetData())
+	if alias == "" || trieStore.GetStorageAlias(from) != alias {
+		return errors.Wrapf(store.ErrNotAliasOwner, "%s", alias)
+	}
+	if tx.Type() == types.AliasTransferType && trieStore.GetStorageAlias(tx.To()) != "" {
+		return errors.Wrapf(store.ErrAliasOwned, "%s", tx.To().String())
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package transactions
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/block"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/chain/utils"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func TestAliasTransferAndRelease(t *testing.T) {
+	trieStore := newExecuteStore(t)
+	owner, buyer := crypto.CommonAddress{1}, crypto.CommonAddress{2}
+	alias := "drepalias"
+	fee, err := types.CheckAlias([]byte(alias))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = trieStore.PutBalance(&owner, 5, fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := (&params.ForkConfig{SystemLogHeight: 1, AliasTransferHeight: 1}).Rules(5)
+	blockContext := block.NewBlockExecuteContext(trieStore, nil, nil, &types.Block{Header: &types.BlockHeader{Height: 5}}, rules)
+	processor := &Processor{}
+	execute := func(from *crypto.CommonAddress, tx *types.Transaction) *types.ExecuteTransactionResult {
+		context := NewExecuteTransactionContext(blockContext, trieStore, new(utils.GasPool).AddGas(1000000), from, tx)
+		if err := context.PreCheck(); err != nil {
+			t.Fatal(err)
+		}
+		return processor.ExecuteTransaction(context)
+	}
+	resolves := func(addr *crypto.CommonAddress) {
+		if got := trieStore.GetStorageAlias(addr); got != alias {
+			t.Fatalf("expect %s to have the alias, got %q", addr.String(), got)
+		}
+		if got, err := trieStore.AliasGet(alias); err != nil || *got != *addr {
+			t.Fatalf("expect the alias to resolve to %s, got %v %v", addr.String(), got, err)
+		}
+	}
+
+	etr := execute(&owner, types.NewAliasTransaction(alias, big.NewInt(0), big.NewInt(100000), 0))
+	if etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+	resolves(&owner)
+	etr = execute(&owner, types.NewAliasTransaction("another", big.NewInt(0), big.NewInt(100000), 1))
+	if etr.Txerror != ErrNotSupportRenameAlias {
+		t.Fatalf("expect a second alias refused, got %v", etr.Txerror)
+	}
+
+	tx := types.NewAliasTransferTransaction(buyer, alias, big.NewInt(0), big.NewInt(100000), 1)
+	if err := CheckPool(tx, &buyer, nil, trieStore, 5); errors.Cause(err) != store.ErrNotAliasOwner {
+		t.Fatalf("expect the pool to refuse a transfer by another address, got %v", err)
+	}
+	if err := CheckPool(tx, &owner, nil, trieStore, 5); err != nil {
+		t.Fatal(err)
+	}
+	etr = execute(&owner, tx)
+	if etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+	resolves(&buyer)
+	if got := trieStore.GetStorageAlias(&owner); got != "" {
+		t.Fatalf("expect the old owner to lose the alias, got %q", got)
+	}
+	log := etr.ContractTxLog[0]
+	if log.Topics[0] != types.AliasTransferEvent || log.Topics[3] != types.AddressTopic(&buyer) {
+		t.Fatalf("unexpected transfer log %v", log.Topics)
+	}
+
+	etr = execute(&owner, types.NewAliasReleaseTransaction(alias, big.NewInt(0), big.NewInt(100000), 2))
+	if errors.Cause(etr.Txerror) != store.ErrNotAliasOwner {
+		t.Fatalf("expect a release by the old owner refused, got %v", etr.Txerror)
+	}
+	etr = execute(&buyer, types.NewAliasReleaseTransaction(alias, big.NewInt(0), big.NewInt(100000), 0))
+	if etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+	if trieStore.AliasExist(alias) || trieStore.GetStorageAlias(&buyer) != "" {
+		t.Fatal("expect the alias freed")
+	}
+
+	tx = types.NewAliasReleaseTransaction(alias, big.NewInt(0), big.NewInt(100000), 0)
+	err = ValidateTransaction(tx, (&params.ForkConfig{AliasTransferHeight: 10}).Rules(9))
+	if errors.Cause(err) != ErrTxTypeInactive {
+		t.Fatalf("expect %v before the fork, got %v", ErrTxTypeInactive, err)
+	}
+}
@@ -0,0 +1,285 @@
+package transactions
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func isAsset(rules *params.Rules) bool {
+	return rules.IsAsset
+}
+
+//validateIssueAsset checks the symbol of the new asset, an asset transaction moves no coin
+func validateIssueAsset(tx *types.Transaction) error {
+	issue, err := tx.AssetIssue()
+	if err != nil {
+		return errors.Wrap(ErrAsset, err.Error())
+	}
+	if tx.Amount().Sign() != 0 {
+		return errors.Wrapf(ErrAsset, "amount %v of an asset transaction", tx.Amount())
+	}
+	return types.CheckAssetSymbol(issue.Symbol)
+}
+
+//validateAssetAmount checks the symbol and the amount moved by an asset transfer, mint or burn
+func validateAssetAmount(tx *types.Transaction) error {
+	amount, err := tx.AssetAmount()
+	if err != nil {
+		return errors.Wrap(ErrAsset, err.Error())
+	}
+	if tx.Amount().Sign() != 0 {
+		return errors.Wrapf(ErrAsset, "amount %v of an asset transaction", tx.Amount())
+	}
+	if amount.Amount.ToInt().Sign() == 0 {
+		return errors.Wrapf(ErrAsset, "zero %s moved", amount.Symbol)
+	}
+	return types.CheckAssetSymbol(amount.Symbol)
+}
+
+func issueAssetGas(tx *types.Transaction) (uint64, error) {
+	gas, err := tx.IntrinsicGas()
+	if err != nil {
+		return 0, err
+	}
+	return gas + params.IssueAssetGas, nil
+}
+
+func issueAssetView(tx *types.Transaction) interface{} {
+	issue, err := tx.AssetIssue()
+	if err != nil {
+		return nil
+	}
+	return issue
+}
+
+func assetAmountView(tx *types.Transaction) interface{} {
+	amount, err := tx.AssetAmount()
+	if err != nil {
+		return nil
+	}
+	return amount
+}
+
+func issueAssetTopics(tx *types.Transaction) []crypto.Hash {
+	issue, _ := tx.AssetIssue()
+	return []crypto.Hash{crypto.Keccak256Hash([]byte(issue.Symbol))}
+}
+
+func assetTopics(tx *types.Transaction) []crypto.Hash {
+	amount, _ := tx.AssetAmount()
+	return []crypto.Hash{crypto.Keccak256Hash([]byte(amount.Symbol))}
+}
+
+func assetToTopics(tx *types.Transaction) []crypto.Hash {
+	return append(toTopic(tx), assetTopics(tx)...)
+}
+
+func executeIssueAsset(context *ExecuteTransactionContext) ([]byte, error) {
+	issue, err := context.Tx().AssetIssue()
+	if err != nil {
+		return nil, err
+	}
+	store := context.TrieStore()
+	old, err := store.GetAsset(issue.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if old != nil {
+		return nil, errors.Wrapf(ErrAssetExist, "%s", issue.Symbol)
+	}
+	err = burnIssueFee(store, context.From(), types.GetAssetIssueFee(len(issue.Symbol)), context.Header().Height)
+	if err != nil {
+		return nil, err
+	}
+	err = store.PutAsset(&types.Asset{
+		Symbol:        issue.Symbol,
+		Issuer:        *context.From(),
+		Supply:        issue.Supply,
+		MintAuthority: issue.MintAuthority,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = store.PutAssetBalance(context.From(), issue.Symbol, issue.Supply.ToInt())
+	if err != nil {
+		return nil, err
+	}
+	return types.AssetData(issue.Supply.ToInt(), issue.Symbol), nil
+}
+
+//burnIssueFee moves the issue fee from the issuer to the hole address, like the fee of an alias
+func burnIssueFee(store store.StoreInterface, issuer *crypto.CommonAddress, fee *big.Int, height uint64) error {
+	balance := store.GetBalance(issuer, height)
+	if balance.Cmp(fee) < 0 {
+		return errors.Wrapf(ErrBalance, "issue fee %v of %s", fee, issuer.String())
+	}
+	err := store.PutBalance(issuer, height, balance.Sub(balance, fee))
+	if err != nil {
+		return err
+	}
+	holeBalance := store.GetBalance(&params.HoleAddress, height)
+	return store.PutBalance(&params.HoleAddress, height, holeBalance.Add(holeBalance, fee))
+}
+
+func executeAssetTransfer(context *ExecuteTransactionContext) ([]byte, error) {
+	amount, err := context.Tx().AssetAmount()
+	if err != nil {
+		return nil, err
+	}
+	_, err = getAsset(context.TrieStore(), amount.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	err = subAssetBalance(context.TrieStore(), context.From(), amount)
+	if err != nil {
+		return nil, err
+	}
+	err = addAssetBalance(context.TrieStore(), context.Tx().To(), amount)
+	if err != nil {
+		return nil, err
+	}
+	return types.AssetData(amount.Amount.ToInt(), amount.Symbol), nil
+}
+
+func executeAssetMint(context *ExecuteTransactionContext) ([]byte, error) {
+	amount, err := context.Tx().AssetAmount()
+	if err != nil {
+		return nil, err
+	}
+	store := context.TrieStore()
+	asset, err := getAsset(store, amount.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if !asset.Mintable() || asset.MintAuthority != *context.From() {
+		return nil, errors.Wrapf(ErrNotMintAuthority, "%s", amount.Symbol)
+	}
+	asset.Supply = common.Big(*new(big.Int).Add(asset.Supply.ToInt(), amount.Amount.ToInt()))
+	err = store.PutAsset(asset)
+	if err != nil {
+		return nil, err
+	}
+	err = addAssetBalance(store, context.Tx().To(), amount)
+	if err != nil {
+		return nil, err
+	}
+	return types.AssetData(amount.Amount.ToInt(), amount.Symbol), nil
+}
+
+func executeAssetBurn(context *ExecuteTransactionContext) ([]byte, error) {
+	amount, err := context.Tx().AssetAmount()
+	if err != nil {
+		return nil, err
+	}
+	store := context.TrieStore()
+	asset, err := getAsset(store, amount.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	err = subAssetBalance(store, context.From(), amount)
+	if err != nil {
+		return nil, err
+	}
+	asset.Supply = common.Big(*new(big.Int).Sub(asset.Supply.ToInt(), amount.Amount.ToInt()))
+	err = store.PutAsset(asset)
+	if err != nil {
+		return nil, err
+	}
+	return types.AssetData(amount.Amount.ToInt(), amount.Symbol), nil
+}
+
+func getAsset(store store.StoreInterface, symbol string) (*types.Asset, error) {
+	asset, err := store.GetAsset(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if asset == nil {
+		return nil, errors.Wrapf(ErrAssetNotExist, "%s", symbol)
+	}
+	return asset, nil
+}
+
+func subAssetBalance(store store.StoreInterface, addr *crypto.CommonAddress, amount *types.AssetAmount) error {
+	balance := store.GetAssetBalance(addr, amount.Symbol)
+	if balance.Cmp(amount.Amount.ToInt()) < 0 {
+		return errors.Wrapf(ErrAssetBalance, "%v %s of %s", balance, amount.Symbol, addr.String())
+	}
+	return store.PutAssetBalance(addr, amount.Symbol, balance.Sub(balance, amount.Amount.ToInt()))
+}
+
+func addAssetBalance(store store.StoreInterface, addr *crypto.CommonAddress, amount *types.AssetAmount) error {
+	balance := store.GetAssetBalance(addr, amount.Symbol)
+	return store.PutAssetBalance(addr, amount.Symbol, balance.Add(balance, amount.Amount.ToInt()))
+}
+
+//checkAssetPool checks a transaction of an asset against the state of the chain tip, the transactions of the sender
+//waiting in the pool are counted as executed before it
+func checkAssetPool(tx *types.Transaction, from *crypto.CommonAddress, pooled []*types.Transaction, trieStore store.StoreInterface, height uint64) error {
+	//a pooled transaction with the nonce of tx is replaced by it
+	others := make([]*types.Transaction, 0, len(pooled))
+	for _, pooledTx := range pooled {
+		if pooledTx.Nonce() != tx.Nonce() {
+			others = append(others, pooledTx)
+		}
+	}
+	if tx.Type() == types.IssueAssetType {
+		issue, err := tx.AssetIssue()
+		if err != nil {
+			return err
+		}
+		if asset, _ := trieStore.GetAsset(issue.Symbol); asset != nil {
+			return errors.Wrapf(ErrAssetExist, "%s", issue.Symbol)
+		}
+		fee := types.GetAssetIssueFee(len(issue.Symbol))
+		for _, pooledTx := range others {
+			if pooledTx.Type() != types.IssueAssetType {
+				continue
+			}
+			pooledIssue, err := pooledTx.AssetIssue()
+			if err != nil {
+				continue
+			}
+			if pooledIssue.Symbol == issue.Symbol {
+				return errors.Wrapf(ErrAssetExist, "%s in the pool", issue.Symbol)
+			}
+			fee.Add(fee, types.GetAssetIssueFee(len(pooledIssue.Symbol)))
+		}
+		if trieStore.GetBalance(from, height).Cmp(fee) < 0 {
+			return errors.Wrapf(ErrBalance, "issue fee %v of %s", fee, from.String())
+		}
+		return nil
+	}
+	amount, err := tx.AssetAmount()
+	if err != nil {
+		return err
+	}
+	asset, err := getAsset(trieStore, amount.Symbol)
+	if err != nil {
+		return err
+	}
+	if tx.Type() == types.AssetMintType {
+		if !asset.Mintable() || asset.MintAuthority != *from {
+			return errors.Wrapf(ErrNotMintAuthority, "%s", amount.Symbol)
+		}
+		return nil
+	}
+	spent := new(big.Int).Set(amount.Amount.ToInt())
+	for _, pooledTx := range others {
+		if pooledTx.Type() != types.AssetTransferType && pooledTx.Type() != types.AssetBurnType {
+			continue
+		}
+		if pooledAmount, err := pooledTx.AssetAmount(); err == nil && pooledAmount.Symbol == amount.Symbol {
+			spent.Add(spent, pooledAmount.Amount.ToInt())
+		}
+	}
+	if trieStore.GetAssetBalance(from, amount.Symbol).Cmp(spent) < 0 {
+		return errors.Wrapf(ErrAssetBalance, "%v %s spent by %s", spent, amount.Symbol, from.String())
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package transactions
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/block"
+	"github.com/drep-project/DREP-Chain/chain/utils"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func TestExecuteAsset(t *testing.T) {
+	trieStore := newExecuteStore(t)
+	issuer, holder := crypto.CommonAddress{1}, crypto.CommonAddress{2}
+	rules := (&params.ForkConfig{SystemLogHeight: 1, AssetHeight: 1}).Rules(5)
+	blockContext := block.NewBlockExecuteContext(trieStore, nil, nil, &types.Block{Header: &types.BlockHeader{Height: 5}}, rules)
+	execute := func(from *crypto.CommonAddress, tx *types.Transaction, err error) *types.ExecuteTransactionResult {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ValidateTransaction(tx, rules); err != nil {
+			t.Fatal(err)
+		}
+		context := NewExecuteTransactionContext(blockContext, trieStore, new(utils.GasPool).AddGas(1000000), from, tx)
+		if err := context.PreCheck(); err != nil {
+			t.Fatal(err)
+		}
+		return (&Processor{}).ExecuteTransaction(context)
+	}
+	expect := func(symbol string, balances map[crypto.CommonAddress]int64, supply int64) {
+		for addr, balance := range balances {
+			if got := trieStore.GetAssetBalance(&addr, symbol); got.Int64() != balance {
+				t.Fatalf("expect %d %s of %s, got %v", balance, symbol, addr.String(), got)
+			}
+		}
+		asset, err := trieStore.GetAsset(symbol)
+		if err != nil || asset.Supply.ToInt().Int64() != supply {
+			t.Fatalf("expect supply %d, got %v %v", supply, asset, err)
+		}
+	}
+	gasPrice, gasLimit := big.NewInt(0), big.NewInt(100000)
+	fee := types.GetAssetIssueFee(len("POINT"))
+	if err := trieStore.PutBalance(&issuer, 5, fee); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := types.NewIssueAssetTransaction("POINT", big.NewInt(1000), issuer, gasPrice, gasLimit, 0)
+	etr := execute(&issuer, tx, err)
+	if etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+	expect("POINT", map[crypto.CommonAddress]int64{issuer: 1000}, 1000)
+	if trieStore.GetBalance(&issuer, 5).Sign() != 0 || trieStore.GetBalance(&params.HoleAddress, 5).Cmp(fee) != 0 {
+		t.Fatalf("expect the issue fee %v burnt, got %v", fee, trieStore.GetBalance(&issuer, 5))
+	}
+	tx, err = types.NewIssueAssetTransaction("OTHER", big.NewInt(1), crypto.CommonAddress{}, gasPrice, gasLimit, 1)
+	if etr := execute(&issuer, tx, err); errors.Cause(etr.Txerror) != ErrBalance {
+		t.Fatalf("expect the issue fee to be paid, got %v", etr.Txerror)
+	}
+	tx, err = types.NewIssueAssetTransaction("POINT", big.NewInt(1), crypto.CommonAddress{}, gasPrice, gasLimit, 0)
+	if etr := execute(&holder, tx, err); errors.Cause(etr.Txerror) != ErrAssetExist {
+		t.Fatalf("expect a symbol issued once, got %v", etr.Txerror)
+	}
+
+	tx, err = types.NewAssetTransferTransaction(holder, "POINT", big.NewInt(300), gasPrice, gasLimit, 1)
+	etr = execute(&issuer, tx, err)
+	if etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+	expect("POINT", map[crypto.CommonAddress]int64{issuer: 700, holder: 300}, 1000)
+	log := etr.ContractTxLog[0]
+	if log.Topics[0] != types.AssetTransferEvent || log.Topics[2] != types.AddressTopic(&holder) || log.Topics[3] != crypto.Keccak256Hash([]byte("POINT")) {
+		t.Fatalf("unexpected transfer log %v", log.Topics)
+	}
+	if string(log.Data[crypto.HashLength:]) != "POINT" || new(big.Int).SetBytes(log.Data[:crypto.HashLength]).Int64() != 300 {
+		t.Fatalf("unexpected transfer log data %x", log.Data)
+	}
+
+	tx, err = types.NewAssetTransferTransaction(issuer, "POINT", big.NewInt(301), gasPrice, gasLimit, 0)
+	if etr := execute(&holder, tx, err); errors.Cause(etr.Txerror) != ErrAssetBalance {
+		t.Fatalf("expect the balance to be insufficient, got %v", etr.Txerror)
+	}
+	tx, err = types.NewAssetMintTransaction(holder, "POINT", big.NewInt(50), gasPrice, gasLimit, 0)
+	if etr := execute(&holder, tx, err); errors.Cause(etr.Txerror) != ErrNotMintAuthority {
+		t.Fatalf("expect only the mint authority to mint, got %v", etr.Txerror)
+	}
+	tx, err = types.NewAssetMintTransaction(holder, "POINT", big.NewInt(50), gasPrice, gasLimit, 2)
+	if etr := execute(&issuer, tx, err); etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+	expect("POINT", map[crypto.CommonAddress]int64{issuer: 700, holder: 350}, 1050)
+
+	tx, err = types.NewAssetBurnTransaction("POINT", big.NewInt(350), gasPrice, gasLimit, 0)
+	if etr := execute(&holder, tx, err); etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+	expect("POINT", map[crypto.CommonAddress]int64{issuer: 700, holder: 0}, 700)
+
+	err = ValidateTransaction(tx, (&params.ForkConfig{AssetHeight: 10}).Rules(9))
+	if errors.Cause(err) != ErrTxTypeInactive {
+		t.Fatalf("expect %v before the fork, got %v", ErrTxTypeInactive, err)
+	}
+}
+
+func TestCheckAssetPool(t *testing.T) {
+	trieStore := newExecuteStore(t)
+	issuer := crypto.CommonAddress{1}
+	gasPrice, gasLimit := big.NewInt(0), big.NewInt(100000)
+	err := trieStore.PutAsset(&types.Asset{Symbol: "POINT", Issuer: issuer, Supply: common.Big(*big.NewInt(100))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := trieStore.PutAssetBalance(&issuer, "POINT", big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	newTx := func(tx *types.Transaction, err error) *types.Transaction {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	//the amounts spent by the pooled transactions of the sender are counted
+	transfer := newTx(types.NewAssetTransferTransaction(crypto.CommonAddress{2}, "POINT", big.NewInt(60), gasPrice, gasLimit, 0))
+	burn := newTx(types.NewAssetBurnTransaction("POINT", big.NewInt(60), gasPrice, gasLimit, 1))
+	if err := CheckPool(burn, &issuer, nil, trieStore, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckPool(burn, &issuer, []*types.Transaction{transfer}, trieStore, 5); errors.Cause(err) != ErrAssetBalance {
+		t.Fatalf("expect the pooled transfer counted, got %v", err)
+	}
+	replace := newTx(types.NewAssetBurnTransaction("POINT", big.NewInt(60), gasPrice, gasLimit, 0))
+	if err := CheckPool(replace, &issuer, []*types.Transaction{transfer}, trieStore, 5); err != nil {
+		t.Fatalf("expect a replaced transaction not counted, got %v", err)
+	}
+
+	//so are the fees of the pooled issues
+	fee := types.GetAssetIssueFee(len("COIN"))
+	if err := trieStore.PutBalance(&issuer, 5, fee); err != nil {
+		t.Fatal(err)
+	}
+	issue := newTx(types.NewIssueAssetTransaction("COIN", big.NewInt(1), crypto.CommonAddress{}, gasPrice, gasLimit, 2))
+	if err := CheckPool(issue, &issuer, nil, trieStore, 5); err != nil {
+		t.Fatal(err)
+	}
+	other := newTx(types.NewIssueAssetTransaction("GEMS", big.NewInt(1), crypto.CommonAddress{}, gasPrice, gasLimit, 3))
+	if err := CheckPool(other, &issuer, []*types.Transaction{issue}, trieStore, 5); errors.Cause(err) != ErrBalance {
+		t.Fatalf("expect the pooled issue fee counted, got %v", err)
+	}
+	again := newTx(types.NewIssueAssetTransaction("COIN", big.NewInt(1), crypto.CommonAddress{}, gasPrice, gasLimit, 3))
+	if err := CheckPool(again, &issuer, []*types.Transaction{issue}, trieStore, 5); errors.Cause(err) != ErrAssetExist {
+		t.Fatalf("expect a symbol issued once in the pool, got %v", err)
+	}
+	if err := CheckPool(newTx(types.NewIssueAssetTransaction("POINT", big.NewInt(1), crypto.CommonAddress{}, gasPrice, gasLimit, 2)), &issuer, nil, trieStore, 5); errors.Cause(err) != ErrAssetExist {
+		t.Fatalf("expect an issued symbol refused, got %v", err)
+	}
+}
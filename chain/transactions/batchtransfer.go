@@ -0,0 +1,78 @@
+package transactions
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+//validateBatchTransfer checks the payouts, the amount of the transaction must be their sum so that the cost of
+//the transaction covers all of them
+func validateBatchTransfer(tx *types.Transaction) error {
+	payouts, err := tx.Payouts()
+	if err != nil {
+		return errors.Wrap(ErrBatchTransfer, err.Error())
+	}
+	if len(payouts) == 0 || len(payouts) > types.MaxBatchPayouts {
+		return errors.Wrapf(ErrBatchTransfer, "%d payouts, at most %d", len(payouts), types.MaxBatchPayouts)
+	}
+	total := new(big.Int)
+	for i := range payouts {
+		total.Add(total, payouts[i].Amount.ToInt())
+	}
+	if total.Cmp(tx.Amount()) != 0 {
+		return errors.Wrapf(ErrBatchTransfer, "amount %v differs from the sum %v of the payouts", tx.Amount(), total)
+	}
+	return nil
+}
+
+//batchTransferGas charges every payout on top of the gas of a transfer
+func batchTransferGas(tx *types.Transaction) (uint64, error) {
+	gas, err := tx.IntrinsicGas()
+	if err != nil {
+		return 0, err
+	}
+	payouts, err := tx.Payouts()
+	if err != nil {
+		return 0, errors.Wrap(ErrBatchTransfer, err.Error())
+	}
+	return gas + uint64(len(payouts))*params.BatchPayoutGas, nil
+}
+
+//batchTransferExecutor pays all recipients of a batch transfer and logs a Transfer event for every payout
+type batchTransferExecutor struct {
+}
+
+func (executor *batchTransferExecutor) ExecuteTransaction(context *ExecuteTransactionContext) *types.ExecuteTransactionResult {
+	etr := &types.ExecuteTransactionResult{}
+	tx, store, height := context.Tx(), context.TrieStore(), context.Header().Height
+	payouts, err := tx.Payouts()
+	if err != nil {
+		etr.Txerror = err
+		return etr
+	}
+	//the amount is the sum of the payouts, see validateBatchTransfer
+	err = subBalance(context)
+	if err != nil {
+		etr.Txerror = err
+		return etr
+	}
+
+	fromTopic := types.AddressTopic(context.From())
+	for i := range payouts {
+		payout := &payouts[i]
+		//AddBalance adds the released credit of the recipient to the amount it is given
+		err = store.AddBalance(&payout.To, height, new(big.Int).Set(payout.Amount.ToInt()))
+		if err != nil {
+			etr.Txerror = err
+			return etr
+		}
+		if context.Rules().IsSystemLog {
+			log := types.NewSystemLog(tx, height, types.AmountData(payout.Amount.ToInt()), types.TransferEvent, fromTopic, types.AddressTopic(&payout.To))
+			etr.ContractTxLog = append(etr.ContractTxLog, log)
+		}
+	}
+	return etr
+}
@@ -0,0 +1,138 @@
+package transactions
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/block"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func newPayout(to byte, amount int64) types.Payout {
+	return types.Payout{To: crypto.CommonAddress{to}, Amount: common.Big(*big.NewInt(amount))}
+}
+
+func TestValidateBatchTransfer(t *testing.T) {
+	rules := (&params.ForkConfig{BatchTransferHeight: 10}).Rules(10)
+	tooMany := make([]types.Payout, types.MaxBatchPayouts+1)
+	tests := []struct {
+		payouts []types.Payout
+		amount  *big.Int //overrides the sum of the payouts
+		err     error
+	}{
+		{[]types.Payout{newPayout(2, 10), newPayout(3, 20)}, nil, nil},
+		{[]types.Payout{}, nil, ErrBatchTransfer},
+		{tooMany, nil, ErrBatchTransfer},
+		{[]types.Payout{newPayout(2, 10), newPayout(3, 20)}, big.NewInt(31), ErrBatchTransfer},
+	}
+	for i, test := range tests {
+		tx, err := types.NewBatchTransferTransaction(test.payouts, big.NewInt(1), big.NewInt(100000), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.amount != nil {
+			tx.Data.Amount = common.Big(*test.amount)
+		}
+		err = ValidateTransaction(tx, rules)
+		if errors.Cause(err) != test.err {
+			t.Fatalf("case %d: expect %v, got %v", i, test.err, err)
+		}
+	}
+
+	tx, _ := types.NewBatchTransferTransaction([]types.Payout{newPayout(2, 10)}, big.NewInt(1), big.NewInt(100000), 0)
+	err := ValidateTransaction(tx, (&params.ForkConfig{BatchTransferHeight: 10}).Rules(9))
+	if errors.Cause(err) != ErrTxTypeInactive {
+		t.Fatalf("expect %v before the fork, got %v", ErrTxTypeInactive, err)
+	}
+}
+
+func TestBatchTransferGas(t *testing.T) {
+	payouts := []types.Payout{newPayout(2, 10), newPayout(3, 20), newPayout(4, 30)}
+	tx, err := types.NewBatchTransferTransaction(payouts, big.NewInt(1), big.NewInt(100000), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := tx.IntrinsicGas()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gas, err := IntrinsicGas(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gas != base+3*params.BatchPayoutGas {
+		t.Fatalf("expect gas %d, got %d", base+3*params.BatchPayoutGas, gas)
+	}
+}
+
+//newExecuteStore returns an empty state which balances can be added to
+func newExecuteStore(t *testing.T) store.StoreInterface {
+	db := memorydb.New()
+	changeInterval := make([]byte, 8)
+	binary.BigEndian.PutUint64(changeInterval, 100)
+	db.Put([]byte(store.ChangeInterval), changeInterval)
+	trieStore, err := store.TrieStoreFromStore(db, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trieStore
+}
+
+func TestExecuteBatchTransfer(t *testing.T) {
+	trieStore := newExecuteStore(t)
+	from := crypto.CommonAddress{1}
+	err := trieStore.PutBalance(&from, 5, big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payouts := []types.Payout{newPayout(2, 10), newPayout(3, 20), newPayout(2, 5)}
+	tx, err := types.NewBatchTransferTransaction(payouts, big.NewInt(1), big.NewInt(100000), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := (&params.ForkConfig{BatchTransferHeight: 1, SystemLogHeight: 1}).Rules(5)
+	blockContext := block.NewBlockExecuteContext(trieStore, nil, nil, &types.Block{Header: &types.BlockHeader{Height: 5}}, rules)
+	processor := &Processor{}
+	etr := processor.ExecuteTransaction(NewExecuteTransactionContext(blockContext, trieStore, nil, &from, tx))
+	if etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+
+	balances := map[crypto.CommonAddress]int64{from: 65, {2}: 15, {3}: 20}
+	for addr, balance := range balances {
+		if got := trieStore.GetBalance(&addr, 5); got.Int64() != balance {
+			t.Fatalf("expect balance %d of %s, got %v", balance, addr.String(), got)
+		}
+	}
+	if nonce := trieStore.GetNonce(&from); nonce != 1 {
+		t.Fatalf("expect nonce 1, got %d", nonce)
+	}
+	if len(etr.ContractTxLog) != len(payouts) {
+		t.Fatalf("expect %d logs, got %d", len(payouts), len(etr.ContractTxLog))
+	}
+	for i, log := range etr.ContractTxLog {
+		to := payouts[i].To
+		if log.Topics[0] != types.TransferEvent || log.Topics[2] != types.AddressTopic(&to) {
+			t.Fatalf("log %d: unexpected topics %v", i, log.Topics)
+		}
+	}
+
+	payouts = []types.Payout{newPayout(2, 50), newPayout(3, 50)}
+	tx, _ = types.NewBatchTransferTransaction(payouts, big.NewInt(1), big.NewInt(100000), 1)
+	etr = processor.ExecuteTransaction(NewExecuteTransactionContext(blockContext, trieStore, nil, &from, tx))
+	if etr.Txerror == nil {
+		t.Fatal("expect the balance to be insufficient")
+	}
+	if got := trieStore.GetBalance(&crypto.CommonAddress{2}, 5); got.Int64() != 15 {
+		t.Fatalf("expect no payout after a failed transfer, got balance %v", got)
+	}
+}
@@ -0,0 +1,330 @@
+package transactions
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/pkgs/evm"
+	"github.com/drep-project/DREP-Chain/pkgs/evm/vm"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func init() {
+	RegisterTransactionType(types.TransferType, &TransactionType{
+		Name:     "transfer",
+		Executor: &nativeExecutor{event: types.TransferEvent, topics: toTopic, execute: executeTransfer},
+	})
+	RegisterTransactionType(types.CreateContractType, &TransactionType{
+		Name:      "createContract",
+		Executor:  &evmExecutor{},
+		KeepNonce: true,
+	})
+	RegisterTransactionType(types.CallContractType, &TransactionType{
+		Name:      "callContract",
+		Executor:  &evmExecutor{},
+		KeepNonce: true,
+	})
+	RegisterTransactionType(types.SetAliasType, &TransactionType{
+		Name:      "setAlias",
+		CheckPool: checkAliasPool,
+		Executor:  &nativeExecutor{event: types.SetAliasEvent, topics: aliasTopic, execute: executeSetAlias},
+		View:      aliasView,
+	})
+	RegisterTransactionType(types.VoteCreditType, &TransactionType{
+		Name:      "voteCredit",
+		CheckPool: checkVotePool,
+		Executor:  &nativeExecutor{event: types.VoteCreditEvent, topics: toTopic, execute: executeVoteCredit},
+	})
+	RegisterTransactionType(types.CancelVoteCreditType, &TransactionType{
+		Name:      "cancelVoteCredit",
+		CheckPool: checkCancelPool,
+		Executor:  &nativeExecutor{event: types.CancelVoteCreditEvent, topics: toTopic, execute: executeCancelVoteCredit},
+	})
+	RegisterTransactionType(types.CandidateType, &TransactionType{
+		Name:     "candidate",
+		Executor: &nativeExecutor{event: types.CandidateEvent, execute: executeCandidate},
+		View: func(tx *types.Transaction) interface{} {
+			candidateData := &types.CandidateData{}
+			if candidateData.Unmarshal(tx.GetData()) != nil {
+				return nil
+			}
+			return candidateData
+		},
+	})
+	RegisterTransactionType(types.CancelCandidateType, &TransactionType{
+		Name:      "cancelCandidate",
+		CheckPool: checkCancelPool,
+		Executor:  &nativeExecutor{event: types.CancelCandidateEvent, execute: executeCancelCandidate},
+	})
+	RegisterTransactionType(types.CreateMultiSigType, &TransactionType{
+		Name: "createMultiSig",
+		Active: func(rules *params.Rules) bool {
+			return rules.IsMultiSig
+		},
+		Validate: validateCreateMultiSig,
+		Executor: &nativeExecutor{event: types.CreateMultiSigEvent, execute: executeCreateMultiSig},
+		View: func(tx *types.Transaction) interface{} {
+			account, err := decodeMultiSigAccount(tx)
+			if err != nil {
+				return nil
+			}
+			return account
+		},
+	})
+	RegisterTransactionType(types.BatchTransferType, &TransactionType{
+		Name: "batchTransfer",
+		Active: func(rules *params.Rules) bool {
+			return rules.IsBatchTransfer
+		},
+		Validate:     validateBatchTransfer,
+		IntrinsicGas: batchTransferGas,
+		Executor:     &batchTransferExecutor{},
+		View: func(tx *types.Transaction) interface{} {
+			payouts, err := tx.Payouts()
+			if err != nil {
+				return nil
+			}
+			return payouts
+		},
+		Recipients: func(tx *types.Transaction) []crypto.CommonAddress {
+			payouts, _ := tx.Payouts()
+			recipients := make([]crypto.CommonAddress, len(payouts))
+			for i, payout := range payouts {
+				recipients[i] = payout.To
+			}
+			return recipients
+		},
+	})
+	RegisterTransactionType(types.AliasTransferType, &TransactionType{
+		Name:      "aliasTransfer",
+		Active:    isAliasTransfer,
+		CheckPool: checkAliasOwnerPool,
+		Executor:  &nativeExecutor{event: types.AliasTransferEvent, topics: aliasTransferTopics, execute: executeAliasTransfer},
+		View:      aliasView,
+	})
+	RegisterTransactionType(types.AliasReleaseType, &TransactionType{
+		Name:      "aliasRelease",
+		Active:    isAliasTransfer,
+		CheckPool: checkAliasOwnerPool,
+		Executor:  &nativeExecutor{event: types.AliasReleaseEvent, topics: aliasTopic, execute: executeAliasRelease},
+		View:      aliasView,
+	})
+	RegisterTransactionType(types.IssueAssetType, &TransactionType{
+		Name:         "issueAsset",
+		Active:       isAsset,
+		Validate:     validateIssueAsset,
+		IntrinsicGas: issueAssetGas,
+		CheckPool:    checkAssetPool,
+		Executor:     &nativeExecutor{event: types.IssueAssetEvent, topics: issueAssetTopics, execute: executeIssueAsset},
+		View:         issueAssetView,
+	})
+	RegisterTransactionType(types.AssetTransferType, &TransactionType{
+		Name:      "assetTransfer",
+		Active:    isAsset,
+		Validate:  validateAssetAmount,
+		CheckPool: checkAssetPool,
+		Executor:  &nativeExecutor{event: types.AssetTransferEvent, topics: assetToTopics, execute: executeAssetTransfer},
+		View:      assetAmountView,
+	})
+	RegisterTransactionType(types.AssetMintType, &TransactionType{
+		Name:      "assetMint",
+		Active:    isAsset,
+		Validate:  validateAssetAmount,
+		CheckPool: checkAssetPool,
+		Executor:  &nativeExecutor{event: types.AssetMintEvent, topics: assetToTopics, execute: executeAssetMint},
+		View:      assetAmountView,
+	})
+	RegisterTransactionType(types.AssetBurnType, &TransactionType{
+		Name:      "assetBurn",
+		Active:    isAsset,
+		Validate:  validateAssetAmount,
+		CheckPool: checkAssetPool,
+		Executor:  &nativeExecutor{event: types.AssetBurnEvent, topics: assetTopics, execute: executeAssetBurn},
+		View:      assetAmountView,
+	})
+}
+
+//nativeExecutor executes a transaction type implemented by the chain itself and logs its event at the system address
+type nativeExecutor struct {
+	event crypto.Hash
+	//topics returns the topics following the event and the sender, nil means none
+	topics func(tx *types.Transaction) []crypto.Hash
+	//execute applies the transaction and returns the data of its log
+	execute func(context *ExecuteTransactionContext) ([]byte, error)
+}
+
+func (executor *nativeExecutor) ExecuteTransaction(context *ExecuteTransactionContext) *types.ExecuteTransactionResult {
+	etr := &types.ExecuteTransactionResult{}
+	data, err := executor.execute(context)
+	if err != nil {
+		etr.Txerror = err
+		return etr
+	}
+	if context.Rules().IsSystemLog {
+		etr.ContractTxLog = executor.logs(context.Tx(), context.From(), context.Header().Height, data)
+	}
+	return etr
+}
+
+//logs returns the logs of a transaction, see types.SystemLogAddress for their layout
+func (executor *nativeExecutor) logs(tx *types.Transaction, from *crypto.CommonAddress, height uint64, data []byte) []*types.Log {
+	topics := []crypto.Hash{executor.event, types.AddressTopic(from)}
+	if executor.topics != nil {
+		topics = append(topics, executor.topics(tx)...)
+	}
+	return []*types.Log{types.NewSystemLog(tx, height, data, topics...)}
+}
+
+func toTopic(tx *types.Transaction) []crypto.Hash {
+	return []crypto.Hash{types.AddressTopic(tx.To())}
+}
+
+func aliasTopic(tx *types.Transaction) []crypto.Hash {
+	return []crypto.Hash{crypto.Keccak256Hash(tx.GetData())}
+}
+
+func subBalance(context *ExecuteTransactionContext) error {
+	originBalance := context.TrieStore().GetBalance(context.From(), context.Header().Height)
+	leftBalance := originBalance.Sub(originBalance, context.Tx().Amount())
+	if leftBalance.Sign() < 0 {
+		return ErrBalance
+	}
+	return context.TrieStore().PutBalance(context.From(), context.Header().Height, leftBalance)
+}
+
+func executeTransfer(context *ExecuteTransactionContext) ([]byte, error) {
+	err := subBalance(context)
+	if err != nil {
+		return nil, err
+	}
+	store, to, height := context.TrieStore(), context.Tx().To(), context.Header().Height
+	toBalance := store.GetBalance(to, height)
+	addBalance := toBalance.Add(toBalance, context.Tx().Amount())
+	return types.AmountData(context.Tx().Amount()), store.PutBalance(to, height, addBalance)
+}
+
+func executeVoteCredit(context *ExecuteTransactionContext) ([]byte, error) {
+	err := subBalance(context)
+	if err != nil {
+		return nil, err
+	}
+	tx := context.Tx()
+	err = context.TrieStore().VoteCredit(context.From(), tx.To(), tx.Amount(), context.Header().Height)
+	return types.AmountData(tx.Amount()), err
+}
+
+func executeCandidate(context *ExecuteTransactionContext) ([]byte, error) {
+	err := subBalance(context)
+	if err != nil {
+		return nil, err
+	}
+	tx := context.Tx()
+	err = context.TrieStore().CandidateCredit(context.From(), tx.Amount(), tx.GetData(), context.Header().Height)
+	return types.AmountData(tx.Amount()), err
+}
+
+func executeCancelVoteCredit(context *ExecuteTransactionContext) ([]byte, error) {
+	tx := context.Tx()
+	detail, err := context.TrieStore().CancelVoteCredit(context.From(), tx.To(), tx.Amount(), context.Header().Height)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(detail)
+}
+
+func executeCancelCandidate(context *ExecuteTransactionContext) ([]byte, error) {
+	detail, err := context.TrieStore().CancelCandidateCredit(context.From(), context.Tx().Amount(), context.Header().Height)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(detail)
+}
+
+func executeSetAlias(context *ExecuteTransactionContext) ([]byte, error) {
+	alias := context.Tx().GetData()
+	//an address owning an alias would leave the old one resolving to it
+	if context.Rules().IsAliasTransfer && context.TrieStore().GetStorageAlias(context.From()) != "" {
+		return nil, ErrNotSupportRenameAlias
+	}
+	err := context.TrieStore().AliasSet(context.From(), string(alias), context.Header().Height)
+	if err != nil {
+		return nil, err
+	}
+	return alias, context.UseGas(params.AliasGas * uint64(len(alias)))
+}
+
+//evmExecutor deploys and calls contracts, the evm increments the nonce of the sender
+type evmExecutor struct {
+}
+
+func (executor *evmExecutor) ExecuteTransaction(context *ExecuteTransactionContext) *types.ExecuteTransactionResult {
+	etr := &types.ExecuteTransactionResult{}
+	evmService := &evm.EvmService{}
+	evmService.Config = evm.DefaultEvmConfig
+	state := vm.NewState(context.TrieStore(), context.Rules())
+	ret, gas, addr, failed, err := evmService.Eval(state, context.Tx(), context.Header(), context.Rules(), context.GasRemained(), context.Value())
+	if err != nil {
+		etr.Txerror = err
+		return etr
+	}
+	etr.TxResult = ret
+	etr.ContractAddr = addr
+	etr.ContractTxExecuteFail = failed
+
+	//the evm never uses more than the remaining gas
+	context.UseGas(gas)
+	refund := context.GasUsed() / 2
+	if refund > state.GetRefund() {
+		refund = state.GetRefund()
+	}
+	context.RefundGas(refund)
+	return etr
+}
+
+func checkAliasPool(tx *types.Transaction, from *crypto.CommonAddress, pooled []*types.Transaction, store store.StoreInterface, height uint64) error {
+	newAlias := tx.GetData()
+	if newAlias == nil {
+		return ErrUnsupportAliasChar
+	}
+	drepFee, err := types.CheckAlias(newAlias)
+	if err != nil {
+		return err
+	}
+	balBefore := store.GetBalance(from, height)
+	balAfter := balBefore.Sub(balBefore, drepFee)
+	if balAfter.Sign() < 0 {
+		return ErrBalance
+	}
+	if store.GetStorageAlias(from) != "" {
+		return ErrNotSupportRenameAlias
+	}
+	return nil
+}
+
+func checkVotePool(tx *types.Transaction, from *crypto.CommonAddress, pooled []*types.Transaction, store store.StoreInterface, height uint64) error {
+	if *from == *tx.To() {
+		return ErrVoteSelf
+	}
+	return nil
+}
+
+//checkCancelPool checks that the credit a cancel takes back is staked, a vote is kept by the voted address,
+//the pledge of a candidate by the candidate itself
+func checkCancelPool(tx *types.Transaction, from *crypto.CommonAddress, pooled []*types.Transaction, store store.StoreInterface, height uint64) error {
+	owner := from
+	if tx.Type() == types.CancelVoteCreditType {
+		owner = tx.To()
+	}
+	credits := store.GetCreditDetails(owner)
+	if credits == nil {
+		return fmt.Errorf("stake storage key not exist")
+	}
+	total := credits[*from]
+	if tx.Amount().Cmp(&total) <= 0 {
+		return nil
+	}
+	return fmt.Errorf("vote total:%v < req amount:%v", &total, tx.Amount())
+}
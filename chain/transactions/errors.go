@@ -11,4 +11,20 @@ var (
 	ErrInsufficientBalanceForGas = errors.New("insufficient balance to pay for gasRemained")
 	ErrOutOfGas                  = errors.New("out gas of block")
 	ErrTxUnSupport               = errors.New("unsupported transaction type")
+	ErrTxTypeInactive            = errors.New("transaction type not active at this height")
+	ErrUnsupportAliasChar        = errors.New("alias only support number and letter")
+	ErrNotSupportRenameAlias     = errors.New("not suppport rename alias")
+	ErrVoteSelf                  = errors.New("from equal to addr")
+	ErrMultiSigAccountNotExist   = errors.New("multisig account not exist")
+	ErrMultiSigAccountExist      = errors.New("multisig account already exist")
+	ErrBatchTransfer             = errors.New("invalid batch transfer")
+	ErrSponsor                   = errors.New("invalid sponsor signature")
+	ErrSponsorBalance            = errors.New("not enough balance of the sponsor")
+	ErrTxChainId                 = errors.New("transaction chain id not matched")
+	ErrTxExpired                 = errors.New("transaction validity window passed")
+	ErrAsset                     = errors.New("invalid asset transaction")
+	ErrAssetExist                = errors.New("asset already issued")
+	ErrAssetNotExist             = errors.New("asset not issued")
+	ErrAssetBalance              = errors.New("not enough balance of the asset")
+	ErrNotMintAuthority          = errors.New("sender is not the mint authority of the asset")
 )
@@ -0,0 +1,84 @@
+package transactions
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func TestSystemLogs(t *testing.T) {
+	from := crypto.CommonAddress{1}
+	to := crypto.CommonAddress{2}
+	amount := big.NewInt(1000)
+	gasPrice, gasLimit := big.NewInt(1), big.NewInt(100000)
+	detail := &types.CancelCreditDetail{PrincipalData: []types.HeightValue{{CreditHeight: 3}}}
+
+	tests := []struct {
+		tx     *types.Transaction
+		detail *types.CancelCreditDetail
+		topics []crypto.Hash
+	}{
+		{types.NewTransaction(to, amount, gasPrice, gasLimit, 0), nil, []crypto.Hash{types.TransferEvent, types.AddressTopic(&from), types.AddressTopic(&to)}},
+		{types.NewVoteTransaction(to, amount, gasPrice, gasLimit, 0), nil, []crypto.Hash{types.VoteCreditEvent, types.AddressTopic(&from), types.AddressTopic(&to)}},
+		{types.NewCancelVoteTransaction(to, amount, gasPrice, gasLimit, 0), detail, []crypto.Hash{types.CancelVoteCreditEvent, types.AddressTopic(&from), types.AddressTopic(&to)}},
+		{types.NewCandidateTransaction(amount, gasPrice, gasLimit, 0, nil), nil, []crypto.Hash{types.CandidateEvent, types.AddressTopic(&from)}},
+		{types.NewCancleCandidateTransaction(amount, gasPrice, gasLimit, 0), detail, []crypto.Hash{types.CancelCandidateEvent, types.AddressTopic(&from)}},
+		{types.NewAliasTransaction("drep", gasPrice, gasLimit, 0), nil, []crypto.Hash{types.SetAliasEvent, types.AddressTopic(&from), crypto.Keccak256Hash([]byte("drep"))}},
+	}
+	for _, test := range tests {
+		transactionType, err := GetTransactionType(test.tx.Type())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data []byte
+		if test.detail != nil {
+			data, _ = json.Marshal(test.detail)
+		}
+		logs := transactionType.Executor.(*nativeExecutor).logs(test.tx, &from, 7, data)
+		if len(logs) != 1 {
+			t.Fatalf("tx type %d: expect 1 log, got %d", test.tx.Type(), len(logs))
+		}
+		log := logs[0]
+		if log.Address != types.SystemLogAddress || log.TxHash != *test.tx.TxHash() || log.Height != 7 {
+			t.Fatalf("tx type %d: unexpected log %+v", test.tx.Type(), log)
+		}
+		if len(log.Topics) != len(test.topics) {
+			t.Fatalf("tx type %d: expect %d topics, got %d", test.tx.Type(), len(test.topics), len(log.Topics))
+		}
+		for i, topic := range test.topics {
+			if log.Topics[i] != topic {
+				t.Fatalf("tx type %d: topic %d mismatch", test.tx.Type(), i)
+			}
+		}
+		if test.detail != nil {
+			got := &types.CancelCreditDetail{}
+			if err := json.Unmarshal(log.Data, got); err != nil || len(got.PrincipalData) != 1 {
+				t.Fatalf("tx type %d: cancel detail not in log data, %v", test.tx.Type(), err)
+			}
+		}
+
+		receipt := &types.Receipt{Logs: logs}
+		bloom := types.CreateBloom(types.Receipts{receipt})
+		if !types.BloomLookup(bloom, types.SystemLogAddress) {
+			t.Fatalf("tx type %d: system address not in bloom", test.tx.Type())
+		}
+		for _, topic := range test.topics {
+			if !types.BloomLookup(bloom, topic) {
+				t.Fatalf("tx type %d: topic not in bloom", test.tx.Type())
+			}
+		}
+	}
+
+	for _, txType := range []types.TxType{types.CreateContractType, types.CallContractType} {
+		transactionType, err := GetTransactionType(txType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := transactionType.Executor.(*nativeExecutor); ok {
+			t.Fatal("contract transactions must not get system logs")
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package transactions
+
+import (
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+	"github.com/pkg/errors"
+)
+
+//MultiSigAddress returns the address of the multisig account created by the transaction of from with nonce
+func MultiSigAddress(from *crypto.CommonAddress, nonce uint64) crypto.CommonAddress {
+	return crypto.CreateAddress(*from, nonce)
+}
+
+//VerifyMultiSig checks the proof of a transaction spent from a multisig account against the keys registered in
+//store and makes the account the sender of tx, the sender of any other transaction is proven by the recovery of
+//its signature
+func VerifyMultiSig(tx *types.Transaction, store store.StoreInterface, rules *params.Rules) error {
+	if !tx.IsMultiSig() {
+		return nil
+	}
+	if !rules.IsMultiSig {
+		return errors.Wrapf(ErrTxTypeInactive, "multisig at height %d", rules.Height)
+	}
+	proof, err := tx.MultiSigProof()
+	if err != nil {
+		return err
+	}
+	account, err := store.GetMultiSigAccount(&proof.Account)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return errors.Wrapf(ErrMultiSigAccountNotExist, "%s", proof.Account.String())
+	}
+	return account.VerifyTransaction(tx)
+}
+
+//multiSigGas charges the verification of every signer of a multisig transaction
+func multiSigGas(tx *types.Transaction) (uint64, error) {
+	if !tx.IsMultiSig() {
+		return 0, nil
+	}
+	proof, err := tx.MultiSigProof()
+	if err != nil {
+		return 0, err
+	}
+	signers := uint64(0)
+	for _, val := range proof.Bitmap {
+		if val == 1 {
+			signers++
+		}
+	}
+	return signers * params.MultiSigSignerGas, nil
+}
+
+func decodeMultiSigAccount(tx *types.Transaction) (*types.MultiSigAccount, error) {
+	account := &types.MultiSigAccount{}
+	err := binary.Unmarshal(tx.GetData(), account)
+	if err != nil {
+		return nil, errors.Wrap(types.ErrMultiSigAccount, err.Error())
+	}
+	return account, nil
+}
+
+func validateCreateMultiSig(tx *types.Transaction) error {
+	account, err := decodeMultiSigAccount(tx)
+	if err != nil {
+		return err
+	}
+	return account.Check()
+}
+
+func executeCreateMultiSig(context *ExecuteTransactionContext) ([]byte, error) {
+	account, err := decodeMultiSigAccount(context.Tx())
+	if err != nil {
+		return nil, err
+	}
+	store, height := context.TrieStore(), context.Header().Height
+	addr := MultiSigAddress(context.From(), context.Tx().Nonce())
+	old, err := store.GetMultiSigAccount(&addr)
+	if err != nil {
+		return nil, err
+	}
+	if old != nil || !store.Empty(&addr) {
+		return nil, errors.Wrapf(ErrMultiSigAccountExist, "%s", addr.String())
+	}
+
+	err = subBalance(context)
+	if err != nil {
+		return nil, err
+	}
+	err = store.PutBalance(&addr, height, context.Tx().Amount())
+	if err != nil {
+		return nil, err
+	}
+	err = store.PutMultiSigAccount(&addr, account)
+	if err != nil {
+		return nil, err
+	}
+	accountTopic := types.AddressTopic(&addr)
+	return append(accountTopic.Bytes(), types.AmountData(context.Tx().Amount())...), nil
+}
@@ -0,0 +1,193 @@
+package transactions
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1/schnorr"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func newMultiSigKeys(t *testing.T, n int) ([]*secp256k1.PrivateKey, []*secp256k1.PublicKey) {
+	privkeys := make([]*secp256k1.PrivateKey, n)
+	pubkeys := make([]*secp256k1.PublicKey, n)
+	for i := range privkeys {
+		privkey, err := secp256k1.GeneratePrivateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privkeys[i], pubkeys[i] = privkey, privkey.PubKey()
+	}
+	return privkeys, pubkeys
+}
+
+func TestMultiSigAccountCheck(t *testing.T) {
+	_, pubkeys := newMultiSigKeys(t, 3)
+	tests := []struct {
+		account *types.MultiSigAccount
+		valid   bool
+	}{
+		{&types.MultiSigAccount{Threshold: 2, Pubkeys: pubkeys}, true},
+		{&types.MultiSigAccount{Threshold: 3, Pubkeys: pubkeys}, true},
+		{&types.MultiSigAccount{Threshold: 0, Pubkeys: pubkeys}, false},
+		{&types.MultiSigAccount{Threshold: 4, Pubkeys: pubkeys}, false},
+		{&types.MultiSigAccount{Threshold: 1, Pubkeys: append(pubkeys, pubkeys[1])}, false},
+		{&types.MultiSigAccount{Threshold: 1}, false},
+	}
+	for i, test := range tests {
+		tx, err := types.NewCreateMultiSigTransaction(test.account, big.NewInt(0), big.NewInt(1), big.NewInt(100000), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ValidateTransaction(tx, (&params.ForkConfig{}).Rules(1))
+		if test.valid != (err == nil) {
+			t.Fatalf("case %d: expect valid %v, got %v", i, test.valid, err)
+		}
+	}
+}
+
+func TestVerifyMultiSig(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	privkeys, pubkeys := newMultiSigKeys(t, 3)
+	creator := crypto.CommonAddress{1}
+	addr := MultiSigAddress(&creator, 5)
+	err = trieStore.PutMultiSigAccount(&addr, &types.MultiSigAccount{Threshold: 2, Pubkeys: pubkeys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := (&params.ForkConfig{MultiSigHeight: 10}).Rules(10)
+
+	newTx := func(proof *types.MultiSigProof) *types.Transaction {
+		tx := types.NewTransaction(crypto.CommonAddress{2}, big.NewInt(10), big.NewInt(1), big.NewInt(100000), 0)
+		tx.Data.Timestamp = 1
+		if err := tx.SetMultiSigProof(proof); err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	hash := newTx(types.NewMultiSigProof(addr, 3)).TxHash().Bytes()
+	compactSig := func(i int) []byte {
+		sig, err := secp256k1.SignCompact(privkeys[i], hash, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+
+	//compact signatures added out of key order
+	proof := types.NewMultiSigProof(addr, 3)
+	proof.AddSig(2, compactSig(2))
+	proof.AddSig(0, compactSig(0))
+	tx := newTx(proof)
+	if from, err := tx.From(); err != types.ErrUnverifiedSender {
+		t.Fatalf("expect no sender before the proof is verified, got %v %v", from, err)
+	}
+	if err := VerifyMultiSig(tx, trieStore, rules); err != nil {
+		t.Fatal(err)
+	}
+	if from, err := tx.From(); err != nil || *from != addr {
+		t.Fatalf("expect the multisig account as sender, got %v %v", from, err)
+	}
+	if err := VerifyMultiSig(tx, trieStore, (&params.ForkConfig{MultiSigHeight: 10}).Rules(9)); errors.Cause(err) != ErrTxTypeInactive {
+		t.Fatalf("expect multisig inactive before its fork, got %v", err)
+	}
+	gas, err := IntrinsicGas(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainGas, _ := tx.IntrinsicGas()
+	if gas != plainGas+2*params.MultiSigSignerGas {
+		t.Fatalf("expect the signers charged, got %d for %d", gas, plainGas)
+	}
+
+	//below threshold, a signature in the place of another key, an unknown account
+	proof = types.NewMultiSigProof(addr, 3)
+	proof.AddSig(1, compactSig(1))
+	if err := VerifyMultiSig(newTx(proof), trieStore, rules); err != types.ErrMultiSigThreshold {
+		t.Fatalf("expect threshold error, got %v", err)
+	}
+	proof.AddSig(2, compactSig(0))
+	if err := VerifyMultiSig(newTx(proof), trieStore, rules); err != types.ErrMultiSig {
+		t.Fatalf("expect signature error, got %v", err)
+	}
+	proof = types.NewMultiSigProof(crypto.CommonAddress{3}, 3)
+	if err := VerifyMultiSig(newTx(proof), trieStore, rules); errors.Cause(err) != ErrMultiSigAccountNotExist {
+		t.Fatalf("expect unknown account, got %v", err)
+	}
+
+	//partial schnorr signatures of key 0 and 1 collected in separate proofs
+	signers := []int{0, 1}
+	privNonces := make([]*secp256k1.PrivateKey, len(signers))
+	pubNonces := make([]*secp256k1.PublicKey, len(signers))
+	for i, signer := range signers {
+		privNonces[i], pubNonces[i], err = schnorr.GenerateNoncePair(secp256k1.S256(), hash, privkeys[signer], nil, schnorr.Sha256VersionStringRFC6979)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	account := &types.MultiSigAccount{Threshold: 2, Pubkeys: pubkeys}
+	proofs := []*types.MultiSigProof{}
+	for i, signer := range signers {
+		sig, err := schnorr.PartialSign(secp256k1.S256(), hash, account.SigningKey(signer, privkeys[signer]), privNonces[i], pubNonces[1-i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof := types.NewMultiSigProof(addr, 3)
+		proof.AddSig(signer, sig.Serialize())
+		proofs = append(proofs, proof)
+	}
+	proof, err = types.MergeMultiSigProofs(proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.IsPartial() {
+		t.Fatal("expect partial signatures")
+	}
+	if err := proof.CombinePartialSigs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyMultiSig(newTx(proof), trieStore, rules); err != nil {
+		t.Fatal(err)
+	}
+	proof.Bitmap[2] = 1
+	if err := VerifyMultiSig(newTx(proof), trieStore, rules); err != types.ErrMultiSig {
+		t.Fatalf("expect the aggregated signature to cover exactly the signers, got %v", err)
+	}
+}
+
+func TestMultiSigRogueKey(t *testing.T) {
+	privkeys, pubkeys := newMultiSigKeys(t, 2)
+	victim, attacker := pubkeys[0], privkeys[1]
+
+	//the rogue key is chosen so that it sums up with the key of the victim to the key of the attacker
+	curve := secp256k1.S256()
+	negVictim := secp256k1.NewPublicKey(victim.GetX(), new(big.Int).Sub(curve.P, victim.GetY()))
+	rogue := schnorr.CombinePubkeys([]*secp256k1.PublicKey{attacker.PubKey(), negVictim})
+	account := &types.MultiSigAccount{Threshold: 2, Pubkeys: []*secp256k1.PublicKey{victim, rogue}}
+
+	hash := crypto.Keccak256Hash([]byte("rogue")).Bytes()
+	r, sig, err := schnorr.Sign(attacker, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schnorr.Verify(schnorr.CombinePubkeys(account.Pubkeys), hash, r, sig) {
+		t.Fatal("expect the attacker to sign for the plain sum of the keys")
+	}
+	proof := types.NewMultiSigProof(crypto.CommonAddress{1}, 2)
+	proof.Bitmap = []byte{1, 1}
+	proof.Sig = schnorr.NewSignature(r, sig).Serialize()
+	if err := account.Verify(hash, proof); err != types.ErrMultiSig {
+		t.Fatalf("expect the signature of the attacker alone rejected, got %v", err)
+	}
+}
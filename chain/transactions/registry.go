@@ -0,0 +1,148 @@
+package transactions
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+//TransactionType is what a module registers for every transaction type it adds, only Name and Executor are required
+type TransactionType struct {
+	Name string
+	//Active reports whether blocks with the rules may contain the type, nil means always
+	Active func(rules *params.Rules) bool
+	//Validate checks the transaction without any state, it runs for blocks and for the pool
+	Validate func(tx *types.Transaction) error
+	//IntrinsicGas is charged before the execution, nil means types.Transaction.IntrinsicGas
+	IntrinsicGas func(tx *types.Transaction) (uint64, error)
+	//CheckPool admits a transaction into the pool against the state of the chain tip and pooled, the transactions of
+	//the sender already waiting in the pool
+	CheckPool func(tx *types.Transaction, from *crypto.CommonAddress, pooled []*types.Transaction, store store.StoreInterface, height uint64) error
+	//Executor applies the transaction to the state of the block
+	Executor ITransactionValidator
+	//KeepNonce is set for executors which increment the nonce of the sender themselves
+	KeepNonce bool
+	//View returns the data of the transaction in a JSON friendly form, nil means the raw data only
+	View func(tx *types.Transaction) interface{}
+	//Recipients returns the addresses the transaction pays or calls, nil means the To address of the transaction
+	Recipients func(tx *types.Transaction) []crypto.CommonAddress
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[types.TxType]*TransactionType{}
+)
+
+//RegisterTransactionType adds a transaction type, it is meant to be called from the init function of the module
+//owning the type and panics if the type is taken
+func RegisterTransactionType(txType types.TxType, transactionType *TransactionType) {
+	if transactionType == nil || transactionType.Executor == nil {
+		panic(fmt.Sprintf("transaction type %d registered without executor", txType))
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if old, ok := registry[txType]; ok {
+		panic(fmt.Sprintf("transaction type %d registered twice, by %s and %s", txType, old.Name, transactionType.Name))
+	}
+	registry[txType] = transactionType
+}
+
+//GetTransactionType returns the registered handling of a transaction type
+func GetTransactionType(txType types.TxType) (*TransactionType, error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	transactionType, ok := registry[txType]
+	if !ok {
+		return nil, errors.Wrapf(ErrTxUnSupport, "type %d", txType)
+	}
+	return transactionType, nil
+}
+
+//TransactionTypes returns every registered type in ascending order
+func TransactionTypes() []types.TxType {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	txTypes := make([]types.TxType, 0, len(registry))
+	for txType := range registry {
+		txTypes = append(txTypes, txType)
+	}
+	sort.Slice(txTypes, func(i, j int) bool {
+		return txTypes[i] < txTypes[j]
+	})
+	return txTypes
+}
+
+//ValidateTransaction checks that the type of tx is active with the rules and runs its stateless validation
+func ValidateTransaction(tx *types.Transaction, rules *params.Rules) error {
+	transactionType, err := GetTransactionType(tx.Type())
+	if err != nil {
+		return err
+	}
+	if transactionType.Active != nil && !transactionType.Active(rules) {
+		return errors.Wrapf(ErrTxTypeInactive, "%s at height %d", transactionType.Name, rules.Height)
+	}
+	if transactionType.Validate != nil {
+		return transactionType.Validate(tx)
+	}
+	return nil
+}
+
+//IntrinsicGas returns the gas charged for tx before its execution, including the verification of the signers
+//of a multisig transaction and of the sponsor
+func IntrinsicGas(tx *types.Transaction) (uint64, error) {
+	transactionType, err := GetTransactionType(tx.Type())
+	if err != nil {
+		return 0, err
+	}
+	var gas uint64
+	if transactionType.IntrinsicGas != nil {
+		gas, err = transactionType.IntrinsicGas(tx)
+	} else {
+		gas, err = tx.IntrinsicGas()
+	}
+	if err != nil {
+		return 0, err
+	}
+	signerGas, err := multiSigGas(tx)
+	if err != nil {
+		return 0, err
+	}
+	return gas + signerGas + sponsorGas(tx), nil
+}
+
+//CheckPool runs the pool admission check of the type of tx against store, the state at height, and pooled, the
+//transactions of the sender waiting in the pool
+func CheckPool(tx *types.Transaction, from *crypto.CommonAddress, pooled []*types.Transaction, store store.StoreInterface, height uint64) error {
+	transactionType, err := GetTransactionType(tx.Type())
+	if err != nil {
+		return err
+	}
+	if transactionType.CheckPool != nil {
+		return transactionType.CheckPool(tx, from, pooled, store, height)
+	}
+	return nil
+}
+
+//TransactionView returns the JSON friendly form of the data of tx, nil if its type has none
+func TransactionView(tx *types.Transaction) interface{} {
+	transactionType, err := GetTransactionType(tx.Type())
+	if err != nil || transactionType.View == nil {
+		return nil
+	}
+	return transactionType.View(tx)
+}
+
+//Recipients returns the addresses tx pays or calls, they index the received transactions of an address
+func Recipients(tx *types.Transaction) []crypto.CommonAddress {
+	transactionType, err := GetTransactionType(tx.Type())
+	if err != nil || transactionType.Recipients == nil {
+		return []crypto.CommonAddress{*tx.To()}
+	}
+	return transactionType.Recipients(tx)
+}
@@ -0,0 +1,88 @@
+package transactions
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestRegisterTransactionType(t *testing.T) {
+	const testType = types.TxType(200)
+	errInvalid := errors.New("invalid")
+	RegisterTransactionType(testType, &TransactionType{
+		Name: "test",
+		Active: func(rules *params.Rules) bool {
+			return rules.Height >= 10
+		},
+		Validate: func(tx *types.Transaction) error {
+			if tx.Amount().Sign() == 0 {
+				return errInvalid
+			}
+			return nil
+		},
+		Executor: &evmExecutor{},
+	})
+	defer func() {
+		registryLock.Lock()
+		delete(registry, testType)
+		registryLock.Unlock()
+	}()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("registering a type twice must panic")
+			}
+		}()
+		RegisterTransactionType(testType, &TransactionType{Name: "again", Executor: &evmExecutor{}})
+	}()
+
+	tx := types.NewTransaction(crypto.CommonAddress{}, big.NewInt(1), big.NewInt(1), big.NewInt(100000), 0)
+	tx.Data.Type = testType
+	forks := &params.ForkConfig{}
+	if err := ValidateTransaction(tx, forks.Rules(9)); pkgerrors.Cause(err) != ErrTxTypeInactive {
+		t.Fatalf("expect inactive type below its height, got %v", err)
+	}
+	if err := ValidateTransaction(tx, forks.Rules(10)); err != nil {
+		t.Fatal(err)
+	}
+	tx.Data.Amount = common.Big(*big.NewInt(0))
+	if err := ValidateTransaction(tx, forks.Rules(10)); err != errInvalid {
+		t.Fatalf("expect the validation of the type, got %v", err)
+	}
+
+	tx.Data.Type = types.TxType(201)
+	if _, err := GetTransactionType(tx.Type()); pkgerrors.Cause(err) != ErrTxUnSupport {
+		t.Fatalf("expect unsupported type, got %v", err)
+	}
+	if err := ValidateTransaction(tx, forks.Rules(10)); pkgerrors.Cause(err) != ErrTxUnSupport {
+		t.Fatalf("expect unsupported type, got %v", err)
+	}
+}
+
+func TestCheckCancelPool(t *testing.T) {
+	trieStore := newExecuteStore(t)
+	voter, candidate := crypto.CommonAddress{1}, crypto.CommonAddress{2}
+	if err := trieStore.VoteCredit(&voter, &candidate, big.NewInt(100), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := types.NewCancelVoteTransaction(candidate, big.NewInt(100), big.NewInt(1), big.NewInt(100000), 0)
+	if err := CheckPool(tx, &voter, nil, trieStore, 0); err != nil {
+		t.Fatal(err)
+	}
+	tx = types.NewCancelVoteTransaction(candidate, big.NewInt(101), big.NewInt(1), big.NewInt(100000), 0)
+	if err := CheckPool(tx, &voter, nil, trieStore, 0); err == nil {
+		t.Fatal("expect a cancel above the vote refused")
+	}
+	tx = types.NewCancelVoteTransaction(voter, big.NewInt(1), big.NewInt(1), big.NewInt(100000), 0)
+	if err := CheckPool(tx, &candidate, nil, trieStore, 0); err == nil {
+		t.Fatal("expect a cancel without a vote refused")
+	}
+}
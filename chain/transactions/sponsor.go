@@ -0,0 +1,31 @@
+package transactions
+
+import (
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+//VerifySponsor checks that the sponsor of a sponsored transaction signed it, the sponsor pays the gas and the
+//sender the amount
+func VerifySponsor(tx *types.Transaction, rules *params.Rules) error {
+	if !tx.IsSponsored() {
+		return nil
+	}
+	if !rules.IsSponsor {
+		return errors.Wrapf(ErrTxTypeInactive, "sponsor at height %d", rules.Height)
+	}
+	_, err := tx.Sponsor()
+	if err != nil {
+		return errors.Wrap(ErrSponsor, err.Error())
+	}
+	return nil
+}
+
+//sponsorGas charges the recovery of the sponsor of a sponsored transaction
+func sponsorGas(tx *types.Transaction) uint64 {
+	if !tx.IsSponsored() {
+		return 0
+	}
+	return params.SponsorGas
+}
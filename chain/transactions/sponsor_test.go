@@ -0,0 +1,123 @@
+package transactions
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/block"
+	"github.com/drep-project/DREP-Chain/chain/utils"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func newSponsoredTx(t *testing.T, sender, sponsor *secp256k1.PrivateKey) *types.Transaction {
+	tx := types.NewTransaction(crypto.CommonAddress{9}, big.NewInt(10), big.NewInt(2), big.NewInt(100000), 0)
+	from := crypto.PubkeyToAddress(sender.PubKey())
+	sig, err := secp256k1.SignCompact(sponsor, tx.SponsorHash(&from), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the sponsor may sign first
+	if err := tx.SetSponsorSig(sig); err != nil {
+		t.Fatal(err)
+	}
+	sig, err = secp256k1.SignCompact(sender, tx.TxHash().Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SetSenderSig(sig); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestVerifySponsor(t *testing.T) {
+	privkeys, pubkeys := newMultiSigKeys(t, 3)
+	tx := newSponsoredTx(t, privkeys[0], privkeys[1])
+
+	from, err := tx.From()
+	if err != nil || *from != crypto.PubkeyToAddress(pubkeys[0]) {
+		t.Fatalf("expect the signer as sender, got %v %v", from, err)
+	}
+	sponsor, err := tx.Payer()
+	if err != nil || *sponsor != crypto.PubkeyToAddress(pubkeys[1]) {
+		t.Fatalf("expect the sponsor as payer, got %v %v", sponsor, err)
+	}
+	if err := VerifySponsor(tx, (&params.ForkConfig{SponsorHeight: 10}).Rules(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySponsor(tx, (&params.ForkConfig{SponsorHeight: 10}).Rules(9)); errors.Cause(err) != ErrTxTypeInactive {
+		t.Fatalf("expect sponsor inactive before its fork, got %v", err)
+	}
+
+	gas, err := IntrinsicGas(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainGas, _ := tx.IntrinsicGas()
+	if gas != plainGas+params.SponsorGas {
+		t.Fatalf("expect the sponsor charged, got %d for %d", gas, plainGas)
+	}
+
+	//a sponsor signature over the hash of the sender is rejected
+	sig, _ := secp256k1.SignCompact(privkeys[1], tx.TxHash().Bytes(), true)
+	tampered := newSponsoredTx(t, privkeys[0], privkeys[1])
+	tampered.SetSponsorSig(sig)
+	if sponsor, _ := tampered.Sponsor(); sponsor != nil && *sponsor == crypto.PubkeyToAddress(pubkeys[1]) {
+		t.Fatal("expect the sponsor hash to differ from the transaction hash")
+	}
+
+	//the signature of the sponsor does not pay for the same transaction of another sender
+	moved := newSponsoredTx(t, privkeys[0], privkeys[1])
+	sig, _ = secp256k1.SignCompact(privkeys[2], moved.TxHash().Bytes(), true)
+	moved.SetSenderSig(sig)
+	if from, err := moved.From(); err != nil || *from != crypto.PubkeyToAddress(pubkeys[2]) {
+		t.Fatalf("expect the other signer as sender, got %v %v", from, err)
+	}
+	if sponsor, _ := moved.Sponsor(); sponsor != nil && *sponsor == crypto.PubkeyToAddress(pubkeys[1]) {
+		t.Fatal("expect the sponsor signature bound to the sender")
+	}
+}
+
+func TestExecuteSponsored(t *testing.T) {
+	privkeys, pubkeys := newMultiSigKeys(t, 2)
+	tx := newSponsoredTx(t, privkeys[0], privkeys[1])
+	from, sponsor := crypto.PubkeyToAddress(pubkeys[0]), crypto.PubkeyToAddress(pubkeys[1])
+
+	trieStore := newExecuteStore(t)
+	trieStore.PutBalance(&from, 5, big.NewInt(10))
+	trieStore.PutBalance(&sponsor, 5, big.NewInt(1000000))
+
+	rules := (&params.ForkConfig{SponsorHeight: 1}).Rules(5)
+	blockContext := block.NewBlockExecuteContext(trieStore, nil, nil, &types.Block{Header: &types.BlockHeader{Height: 5}}, rules)
+	context := NewExecuteTransactionContext(blockContext, trieStore, new(utils.GasPool).AddGas(1000000), &from, tx)
+	if err := context.PreCheck(); err != nil {
+		t.Fatal(err)
+	}
+	gas, _ := IntrinsicGas(tx)
+	if err := context.UseGas(gas); err != nil {
+		t.Fatal(err)
+	}
+	etr := (&Processor{}).ExecuteTransaction(context)
+	if etr.Txerror != nil {
+		t.Fatal(etr.Txerror)
+	}
+	if err := context.RefundCoin(); err != nil {
+		t.Fatal(err)
+	}
+
+	if balance := trieStore.GetBalance(&from, 5); balance.Sign() != 0 {
+		t.Fatalf("expect the sender to pay the amount only, left %v", balance)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gas), tx.GasPrice())
+	if balance := trieStore.GetBalance(&sponsor, 5); balance.Cmp(new(big.Int).Sub(big.NewInt(1000000), fee)) != 0 {
+		t.Fatalf("expect the sponsor to pay %v, left %v", fee, balance)
+	}
+	log := context.SponsorLog()
+	if log == nil || log.Topics[0] != types.SponsorEvent || log.Topics[1] != types.AddressTopic(&sponsor) {
+		t.Fatalf("unexpected sponsor log %v", log)
+	}
+}
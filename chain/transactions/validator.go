@@ -8,17 +8,15 @@ import (
 
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
+//ITransactionValidator executes the transactions of a registered type, see RegisterTransactionType
 type ITransactionValidator interface {
 	ExecuteTransaction(context *ExecuteTransactionContext) *types.ExecuteTransactionResult
 }
 
-type ITransactionSelector interface {
-	Select(tx *types.Transaction) bool
-}
-
 type ExecuteTransactionContext struct {
 	blockContext *block.BlockExecuteContext
 	trieStore    store.StoreInterface
@@ -26,6 +24,7 @@ type ExecuteTransactionContext struct {
 	gp          *utils.GasPool
 	tx          *types.Transaction
 	from        *crypto.CommonAddress
+	payer       *crypto.CommonAddress //pays the gas, the sponsor of a sponsored transaction
 	gasPrice    *big.Int
 	value       *big.Int
 	data        []byte
@@ -38,6 +37,7 @@ func NewExecuteTransactionContext(blockContext *block.BlockExecuteContext, chain
 	context := &ExecuteTransactionContext{trieStore: chainstore, gp: gasPool, tx: tx, from: from}
 	context.blockContext = blockContext
 	context.from = from
+	context.payer = from
 	context.gasPrice = tx.GasPrice()
 	context.value = tx.Amount()
 	context.data = tx.GetData()
@@ -48,6 +48,11 @@ func (context *ExecuteTransactionContext) Header() *types.BlockHeader {
 	return context.header
 }
 
+//Rules returns the rules of the block the transaction is executed in
+func (context *ExecuteTransactionContext) Rules() *params.Rules {
+	return context.blockContext.Rules
+}
+
 func (context *ExecuteTransactionContext) GasRemained() uint64 {
 	return context.gasRemained
 }
@@ -76,6 +81,11 @@ func (context *ExecuteTransactionContext) From() *crypto.CommonAddress {
 	return context.from
 }
 
+//Payer returns the address paying the gas of the transaction, it is known after PreCheck
+func (context *ExecuteTransactionContext) Payer() *crypto.CommonAddress {
+	return context.payer
+}
+
 func (context *ExecuteTransactionContext) Tx() *types.Transaction {
 	return context.tx
 }
@@ -91,7 +101,7 @@ func (context *ExecuteTransactionContext) TrieStore() store.StoreInterface {
 func (context *ExecuteTransactionContext) RefundCoin() error {
 	// Return DREP for remaining gasRemained, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(context.gasRemained), context.gasPrice)
-	err := context.trieStore.AddBalance(context.from, context.header.Height, remaining)
+	err := context.trieStore.AddBalance(context.payer, context.header.Height, remaining)
 	if err != nil {
 		return err
 	}
@@ -124,7 +134,7 @@ func (context *ExecuteTransactionContext) UseGas(amount uint64) error {
 
 func (context *ExecuteTransactionContext) buyGas() error {
 	mgval := new(big.Int).Mul(new(big.Int).SetUint64(context.tx.Gas()), context.gasPrice)
-	if context.trieStore.GetBalance(context.from, context.header.Height).Cmp(mgval) < 0 {
+	if context.trieStore.GetBalance(context.payer, context.header.Height).Cmp(mgval) < 0 {
 		return ErrInsufficientBalanceForGas
 	}
 	if err := context.gp.SubGas(context.tx.Gas()); err != nil {
@@ -133,7 +143,7 @@ func (context *ExecuteTransactionContext) buyGas() error {
 	context.gasRemained += context.tx.Gas()
 
 	context.initialGas = context.tx.Gas()
-	return context.trieStore.SubBalance(context.from, context.header.Height, mgval)
+	return context.trieStore.SubBalance(context.payer, context.header.Height, mgval)
 }
 
 func (context *ExecuteTransactionContext) PreCheck() error {
@@ -146,5 +156,22 @@ func (context *ExecuteTransactionContext) PreCheck() error {
 		log.WithField("db nonce", nonce).WithField("tx nonce", context.tx.Nonce()).WithField("from", context.from.String()).Info("state precheck too low")
 		return ErrNonceTooLow
 	}
+	if context.tx.IsSponsored() {
+		sponsor, err := context.tx.Sponsor()
+		if err != nil {
+			return err
+		}
+		context.payer = sponsor
+	}
 	return context.buyGas()
 }
+
+//SponsorLog returns the log telling the sponsor of a sponsored transaction and the fee it paid, nil if the
+//sender paid the gas itself. It is read after RefundCoin.
+func (context *ExecuteTransactionContext) SponsorLog() *types.Log {
+	if *context.payer == *context.from {
+		return nil
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(context.GasUsed()), context.gasPrice)
+	return types.NewSystemLog(context.tx, context.header.Height, types.AmountData(fee), types.SponsorEvent, types.AddressTopic(context.payer), types.AddressTopic(context.from))
+}
@@ -0,0 +1,26 @@
+package transactions
+
+import (
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+//VerifyValidity checks that tx was signed for the chain and that the block at height with timestamp is within
+//its validity window. Before the replay protection fork neither is checked and no window may be set.
+func VerifyValidity(tx *types.Transaction, chainId types.ChainIdType, height uint64, timestamp int64, rules *params.Rules) error {
+	window := tx.ValidityWindow()
+	if !rules.IsReplayProtection {
+		if window != nil {
+			return errors.Wrapf(ErrTxTypeInactive, "validity window at height %d", rules.Height)
+		}
+		return nil
+	}
+	if tx.ChainId() != chainId {
+		return errors.Wrapf(ErrTxChainId, "transaction %d, chain %d", tx.ChainId(), chainId)
+	}
+	if window != nil && window.Expired(height, timestamp) {
+		return errors.Wrapf(ErrTxExpired, "until height %d time %d, block height %d time %d", window.UntilHeight, window.UntilTime, height, timestamp)
+	}
+	return nil
+}
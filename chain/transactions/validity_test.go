@@ -0,0 +1,90 @@
+package transactions
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+func newWindowTx(t *testing.T, chainId types.ChainIdType, window *types.ValidityWindow) *types.Transaction {
+	tx := types.NewTransaction(crypto.CommonAddress{2}, big.NewInt(10), big.NewInt(1), big.NewInt(100000), 0)
+	tx.Data.ChainId = chainId
+	if window != nil {
+		if err := tx.SetValidityWindow(window); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tx
+}
+
+func TestVerifyValidity(t *testing.T) {
+	rules := (&params.ForkConfig{ReplayProtectionHeight: 10}).Rules(20)
+	tests := []struct {
+		tx    *types.Transaction
+		rules *params.Rules
+		err   error
+	}{
+		{newWindowTx(t, 7, nil), rules, nil},
+		{newWindowTx(t, 8, nil), rules, ErrTxChainId},
+		{newWindowTx(t, 8, nil), (&params.ForkConfig{ReplayProtectionHeight: 10}).Rules(9), nil},
+		{newWindowTx(t, 7, &types.ValidityWindow{UntilHeight: 20}), rules, nil},
+		{newWindowTx(t, 7, &types.ValidityWindow{UntilHeight: 19}), rules, ErrTxExpired},
+		{newWindowTx(t, 7, &types.ValidityWindow{UntilTime: 1000}), rules, nil},
+		{newWindowTx(t, 7, &types.ValidityWindow{UntilTime: 999}), rules, ErrTxExpired},
+		{newWindowTx(t, 7, &types.ValidityWindow{UntilHeight: 20}), (&params.ForkConfig{ReplayProtectionHeight: 10}).Rules(9), ErrTxTypeInactive},
+	}
+	for i, test := range tests {
+		err := VerifyValidity(test.tx, 7, test.rules.Height, 1000, test.rules)
+		if errors.Cause(err) != test.err {
+			t.Fatalf("case %d: expect %v, got %v", i, test.err, err)
+		}
+	}
+}
+
+func TestValidityWindowSignature(t *testing.T) {
+	privkeys, pubkeys := newMultiSigKeys(t, 2)
+	tx := newWindowTx(t, 7, &types.ValidityWindow{UntilHeight: 20})
+	//the window is part of the hash the sender signs and the pool knows the transaction by
+	plain := newWindowTx(t, 7, nil)
+	plain.Data.Timestamp = tx.Data.Timestamp
+	other := newWindowTx(t, 7, &types.ValidityWindow{UntilHeight: 21})
+	other.Data.Timestamp = tx.Data.Timestamp
+	if *tx.TxHash() == *plain.TxHash() || *tx.TxHash() == *other.TxHash() {
+		t.Fatal("expect the window to be part of the transaction hash")
+	}
+	if string(tx.SigHash()) != string(tx.TxHash().Bytes()) {
+		t.Fatal("expect the sender to sign the transaction hash")
+	}
+	sig, _ := secp256k1.SignCompact(privkeys[0], tx.SigHash(), true)
+	if err := tx.SetSenderSig(sig); err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(pubkeys[0])
+	sig, _ = secp256k1.SignCompact(privkeys[1], tx.SponsorHash(&from), true)
+	if err := tx.SetSponsorSig(sig); err != nil {
+		t.Fatal(err)
+	}
+
+	if window := tx.ValidityWindow(); window == nil || window.UntilHeight != 20 {
+		t.Fatalf("expect the window kept by the sponsor, got %v", window)
+	}
+	if from, err := tx.From(); err != nil || *from != crypto.PubkeyToAddress(pubkeys[0]) {
+		t.Fatalf("expect the signer as sender, got %v %v", from, err)
+	}
+	if sponsor, err := tx.Sponsor(); err != nil || *sponsor != crypto.PubkeyToAddress(pubkeys[1]) {
+		t.Fatalf("expect the sponsor, got %v %v", sponsor, err)
+	}
+
+	//a sender signature moved to a longer window no longer recovers the sender
+	extended := newWindowTx(t, 7, &types.ValidityWindow{UntilHeight: 1000})
+	extended.Data.Timestamp = tx.Data.Timestamp
+	extended.SetSenderSig(tx.SenderSig())
+	if from, err := extended.From(); err == nil && *from == crypto.PubkeyToAddress(pubkeys[0]) {
+		t.Fatal("expect the window bound to the signature")
+	}
+}
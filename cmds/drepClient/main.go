@@ -880,7 +880,7 @@ func getPoolTransactions(args cli.Args, client *rpc.Client, ctx context.Context)
 	for _, txs := range resp {
 		for _, tx := range txs {
 
-			from, _ := tx.From()
+			from, _ := tx.ClaimedFrom()
 			fmt.Println("from:", from.String(), "to:", tx.To().String(), "nonce:", tx.Nonce(), "amount:", tx.Amount())
 			fmt.Println("txHash:", tx.TxHash())
 			fmt.Println("no sign data:", hexutil.Encode(tx.AsSignMessage()))
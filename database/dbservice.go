@@ -3,6 +3,7 @@ package database
 import (
 	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/database/dbinterface"
 	"github.com/drep-project/DREP-Chain/database/leveldb"
 	"github.com/drep-project/DREP-Chain/database/memorydb"
@@ -24,12 +25,13 @@ var (
 )
 
 type DatabaseService struct {
-	Config *DatabaseConfig
-	db     dbinterface.KeyValueStore
+	Config  *DatabaseConfig
+	db      dbinterface.KeyValueStore
+	stateDb *trie.Database //Shared by every state trie, holds the state nodes which are not yet written to db
 }
 
 func NewDatabaseService(db dbinterface.KeyValueStore) *DatabaseService {
-	ds := &DatabaseService{db: db}
+	ds := &DatabaseService{db: db, stateDb: trie.NewDatabase(db)}
 	return ds
 }
 
@@ -55,6 +57,7 @@ func (database *DatabaseService) Init(executeContext *app.ExecuteContext) error
 	if err != nil {
 		return err
 	}
+	database.stateDb = trie.NewDatabase(database.db)
 	return nil
 }
 
@@ -70,6 +73,12 @@ func (database *DatabaseService) LevelDb() dbinterface.KeyValueStore {
 	return database.db
 }
 
+//StateDb returns the trie database of the state tree, state nodes of recent blocks may only live in its
+//memory cache so every state trie of the chain should be opened on it
+func (database *DatabaseService) StateDb() *trie.Database {
+	return database.stateDb
+}
+
 func (database *DatabaseService) MemoryDb() dbinterface.KeyValueStore {
 	return memorydb.New()
 }
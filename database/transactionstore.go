@@ -2,22 +2,30 @@ package database
 
 import (
 	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
 	"sync"
 )
 
 type TransactionStore struct {
 	dirties *sync.Map //The data belongs to storage's cache
 	trie    *trie.SecureTrie
+	onleaf  trie.LeafCallback //Called for every leaf written to the trie database by Flush, may be nil
 }
 type SnapShot dirtiesKV
 type dirtiesKV struct {
-	storageDirties *sync.Map //The data belongs to storage's cache
+	storageDirties *sync.Map         //The data belongs to storage's cache
+	trie           *trie.SecureTrie  //The trie at the time of the snapshot, a flush after it is reverted too
+	store          *TransactionStore //The store the snapshot is taken of
+
+	//Storages are the snapshots of the contract storages opened at the time of the snapshot
+	Storages map[crypto.CommonAddress]*SnapShot
 }
 
-func NewTransactionStore(trie *trie.SecureTrie) *TransactionStore {
+func NewTransactionStore(trie *trie.SecureTrie, onleaf trie.LeafCallback) *TransactionStore {
 	return &TransactionStore{
 		dirties: new(sync.Map),
 		trie:    trie,
+		onleaf:  onleaf,
 	}
 }
 
@@ -56,20 +64,34 @@ func (tDb *TransactionStore) Flush() {
 				log.Error("Flush():", err)
 				panic(err)
 			}
-
-			tDb.trie.Commit(nil)
 			return true
 		} else {
 			tDb.trie.Delete(bk)
-			tDb.trie.Commit(nil)
 		}
 		tDb.dirties.Delete(key)
 		return true
 	})
+	//commit once, so that only the nodes of the final trie are written to the trie database
+	tDb.trie.Commit(tDb.onleaf)
+}
+
+//Hash returns the root hash of the underlying trie, call Flush before to include the dirty data
+func (tDb *TransactionStore) Hash() crypto.Hash {
+	return tDb.trie.Hash()
 }
 
 func (tDb *TransactionStore) RevertState(snapShot *SnapShot) {
 	tDb.dirties = snapShot.storageDirties
+	if snapShot.trie != nil {
+		//the trie may be shared with the owner of the store, it is reverted in place
+		*tDb.trie = *snapShot.trie.Copy()
+	}
+}
+
+//Revert reverts the store the snapshot was taken of and returns it
+func (snapShot *SnapShot) Revert() *TransactionStore {
+	snapShot.store.RevertState(snapShot)
+	return snapShot.store
 }
 
 func (tDb *TransactionStore) CopyState() *SnapShot {
@@ -93,5 +115,7 @@ func (tDb *TransactionStore) CopyState() *SnapShot {
 	})
 
 	newDirties.storageDirties = newMap
+	newDirties.trie = tDb.trie.Copy()
+	newDirties.store = tDb
 	return (*SnapShot)(&newDirties)
 }
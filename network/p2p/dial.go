@@ -25,6 +25,7 @@ import (
 
 	"github.com/drep-project/DREP-Chain/network/p2p/enode"
 	"github.com/drep-project/DREP-Chain/network/p2p/netutil"
+	"github.com/drep-project/DREP-Chain/network/p2p/reputation"
 )
 
 const (
@@ -70,6 +71,7 @@ type dialstate struct {
 	maxDynDials int
 	ntab        discoverTable
 	netrestrict *netutil.Netlist
+	bans        *reputation.BanList
 	self        enode.ID
 
 	lookupRunning bool
@@ -263,6 +265,7 @@ var (
 	errAlreadyConnected = errors.New("already connected")
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNotWhitelisted   = errors.New("not contained in netrestrict whitelist")
+	errBanned           = errors.New("banned")
 )
 
 func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
@@ -279,6 +282,8 @@ func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
 		return errNotWhitelisted
 	case s.hist.contains(n.ID()):
 		return errRecentlyDialed
+	case s.bans.Banned(n.ID(), n.IP()):
+		return errBanned
 	}
 	return nil
 }
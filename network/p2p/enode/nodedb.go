@@ -40,6 +40,7 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbBanPrefix    = "ban:" // Ban list entries, the full key is "ban:<ID>" or "ban:<IP>"
 	dbDiscoverRoot = "v4"
 
 	// These fields are stored per ID and Node, the full key is "n:<ID>:v4:<Node>:findfail".
@@ -441,6 +442,79 @@ func nextNode(it iterator.Iterator) *Node {
 	return nil
 }
 
+// Ban is an entry of the ban list, it targets either a node ID or an IP address.
+type Ban struct {
+	ID     ID     // banned node, zero if the entry targets an IP address
+	IP     net.IP // banned address, nil if the entry targets a node
+	Until  int64  // unix time the ban ends at, 0 for a permanent ban
+	Reason string
+}
+
+// banRecord is the stored value of a ban list entry.
+type banRecord struct {
+	Until  int64
+	Reason string
+}
+
+// Expired reports whether the ban has ended at the given time.
+func (ban *Ban) Expired(now time.Time) bool {
+	return ban.Until != 0 && ban.Until <= now.Unix()
+}
+
+// banKey returns the database key of a ban list entry.
+func banKey(ban *Ban) []byte {
+	if ban.IP != nil {
+		return append([]byte(dbBanPrefix), ban.IP.To16()...)
+	}
+	return append([]byte(dbBanPrefix), ban.ID[:]...)
+}
+
+// UpdateBan inserts - potentially overwriting - an entry of the ban list.
+func (db *DB) UpdateBan(ban *Ban) error {
+	blob, err := drepbinary.Marshal(&banRecord{Until: ban.Until, Reason: ban.Reason})
+	if err != nil {
+		return err
+	}
+	return db.lvl.Put(banKey(ban), blob, nil)
+}
+
+// DeleteBan removes an entry of the ban list.
+func (db *DB) DeleteBan(ban *Ban) error {
+	return db.lvl.Delete(banKey(ban), nil)
+}
+
+// DeleteBans removes all the entries of the ban list.
+func (db *DB) DeleteBans() {
+	deleteRange(db.lvl, []byte(dbBanPrefix))
+}
+
+// Bans retrieves all the entries of the ban list, entries which can't be decoded are skipped.
+func (db *DB) Bans() []*Ban {
+	var bans []*Ban
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbBanPrefix)), nil)
+	defer it.Release()
+	for it.Next() {
+		var record banRecord
+		if err := drepbinary.Unmarshal(it.Value(), &record); err != nil {
+			continue
+		}
+		ban := &Ban{Until: record.Until, Reason: record.Reason}
+		switch target := it.Key()[len(dbBanPrefix):]; len(target) {
+		case len(ban.ID):
+			copy(ban.ID[:], target)
+		case net.IPv6len:
+			ban.IP = net.IP(append([]byte{}, target...))
+			if ip4 := ban.IP.To4(); ip4 != nil {
+				ban.IP = ip4
+			}
+		default:
+			continue
+		}
+		bans = append(bans, ban)
+	}
+	return bans
+}
+
 // close flushes and closes the database files.
 func (db *DB) Close() {
 	close(db.quit)
@@ -70,6 +70,10 @@ const (
 	DiscSelf
 	DiscReadTimeout
 	DiscSubprotocolError = 0x10
+	//DiscIncompatibleChain is used by the block protocol for a peer on another network, genesis or fork schedule
+	DiscIncompatibleChain DiscReason = 0x11
+	//DiscBanned is used for a peer on the ban list, either banned by the operator or for misbehaving
+	DiscBanned DiscReason = 0x12
 )
 
 var discReasonToString = [...]string{
@@ -86,10 +90,12 @@ var discReasonToString = [...]string{
 	DiscSelf:                "connected to self",
 	DiscReadTimeout:         "read timeout",
 	DiscSubprotocolError:    "subprotocol error",
+	DiscIncompatibleChain:   "incompatible chain",
+	DiscBanned:              "banned",
 }
 
 func (d DiscReason) String() string {
-	if len(discReasonToString) < int(d) {
+	if len(discReasonToString) <= int(d) {
 		return fmt.Sprintf("unknown disconnect reason %d", d)
 	}
 	return discReasonToString[d]
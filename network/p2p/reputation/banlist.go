@@ -0,0 +1,120 @@
+package reputation
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+// BanList keeps the banned node IDs and IP addresses in memory, every change is
+// written to the node database so that the bans survive a restart.
+type BanList struct {
+	lock sync.RWMutex
+	db   *enode.DB
+	ids  map[enode.ID]*enode.Ban
+	ips  map[string]*enode.Ban
+}
+
+// NewBanList loads the ban list stored in the node database, ended bans are dropped.
+func NewBanList(db *enode.DB) *BanList {
+	bans := &BanList{
+		db:  db,
+		ids: make(map[enode.ID]*enode.Ban),
+		ips: make(map[string]*enode.Ban),
+	}
+	now := time.Now()
+	for _, ban := range db.Bans() {
+		if ban.Expired(now) {
+			db.DeleteBan(ban)
+			continue
+		}
+		bans.put(ban)
+	}
+	return bans
+}
+
+func (bans *BanList) put(ban *enode.Ban) {
+	if ban.IP != nil {
+		bans.ips[ban.IP.String()] = ban
+	} else {
+		bans.ids[ban.ID] = ban
+	}
+}
+
+// Add inserts or replaces the ban of a node ID or an IP address.
+func (bans *BanList) Add(ban *enode.Ban) error {
+	bans.lock.Lock()
+	defer bans.lock.Unlock()
+	if err := bans.db.UpdateBan(ban); err != nil {
+		return err
+	}
+	bans.put(ban)
+	return nil
+}
+
+// Remove lifts the ban of a node ID or an IP address.
+func (bans *BanList) Remove(ban *enode.Ban) error {
+	bans.lock.Lock()
+	defer bans.lock.Unlock()
+	if ban.IP != nil {
+		delete(bans.ips, ban.IP.String())
+	} else {
+		delete(bans.ids, ban.ID)
+	}
+	return bans.db.DeleteBan(ban)
+}
+
+// Clear lifts all the bans.
+func (bans *BanList) Clear() {
+	bans.lock.Lock()
+	defer bans.lock.Unlock()
+	bans.ids = make(map[enode.ID]*enode.Ban)
+	bans.ips = make(map[string]*enode.Ban)
+	bans.db.DeleteBans()
+}
+
+// List returns the bans in force.
+func (bans *BanList) List() []*enode.Ban {
+	bans.lock.RLock()
+	defer bans.lock.RUnlock()
+	now := time.Now()
+	list := make([]*enode.Ban, 0, len(bans.ids)+len(bans.ips))
+	for _, ban := range bans.ids {
+		if !ban.Expired(now) {
+			list = append(list, ban)
+		}
+	}
+	for _, ban := range bans.ips {
+		if !ban.Expired(now) {
+			list = append(list, ban)
+		}
+	}
+	return list
+}
+
+// BannedIP reports whether connections from the IP address are refused.
+func (bans *BanList) BannedIP(ip net.IP) bool {
+	if bans == nil || ip == nil {
+		return false
+	}
+	bans.lock.RLock()
+	ban, ok := bans.ips[ip.String()]
+	bans.lock.RUnlock()
+	return ok && !ban.Expired(time.Now())
+}
+
+// Banned reports whether the node or its IP address is banned.
+func (bans *BanList) Banned(id enode.ID, ip net.IP) bool {
+	if bans == nil {
+		return false
+	}
+	bans.lock.RLock()
+	ban, ok := bans.ids[id]
+	bans.lock.RUnlock()
+	if ok && !ban.Expired(time.Now()) {
+		return true
+	}
+	return bans.BannedIP(ip)
+}
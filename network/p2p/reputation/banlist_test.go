@@ -0,0 +1,72 @@
+package reputation
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+func TestBanListPersistence(t *testing.T) {
+	root, err := ioutil.TempDir("", "banlist-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	path := filepath.Join(root, "nodes")
+
+	db, err := enode.OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bans := NewBanList(db)
+	now := time.Now()
+	id, ip := enode.ID{1}, net.ParseIP("10.0.0.1")
+	lifted, expired := enode.ID{2}, enode.ID{3}
+	for _, ban := range []*enode.Ban{
+		{ID: id, Reason: "permanent"},
+		{IP: ip, Until: now.Add(time.Hour).Unix(), Reason: "an hour"},
+		{ID: lifted, Reason: "lifted"},
+		{ID: expired, Until: now.Add(-time.Second).Unix(), Reason: "expired"},
+	} {
+		if err := bans.Add(ban); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bans.Remove(&enode.Ban{ID: lifted}); err != nil {
+		t.Fatal(err)
+	}
+	if len(bans.List()) != 2 {
+		t.Fatalf("expect the bans in force listed, got %d", len(bans.List()))
+	}
+	db.Close()
+
+	//the bans survive the restart, the expired one is dropped from the database
+	db, err = enode.OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	bans = NewBanList(db)
+	if !bans.Banned(id, nil) {
+		t.Fatal("expect the node ban restored")
+	}
+	if !bans.BannedIP(ip) || !bans.Banned(enode.ID{9}, ip) {
+		t.Fatal("expect the address ban restored")
+	}
+	if bans.Banned(lifted, nil) || bans.Banned(expired, nil) {
+		t.Fatal("expect the lifted and the expired bans gone")
+	}
+	if stored := db.Bans(); len(stored) != 2 {
+		t.Fatalf("expect the expired ban deleted from the database, got %d bans", len(stored))
+	}
+
+	bans.Clear()
+	if bans.Banned(id, ip) || len(db.Bans()) != 0 {
+		t.Fatal("expect all the bans cleared")
+	}
+}
@@ -0,0 +1,116 @@
+// Package reputation scores the peers by the faults the protocols report for them
+// and keeps the list of the banned nodes and addresses.
+package reputation
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+const (
+	// A peer whose score reaches BanThreshold is banned for BanDuration.
+	BanThreshold = 100
+	BanDuration  = time.Hour
+
+	// The score of a peer halves every scoreHalfLife, occasional faults of an
+	// honest peer never add up to a ban.
+	scoreHalfLife = 10 * time.Minute
+
+	// Scores which decayed below one point are forgotten once more than
+	// maxScoredPeers peers have a score.
+	maxScoredPeers = 1024
+)
+
+// Fault is a misbehaviour of a peer reported by the protocols.
+type Fault uint
+
+const (
+	FaultTimeout        Fault = iota // a request was not answered in time
+	FaultSpam                        // an invalid transaction or a message nobody asked for
+	FaultBadMessage                  // a message which can not be decoded
+	FaultBadHeaderChain              // headers which are not linked or fail the validation
+	FaultInvalidBlock                // a block which fails the validation
+)
+
+var faultPenalties = [...]float64{
+	FaultTimeout:        10,
+	FaultSpam:           5,
+	FaultBadMessage:     50,
+	FaultBadHeaderChain: 50,
+	FaultInvalidBlock:   50,
+}
+
+var faultToString = [...]string{
+	FaultTimeout:        "request timeout",
+	FaultSpam:           "spam",
+	FaultBadMessage:     "undecodable message",
+	FaultBadHeaderChain: "bad header chain",
+	FaultInvalidBlock:   "invalid block",
+}
+
+func (f Fault) String() string {
+	if len(faultToString) <= int(f) {
+		return fmt.Sprintf("unknown fault %d", f)
+	}
+	return faultToString[f]
+}
+
+type peerScore struct {
+	value   float64
+	updated time.Time
+}
+
+// decay returns the score at the given time.
+func (score *peerScore) decay(now time.Time) float64 {
+	elapsed := now.Sub(score.updated)
+	return score.value * math.Pow(0.5, float64(elapsed)/float64(scoreHalfLife))
+}
+
+// Reputation scores the peers by the faults reported for them.
+type Reputation struct {
+	lock   sync.Mutex
+	scores map[enode.ID]*peerScore
+}
+
+func New() *Reputation {
+	return &Reputation{scores: make(map[enode.ID]*peerScore)}
+}
+
+// Penalize adds the penalty of the fault to the score of the node and returns the new score.
+func (rep *Reputation) Penalize(id enode.ID, fault Fault, now time.Time) float64 {
+	rep.lock.Lock()
+	defer rep.lock.Unlock()
+
+	score, ok := rep.scores[id]
+	if !ok {
+		if len(rep.scores) >= maxScoredPeers {
+			rep.prune(now)
+		}
+		score = &peerScore{}
+		rep.scores[id] = score
+	}
+	if int(fault) < len(faultPenalties) {
+		score.value = score.decay(now) + faultPenalties[fault]
+	}
+	score.updated = now
+	return score.value
+}
+
+// Forget drops the score of the node, it starts from zero once its ban ends.
+func (rep *Reputation) Forget(id enode.ID) {
+	rep.lock.Lock()
+	defer rep.lock.Unlock()
+	delete(rep.scores, id)
+}
+
+func (rep *Reputation) prune(now time.Time) {
+	for id, score := range rep.scores {
+		if score.decay(now) < 1 {
+			delete(rep.scores, id)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+func TestReputationPenalize(t *testing.T) {
+	rep := New()
+	id := enode.ID{1}
+	now := time.Now()
+
+	if score := rep.Penalize(id, FaultSpam, now); score != faultPenalties[FaultSpam] {
+		t.Fatalf("expect the penalty of the fault, got %v", score)
+	}
+	if score := rep.Penalize(id, FaultInvalidBlock, now); score != faultPenalties[FaultSpam]+faultPenalties[FaultInvalidBlock] {
+		t.Fatalf("expect the penalties added up, got %v", score)
+	}
+
+	//the score halves every half life
+	later := now.Add(scoreHalfLife)
+	expect := (faultPenalties[FaultSpam]+faultPenalties[FaultInvalidBlock])/2 + faultPenalties[FaultTimeout]
+	if score := rep.Penalize(id, FaultTimeout, later); score != expect {
+		t.Fatalf("expect the score decayed before the penalty, got %v want %v", score, expect)
+	}
+
+	//an unknown fault costs nothing
+	if score := rep.Penalize(enode.ID{2}, Fault(100), now); score != 0 {
+		t.Fatalf("expect no penalty for an unknown fault, got %v", score)
+	}
+
+	rep.Forget(id)
+	if score := rep.Penalize(id, FaultSpam, later); score != faultPenalties[FaultSpam] {
+		t.Fatalf("expect a forgotten peer to start from zero, got %v", score)
+	}
+}
+
+func TestReputationPrune(t *testing.T) {
+	rep := New()
+	now := time.Now()
+	later := now.Add(10 * scoreHalfLife)
+	for i := 0; i < maxScoredPeers-1; i++ {
+		rep.Penalize(enode.ID{byte(i), byte(i >> 8)}, FaultSpam, now)
+	}
+	fresh := enode.ID{0xff, 0xff}
+	rep.Penalize(fresh, FaultSpam, later)
+
+	//the table is full, the decayed scores are forgotten to make room
+	rep.Penalize(enode.ID{0xfe, 0xff}, FaultBadMessage, later)
+	if len(rep.scores) != 2 {
+		t.Fatalf("expect the decayed scores pruned, got %d scores", len(rep.scores))
+	}
+	if _, ok := rep.scores[fresh]; !ok {
+		t.Fatal("expect the fresh score kept")
+	}
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/drep-project/DREP-Chain/network/p2p/enr"
 	"github.com/drep-project/DREP-Chain/network/p2p/nat"
 	"github.com/drep-project/DREP-Chain/network/p2p/netutil"
+	"github.com/drep-project/DREP-Chain/network/p2p/reputation"
 	"github.com/drep-project/binary"
 	"github.com/sirupsen/logrus"
 )
@@ -166,6 +167,8 @@ type Server struct {
 	running bool
 
 	nodedb       *enode.DB
+	bans         *reputation.BanList
+	reputation   *reputation.Reputation
 	localnode    *enode.LocalNode
 	ntab         discoverTable
 	listener     net.Listener
@@ -355,6 +358,94 @@ func (srv *Server) RemoveTrustedPeer(node *enode.Node) {
 	}
 }
 
+// Fault is a misbehaviour of a peer reported by the protocols.
+type Fault = reputation.Fault
+
+const (
+	FaultTimeout        = reputation.FaultTimeout
+	FaultSpam           = reputation.FaultSpam
+	FaultBadMessage     = reputation.FaultBadMessage
+	FaultBadHeaderChain = reputation.FaultBadHeaderChain
+	FaultInvalidBlock   = reputation.FaultInvalidBlock
+)
+
+// Penalize adds the penalty of a fault to the score of the peer. A peer whose
+// score reaches the ban threshold is disconnected and banned together with its
+// IP address for a while. Trusted peers are never banned automatically.
+func (srv *Server) Penalize(p *Peer, fault Fault) {
+	if srv.reputation == nil || p == nil {
+		return
+	}
+	score := srv.reputation.Penalize(p.ID(), fault, time.Now())
+	srv.log.WithField("id", p.ID()).WithField("ip", p.IP()).WithField("fault", fault).WithField("score", score).Debug("Penalize peer")
+	if score < reputation.BanThreshold || p.rw.is(trustedConn) {
+		return
+	}
+
+	srv.reputation.Forget(p.ID())
+	until := time.Now().Add(reputation.BanDuration).Unix()
+	reason := fmt.Sprintf("score %.0f, last fault %s", score, fault)
+	bans := []*enode.Ban{{ID: p.ID(), Until: until, Reason: reason}}
+	if tcp, ok := p.RemoteAddr().(*net.TCPAddr); ok {
+		bans = append(bans, &enode.Ban{IP: tcp.IP, Until: until, Reason: reason})
+	}
+	for _, ban := range bans {
+		if err := srv.bans.Add(ban); err != nil {
+			srv.log.WithField("err", err).Warn("Store ban")
+		}
+	}
+	srv.log.WithField("id", p.ID()).WithField("ip", p.IP()).WithField("reason", reason).Info("Ban peer")
+	p.Disconnect(DiscBanned)
+}
+
+// Ban adds a node ID or an IP address to the ban list, connected peers matching
+// it are disconnected.
+func (srv *Server) Ban(ban *enode.Ban) error {
+	if srv.bans == nil {
+		return errServerStopped
+	}
+	if err := srv.bans.Add(ban); err != nil {
+		return err
+	}
+	select {
+	case srv.peerOp <- func(peers map[enode.ID]*Peer) {
+		for _, p := range peers {
+			if srv.bans.Banned(p.ID(), p.Node().IP()) {
+				p.Disconnect(DiscBanned)
+			}
+		}
+	}:
+		<-srv.peerOpDone
+	case <-srv.quit:
+	}
+	return nil
+}
+
+// Unban removes a node ID or an IP address from the ban list.
+func (srv *Server) Unban(ban *enode.Ban) error {
+	if srv.bans == nil {
+		return errServerStopped
+	}
+	return srv.bans.Remove(ban)
+}
+
+// ClearBans empties the ban list.
+func (srv *Server) ClearBans() error {
+	if srv.bans == nil {
+		return errServerStopped
+	}
+	srv.bans.Clear()
+	return nil
+}
+
+// Bans returns the entries of the ban list in force.
+func (srv *Server) Bans() []*enode.Ban {
+	if srv.bans == nil {
+		return nil
+	}
+	return srv.bans.List()
+}
+
 // SubscribePeers subscribes the given channel to peer events
 func (srv *Server) SubscribeEvents(ch chan *PeerEvent) event.Subscription {
 	return srv.peerFeed.Subscribe(ch)
@@ -470,6 +561,7 @@ func (srv *Server) Start() (err error) {
 
 	dynPeers := srv.maxDialedConns()
 	dialer := newDialState(srv.localnode.ID(), srv.StaticNodes, srv.BootstrapNodes, srv.ntab, dynPeers, srv.NetRestrict)
+	dialer.bans = srv.bans
 	srv.loopWG.Add(1)
 	go srv.run(dialer)
 	return nil
@@ -490,6 +582,8 @@ func (srv *Server) setupLocalNode() error {
 		return err
 	}
 	srv.nodedb = db
+	srv.bans = reputation.NewBanList(db)
+	srv.reputation = reputation.New()
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	srv.localnode.Set(capsByNameAndVersion(srv.ourHandshake.Caps))
@@ -814,6 +908,8 @@ func (srv *Server) protoHandshakeChecks(peers map[enode.ID]*Peer, inboundCount i
 
 func (srv *Server) encHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	switch {
+	case srv.bans.Banned(c.peerNode.ID(), c.peerNode.IP()):
+		return DiscBanned
 	case !c.is(trustedConn|staticDialedConn) && len(peers) >= srv.MaxPeers:
 		return DiscTooManyPeers
 	case !c.is(trustedConn) && c.is(inboundConn) && inboundCount >= srv.maxInboundConns():
@@ -878,6 +974,14 @@ func (srv *Server) listenLoop() {
 			break
 		}
 
+		// Reject connections from banned addresses before the handshake.
+		if tcp, ok := fd.RemoteAddr().(*net.TCPAddr); ok && srv.bans.BannedIP(tcp.IP) {
+			srv.log.WithField("addr", fd.RemoteAddr()).Debug("Rejected conn (banned)")
+			fd.Close()
+			slots <- struct{}{}
+			continue
+		}
+
 		// Reject connections that do not match NetRestrict.
 		if srv.NetRestrict != nil {
 			if tcp, ok := fd.RemoteAddr().(*net.TCPAddr); ok && !srv.NetRestrict.Contains(tcp.IP) {
@@ -13,6 +13,6 @@ var (
 	log = dlog.EnsureLogger(MODULENAME)
 )
 
-func NewLog() *logrus.Logger {
+func NewLog() *logrus.Entry {
 	return dlog.EnsureLogger(MODULENAME)
 }
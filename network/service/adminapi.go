@@ -0,0 +1,123 @@
+package service
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/pkg/errors"
+)
+
+/*
+name: Admin RPC interface
+usage: Node maintenance, not public, only reachable through ipc or a whitelisted admin module
+prefix:admin
+*/
+type P2PAdminApi struct {
+	p2pService *P2pService
+}
+
+//BanInfo describes an entry of the ban list
+type BanInfo struct {
+	Target string `json:"target"` //node id or ip address
+	Until  int64  `json:"until"`  //unix time the ban ends at, 0 for a permanent ban
+	Reason string `json:"reason"`
+}
+
+//parseBanTarget reads a node url, a node id or an ip address
+func parseBanTarget(target string) (*enode.Ban, error) {
+	if ip := net.ParseIP(target); ip != nil {
+		return &enode.Ban{IP: ip}, nil
+	}
+	if strings.HasPrefix(target, "enode://") {
+		node := enode.Node{}
+		if err := node.UnmarshalText([]byte(target)); err != nil {
+			return nil, errors.Wrapf(ErrBanTarget, "%s: %v", target, err)
+		}
+		return &enode.Ban{ID: node.ID()}, nil
+	}
+	var id enode.ID
+	if err := id.UnmarshalText([]byte(target)); err != nil {
+		return nil, errors.Wrapf(ErrBanTarget, "%s: %v", target, err)
+	}
+	return &enode.Ban{ID: id}, nil
+}
+
+/*
+ name: bans
+ usage: List the banned nodes and ip addresses, peers are banned by the operator or for misbehaving
+ params:
+ return: entries of the ban list
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_bans","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":[{"target":"192.168.74.1","until":1592457284,"reason":"score 110, last fault invalid block"},{"target":"e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f1091","until":0,"reason":"banned by the operator"}]}
+*/
+func (adminApi *P2PAdminApi) Bans() []*BanInfo {
+	bans := adminApi.p2pService.server.Bans()
+	infos := make([]*BanInfo, 0, len(bans))
+	for _, ban := range bans {
+		info := &BanInfo{Until: ban.Until, Reason: ban.Reason}
+		if ban.IP != nil {
+			info.Target = ban.IP.String()
+		} else {
+			info.Target = ban.ID.String()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+/*
+ name: addBan
+ usage: Ban a node or an ip address, connected peers matching it are disconnected and new connections are refused
+ params:
+	1. node url, node id or ip address
+	2. duration of the ban in seconds, 0 bans permanently
+ return: error if the target can not be parsed
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_addBan","params":["192.168.74.1", 3600], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *P2PAdminApi) AddBan(target string, seconds uint64) error {
+	ban, err := parseBanTarget(target)
+	if err != nil {
+		return err
+	}
+	ban.Reason = "banned by the operator"
+	if seconds > 0 {
+		ban.Until = time.Now().Add(time.Duration(seconds) * time.Second).Unix()
+	}
+	return adminApi.p2pService.server.Ban(ban)
+}
+
+/*
+ name: removeBan
+ usage: Lift the ban of a node or an ip address
+ params:
+	1. node url, node id or ip address
+ return: error if the target can not be parsed
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_removeBan","params":["192.168.74.1"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *P2PAdminApi) RemoveBan(target string) error {
+	ban, err := parseBanTarget(target)
+	if err != nil {
+		return err
+	}
+	return adminApi.p2pService.server.Unban(ban)
+}
+
+/*
+ name: clearBans
+ usage: Lift all the bans
+ params:
+ return: nil
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_clearBans","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *P2PAdminApi) ClearBans() error {
+	return adminApi.p2pService.server.ClearBans()
+}
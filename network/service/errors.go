@@ -0,0 +1,10 @@
+package service
+
+import (
+	"errors"
+)
+
+var (
+	// ErrBanTarget print error message.
+	ErrBanTarget = errors.New("expect a node url, a node id or an ip address")
+)
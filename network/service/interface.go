@@ -15,5 +15,7 @@ type P2P interface {
 	RemovePeer(url string)
 	AddProtocols(protocols []p2p.Protocol)
 	LocalNode() *enode.Node
+	//Penalize reports a misbehaviour of the peer, a peer misbehaving too often is banned
+	Penalize(peer *p2p.Peer, fault p2p.Fault)
 	//SubscribeEvents(ch chan *p2p.PeerEvent) event.Subscription
 }
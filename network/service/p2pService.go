@@ -108,6 +108,14 @@ func (p2pService *P2pService) Init(executeContext *app.ExecuteContext) error {
 			},
 			Public: true,
 		},
+		app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service: &P2PAdminApi{
+				p2pService: p2pService,
+			},
+			Public: false,
+		},
 	}
 	return nil
 }
@@ -122,6 +130,9 @@ func (p2pService *P2pService) Start(executeContext *app.ExecuteContext) error {
 	return nil
 }
 
+//Online marks the service as a network service, it connects to peers
+func (p2pService *P2pService) Online() {}
+
 func (p2pService *P2pService) Stop(executeContext *app.ExecuteContext) error {
 	if p2pService.server == nil {
 		return nil
@@ -204,6 +215,10 @@ func (p2pService *P2pService) LocalNode() *enode.Node {
 	return p2pService.server.LocalNode()
 }
 
+func (p2pService *P2pService) Penalize(peer *p2p.Peer, fault p2p.Fault) {
+	p2pService.server.Penalize(peer, fault)
+}
+
 func (p2pService *P2pService) DefaultConfig(netType params.NetType) *p2pTypes.P2pConfig {
 	switch netType {
 	case params.MainnetType:
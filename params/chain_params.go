@@ -9,6 +9,11 @@ const (
 
 	AliasGas uint64 = 68 // gas Use when alias a address
 
+	MultiSigSignerGas uint64 = 3000  // Per signer of a transaction spent from a multisig account, like EcrecoverGas
+	BatchPayoutGas    uint64 = 9000  // Per recipient of a batch transfer, like CallValueTransferGas
+	SponsorGas        uint64 = 3000  // Recovery of the sponsor signature of a sponsored transaction, like EcrecoverGas
+	IssueAssetGas     uint64 = 32000 // Creation of a native asset, like CreateGas
+
 	//GasLimitBoundDivisor uint64 = 64       // The bound divisor of the gas limit, used in update calculations.
 	MinGasLimit     uint64 = 18000000 // Minimum the gas limit may ever be.
 	GenesisGasLimit uint64 = 18000000 // Gas limit of the Genesis block.
@@ -79,6 +84,8 @@ const (
 	Bn256PairingPerPointGas uint64 = 80000  // Per-point price for an elliptic curve pairing check
 
 	RootChain                 uint32 = 0
+	MainnetChainId            uint32 = 1 //Replay protection binds the transactions to the chain id of their network
+	TestnetChainId            uint32 = 2
 	RemotePortMainnet         uint16 = 10087
 	GenesisProducerNumMainnet        = 21
 
@@ -0,0 +1,98 @@
+package params
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+	"sort"
+)
+
+var (
+	//ErrRemoteStale is returned by CheckForkID when the peer misses a fork the local node already passed
+	ErrRemoteStale = errors.New("remote needs update")
+	//ErrLocalIncompatibleOrStale is returned by CheckForkID when the peer follows another schedule
+	ErrLocalIncompatibleOrStale = errors.New("local incompatible or needs update")
+)
+
+//ForkID identifies the fork schedule a node follows, Hash is a checksum of the genesis hash and of the forks
+//passed, Next is the height of the next scheduled fork or 0 if none is known
+type ForkID struct {
+	Hash uint32
+	Next uint64
+}
+
+//forkHeights returns the distinct activation heights after genesis in ascending order, forks active from
+//genesis are part of it and unscheduled ones are left out
+func (forks *ForkConfig) forkHeights() []uint64 {
+	seen := map[uint64]bool{}
+	var heights []uint64
+	for _, fork := range forks.Forks() {
+		if fork.Height == 0 || fork.Height == math.MaxUint64 || seen[fork.Height] {
+			continue
+		}
+		seen[fork.Height] = true
+		heights = append(heights, fork.Height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+
+//forkSums returns the checksum in force before each fork height and, last, the one after all of them
+func (forks *ForkConfig) forkSums(genesis []byte) ([]uint64, []uint32) {
+	heights := forks.forkHeights()
+	sums := make([]uint32, len(heights)+1)
+	sums[0] = crc32.ChecksumIEEE(genesis)
+	for i, height := range heights {
+		var blob [8]byte
+		binary.BigEndian.PutUint64(blob[:], height)
+		sums[i+1] = crc32.Update(sums[i], crc32.IEEETable, blob[:])
+	}
+	return heights, sums
+}
+
+//ForkID returns the fork identifier at height of the chain starting with the genesis hash
+func (forks *ForkConfig) ForkID(genesis []byte, height uint64) ForkID {
+	heights, sums := forks.forkSums(genesis)
+	for i, fork := range heights {
+		if height < fork {
+			return ForkID{Hash: sums[i], Next: fork}
+		}
+	}
+	return ForkID{Hash: sums[len(heights)]}
+}
+
+//CheckForkID validates the fork identifier of a peer against the local schedule at height, the rules follow
+//EIP-2124: a peer on the same schedule is accepted unless it announces a fork the local node passed without
+//knowing it, a peer behind must know the next local fork and a peer ahead must have passed only local forks
+func (forks *ForkConfig) CheckForkID(genesis []byte, height uint64, remote ForkID) error {
+	heights, sums := forks.forkSums(genesis)
+	current := len(heights)
+	for i, fork := range heights {
+		if height < fork {
+			current = i
+			break
+		}
+	}
+
+	if sums[current] == remote.Hash {
+		if remote.Next > 0 && height >= remote.Next {
+			return ErrLocalIncompatibleOrStale
+		}
+		return nil
+	}
+	for i := 0; i < current; i++ {
+		if sums[i] == remote.Hash {
+			if heights[i] != remote.Next {
+				return ErrRemoteStale
+			}
+			return nil
+		}
+	}
+	for i := current + 1; i < len(sums); i++ {
+		if sums[i] == remote.Hash {
+			return nil
+		}
+	}
+	return ErrLocalIncompatibleOrStale
+}
@@ -0,0 +1,49 @@
+package params
+
+import (
+	"testing"
+)
+
+func TestForkID(t *testing.T) {
+	genesis := []byte{1, 2, 3}
+	forks := &ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 20, MultiSigHeight: 20}
+	if id := forks.ForkID(genesis, 9); id.Next != 10 {
+		t.Fatalf("unexpected fork id before the first fork %+v", id)
+	}
+	if id := forks.ForkID(genesis, 10); id.Next != 20 || id.Hash == forks.ForkID(genesis, 9).Hash {
+		t.Fatalf("unexpected fork id after the first fork %+v", id)
+	}
+	if id := forks.ForkID(genesis, 25); id.Next != 0 || id.Hash == forks.ForkID(genesis, 10).Hash {
+		t.Fatalf("unexpected fork id after the last fork %+v", id)
+	}
+	if forks.ForkID(genesis, 0) == forks.ForkID([]byte{4}, 0) {
+		t.Fatal("fork id does not depend on the genesis")
+	}
+
+	tests := []struct {
+		height uint64
+		remote ForkID
+		err    error
+	}{
+		//same schedule at the same fork
+		{15, forks.ForkID(genesis, 15), nil},
+		{25, forks.ForkID(genesis, 25), nil},
+		//peer behind on the same schedule, or ahead of the local node
+		{25, forks.ForkID(genesis, 5), nil},
+		{5, forks.ForkID(genesis, 25), nil},
+		//peer behind which does not know the next fork
+		{25, ForkID{Hash: forks.ForkID(genesis, 5).Hash, Next: 11}, ErrRemoteStale},
+		{25, ForkID{Hash: forks.ForkID(genesis, 5).Hash}, ErrRemoteStale},
+		//peer announcing a fork the local node passed without it
+		{25, ForkID{Hash: forks.ForkID(genesis, 25).Hash, Next: 24}, ErrLocalIncompatibleOrStale},
+		{25, ForkID{Hash: forks.ForkID(genesis, 25).Hash, Next: 30}, nil},
+		//another chain or schedule
+		{15, (&ForkConfig{ContractStorageHeight: 11}).ForkID(genesis, 15), ErrLocalIncompatibleOrStale},
+		{15, forks.ForkID([]byte{4}, 15), ErrLocalIncompatibleOrStale},
+	}
+	for i, test := range tests {
+		if err := forks.CheckForkID(genesis, test.height, test.remote); err != test.err {
+			t.Fatalf("case %d: expected %v, got %v", i, test.err, err)
+		}
+	}
+}
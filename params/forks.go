@@ -0,0 +1,170 @@
+package params
+
+import (
+	"fmt"
+	"math"
+)
+
+//ForkConfig holds the activation heights of the rule changes, a fork at height 0 is active from genesis.
+//Chains started before a fork was introduced must set its height to one which is not reached yet.
+//The gas constants, the jump table of the EVM, the reward halving and the alias fees have not changed since
+//genesis, so no fork covers them. A change to one of them adds a fork here and reads it from the Rules the EVM,
+//the reward calculator and the transaction executors are given.
+type ForkConfig struct {
+	//from this height contracts keep their storage slots in their own storage trie instead of the state trie
+	ContractStorageHeight uint64 `json:"contractStorageHeight"`
+	//from this height transfer, stake and alias transactions put their logs into the receipt
+	SystemLogHeight uint64 `json:"systemLogHeight"`
+	//from this height multisig accounts may be created and spent from
+	MultiSigHeight uint64 `json:"multiSigHeight"`
+	//from this height batch transfers may pay many recipients in one transaction
+	BatchTransferHeight uint64 `json:"batchTransferHeight"`
+	//from this height a sponsor may pay the gas of a transaction
+	SponsorHeight uint64 `json:"sponsorHeight"`
+	//from this height transactions must carry the chain id and may limit the blocks they are included in
+	ReplayProtectionHeight uint64 `json:"replayProtectionHeight"`
+	//from this height aliases may be transferred and released, and an address owning one may not set another
+	AliasTransferHeight uint64 `json:"aliasTransferHeight"`
+	//from this height native assets may be issued, transferred, minted and burnt
+	AssetHeight uint64 `json:"assetHeight"`
+}
+
+//NotScheduled is the height of a fork which does not activate
+const NotScheduled uint64 = math.MaxUint64
+
+var (
+	//MainnetForks is the fork schedule of the main network. The network was launched before the forks were
+	//introduced and no activation height has been agreed on yet, none of them activates.
+	MainnetForks = unscheduledForks()
+
+	//TestnetForks is the fork schedule of the test network, no activation height has been agreed on yet
+	TestnetForks = unscheduledForks()
+)
+
+//unscheduledForks returns a schedule where no fork activates
+func unscheduledForks() ForkConfig {
+	return ForkConfig{
+		ContractStorageHeight:  NotScheduled,
+		SystemLogHeight:        NotScheduled,
+		MultiSigHeight:         NotScheduled,
+		BatchTransferHeight:    NotScheduled,
+		SponsorHeight:          NotScheduled,
+		ReplayProtectionHeight: NotScheduled,
+		AliasTransferHeight:    NotScheduled,
+		AssetHeight:            NotScheduled,
+	}
+}
+
+//Fork is a named rule change and its activation height
+type Fork struct {
+	Name   string
+	Height uint64
+}
+
+//Forks lists the forks of the schedule in the order they were introduced, new forks are appended
+func (forks *ForkConfig) Forks() []Fork {
+	return []Fork{
+		{"contractStorage", forks.ContractStorageHeight},
+		{"systemLog", forks.SystemLogHeight},
+		{"multiSig", forks.MultiSigHeight},
+		{"batchTransfer", forks.BatchTransferHeight},
+		{"sponsor", forks.SponsorHeight},
+		{"replayProtection", forks.ReplayProtectionHeight},
+		{"aliasTransfer", forks.AliasTransferHeight},
+		{"asset", forks.AssetHeight},
+	}
+}
+
+//Heights returns the activation height of every fork by name, the form in which the schedule is stored
+func (forks *ForkConfig) Heights() map[string]uint64 {
+	heights := map[string]uint64{}
+	for _, fork := range forks.Forks() {
+		heights[fork.Name] = fork.Height
+	}
+	return heights
+}
+
+//Rules are the rules in force for one block, derived from the fork schedule by height
+type Rules struct {
+	Height             uint64
+	IsContractStorage  bool
+	IsSystemLog        bool
+	IsMultiSig         bool
+	IsBatchTransfer    bool
+	IsSponsor          bool
+	IsReplayProtection bool
+	IsAliasTransfer    bool
+	IsAsset            bool
+}
+
+//Rules returns the rules of the block at height
+func (forks *ForkConfig) Rules(height uint64) *Rules {
+	return &Rules{
+		Height:             height,
+		IsContractStorage:  height >= forks.ContractStorageHeight,
+		IsSystemLog:        height >= forks.SystemLogHeight,
+		IsMultiSig:         height >= forks.MultiSigHeight,
+		IsBatchTransfer:    height >= forks.BatchTransferHeight,
+		IsSponsor:          height >= forks.SponsorHeight,
+		IsReplayProtection: height >= forks.ReplayProtectionHeight,
+		IsAliasTransfer:    height >= forks.AliasTransferHeight,
+		IsAsset:            height >= forks.AssetHeight,
+	}
+}
+
+//ForkCompatError is returned when a fork schedule moves a fork the chain head has already passed
+type ForkCompatError struct {
+	Name         string
+	StoredHeight uint64 //NotScheduled if the stored schedule did not know the fork
+	NewHeight    uint64
+	Head         uint64
+	//RewindTo is the highest block valid under both schedules
+	RewindTo uint64
+}
+
+func (err *ForkCompatError) Error() string {
+	stored := "not scheduled"
+	if err.StoredHeight != NotScheduled {
+		stored = fmt.Sprintf("height %d", err.StoredHeight)
+	}
+	return fmt.Sprintf("fork %s moved from %s to height %d, but the chain head %d already passed it, the blocks above %d must be rewound",
+		err.Name, stored, err.NewHeight, err.Head, err.RewindTo)
+}
+
+//CheckCompatible checks whether a chain with head built under the stored schedule can continue under forks.
+//A fork missing from the stored schedule was never active. Every block above genesis up to head must get
+//the same rules from both schedules, genesis itself is not executed.
+func (forks *ForkConfig) CheckCompatible(stored map[string]uint64, head uint64) *ForkCompatError {
+	if head == 0 {
+		return nil
+	}
+	for _, fork := range forks.Forks() {
+		storedHeight, ok := stored[fork.Name]
+		if !ok {
+			storedHeight = NotScheduled
+		}
+		if storedHeight == fork.Height {
+			continue
+		}
+		lower := storedHeight
+		if fork.Height < lower {
+			lower = fork.Height
+		}
+		if lower > head {
+			continue
+		}
+		//the block at the lower height is the first one whose rules differ
+		rewindTo := uint64(0)
+		if lower > 0 {
+			rewindTo = lower - 1
+		}
+		return &ForkCompatError{
+			Name:         fork.Name,
+			StoredHeight: storedHeight,
+			NewHeight:    fork.Height,
+			Head:         head,
+			RewindTo:     rewindTo,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package params
+
+import (
+	"math"
+	"testing"
+)
+
+func TestForkRules(t *testing.T) {
+	forks := &ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 20}
+	tests := []struct {
+		height          uint64
+		contractStorage bool
+		systemLog       bool
+	}{
+		{0, false, false},
+		{9, false, false},
+		{10, true, false},
+		{19, true, false},
+		{20, true, true},
+	}
+	for _, test := range tests {
+		rules := forks.Rules(test.height)
+		if rules.Height != test.height || rules.IsContractStorage != test.contractStorage || rules.IsSystemLog != test.systemLog {
+			t.Fatalf("height %d: unexpected rules %+v", test.height, rules)
+		}
+	}
+}
+
+func TestForkCheckCompatible(t *testing.T) {
+	stored := (&ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 20}).Heights()
+	//a schedule stored before the system log fork was introduced
+	withoutSystemLog := (&ForkConfig{ContractStorageHeight: 10}).Heights()
+	delete(withoutSystemLog, "systemLog")
+	tests := []struct {
+		forks    ForkConfig
+		stored   map[string]uint64
+		head     uint64
+		name     string
+		rewindTo uint64
+	}{
+		{ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 20}, stored, 100, "", 0},
+		//moving a fork the head has not reached yet
+		{ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 30}, stored, 19, "", 0},
+		{ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 30}, stored, 20, "systemLog", 19},
+		{ForkConfig{ContractStorageHeight: 5, SystemLogHeight: 20}, stored, 7, "contractStorage", 4},
+		//only genesis, nothing was executed
+		{ForkConfig{}, stored, 0, "", 0},
+		{ForkConfig{}, stored, 1, "contractStorage", 0},
+		//a fork the stored schedule did not know was never active
+		{ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 50}, withoutSystemLog, 49, "", 0},
+		{ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 50}, withoutSystemLog, 50, "systemLog", 49},
+	}
+	for i, test := range tests {
+		err := test.forks.CheckCompatible(test.stored, test.head)
+		if test.name == "" {
+			if err != nil {
+				t.Fatalf("case %d: unexpected error %v", i, err)
+			}
+			continue
+		}
+		if err == nil || err.Name != test.name || err.RewindTo != test.rewindTo {
+			t.Fatalf("case %d: expect conflict on %s rewinding to %d, got %v", i, test.name, test.rewindTo, err)
+		}
+	}
+
+	err := (&ForkConfig{SystemLogHeight: 3}).CheckCompatible(map[string]uint64{"contractStorage": 0}, 5)
+	if err == nil || err.StoredHeight != math.MaxUint64 {
+		t.Fatalf("expect the unknown fork to be reported as not scheduled, got %v", err)
+	}
+}
+
+func TestPublicForkSchedules(t *testing.T) {
+	//no activation height has been agreed on for the public networks, none of the forks may activate there
+	for name, forks := range map[string]ForkConfig{"mainnet": MainnetForks, "testnet": TestnetForks} {
+		for _, fork := range forks.Forks() {
+			if fork.Height != NotScheduled {
+				t.Fatalf("%s: fork %s scheduled at %d", name, fork.Name, fork.Height)
+			}
+		}
+		if rules := forks.Rules(math.MaxUint64 - 1); rules.IsSystemLog || rules.IsReplayProtection {
+			t.Fatalf("%s: expect no fork active", name)
+		}
+		if id := forks.ForkID([]byte{1}, 1); id.Next != 0 {
+			t.Fatalf("%s: expect no next fork announced, got %d", name, id.Next)
+		}
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/chain/transactions"
 	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/pkgs/evm/vm"
 
@@ -14,10 +16,12 @@ import (
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1/schnorr"
 	"github.com/drep-project/DREP-Chain/database"
 	"github.com/drep-project/DREP-Chain/pkgs/accounts/addrgenerator"
 	"github.com/drep-project/DREP-Chain/pkgs/evm"
 	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
 )
 
 /*
@@ -175,11 +179,10 @@ func (accountapi *AccountApi) Transfer(from crypto.CommonAddress, to crypto.Comm
 
 	nonce := accountapi.poolQuery.GetTransactionCount(&from)
 	tx := types.NewTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -206,11 +209,10 @@ func (accountapi *AccountApi) Transfer(from crypto.CommonAddress, to crypto.Comm
 func (accountapi *AccountApi) TransferWithNonce(from crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big, data common.Bytes, nonce uint64) (string, error) {
 	//nonce := accountapi.poolQuery.GetTransactionCount(&from)
 	tx := types.NewTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -235,11 +237,10 @@ response:
 func (accountapi *AccountApi) SetAlias(srcAddr crypto.CommonAddress, alias string, gasprice, gaslimit *common.Big) (string, error) {
 	nonce := accountapi.poolQuery.GetTransactionCount(&srcAddr)
 	t := types.NewAliasTransaction(alias, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&srcAddr, t.TxHash().Bytes())
+	err := accountapi.signTransaction(&srcAddr, t)
 	if err != nil {
 		return "", err
 	}
-	t.Sig = sig
 	fmt.Println(hex.EncodeToString(t.AsPersistentMessage()))
 	fmt.Println(t.TxHash().String())
 	err = accountapi.messageBroadCastor.SendTransaction(t, true)
@@ -249,6 +250,60 @@ func (accountapi *AccountApi) SetAlias(srcAddr crypto.CommonAddress, alias strin
 	return t.TxHash().String(), nil
 }
 
+/*
+ name: transferAlias
+ usage: Give the alias of an address to another address which owns no alias
+ params:
+	1. address owning the alias
+	2. address receiving the alias
+	3. alias
+	4. gas price
+	5. gas limit
+ return: transaction hash
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_transferAlias","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486","AAAAA","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+response:
+	{"jsonrpc":"2.0","id":1,"result":"0x5adb248f2943e12fb91c140bd3d0df6237712061e9abae97345b0869c3daa749"}
+*/
+func (accountapi *AccountApi) TransferAlias(srcAddr crypto.CommonAddress, to crypto.CommonAddress, alias string, gasprice, gaslimit *common.Big) (string, error) {
+	nonce := accountapi.poolQuery.GetTransactionCount(&srcAddr)
+	tx := types.NewAliasTransferTransaction(to, alias, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	return accountapi.sendTransaction(&srcAddr, tx)
+}
+
+/*
+ name: releaseAlias
+ usage: Free the alias of an address, anyone may set it afterwards
+ params:
+	1. address owning the alias
+	2. alias
+	3. gas price
+	4. gas limit
+ return: transaction hash
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_releaseAlias","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","AAAAA","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+response:
+	{"jsonrpc":"2.0","id":1,"result":"0x5adb248f2943e12fb91c140bd3d0df6237712061e9abae97345b0869c3daa749"}
+*/
+func (accountapi *AccountApi) ReleaseAlias(srcAddr crypto.CommonAddress, alias string, gasprice, gaslimit *common.Big) (string, error) {
+	nonce := accountapi.poolQuery.GetTransactionCount(&srcAddr)
+	tx := types.NewAliasReleaseTransaction(alias, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	return accountapi.sendTransaction(&srcAddr, tx)
+}
+
+//sendTransaction signs tx with the key of from and broadcasts it
+func (accountapi *AccountApi) sendTransaction(from *crypto.CommonAddress, tx *types.Transaction) (string, error) {
+	err := accountapi.signTransaction(from, tx)
+	if err != nil {
+		return "", err
+	}
+	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
 /*
  name: VoteCredit
  usage: vote credit to candidate
@@ -266,11 +321,10 @@ func (accountapi *AccountApi) SetAlias(srcAddr crypto.CommonAddress, alias strin
 func (accountapi *AccountApi) VoteCredit(from crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big) (string, error) {
 	nonce := accountapi.poolQuery.GetTransactionCount(&from)
 	tx := types.NewVoteTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -296,11 +350,10 @@ func (accountapi *AccountApi) VoteCredit(from crypto.CommonAddress, to crypto.Co
 func (accountapi *AccountApi) CancelVoteCredit(from crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big) (string, error) {
 	nonce := accountapi.poolQuery.GetTransactionCount(&from)
 	tx := types.NewCancelVoteTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -335,11 +388,10 @@ func (accountapi *AccountApi) CandidateCredit(from crypto.CommonAddress, amount,
 
 	nonce := accountapi.poolQuery.GetTransactionCount(&from)
 	tx := types.NewCandidateTransaction((*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce, []byte(data))
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err = accountapi.signTransaction(&from, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -365,11 +417,10 @@ func (accountapi *AccountApi) CandidateCredit(from crypto.CommonAddress, amount,
 func (accountapi *AccountApi) CancelCandidateCredit(from crypto.CommonAddress, amount, gasprice, gaslimit *common.Big) (string, error) {
 	nonce := accountapi.poolQuery.GetTransactionCount(&from)
 	tx := types.NewCancleCandidateTransaction((*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -395,18 +446,17 @@ func (accountapi *AccountApi) ReadContract(from, to crypto.CommonAddress, input
 	tx := types.NewTransaction(to, new(big.Int).SetUint64(0), &big.Int{}, new(big.Int).SetUint64(params.MinGasLimit), 0)
 	tx.Data.Data = input
 
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, tx)
 	if err != nil {
 		return nil, err
 	}
-	tx.Sig = sig
 
-	trieStore, err := store.TrieStoreFromStore(accountapi.databaseService.LevelDb(), header.StateRoot)
+	trieStore, err := store.TrieStoreFromDatabase(accountapi.databaseService.LevelDb(), accountapi.databaseService.StateDb(), header.StateRoot)
 	if err != nil {
 		return nil, err
 	}
 
-	ret, err := accountapi.EvmService.Call(trieStore, tx, header)
+	ret, err := accountapi.EvmService.Call(trieStore, tx, header, accountapi.accountService.Chain.GetConfig().Rules(header.Height))
 	fmt.Println(string(common.Bytes(ret)))
 	fmt.Println(new(big.Int).SetBytes(ret))
 	fmt.Println(common.Bytes(ret))
@@ -438,18 +488,18 @@ func (accountapi *AccountApi) EstimateGas(from crypto.CommonAddress, amount *com
 	tx := types.NewTransaction(*to, amount.ToInt(), new(big.Int).SetUint64(blockmgr.DefaultGasPrice), new(big.Int).SetUint64(params.MinGasLimit), 0)
 	tx.Data.Data = data
 
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, tx)
 	if err != nil {
 		return 0, err
 	}
-	tx.Sig = sig
 
-	trieStore, err := store.TrieStoreFromStore(accountapi.databaseService.LevelDb(), header.StateRoot)
+	trieStore, err := store.TrieStoreFromDatabase(accountapi.databaseService.LevelDb(), accountapi.databaseService.StateDb(), header.StateRoot)
 	if err != nil {
 		return 0, err
 	}
 
-	state := vm.NewState(trieStore, header.Height)
+	rules := accountapi.accountService.Chain.GetConfig().Rules(header.Height)
+	state := vm.NewState(trieStore, rules)
 
 	gl := new(big.Int).SetUint64(params.MinGasLimit)
 	var (
@@ -457,7 +507,7 @@ func (accountapi *AccountApi) EstimateGas(from crypto.CommonAddress, amount *com
 	)
 
 	for {
-		_, _, _, fail, err = accountapi.EvmService.Eval(state, tx, header, gl.Uint64(), amount.ToInt())
+		_, _, _, fail, err = accountapi.EvmService.Eval(state, tx, header, rules, gl.Uint64(), amount.ToInt())
 		if err != nil || fail {
 			if err == vm.ErrCodeStoreOutOfGas || err == vm.ErrOutOfGas {
 				gl = gl.Add(gl, new(big.Int).SetUint64(1))
@@ -491,11 +541,10 @@ func (accountapi *AccountApi) EstimateGas(from crypto.CommonAddress, amount *com
 func (accountapi *AccountApi) ExecuteContract(from crypto.CommonAddress, to crypto.CommonAddress, input common.Bytes, gasprice, gaslimit *common.Big) (string, error) {
 	nonce := accountapi.poolQuery.GetTransactionCount(&from)
 	t := types.NewCallContractTransaction(to, input, &big.Int{}, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, t.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, t)
 	if err != nil {
 		return "", err
 	}
-	t.Sig = sig
 	accountapi.messageBroadCastor.SendTransaction(t, true)
 	return t.TxHash().String(), nil
 }
@@ -517,11 +566,10 @@ func (accountapi *AccountApi) ExecuteContract(from crypto.CommonAddress, to cryp
 func (accountapi *AccountApi) CreateCode(from crypto.CommonAddress, byteCode common.Bytes, gasprice, gaslimit *common.Big) (string, error) {
 	nonce := accountapi.poolQuery.GetTransactionCount(&from)
 	t := types.NewContractTransaction(byteCode, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, t.TxHash().Bytes())
+	err := accountapi.signTransaction(&from, t)
 	if err != nil {
 		return "", err
 	}
-	t.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(t, true)
 	if err != nil {
 		return "", err
@@ -685,6 +733,556 @@ func (accountapi *AccountApi) GetKeyStores() string {
 	return "path of keystores is: " + accountapi.Wallet.config.KeyStoreDir
 }
 
+/*
+ name: createMultiSigAccount
+ usage: Create an account spent by a threshold of the given keys, the account is funded with amount
+ params:
+	1. The address creating the account
+	2. threshold, the number of keys which must sign a transaction of the account
+	3. public keys of the account
+	4. amount
+	5. gas price
+	6. gas limit
+ return: transaction hash and address of the new account
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_createMultiSigAccount","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5",2,["0x03177b8e4ef31f4f801ce00260db1b04cc501287e828692a404fdbc46c7ad6ff26","0x02e8a4b8f9d5e3a0f5c3c06e5ed4a31b4ab1e1a6a5a3d0b0e2a6a4f6f1c0b5a9e3","0x0350a6a3ee7f9a6c6a5cd63ee6c9c3d6f6a0b5d4e86c3f63ccd2e6f0a3a2b1c0d9"],"0x111","0x110","0x30000"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":{"txHash":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e","account":"0x9c5f7e4a5b0e7b5c9d0a9cdb0cb0f3d2e1b5a4c7"}}
+*/
+func (accountapi *AccountApi) CreateMultiSigAccount(from crypto.CommonAddress, threshold uint64, pubkeys []*secp256k1.PublicKey, amount, gasprice, gaslimit *common.Big) (*RpcMultiSigCreation, error) {
+	account := &types.MultiSigAccount{Threshold: threshold, Pubkeys: pubkeys}
+	if err := account.Check(); err != nil {
+		return nil, err
+	}
+	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+	tx, err := types.NewCreateMultiSigTransaction(account, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	if err != nil {
+		return nil, err
+	}
+	err = accountapi.signTransaction(&from, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
+	if err != nil {
+		return nil, err
+	}
+	return &RpcMultiSigCreation{
+		TxHash:  tx.TxHash().String(),
+		Account: transactions.MultiSigAddress(&from, nonce),
+	}, nil
+}
+
+/*
+ name: newMultiSigTransaction
+ usage: Build a transfer of a multisig account, it is passed to the signers and sent with blockmgr_sendRawTransaction once signed
+ params:
+	1. The multisig account
+	2. Recipient's address
+	3. amount
+	4. gas price
+	5. gas limit, it must cover the signatures added later
+	6. commit
+ return: the unsigned transaction
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_newMultiSigTransaction","params":["0x9c5f7e4a5b0e7b5c9d0a9cdb0cb0f3d2e1b5a4c7","0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x111","0x110","0x30000",""], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"}
+*/
+func (accountapi *AccountApi) NewMultiSigTransaction(account crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big, data common.Bytes) (common.Bytes, error) {
+	multiSigAccount, err := accountapi.getMultiSigAccount(&account)
+	if err != nil {
+		return nil, err
+	}
+	nonce := accountapi.poolQuery.GetTransactionCount(&account)
+	tx := types.NewTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	tx.Data.Data = data
+	err = accountapi.prepareTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.SetMultiSigProof(types.NewMultiSigProof(account, len(multiSigAccount.Pubkeys)))
+	if err != nil {
+		return nil, err
+	}
+	return binary.Marshal(tx)
+}
+
+/*
+ name: signMultiSigTransaction
+ usage: Add the signature of a key of the multisig account to the transaction
+ params:
+	1. The address of the signing key
+	2. The transaction of the multisig account
+ return: the transaction with the signature
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_signMultiSigTransaction","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"}
+*/
+func (accountapi *AccountApi) SignMultiSigTransaction(signer crypto.CommonAddress, txbytes common.Bytes) (common.Bytes, error) {
+	tx, proof, index, err := accountapi.multiSigSigner(&signer, txbytes)
+	if err != nil {
+		return nil, err
+	}
+	if proof.IsPartial() {
+		return nil, ErrMixedMultiSig
+	}
+	sig, err := accountapi.Wallet.Sign(&signer, tx.SigHash())
+	if err != nil {
+		return nil, err
+	}
+	return accountapi.addMultiSig(tx, proof, index, sig)
+}
+
+/*
+ name: multiSigNonce
+ usage: Get the public nonce of a key for the aggregated signature of a multisig transaction, the nonces of all signers are passed to account_partialSignMultiSigTransaction
+ params:
+	1. The address of the signing key
+	2. The transaction of the multisig account
+ return: public nonce
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_multiSigNonce","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x03177b8e4ef31f4f801ce00260db1b04cc501287e828692a404fdbc46c7ad6ff26"}
+*/
+func (accountapi *AccountApi) MultiSigNonce(signer crypto.CommonAddress, txbytes common.Bytes) (*secp256k1.PublicKey, error) {
+	tx, _, _, err := accountapi.multiSigSigner(&signer, txbytes)
+	if err != nil {
+		return nil, err
+	}
+	_, pubNonce, err := accountapi.multiSigNoncePair(&signer, tx)
+	return pubNonce, err
+}
+
+/*
+ name: partialSignMultiSigTransaction
+ usage: Add the partial schnorr signature of a key to the transaction, every signer must sign with the same nonces, once the threshold is reached account_mergeMultiSigTransactions combines them
+ params:
+	1. The address of the signing key
+	2. The transaction of the multisig account
+	3. public nonces of all signers, including the one of this key
+ return: the transaction with the partial signature
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_partialSignMultiSigTransaction","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564",["0x03177b8e4ef31f4f801ce00260db1b04cc501287e828692a404fdbc46c7ad6ff26","0x02e8a4b8f9d5e3a0f5c3c06e5ed4a31b4ab1e1a6a5a3d0b0e2a6a4f6f1c0b5a9e3"]], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"}
+*/
+func (accountapi *AccountApi) PartialSignMultiSigTransaction(signer crypto.CommonAddress, txbytes common.Bytes, nonces []*secp256k1.PublicKey) (common.Bytes, error) {
+	tx, proof, index, err := accountapi.multiSigSigner(&signer, txbytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(proof.Sigs) > 0 && !proof.IsPartial() {
+		return nil, ErrMixedMultiSig
+	}
+	privNonce, pubNonce, err := accountapi.multiSigNoncePair(&signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	others := []*secp256k1.PublicKey{}
+	for _, nonce := range nonces {
+		if !nonce.IsEqual(pubNonce) {
+			others = append(others, nonce)
+		}
+	}
+	if len(others) != len(nonces)-1 || len(others) == 0 {
+		return nil, ErrMultiSigNonces
+	}
+	account, err := accountapi.getMultiSigAccount(&proof.Account)
+	if err != nil {
+		return nil, err
+	}
+	privkey, err := accountapi.Wallet.DumpPrivateKey(&signer)
+	if err != nil {
+		return nil, err
+	}
+	//the key signs weighted by its coefficient in the aggregated key of the account
+	sig, err := schnorr.PartialSign(secp256k1.S256(), tx.SigHash(), account.SigningKey(index, privkey), privNonce, schnorr.CombinePubkeys(others))
+	if err != nil {
+		return nil, err
+	}
+	return accountapi.addMultiSig(tx, proof, index, sig.Serialize())
+}
+
+/*
+ name: mergeMultiSigTransactions
+ usage: Collect the signatures of copies of a multisig transaction, partial schnorr signatures are combined once the threshold is reached
+ params:
+	1. copies of the transaction signed by different keys
+ return: the transaction with all signatures
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_mergeMultiSigTransactions","params":[["0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564","0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"]], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"}
+*/
+func (accountapi *AccountApi) MergeMultiSigTransactions(txsbytes []common.Bytes) (common.Bytes, error) {
+	if len(txsbytes) == 0 {
+		return nil, ErrNothingToMerge
+	}
+	var tx *types.Transaction
+	proofs := make([]*types.MultiSigProof, len(txsbytes))
+	for i, txbytes := range txsbytes {
+		copyTx := &types.Transaction{}
+		err := binary.Unmarshal(txbytes, copyTx)
+		if err != nil {
+			return nil, err
+		}
+		if tx != nil && !bytes.Equal(copyTx.SigHash(), tx.SigHash()) {
+			return nil, ErrMultiSigTxMismatch
+		}
+		tx = copyTx
+		proofs[i], err = copyTx.MultiSigProof()
+		if err != nil {
+			return nil, err
+		}
+	}
+	proof, err := types.MergeMultiSigProofs(proofs)
+	if err != nil {
+		return nil, err
+	}
+	if proof.IsPartial() {
+		account, err := accountapi.getMultiSigAccount(&proof.Account)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(proof.Sigs)) >= account.Threshold {
+			err = proof.CombinePartialSigs()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	err = tx.SetMultiSigProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	return binary.Marshal(tx)
+}
+
+/*
+ name: batchTransfer
+ usage: Pay a list of recipients in one transaction under one nonce, either all of them are paid or none
+ params:
+	1. The address at which the transfer was initiated
+	2. payouts, a list of recipient address and amount
+	3. gas price
+	4. gas limit, every payout costs 9000 gas on top of a transfer
+ return: transaction hash
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_batchTransfer","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5",[{"to":"0x8a8e541ddd1272d53729164c70197221a3c27486","amount":"0x111"},{"to":"0x9c5f7e4a5b0e7b5c9d0a9cdb0cb0f3d2e1b5a4c7","amount":"0x222"}],"0x110","0x30000"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (accountapi *AccountApi) BatchTransfer(from crypto.CommonAddress, payouts []types.Payout, gasprice, gaslimit *common.Big) (string, error) {
+	if gasprice.ToInt().Uint64() < blockmgr.DefaultGasPrice {
+		gasprice.SetMathBig(*new(big.Int).SetUint64(blockmgr.DefaultGasPrice))
+	}
+
+	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+	tx, err := types.NewBatchTransferTransaction(payouts, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	if err != nil {
+		return "", err
+	}
+	err = accountapi.signTransaction(&from, tx)
+	if err != nil {
+		return "", err
+	}
+	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+/*
+ name: newSponsoredTransaction
+ usage: Build and sign a transaction whose gas is paid by a sponsor, the sender only needs the amount. It is passed to the sponsor for account_sponsorTransaction
+ params:
+	1. The address at which the transaction was initiated
+	2. Recipient's address or contract address
+	3. amount
+	4. gas price
+	5. gas limit
+	6. contract call input, empty for a transfer
+ return: the signed transaction without the signature of the sponsor
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_newSponsoredTransaction","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486","0x0","0x110","0x30000","0x6d4ce63c"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"}
+*/
+func (accountapi *AccountApi) NewSponsoredTransaction(from crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big, data common.Bytes) (common.Bytes, error) {
+	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+	var tx *types.Transaction
+	if len(data) > 0 {
+		tx = types.NewCallContractTransaction(to, data, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	} else {
+		tx = types.NewTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	}
+	err := accountapi.signTransaction(&from, tx)
+	if err != nil {
+		return nil, err
+	}
+	return binary.Marshal(tx)
+}
+
+/*
+ name: sponsorTransaction
+ usage: Co-sign a transaction as its sponsor, the sponsor pays its gas. The result is sent with blockmgr_sendRawTransaction
+ params:
+	1. The address of the sponsor
+	2. The transaction signed by the sender
+ return: the transaction with the signature of the sponsor
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_sponsorTransaction","params":["0x9c5f7e4a5b0e7b5c9d0a9cdb0cb0f3d2e1b5a4c7","0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"}
+*/
+func (accountapi *AccountApi) SponsorTransaction(sponsor crypto.CommonAddress, txbytes common.Bytes) (common.Bytes, error) {
+	tx := &types.Transaction{}
+	err := binary.Unmarshal(txbytes, tx)
+	if err != nil {
+		return nil, err
+	}
+	from, err := tx.From()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := accountapi.Wallet.Sign(&sponsor, tx.SponsorHash(from))
+	if err != nil {
+		return nil, err
+	}
+	err = tx.SetSponsorSig(sig)
+	if err != nil {
+		return nil, err
+	}
+	return binary.Marshal(tx)
+}
+
+/*
+ name: setTxValidity
+ usage: Limit the blocks which may include the transactions the wallet signs from now on, zero means no bound. It takes effect with the replay protection fork
+ params:
+	1. number of blocks following the chain head
+	2. number of seconds from the signing
+ return: null
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_setTxValidity","params":[100, 3600], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (accountapi *AccountApi) SetTxValidity(blocks uint64, seconds int64) error {
+	if seconds < 0 {
+		return ErrTxValidity
+	}
+	accountapi.accountService.Config.TxValidBlocks = blocks
+	accountapi.accountService.Config.TxValidSeconds = seconds
+	return nil
+}
+
+/*
+ name: issueAsset
+ usage: Issue a native asset, its whole supply is credited to the issuer. The issuer burns a fee of 100000 DREP for a
+	symbol of up to 3 letters, 50000 for 4, 20000 for 5, 10000 for 6 and 5000 for a longer one
+ params:
+	1. address of the issuer
+	2. symbol, 1 to 12 upper case letters and digits
+	3. supply
+	4. address allowed to mint more of the asset, empty for a fixed supply
+	5. gas price
+	6. gas limit, issuing costs 32000 gas on top of a transfer
+ return: transaction hash
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_issueAsset","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","POINT","0xf4240","0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x110","0x30000"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (accountapi *AccountApi) IssueAsset(from crypto.CommonAddress, symbol string, supply *common.Big, mintAuthority *crypto.CommonAddress, gasprice, gaslimit *common.Big) (string, error) {
+	authority := crypto.CommonAddress{}
+	if mintAuthority != nil {
+		authority = *mintAuthority
+	}
+	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+	tx, err := types.NewIssueAssetTransaction(symbol, supply.ToInt(), authority, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	if err != nil {
+		return "", err
+	}
+	return accountapi.sendTransaction(&from, tx)
+}
+
+/*
+ name: transferAsset
+ usage: Pay a native asset
+ params:
+	1. address of the payer
+	2. address of the recipient
+	3. symbol of the asset
+	4. amount
+	5. gas price
+	6. gas limit
+ return: transaction hash
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_transferAsset","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486","POINT","0x64","0x110","0x30000"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (accountapi *AccountApi) TransferAsset(from, to crypto.CommonAddress, symbol string, amount, gasprice, gaslimit *common.Big) (string, error) {
+	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+	tx, err := types.NewAssetTransferTransaction(to, symbol, amount.ToInt(), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	if err != nil {
+		return "", err
+	}
+	return accountapi.sendTransaction(&from, tx)
+}
+
+/*
+ name: mintAsset
+ usage: Raise the supply of a native asset, only its mint authority may
+ params:
+	1. address of the mint authority
+	2. address credited with the minted amount
+	3. symbol of the asset
+	4. amount
+	5. gas price
+	6. gas limit
+ return: transaction hash
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_mintAsset","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486","POINT","0x64","0x110","0x30000"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (accountapi *AccountApi) MintAsset(from, to crypto.CommonAddress, symbol string, amount, gasprice, gaslimit *common.Big) (string, error) {
+	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+	tx, err := types.NewAssetMintTransaction(to, symbol, amount.ToInt(), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	if err != nil {
+		return "", err
+	}
+	return accountapi.sendTransaction(&from, tx)
+}
+
+/*
+ name: burnAsset
+ usage: Destroy a native asset held by the address, lowering its supply
+ params:
+	1. address holding the asset
+	2. symbol of the asset
+	3. amount
+	4. gas price
+	5. gas limit
+ return: transaction hash
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_burnAsset","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","POINT","0x64","0x110","0x30000"], "id": 3}' -H "Content-Type:application/json"
+response:
+	{"jsonrpc":"2.0","id":3,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (accountapi *AccountApi) BurnAsset(from crypto.CommonAddress, symbol string, amount, gasprice, gaslimit *common.Big) (string, error) {
+	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+	tx, err := types.NewAssetBurnTransaction(symbol, amount.ToInt(), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	if err != nil {
+		return "", err
+	}
+	return accountapi.sendTransaction(&from, tx)
+}
+
+//signTransaction signs tx with the key of from for the chain of the node. With the replay protection fork active
+//the transaction gets the validity window set in the config of the wallet.
+func (accountapi *AccountApi) signTransaction(from *crypto.CommonAddress, tx *types.Transaction) error {
+	err := accountapi.prepareTransaction(tx)
+	if err != nil {
+		return err
+	}
+	sig, err := accountapi.Wallet.Sign(from, tx.SigHash())
+	if err != nil {
+		return err
+	}
+	return tx.SetSenderSig(sig)
+}
+
+//prepareTransaction sets the chain id and the validity window of tx before it is signed
+func (accountapi *AccountApi) prepareTransaction(tx *types.Transaction) error {
+	chainService := accountapi.accountService.Chain
+	tx.Data.ChainId = chainService.ChainID()
+	height := chainService.BestChain().Height()
+	config := accountapi.accountService.Config
+	if !chainService.GetConfig().Rules(height+1).IsReplayProtection || (config.TxValidBlocks == 0 && config.TxValidSeconds == 0) {
+		return nil
+	}
+	window := &types.ValidityWindow{}
+	if config.TxValidBlocks != 0 {
+		window.UntilHeight = height + config.TxValidBlocks
+	}
+	if config.TxValidSeconds != 0 {
+		window.UntilTime = time.Now().Unix() + config.TxValidSeconds
+	}
+	return tx.SetValidityWindow(window)
+}
+
+//getMultiSigAccount reads the multisig account at addr from the state of the chain head
+func (accountapi *AccountApi) getMultiSigAccount(addr *crypto.CommonAddress) (*types.MultiSigAccount, error) {
+	header := accountapi.accountService.Chain.GetCurrentHeader()
+	trieStore, err := store.TrieStoreFromDatabase(accountapi.databaseService.LevelDb(), accountapi.databaseService.StateDb(), header.StateRoot)
+	if err != nil {
+		return nil, err
+	}
+	account, err := trieStore.GetMultiSigAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, transactions.ErrMultiSigAccountNotExist
+	}
+	return account, nil
+}
+
+//multiSigSigner decodes a multisig transaction and finds the position of the key of signer in its account
+func (accountapi *AccountApi) multiSigSigner(signer *crypto.CommonAddress, txbytes common.Bytes) (*types.Transaction, *types.MultiSigProof, int, error) {
+	tx := &types.Transaction{}
+	err := binary.Unmarshal(txbytes, tx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	proof, err := tx.MultiSigProof()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	account, err := accountapi.getMultiSigAccount(&proof.Account)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	node, err := accountapi.Wallet.GetAccountByAddress(signer)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	index := account.KeyIndex(node.PrivateKey.PubKey())
+	if index < 0 {
+		return nil, nil, 0, ErrNotMultiSigKey
+	}
+	return tx, proof, index, nil
+}
+
+//multiSigNoncePair derives the nonce of signer for tx, it is deterministic so the signer needs to keep no state
+//between the rounds
+func (accountapi *AccountApi) multiSigNoncePair(signer *crypto.CommonAddress, tx *types.Transaction) (*secp256k1.PrivateKey, *secp256k1.PublicKey, error) {
+	privkey, err := accountapi.Wallet.DumpPrivateKey(signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schnorr.GenerateNoncePair(secp256k1.S256(), tx.SigHash(), privkey, nil, schnorr.Sha256VersionStringRFC6979)
+}
+
+func (accountapi *AccountApi) addMultiSig(tx *types.Transaction, proof *types.MultiSigProof, index int, sig []byte) (common.Bytes, error) {
+	err := proof.AddSig(index, sig)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.SetMultiSigProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	return binary.Marshal(tx)
+}
+
 type RpcAddresses struct {
 	BtcAddress      string
 	EthAddress      string
@@ -701,3 +1299,8 @@ type RpcAccount struct {
 	Addr   *crypto.CommonAddress
 	Pubkey string
 }
+
+type RpcMultiSigCreation struct {
+	TxHash  string               `json:"txHash"`
+	Account crypto.CommonAddress `json:"account"`
+}
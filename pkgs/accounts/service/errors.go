@@ -3,14 +3,19 @@ package service
 import "errors"
 
 var (
-	ErrExistKeystore   = errors.New("exist keystore")
-	ErrClosedWallet    = errors.New("wallet is not open")
-	ErrLockedWallet    = errors.New("wallet is already locked")
-	ErrNotAHash        = errors.New("msg is not a hash")
-	ErrAlreadyUnLocked = errors.New("wallet is already unlocked")
-	ErrExistKey        = errors.New("privkey is exist")
-	ErrMissingKeystore = errors.New("not found keystore")
-	ErrAccountExist    = errors.New("addr is not exist")
-	ErrMissingPath = errors.New("not found path")
-
+	ErrExistKeystore      = errors.New("exist keystore")
+	ErrClosedWallet       = errors.New("wallet is not open")
+	ErrLockedWallet       = errors.New("wallet is already locked")
+	ErrNotAHash           = errors.New("msg is not a hash")
+	ErrAlreadyUnLocked    = errors.New("wallet is already unlocked")
+	ErrExistKey           = errors.New("privkey is exist")
+	ErrMissingKeystore    = errors.New("not found keystore")
+	ErrAccountExist       = errors.New("addr is not exist")
+	ErrMissingPath        = errors.New("not found path")
+	ErrNotMultiSigKey     = errors.New("address is not a key of the multisig account")
+	ErrMixedMultiSig      = errors.New("compact and partial schnorr signatures can not be mixed")
+	ErrMultiSigNonces     = errors.New("nonces of the signers missing")
+	ErrMultiSigTxMismatch = errors.New("copies of different transactions")
+	ErrNothingToMerge     = errors.New("no transaction to merge")
+	ErrTxValidity         = errors.New("validity of transactions can not be negative")
 )
@@ -5,4 +5,8 @@ type Config struct {
 	Type        string `json:"type,omitempty"`
 	KeyStoreDir string `json:"keyStoreDir,omitempty"`
 	Password    string `json:"password,omitempty"`
+	//Transactions signed by the wallet may be included in the next TxValidBlocks blocks or TxValidSeconds
+	//seconds only, zero means no bound
+	TxValidBlocks  uint64 `json:"txValidBlocks,omitempty"`
+	TxValidSeconds int64  `json:"txValidSeconds,omitempty"`
 }
@@ -85,6 +85,6 @@ func (blockMultiSigValidator *BlockMultiSigValidator) ExecuteBlock(context *bloc
 		return fmt.Errorf("executeBlock producer num:%d != multisig num:%d", len(producers), len(multiSig.Bitmap))
 	}
 
-	calculator := NewRewardCalculator(context.TrieStore, multiSig, producers, context.GasFee, context.Block.Header.Height)
-	return calculator.AccumulateRewards(context.Block.Header.Height)
+	calculator := NewRewardCalculator(context.TrieStore, multiSig, producers, context.GasFee, context.Rules)
+	return calculator.AccumulateRewards()
 }
@@ -112,7 +112,7 @@ func (bftConsensus *BftConsensus) loadProducers(height uint64, topN int) ([]type
 	if err != nil {
 		return nil, err
 	}
-	trie, err := store.TrieStoreFromStore(bftConsensus.DbService.LevelDb(), block.Header.StateRoot)
+	trie, err := store.TrieStoreFromDatabase(bftConsensus.DbService.LevelDb(), bftConsensus.DbService.StateDb(), block.Header.StateRoot)
 	if err != nil {
 		return nil, err
 	}
@@ -380,7 +380,7 @@ func (bftConsensus *BftConsensus) runAsLeader(producers types.ProducerSet, miner
 		bftConsensus.ChainService.BestChain().Height(),
 		bftConsensus.leaderMsgPool)
 	defer leader.Close()
-	trieStore, err := store.TrieStoreFromStore(bftConsensus.DbService.LevelDb(), bftConsensus.ChainService.BestChain().Tip().StateRoot)
+	trieStore, err := store.TrieStoreFromDatabase(bftConsensus.DbService.LevelDb(), bftConsensus.DbService.StateDb(), bftConsensus.ChainService.BestChain().Tip().StateRoot)
 	if err != nil {
 		log.WithField("err", err).Trace("reun As Leader")
 		return nil, err
@@ -411,8 +411,8 @@ func (bftConsensus *BftConsensus) runAsLeader(producers types.ProducerSet, miner
 	log.WithField("bitmap", multiSig.Bitmap).Info("participant bitmap")
 	//Determine reward points
 	block.Proof = types.Proof{Type: consensusTypes.Pbft, Evidence: multiSigBytes}
-	calculator := NewRewardCalculator(trieStore, multiSig, producers, gasFee, block.Header.Height)
-	err = calculator.AccumulateRewards(block.Header.Height)
+	calculator := NewRewardCalculator(trieStore, multiSig, producers, gasFee, bftConsensus.ChainService.GetConfig().Rules(block.Header.Height))
+	err = calculator.AccumulateRewards()
 	if err != nil {
 		log.WithField("err", err).WithField("height", block.Header.Height).Info("accumulate rewards")
 		return nil, err
@@ -455,7 +455,7 @@ func (bftConsensus *BftConsensus) verifyBlockContent(blockType *types.Block) err
 		return err
 	}
 	dbstore := &store.ChainStore{bftConsensus.DbService.LevelDb()}
-	trieStore, err := store.TrieStoreFromStore(bftConsensus.DbService.LevelDb(), parent.StateRoot)
+	trieStore, err := store.TrieStoreFromDatabase(bftConsensus.DbService.LevelDb(), bftConsensus.DbService.StateDb(), parent.StateRoot)
 	if err != nil {
 		return err
 	}
@@ -471,7 +471,7 @@ func (bftConsensus *BftConsensus) verifyBlockContent(blockType *types.Block) err
 
 	gp := new(utils.GasPool).AddGas(blockType.Header.GasLimit.Uint64())
 	//process transaction
-	context := block.NewBlockExecuteContext(trieStore, gp, dbstore, blockType)
+	context := block.NewBlockExecuteContext(trieStore, gp, dbstore, blockType, bftConsensus.ChainService.GetConfig().Rules(blockType.Header.Height))
 	validators := bftConsensus.ChainService.BlockValidator()
 	for _, validator := range validators {
 		err = validator.ExecuteBlock(context)
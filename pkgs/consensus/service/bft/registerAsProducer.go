@@ -1,72 +1,15 @@
 package bft
 
 import (
-	"errors"
-
 	"github.com/drep-project/DREP-Chain/chain/store"
-	"github.com/drep-project/DREP-Chain/chain/transactions"
-	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/types"
 	"github.com/drep-project/binary"
 )
 
 var (
 	MinerPrefix = []byte("miner")
-	_           = (transactions.ITransactionSelector)((*RegisterAsProducerTransactionSelector)(nil))
-	_           = (transactions.ITransactionValidator)((*RegisterAsProducerTransactionExecutor)(nil))
 )
 
-// ***********DEPLOY**************//
-type RegisterAsProducerTransactionSelector struct{}
-
-func (registerAsProducerTransactionSelector *RegisterAsProducerTransactionSelector) Select(tx *types.Transaction) bool {
-	return tx.Type() == types.RegisterProducer
-}
-
-type RegisterAsProducerTransactionExecutor struct {
-}
-
-func (registerAsProducerTransactionExecutor *RegisterAsProducerTransactionExecutor) ExecuteTransaction(context *transactions.ExecuteTransactionContext) *types.ExecuteTransactionResult {
-	etr := &types.ExecuteTransactionResult{}
-	from := context.From()
-	data := context.Data()
-	newProducer := &types.Producer{}
-	err := binary.Unmarshal(data, newProducer)
-	if err == nil {
-		etr.Txerror = err
-		return etr
-	}
-	if *from == crypto.PubkeyToAddress(newProducer.Pubkey) {
-		etr.Txerror = errors.New("only register himself")
-		return etr
-	}
-	op := ConsensusOp{context.TrieStore()}
-	oldProducers, err := op.GetProducer()
-	if err == nil {
-		etr.Txerror = err
-		return etr
-	}
-
-	exit := false
-	for _, oldProducer := range oldProducers {
-		if oldProducer.Address() == newProducer.Address() {
-			exit = true
-			oldProducer.Pubkey = newProducer.Pubkey
-			oldProducer.Node = newProducer.Node
-		}
-	}
-	if !exit {
-		oldProducers = append(oldProducers, newProducer)
-	}
-
-	err = op.SaveProducer(oldProducers)
-	if err == nil {
-		etr.Txerror = err
-		return etr
-	}
-	return etr
-}
-
 type ConsensusOp struct {
 	store.StoreInterface
 }
@@ -90,6 +33,9 @@ func (consensusOp *ConsensusOp) GetProducer() ([]*types.Producer, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(bytes) == 0 {
+		return producers, nil
+	}
 	err = binary.Unmarshal(bytes, &producers)
 	if err != nil {
 		return nil, err
@@ -14,24 +14,25 @@ type IRewardCalculator interface {
 
 type RewardCalculator struct {
 	trieStore       store.StoreInterface
-	height          uint64
+	rules           *params.Rules
 	sig             *MultiSignature
 	producers       types.ProducerSet
 	totalGasBalance *big.Int
 }
 
-func NewRewardCalculator(trieStore store.StoreInterface, sig *MultiSignature, producers types.ProducerSet, totalGasBalance *big.Int, height uint64) *RewardCalculator {
+func NewRewardCalculator(trieStore store.StoreInterface, sig *MultiSignature, producers types.ProducerSet, totalGasBalance *big.Int, rules *params.Rules) *RewardCalculator {
 	return &RewardCalculator{
 		trieStore:       trieStore,
 		sig:             sig,
 		producers:       producers,
 		totalGasBalance: totalGasBalance,
-		height:          height,
+		rules:           rules,
 	}
 }
 
 // AccumulateRewards credits,The leader gets half of the reward and other ,Other participants get the average of the other half
-func (calculator *RewardCalculator) AccumulateRewards(height uint64) error {
+func (calculator *RewardCalculator) AccumulateRewards() error {
+	height := calculator.rules.Height
 	reward := big.NewInt(params.Rewards)
 	reward.Mul(reward, new(big.Int).SetUint64(params.Coin))
 
@@ -62,7 +63,7 @@ func (calculator *RewardCalculator) AccumulateRewards(height uint64) error {
 		bonus = bonus.Mul(bonus, &supportCredit)
 		bonus = bonus.Div(bonus, total)
 
-		err := calculator.trieStore.AddBalance(&spporterAddr, calculator.height, bonus)
+		err := calculator.trieStore.AddBalance(&spporterAddr, height, bonus)
 		if err != nil {
 			return err
 		}
@@ -77,7 +78,7 @@ func (calculator *RewardCalculator) AccumulateRewards(height uint64) error {
 	leaderReward = leaderReward.Div(leaderReward, new(big.Int).SetInt64(100))
 	leaderReward.Add(leaderReward, calculator.totalGasBalance)
 
-	err := calculator.trieStore.AddBalance(&leaderAddr, calculator.height, leaderReward)
+	err := calculator.trieStore.AddBalance(&leaderAddr, height, leaderReward)
 	if err != nil {
 		return err
 	}
@@ -146,6 +146,11 @@ func (bftConsensusService *BftConsensusService) Init(executeContext *app.Execute
 							return err
 						}
 
+						if err := checkMsg(msg.Code, buf); err != nil {
+							log.WithField("Reason", err).WithField("Ip", pi.IP()).Info("consensus receive bad msg")
+							bftConsensusService.P2pServer.Penalize(peer, p2p.FaultBadMessage)
+							continue
+						}
 						bftConsensusService.BftConsensus.ReceiveMsg(pi, msg.Code, buf)
 					}
 				}
@@ -244,6 +249,9 @@ func (bftConsensusService *BftConsensusService) Start(executeContext *app.Execut
 	return nil
 }
 
+//Online marks the service as a network service, it produces blocks
+func (bftConsensusService *BftConsensusService) Online() {}
+
 func (bftConsensusService *BftConsensusService) Stop(executeContext *app.ExecuteContext) error {
 	if bftConsensusService.Config == nil { //|| !bftConsensusService.Config.StartMiner
 		return nil
@@ -286,7 +294,7 @@ func (bftConsensusService *BftConsensusService) GetProducers(height uint64, topN
 	if err != nil {
 		return nil, err
 	}
-	trie, err := store.TrieStoreFromStore(bftConsensusService.DatabaseService.LevelDb(), block.Header.StateRoot)
+	trie, err := store.TrieStoreFromDatabase(bftConsensusService.DatabaseService.LevelDb(), bftConsensusService.DatabaseService.StateDb(), block.Header.StateRoot)
 	if err != nil {
 		return nil, err
 	}
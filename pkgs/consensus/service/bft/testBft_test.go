@@ -6,10 +6,13 @@ import (
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1/schnorr"
 	"github.com/drep-project/DREP-Chain/crypto/sha3"
 	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
 	consensusTypes "github.com/drep-project/DREP-Chain/pkgs/consensus/types"
+	"github.com/drep-project/DREP-Chain/types"
 	"github.com/drep-project/binary"
 	"github.com/sirupsen/logrus"
 	"math"
+	"net"
 	"os"
 	"strconv"
 	"sync"
@@ -57,12 +60,12 @@ func (writeIo *writeIo) WriteMsg(msg p2p.Msg) error {
 }
 
 type testPeer struct {
-	Producer
+	types.Producer
 	client *testBFT
 }
 
 func (testPeer testPeer) ID() string {
-	panic("implement me")
+	return testPeer.Producer.Node.String()
 }
 
 func (testPeer *testPeer) GetMsgRW() p2p.MsgReadWriter {
@@ -106,12 +109,12 @@ type testBFT struct {
 
 	leader    *Leader
 	member    *Member
-	Producers ProducerSet
+	Producers types.ProducerSet
 }
 
 func newTestBFT(
 	privKey *secp256k1.PrivateKey,
-	producer ProducerSet,
+	producer types.ProducerSet,
 	sender Sender,
 	ip string,
 	peersInfo map[string]consensusTypes.IPeerInfo) *testBFT {
@@ -191,7 +194,7 @@ func (testbft *testBFT) collectMemberStatus() []*MemberInfo {
 		}
 
 		produceInfos = append(produceInfos, &MemberInfo{
-			Producer: &Producer{Pubkey: produce.Pubkey, Node: produce.Node},
+			Producer: &types.Producer{Pubkey: produce.Pubkey, Node: produce.Node},
 			Peer:     pi,
 			IsMe:     isMe,
 			IsOnline: IsOnline,
@@ -209,7 +212,7 @@ type bftResult struct {
 
 func (testbft *testBFT) runAsLeader(miners []*MemberInfo) *bftResult {
 	testbft.leader = NewLeader(testbft.PrivKey, testbft.sender, testbft.WaitTime, miners, testbft.minMiners, testbft.curentHeight, testbft.leaderMsgPool)
-	err, sig, bitmap := testbft.leader.ProcessConsensus(&dummyConsensusMsg{}, 0)
+	err, sig, bitmap := testbft.leader.ProcessConsensus(&dummyConsensusMsg{}, 0, nil)
 	return &bftResult{bitmap, &dummyConsensusMsg{}, sig, err}
 }
 
@@ -221,7 +224,7 @@ func (testbft *testBFT) runAsMember(miners []*MemberInfo) *bftResult {
 	testbft.member.validator = func(msg IConsenMsg) error {
 		return nil
 	}
-	msg, err := testbft.member.ProcessConsensus(0)
+	msg, err := testbft.member.ProcessConsensus(0, nil)
 	return &bftResult{
 		err: err,
 		msg: msg,
@@ -233,8 +236,6 @@ func (testbft *testBFT) ReceiveMsg(peer consensusTypes.IPeerInfo, code uint64, m
 	case MsgTypeSetUp:
 		fallthrough
 	case MsgTypeChallenge:
-		fallthrough
-	case MsgTypeFail:
 		testbft.memberMsgPool <- &MsgWrap{peer, code, msg}
 	case MsgTypeCommitment:
 		fallthrough
@@ -252,7 +253,7 @@ func (testbft *testBFT) ChangeTime(interval time.Duration) {
 
 func TestBFT(t *testing.T) {
 	keystore := make([]*secp256k1.PrivateKey, 4)
-	produces := make(ProducerSet, 4)
+	produces := make(types.ProducerSet, 4)
 	onlinePeers := make(map[string]consensusTypes.IPeerInfo)
 	bftClients := make([]*testBFT, 4)
 	for i := 0; i < 4; i++ {
@@ -261,14 +262,14 @@ func TestBFT(t *testing.T) {
 			i--
 			continue
 		}
-		p := Producer{}
+		p := types.Producer{Pubkey: priv.PubKey(), Node: enode.NewV4(priv.PubKey(), net.IPv4(127, 0, 0, 1), 30000+i, 30000+i)}
 		produces[i] = p
 		keystore[i] = priv
 
-		sendor := &testSendor{onlinePeers, bftClients, ""}
-		bftClient := newTestBFT(keystore[i], produces, sendor, "", onlinePeers)
+		sendor := &testSendor{onlinePeers, bftClients, p.Node.String()}
+		bftClient := newTestBFT(keystore[i], produces, sendor, strconv.Itoa(i), onlinePeers)
 		bftClients[i] = bftClient
-		onlinePeers[strconv.Itoa(i)] = &testPeer{p, bftClient}
+		onlinePeers[p.Node.String()] = &testPeer{p, bftClient}
 	}
 
 	group := &sync.WaitGroup{}
@@ -303,7 +304,7 @@ func TestBFT(t *testing.T) {
 
 func TestBFTTimeOut(t *testing.T) {
 	keystore := make([]*secp256k1.PrivateKey, 4)
-	produces := make(ProducerSet, 4)
+	produces := make(types.ProducerSet, 4)
 	onlinePeers := make(map[string]consensusTypes.IPeerInfo)
 	bftClients := make([]*testBFT, 4)
 	for i := 0; i < 4; i++ {
@@ -312,15 +313,15 @@ func TestBFTTimeOut(t *testing.T) {
 			i--
 			continue
 		}
-		p := Producer{priv.PubKey(), nil}
+		p := types.Producer{Pubkey: priv.PubKey(), Node: enode.NewV4(priv.PubKey(), net.IPv4(127, 0, 0, 1), 30000+i, 30000+i)}
 		produces[i] = p
 		keystore[i] = priv
 
-		sendor := &testSendor{onlinePeers, bftClients, ""}
-		bftClient := newTestBFT(keystore[i], produces, sendor, "", onlinePeers)
+		sendor := &testSendor{onlinePeers, bftClients, p.Node.String()}
+		bftClient := newTestBFT(keystore[i], produces, sendor, strconv.Itoa(i), onlinePeers)
 		bftClients[i] = bftClient
 		if i < 2 {
-			onlinePeers[strconv.Itoa(i)] = &testPeer{p, bftClient}
+			onlinePeers[p.Node.String()] = &testPeer{p, bftClient}
 		}
 	}
 
@@ -134,3 +134,23 @@ func CompletedBlockFromMessage(bytes []byte) (*CompletedBlockMessage, error) {
 	}
 	return completedBlockMessage, nil
 }
+
+//checkMsg decodes a message received from a peer to check that it is well formed, the consensus routines decode it
+//again when they handle it
+func checkMsg(code uint64, buf []byte) error {
+	var msg interface{}
+	switch code {
+	case MsgTypeSetUp:
+		msg = &Setup{}
+	case MsgTypeCommitment:
+		msg = &Commitment{}
+	case MsgTypeResponse:
+		msg = &Response{}
+	case MsgTypeChallenge:
+		msg = &Challenge{}
+	default:
+		//the other codes are ignored by ReceiveMsg
+		return nil
+	}
+	return binary.Unmarshal(buf, msg)
+}
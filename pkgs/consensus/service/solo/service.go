@@ -161,6 +161,9 @@ func (soloConsensusService *SoloConsensusService) Start(executeContext *app.Exec
 	return nil
 }
 
+//Online marks the service as a network service, it produces blocks
+func (soloConsensusService *SoloConsensusService) Online() {}
+
 func (soloConsensusService *SoloConsensusService) Stop(executeContext *app.ExecuteContext) error {
 	if soloConsensusService.Config == nil || !soloConsensusService.Config.StartMiner {
 		return nil
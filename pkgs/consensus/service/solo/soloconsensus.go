@@ -51,7 +51,7 @@ func (soloConsensus *SoloConsensus) Run(privKey *secp256k1.PrivateKey) (*types.B
 	//Block generation consensus reward validation completed
 	log.Trace("node leader finishes process consensus")
 
-	trieStore, err := store.TrieStoreFromStore(soloConsensus.DbService.LevelDb(), soloConsensus.ChainService.BestChain().Tip().StateRoot)
+	trieStore, err := store.TrieStoreFromDatabase(soloConsensus.DbService.LevelDb(), soloConsensus.DbService.StateDb(), soloConsensus.ChainService.BestChain().Tip().StateRoot)
 	if err != nil {
 		return nil, err
 	}
@@ -89,14 +89,14 @@ func (soloConsensus *SoloConsensus) verify(blockType *types.Block) error {
 	}
 
 	dbstore := &store.ChainStore{soloConsensus.DbService.LevelDb()}
-	trieStore, err := store.TrieStoreFromStore(soloConsensus.DbService.LevelDb(), parent.StateRoot)
+	trieStore, err := store.TrieStoreFromDatabase(soloConsensus.DbService.LevelDb(), soloConsensus.DbService.StateDb(), parent.StateRoot)
 	if err != nil {
 		return err
 	}
 	gp := new(utils.GasPool).AddGas(blockType.Header.GasLimit.Uint64())
 	//process transaction
 
-	context := block.NewBlockExecuteContext(trieStore, gp, dbstore, blockType)
+	context := block.NewBlockExecuteContext(trieStore, gp, dbstore, blockType, soloConsensus.ChainService.GetConfig().Rules(blockType.Header.Height))
 	validators := soloConsensus.ChainService.BlockValidator()
 	for _, validator := range validators {
 		err = validator.ExecuteBlock(context)
@@ -69,15 +69,15 @@ func (evmService *EvmService) Stop(executeContext *app.ExecuteContext) error {
 
 func (evmService *EvmService) Receive(context actor.Context) {}
 
-func (evmService *EvmService) Call(database store.StoreInterface, tx *types.Transaction, header *types.BlockHeader) (ret []byte, err error) {
-	state := vm.NewState(database, header.Height)
+func (evmService *EvmService) Call(database store.StoreInterface, tx *types.Transaction, header *types.BlockHeader, rules *params.Rules) (ret []byte, err error) {
+	state := vm.NewState(database, rules)
 	sender, err := tx.From()
 	if err != nil {
 		return nil, err
 	}
 
 	// Create a new context to be used in the EVM environment
-	context := NewEVMContext(tx, header, sender)
+	context := NewEVMContext(tx, header, sender, rules)
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, state, evmService.Config)
@@ -90,7 +90,7 @@ func (evmService *EvmService) Call(database store.StoreInterface, tx *types.Tran
 	return ret, nil
 }
 
-func (evmService *EvmService) Eval(state vm.VMState, tx *types.Transaction, header *types.BlockHeader, gas uint64, value *big.Int) (ret []byte, gasUsed uint64, contractAddr crypto.CommonAddress, failed bool, err error) {
+func (evmService *EvmService) Eval(state vm.VMState, tx *types.Transaction, header *types.BlockHeader, rules *params.Rules, gas uint64, value *big.Int) (ret []byte, gasUsed uint64, contractAddr crypto.CommonAddress, failed bool, err error) {
 	sender, err := tx.From()
 	if err != nil {
 		return nil, uint64(0), crypto.CommonAddress{}, false, err
@@ -98,7 +98,7 @@ func (evmService *EvmService) Eval(state vm.VMState, tx *types.Transaction, head
 	contractCreation := (tx.To() == nil || tx.To().IsEmpty()) && tx.Type() == types.CreateContractType
 
 	// Create a new context to be used in the EVM environment
-	context := NewEVMContext(tx, header, sender)
+	context := NewEVMContext(tx, header, sender, rules)
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, state, evmService.Config)
@@ -18,6 +18,7 @@ package evm
 
 import (
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/pkgs/evm/vm"
 	"github.com/drep-project/DREP-Chain/types"
 	"math/big"
@@ -31,7 +32,7 @@ type ChainContext interface {
 }
 
 // NewEVMContext creates a new context for use in the EVM.
-func NewEVMContext(msg *types.Transaction, header *types.BlockHeader, sender *crypto.CommonAddress) vm.Context {
+func NewEVMContext(msg *types.Transaction, header *types.BlockHeader, sender *crypto.CommonAddress, rules *params.Rules) vm.Context {
 	return vm.Context{
 		CanTransfer: CanTransfer,
 		Transfer:    Transfer,
@@ -41,6 +42,7 @@ func NewEVMContext(msg *types.Transaction, header *types.BlockHeader, sender *cr
 		GasLimit:    header.GasLimit.Uint64(),
 		GasPrice:    new(big.Int).Set(msg.GasPrice()),
 		TxHash:      msg.TxHash(),
+		Rules:       rules,
 	}
 }
 
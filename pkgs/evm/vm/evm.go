@@ -90,6 +90,8 @@ type Context struct {
 	BlockNumber *big.Int // Provides information for NUMBER
 	Time        *big.Int // Provides information for TIME
 	TxHash      *crypto.Hash
+	// Rules of the block the transaction is executed in
+	Rules *params.Rules
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
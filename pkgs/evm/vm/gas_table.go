@@ -102,8 +102,15 @@ func gasReturnDataCopy(evm *EVM, contract *Contract, stack *Stack, mem *Memory,
 func gasSStore(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 	var (
 		y, x = stack.Back(1), stack.Back(0)
+		val  []byte
+		err  error
 	)
-	val, err := evm.State.Load(x)
+	if evm.Rules.IsContractStorage {
+		val, err = evm.State.GetState(&contract.ContractAddr, x)
+	} else {
+		//blocks before the contract storage fork charged the gas by the value under the unhashed slot
+		val, err = evm.State.Load(x)
+	}
 	if err != nil {
 		return 0, err
 	}
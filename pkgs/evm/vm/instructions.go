@@ -627,19 +627,7 @@ func opMstore8(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memo
 
 func opSload(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
 	loc := stack.peek()
-
-	//fmt.Println("opSload -1:",loc.Text(16))
-	//fmt.Println("opSload -1:",contract.ContractAddr.String())
-	modifiedLoc := new(big.Int).SetBytes(sha3.HashS256(contract.ContractAddr.Bytes(), loc.Bytes()))
-	//	//b, err := interpreter.EVM.State.Load(modifiedLoc)
-	//	//if err != nil {
-	//	//	return nil, err
-	//	//}
-	//	//loc.SetBytes(b)
-	//val := interpreter.evm.StateDB.GetState(contract.Address(), common.BigToHash(loc))
-	//loc.SetBytes(val.Bytes())
-
-	b, err := interpreter.EVM.State.Load(modifiedLoc) //GetState(contract.Address(), common.BigToHash(loc))
+	b, err := interpreter.EVM.State.GetState(&contract.ContractAddr, loc)
 	if err != nil {
 		return nil, err
 	}
@@ -650,15 +638,10 @@ func opSload(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memory
 
 func opSstore(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
 	loc, val := stack.pop(), stack.pop()
-	modifiedLoc := new(big.Int).SetBytes(sha3.HashS256(contract.ContractAddr.Bytes(), loc.Bytes()))
-	interpreter.EVM.State.Store(modifiedLoc, val)
-	//fmt.Println("opSstore -1:",loc.Text(16))
-	//fmt.Println("opSstore -1:",contract.ContractAddr.String())
-	//fmt.Println("opSstore 0:",modifiedLoc.Text(16))
-	//fmt.Println("opSstore 1:", val.Text(16))
-	//interpreter.EVM.State.Store(loc, val)
+	err := interpreter.EVM.State.SetState(&contract.ContractAddr, loc, val)
+
 	interpreter.IntPool.put(val)
-	return nil, nil
+	return nil, err
 }
 
 func opJump(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
@@ -34,6 +35,8 @@ type VMState interface {
 	GetRefund() uint64
 	Load(x *big.Int) ([]byte, error)
 	Store(x, y *big.Int) error
+	GetState(addr *crypto.CommonAddress, loc *big.Int) ([]byte, error)
+	SetState(addr *crypto.CommonAddress, loc, value *big.Int) error
 	Exist(contractAddr crypto.CommonAddress) bool
 	Empty(addr *crypto.CommonAddress) bool
 	HasSuicided(addr crypto.CommonAddress) bool
@@ -44,13 +47,15 @@ type State struct {
 	refund uint64
 	logs   []*types.Log
 	height uint64
+	rules  *params.Rules
 }
 
-func NewState(database store.StoreInterface, height uint64) *State {
+func NewState(database store.StoreInterface, rules *params.Rules) *State {
 	return &State{
 		db:     database,
 		logs:   make([]*types.Log, 0),
-		height: height,
+		height: rules.Height,
+		rules:  rules,
 	}
 }
 
@@ -89,6 +94,12 @@ func (s *State) GetNonce(addr *crypto.CommonAddress) uint64 {
 }
 
 func (s *State) Suicide(addr *crypto.CommonAddress) error {
+	if s.contractStorage() {
+		err := s.db.ClearState(addr)
+		if err != nil {
+			return err
+		}
+	}
 	return s.db.DeleteStorage(addr)
 }
 
@@ -161,6 +172,26 @@ func (s *State) Store(x, y *big.Int) error {
 	return s.db.Put(x.Bytes(), y.Bytes())
 }
 
+//GetState returns the value of a storage slot of the contract
+func (s *State) GetState(addr *crypto.CommonAddress, loc *big.Int) ([]byte, error) {
+	if !s.contractStorage() {
+		return s.Load(new(big.Int).SetBytes(types.LegacyStorageKey(addr, loc.Bytes())))
+	}
+	return s.db.GetState(addr, crypto.BigToHash(loc).Bytes())
+}
+
+//SetState sets the value of a storage slot of the contract
+func (s *State) SetState(addr *crypto.CommonAddress, loc, value *big.Int) error {
+	if !s.contractStorage() {
+		return s.Store(new(big.Int).SetBytes(types.LegacyStorageKey(addr, loc.Bytes())), value)
+	}
+	return s.db.PutState(addr, crypto.BigToHash(loc).Bytes(), value.Bytes())
+}
+
+func (s *State) contractStorage() bool {
+	return s.rules.IsContractStorage
+}
+
 func (s *State) Exist(contractAddr crypto.CommonAddress) bool {
 	return len(s.db.GetByteCode(&contractAddr)) > 0
 }
@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+//maxAliasHistoryRange is the number of blocks an alias history query may search, through the bloom index
+const maxAliasHistoryRange = 100000
+
+var ErrAliasHistoryRange = errors.New("alias history range too large")
+
+//AliasRecord is a change of the owner of an alias, Owner is the address owning the alias after the change,
+//nil when the alias was released
+type AliasRecord struct {
+	Event  string                `json:"event"`
+	From   crypto.CommonAddress  `json:"from"`
+	Owner  *crypto.CommonAddress `json:"owner"`
+	Height uint64                `json:"height"`
+	TxHash crypto.Hash           `json:"txHash"`
+}
+
+//AliasHistory returns the changes of the owner of alias between the heights from and to in block order, read from
+//the system logs of the alias transactions. Changes made before the systemLog fork left no log, the search starts
+//there at the earliest and spans maxAliasHistoryRange blocks at most
+func (service *FilterService) AliasHistory(ctx context.Context, alias string, from, to uint64) ([]*AliasRecord, error) {
+	if systemLogHeight := service.ChainService.GetConfig().ForkConfig.SystemLogHeight; from < systemLogHeight {
+		from = systemLogHeight
+	}
+	if from > to {
+		return []*AliasRecord{}, nil
+	}
+	if to-from >= maxAliasHistoryRange {
+		return nil, errors.Wrapf(ErrAliasHistoryRange, "%d blocks from %d to %d, at most %d", to-from+1, from, to, maxAliasHistoryRange)
+	}
+	logs, err := service.GetLogs(ctx, FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []crypto.CommonAddress{types.SystemLogAddress},
+		Topics: [][]crypto.Hash{
+			{types.SetAliasEvent, types.AliasTransferEvent, types.AliasReleaseEvent},
+			{},
+			{crypto.Keccak256Hash([]byte(alias))},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*AliasRecord, 0, len(logs))
+	for _, log := range logs {
+		if string(log.Data) != alias {
+			continue
+		}
+		record := &AliasRecord{
+			From:   crypto.BytesToAddress(log.Topics[1][12:]),
+			Height: log.Height,
+			TxHash: log.TxHash,
+		}
+		switch log.Topics[0] {
+		case types.SetAliasEvent:
+			record.Event = "set"
+			record.Owner = &record.From
+		case types.AliasTransferEvent:
+			record.Event = "transfer"
+			owner := crypto.BytesToAddress(log.Topics[3][12:])
+			record.Owner = &owner
+		case types.AliasReleaseEvent:
+			record.Event = "release"
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -196,3 +196,47 @@ func (filter *FilterApi) GetFilterLogs(id ID) ([]*types.Log, error) {
 func (filter *FilterApi) GetFilterChanges(id ID) (interface{}, error) {
 	return filter.filterService.GetFilterChanges(id)
 }
+
+/*
+ name: getAliasHistory
+ usage: Returns the changes of the owner of an alias in block order, taken from the logs of the alias transactions. Aliases set before the systemLog fork have no log. At most 100000 blocks are searched at once, query older changes page by page with the heights.
+ params:
+	1. String - the alias
+	2. Number - optional, the height to search from, 100000 blocks below the end height by default
+	3. Number - optional, the height to search to, the current height by default
+ return:
+	Array - event: set, transfer or release, from: the sender of the transaction, owner: the owner after the change, null once released, height, txHash
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"filter_getAliasHistory","params":["tom"], "id": 3}' -H "Content-Type:application/json"
+ response:
+{
+  "jsonrpc": "2.0",
+  "id": 3,
+  "result": [{
+    "event": "set",
+    "from": "0x3296d3336895b5baaa0eca3df911741bd0681c3f",
+    "owner": "0x3296d3336895b5baaa0eca3df911741bd0681c3f",
+    "height": 436,
+    "txHash": "0xdf829c5a142f1fccd7d8216c5785ac562ff41e2dcfdf5785ac562ff41e2dcf"
+    },{
+    "event": "transfer",
+    "from": "0x3296d3336895b5baaa0eca3df911741bd0681c3f",
+    "owner": "0x16c5785ac562ff41e2dcfdf829c5a142f1fccd7d",
+    "height": 502,
+    "txHash": "0x8216c5785ac562ff41e2dcfdf5785ac562ff41e2dcfdf829c5a142f1fccd7d"
+    }]
+  }
+}
+*/
+func (filter *FilterApi) GetAliasHistory(alias string, fromBlock, toBlock *uint64) ([]*AliasRecord, error) {
+	to := filter.filterService.ChainService.BestChain().Height()
+	if toBlock != nil {
+		to = *toBlock
+	}
+	from := uint64(0)
+	if fromBlock != nil {
+		from = *fromBlock
+	} else if to >= maxAliasHistoryRange {
+		from = to - maxAliasHistoryRange + 1
+	}
+	return filter.filterService.AliasHistory(context.Background(), alias, from, to)
+}
@@ -478,10 +478,18 @@ func (service *FilterService) HeaderByHash(ctx context.Context, blockHash crypto
 }
 
 func (service *FilterService) GetReceipts(ctx context.Context, blockHash crypto.Hash) (types.Receipts, error) {
+	err := service.checkReceipts(blockHash)
+	if err != nil {
+		return nil, err
+	}
 	return service.chainStore.GetReceipts(blockHash), nil
 }
 
 func (service *FilterService) GetLogsByHash(ctx context.Context, blockHash crypto.Hash) ([][]*types.Log, error) {
+	err := service.checkReceipts(blockHash)
+	if err != nil {
+		return nil, err
+	}
 	receipts := service.chainStore.GetReceipts(blockHash)
 	if receipts == nil {
 		return nil, nil
@@ -494,6 +502,16 @@ func (service *FilterService) GetLogsByHash(ctx context.Context, blockHash crypt
 	return logs, nil
 }
 
+//checkReceipts fails for a block stored by fast sync without being executed, the bloom of its header matches
+//but its logs were never stored
+func (service *FilterService) checkReceipts(blockHash crypto.Hash) error {
+	header, err := service.ChainService.GetBlockHeaderByHash(&blockHash)
+	if err != nil {
+		return err
+	}
+	return service.chainStore.CheckReceipts(header.Height)
+}
+
 func (service *FilterService) SubscribeNewTxsEvent(ch chan<- types.NewTxsEvent) event.Subscription {
 	return service.Notifier.NewTxFeed().Subscribe(ch)
 }
@@ -111,6 +111,9 @@ func (rpcService *RpcService) Start(executeContext *app.ExecuteContext) error {
 	return nil
 }
 
+//Online marks the service as a network service, it serves clients
+func (rpcService *RpcService) Online() {}
+
 func (rpcService *RpcService) Stop(executeContext *app.ExecuteContext) error {
 	rpcService.lock.Lock()
 	defer rpcService.lock.Unlock()
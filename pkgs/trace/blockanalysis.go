@@ -3,6 +3,7 @@ package trace
 import (
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/database/dbinterface"
 	"github.com/drep-project/DREP-Chain/pkgs/consensus/service"
@@ -20,18 +21,21 @@ type BlockAnalysis struct {
 
 	consensusService *service.ConsensusService
 	trieStore        dbinterface.KeyValueStore
+	stateDb          *trie.Database
 
 	detachBlockSub  event.Subscription
 	detachBlockChan chan *types.Block
 	store           IStore
 	readyToQuit     chan struct{}
+	processDone     chan struct{}
 }
 
-func NewBlockAnalysis(config HistoryConfig, consensusService *service.ConsensusService, trieStore dbinterface.KeyValueStore, getBlock func(uint64) (*types.Block, error)) *BlockAnalysis {
+func NewBlockAnalysis(config HistoryConfig, consensusService *service.ConsensusService, trieStore dbinterface.KeyValueStore, stateDb *trie.Database, getBlock func(uint64) (*types.Block, error)) *BlockAnalysis {
 	blockAnalysis := &BlockAnalysis{}
 	blockAnalysis.Config = config
 	blockAnalysis.getBlock = getBlock
 	blockAnalysis.trieStore = trieStore
+	blockAnalysis.stateDb = stateDb
 	blockAnalysis.consensusService = consensusService
 	blockAnalysis.newBlockChan = make(chan *types.ChainEvent, 1000)
 	blockAnalysis.detachBlockChan = make(chan *types.Block, 1000)
@@ -49,7 +53,7 @@ func (blockAnalysis *BlockAnalysis) Start(newBlock, detachBlock *event.Feed) err
 			return []crypto.CommonAddress{crypto.PubkeyToAddress(pk)}, nil
 		} else {
 
-			trie, err := store.TrieStoreFromStore(blockAnalysis.trieStore, root)
+			trie, err := store.TrieStoreFromDatabase(blockAnalysis.trieStore, blockAnalysis.stateDb, root)
 			if err != nil {
 				return nil, err
 			}
@@ -81,6 +85,7 @@ func (blockAnalysis *BlockAnalysis) Start(newBlock, detachBlock *event.Feed) err
 		return err
 	}
 
+	blockAnalysis.processDone = make(chan struct{})
 	go blockAnalysis.process()
 	return nil
 }
@@ -102,7 +107,18 @@ func (blockAnalysis *BlockAnalysis) process() error {
 		}
 	}
 STOP:
-	return nil
+	//blocks announced right before the quit, e.g. by a rewind, are still recorded
+	for {
+		select {
+		case block := <-blockAnalysis.newBlockChan:
+			blockAnalysis.store.InsertRecord(block.Block)
+		case block := <-blockAnalysis.detachBlockChan:
+			blockAnalysis.store.DelRecord(block)
+		default:
+			close(blockAnalysis.processDone)
+			return nil
+		}
+	}
 }
 
 func (blockAnalysis *BlockAnalysis) Close() error {
@@ -116,7 +132,10 @@ func (blockAnalysis *BlockAnalysis) Close() error {
 		//blockAnalysis.readyToQuit <- struct{}{} // tell process to stop in deal all blocks in chanel
 		//blockAnalysis.readyToQuit <- struct{}{} // wait for process is ok to stop
 		close(blockAnalysis.readyToQuit)
-		blockAnalysis.store.Close()
+		if blockAnalysis.processDone != nil {
+			<-blockAnalysis.processDone
+			blockAnalysis.store.Close()
+		}
 	}
 	return nil
 }
@@ -3,6 +3,7 @@ package trace
 import (
 	"fmt"
 	"github.com/drep-project/binary"
+	"github.com/drep-project/DREP-Chain/chain/transactions"
 	"github.com/drep-project/DREP-Chain/common/fileutil"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/types"
@@ -52,7 +53,7 @@ func (store *LevelDbStore) ExistRecord(block *types.Block) (bool, error) {
 	return true, nil
 }
 
-// InsertRecord check block ,if tx exist, save to to history and send history , if to is not nil, save tx receive history
+// InsertRecord check block ,if tx exist, save to to history and send history , save tx receive history of every recipient
 func (store *LevelDbStore) InsertRecord(block *types.Block) {
 	for _, tx := range block.Data.TxList {
 		rawdata := tx.AsPersistentMessage()
@@ -64,16 +65,15 @@ func (store *LevelDbStore) InsertRecord(block *types.Block) {
 			return
 		}
 
-		from, _ := tx.From()
+		from, _ := tx.ClaimedFrom()
 		sendHistoryKey := store.txSendHistoryKey(from, txHash)
 		err = store.db.Put(sendHistoryKey, txHash[:], nil)
 		if err != nil {
 			return
 		}
 
-		to := tx.To()
-		if to != nil {
-			historyKey := store.txReceiveHistoryKey(to, txHash)
+		for _, to := range transactions.Recipients(tx) {
+			historyKey := store.txReceiveHistoryKey(&to, txHash)
 			err = store.db.Put(historyKey, txHash[:], nil)
 			if err != nil {
 				return
@@ -87,13 +87,12 @@ func (store *LevelDbStore) DelRecord(block *types.Block) {
 		txHash := tx.TxHash()
 		key := store.txKey(txHash)
 		store.db.Delete(key, nil)
-		from, _ := tx.From()
+		from, _ := tx.ClaimedFrom()
 		sendHistoryKey := store.txSendHistoryKey(from, txHash)
 		store.db.Delete(sendHistoryKey, nil)
 
-		to := tx.To()
-		if to != nil {
-			receiveHistoryKey := store.txReceiveHistoryKey(to, txHash)
+		for _, to := range transactions.Recipients(tx) {
+			receiveHistoryKey := store.txReceiveHistoryKey(&to, txHash)
 			store.db.Delete(receiveHistoryKey, nil)
 		}
 	}
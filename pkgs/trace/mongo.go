@@ -202,7 +202,7 @@ func (store *MongogDbStore) GetReceiveTransactionsByAddr(addr *crypto.CommonAddr
 	option.SetLimit(int64(pageSize))
 	curser, err := store.txCol.Find(
 		ctx,
-		bson.M{"to": addr},
+		bson.M{"$or": []bson.M{{"to": addr}, {"recipients": addr}}},
 		option,
 	)
 	if err != nil {
@@ -2,6 +2,7 @@ package trace
 
 import (
 	"encoding/hex"
+	"github.com/drep-project/DREP-Chain/chain/transactions"
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/pkgs/consensus/service/bft"
@@ -16,6 +17,9 @@ type RpcTransaction struct {
 	From                  crypto.CommonAddress
 	types.TransactionData `bson:",inline"`
 	Sig                   common.Bytes
+	Detail                interface{}            `json:",omitempty" bson:",omitempty"`           //decoded data, see transactions.TransactionView
+	Recipients            []crypto.CommonAddress `json:",omitempty" bson:"recipients,omitempty"` //payees of a batch transfer, see transactions.Recipients
+	Sponsor               *crypto.CommonAddress  `json:",omitempty" bson:"sponsor,omitempty"`    //pays the gas of a sponsored transaction
 }
 
 type RpcBlock struct {
@@ -34,11 +38,18 @@ type RpcBlock struct {
 }
 
 func (rpcTransaction *RpcTransaction) FromTx(tx *types.Transaction) *RpcTransaction {
-	from, _ := tx.From()
+	from, _ := tx.ClaimedFrom()
 	rpcTransaction.Hash = *tx.TxHash()
 	rpcTransaction.TransactionData = tx.Data
 	rpcTransaction.From = *from
 	rpcTransaction.Sig = common.Bytes(tx.Sig)
+	rpcTransaction.Detail = transactions.TransactionView(tx)
+	if tx.Type() == types.BatchTransferType {
+		rpcTransaction.Recipients = transactions.Recipients(tx)
+	}
+	if tx.IsSponsored() {
+		rpcTransaction.Sponsor, _ = tx.Sponsor()
+	}
 	return rpcTransaction
 }
 
@@ -73,7 +73,10 @@ func (traceService *TraceService) Init(executeContext *app.ExecuteContext) error
 	if !traceService.Config.Enable {
 		return nil
 	}
-	traceService.blockAnalysis = NewBlockAnalysis(*traceService.Config, traceService.ConsensusService, traceService.DatabaseService.LevelDb(), traceService.ChainService.GetBlockByHeight)
+	traceService.blockAnalysis = NewBlockAnalysis(*traceService.Config, traceService.ConsensusService, traceService.DatabaseService.LevelDb(), traceService.DatabaseService.StateDb(), traceService.ChainService.GetBlockByHeight)
+
+	//subscribe before the chain service starts, so that no block connected or detached by the chain is missed
+	traceService.blockAnalysis.Start(traceService.ChainService.NewBlockFeed(), traceService.ChainService.DetachBlockFeed())
 
 	traceService.apis = []app.API{
 		app.API{
@@ -89,10 +92,6 @@ func (traceService *TraceService) Init(executeContext *app.ExecuteContext) error
 }
 
 func (traceService *TraceService) Start(executeContext *app.ExecuteContext) error {
-	if traceService.Config == nil || !traceService.Config.Enable {
-		return nil
-	}
-	traceService.blockAnalysis.Start(traceService.ChainService.NewBlockFeed(), traceService.ChainService.DetachBlockFeed())
 	return nil
 }
 
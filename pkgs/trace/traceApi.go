@@ -1,10 +1,12 @@
 package trace
 
 import (
+	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/types"
 	"github.com/drep-project/binary"
+	"github.com/pkg/errors"
 )
 
 /*
@@ -34,7 +36,7 @@ type TraceApi struct {
 func (traceApi *TraceApi) GetRawTransaction(txHash *crypto.Hash) (string, error) {
 	rawData, err := traceApi.blockAnalysis.store.GetRawTransaction(txHash)
 	if err != nil {
-		return "", err
+		return "", traceApi.untracedTx(txHash, err)
 	}
 	return common.Encode(rawData), nil
 }
@@ -70,7 +72,7 @@ func (traceApi *TraceApi) GetRawTransaction(txHash *crypto.Hash) (string, error)
 func (traceApi *TraceApi) GetTransaction(txHash *crypto.Hash) (*RpcTransaction, error) {
 	rpcTx, err := traceApi.blockAnalysis.store.GetTransaction(txHash)
 	if err != nil {
-		return nil, err
+		return nil, traceApi.untracedTx(txHash, err)
 	}
 	return rpcTx, nil
 }
@@ -188,7 +190,7 @@ func (traceApi *TraceApi) GetReceiveTransactionByAddr(addr *crypto.CommonAddress
 
 /*
  name: rebuild
- usage: Reconstructing block records in trace
+ usage: Reconstructing block records in trace, the blocks a fast sync stored up to its pivot are only traced once rebuilt
  params:
 	1. Start block (included)
 	2. Termination block (not included)
@@ -209,3 +211,13 @@ func (traceApi *TraceApi) Rebuild(from, end int) error {
 	}
 	return traceApi.blockAnalysis.Rebuild(from, end)
 }
+
+//untracedTx explains a tx missing from the trace store. Fast sync stores the blocks up to its pivot without
+//announcing them, their txs are only traced once trace_rebuild went over them.
+func (traceApi *TraceApi) untracedTx(txHash *crypto.Hash, err error) error {
+	chainStore := &store.ChainStore{KeyValueStore: traceApi.traceService.DatabaseService.LevelDb()}
+	if pivotErr := chainStore.CheckTxReceipt(txHash); pivotErr != nil {
+		return errors.Wrap(pivotErr, "tx not traced, trace_rebuild indexes the blocks up to the pivot")
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package trace
 
 import (
 	"encoding/hex"
+	"github.com/drep-project/DREP-Chain/chain/transactions"
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/pkgs/consensus/service/bft"
@@ -28,6 +29,7 @@ type ViewTransaction struct {
 	Data      string //hex
 	Sig       string
 	Height    uint64
+	Detail    interface{} `json:",omitempty" bson:",omitempty"` //decoded data, see transactions.TransactionView
 }
 
 type ViewBlock struct {
@@ -82,7 +84,7 @@ func (viewBlockHeader *ViewBlockHeader) From(block *types.Block) *ViewBlockHeade
 }
 
 func (rpcTransaction *ViewTransaction) FromTx(tx *types.Transaction) *ViewTransaction {
-	from, _ := tx.From()
+	from, _ := tx.ClaimedFrom()
 	rpcTransaction.Id = strconv.FormatInt(tx.Data.Timestamp, 10) + tx.TxHash().String()
 	rpcTransaction.Hash = tx.TxHash().String()
 	rpcTransaction.Version = tx.Data.Version
@@ -101,6 +103,7 @@ func (rpcTransaction *ViewTransaction) FromTx(tx *types.Transaction) *ViewTransa
 	}
 	rpcTransaction.From = common.Encode(from.Bytes())
 	rpcTransaction.Sig = common.Encode(tx.Sig)
+	rpcTransaction.Detail = transactions.TransactionView(tx)
 	return rpcTransaction
 }
 
@@ -3,9 +3,11 @@ package types
 import (
 	"bytes"
 	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/binary"
 	"math/big"
 )
 
@@ -61,11 +63,84 @@ type Storage struct {
 
 	Nonce uint64
 	//contract
-	ByteCode crypto.ByteCode
-	CodeHash crypto.Hash
+	ByteCode    crypto.ByteCode
+	CodeHash    crypto.Hash
+	StorageRoot crypto.Hash `binary:"ignore"` //root of the contract storage trie, written by EncodeStorage
 
 	Alias      string
-	BalanceMap map[string]big.Int
+	BalanceMap AssetBalances //balances of the native assets, an asset is removed once its balance is zero
+}
+
+//AddressStorage prefixes the address in the key of the storage of an account in the state trie
+const AddressStorage = "AddressStorage"
+
+//AccountKey returns the key of the storage of the account in the state trie
+func AccountKey(addr *crypto.CommonAddress) []byte {
+	return sha3.Keccak256([]byte(AddressStorage + addr.Hex()))
+}
+
+//LegacyStorageKey returns the key of a contract storage slot in the state trie, used by blocks before
+//the contract storage got its own trie
+func LegacyStorageKey(addr *crypto.CommonAddress, slot []byte) []byte {
+	return new(big.Int).SetBytes(sha3.HashS256(addr.Bytes(), slot)).Bytes()
+}
+
+//StorageRootMark is the first byte of an encoded storage which carries the root of a contract storage trie,
+//followed by the root and the encoded storage. No encoded storage without a root starts with it, the first
+//byte of those is the length of the balance.
+const StorageRootMark = byte(0xff)
+
+//HasStorageRoot reports whether the contract of the storage has a non empty storage trie
+func (s *Storage) HasStorageRoot() bool {
+	return s.StorageRoot != (crypto.Hash{}) && s.StorageRoot != trie.EmptyRoot
+}
+
+//EncodeStorage encodes the storage of an account for the state trie. Contract storage tries are only written from
+//the contract storage fork on, before it no storage has a root and every storage keeps the encoding the blocks
+//before the fork were hashed with.
+func EncodeStorage(storage *Storage) ([]byte, error) {
+	value, err := binary.Marshal(storage)
+	if err != nil || !storage.HasStorageRoot() {
+		return value, err
+	}
+	encoded := make([]byte, 0, 1+crypto.HashLength+len(value))
+	encoded = append(encoded, StorageRootMark)
+	encoded = append(encoded, storage.StorageRoot[:]...)
+	return append(encoded, value...), nil
+}
+
+//DecodeStorage decodes a storage encoded by EncodeStorage
+func DecodeStorage(value []byte) (*Storage, error) {
+	storage := &Storage{}
+	if len(value) > 0 && value[0] == StorageRootMark {
+		if len(value) <= 1+crypto.HashLength {
+			return nil, ErrStorageEncoding
+		}
+		storage.StorageRoot = crypto.Bytes2Hash(value[1 : 1+crypto.HashLength])
+		value = value[1+crypto.HashLength:]
+	}
+	err := binary.Unmarshal(value, storage)
+	if err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+//StorageRootOf returns the root of the contract storage trie carried by a value of the state trie. ok is only true
+//for a storage encoded with a root, the value has to decode and encode back to itself.
+func StorageRootOf(value []byte) (root crypto.Hash, ok bool) {
+	if len(value) == 0 || value[0] != StorageRootMark {
+		return root, false
+	}
+	storage, err := DecodeStorage(value)
+	if err != nil || !storage.HasStorageRoot() {
+		return root, false
+	}
+	encoded, err := EncodeStorage(storage)
+	if err != nil || !bytes.Equal(encoded, value) {
+		return root, false
+	}
+	return storage.StorageRoot, true
 }
 
 func newStorage() *Storage {
@@ -85,7 +160,7 @@ func (account *Account) Sign(hash []byte) ([]byte, error) {
 }
 
 func (s *Storage) Empty() bool {
-	return s.Nonce == 0 && s.Balance.Sign() == 0 && bytes.Equal(s.CodeHash[:], emptyCodeHash)
+	return s.Nonce == 0 && s.Balance.Sign() == 0 && bytes.Equal(s.CodeHash[:], emptyCodeHash) && len(s.BalanceMap) == 0
 }
 
 func NewNormalAccount(parent *Node, chainId ChainIdType) (*Account, error) {
@@ -1,9 +1,14 @@
 package types
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/binary"
 )
 
 func TestNewRootAccount(t *testing.T) {
@@ -43,3 +48,50 @@ func TestNewChildAccount(t *testing.T) {
 	fmt.Println("byteCode: ", child.Storage.ByteCode)
 	fmt.Println("codeHash: ", child.Storage.CodeHash)
 }
+
+func TestEncodeStorage(t *testing.T) {
+	storage := &Storage{Nonce: 3, Alias: "alice"}
+	storage.Balance.SetInt64(100)
+	legacy, err := binary.Marshal(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//a storage without a contract storage trie keeps the encoding of the blocks before the fork
+	for _, root := range []crypto.Hash{{}, trie.EmptyRoot} {
+		storage.StorageRoot = root
+		value, err := EncodeStorage(storage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(value, legacy) {
+			t.Fatalf("expect the legacy encoding for root %v, got %x", root, value)
+		}
+		if _, ok := StorageRootOf(value); ok {
+			t.Fatal("expect no storage root in the legacy encoding")
+		}
+	}
+
+	storage.StorageRoot = crypto.RandomHash()
+	value, err := EncodeStorage(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeStorage(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.StorageRoot != storage.StorageRoot || decoded.Nonce != 3 || decoded.Alias != "alice" || decoded.Balance.Int64() != 100 {
+		t.Fatalf("unexpected decoded storage %v", decoded)
+	}
+	if root, ok := StorageRootOf(value); !ok || root != storage.StorageRoot {
+		t.Fatalf("expect the storage root %v, got %v %v", storage.StorageRoot, root, ok)
+	}
+
+	//other values of the state trie are no storage roots, among them 32 byte values and values behind the mark
+	for _, other := range [][]byte{storage.StorageRoot[:], append([]byte{StorageRootMark}, storage.StorageRoot[:]...), append(value, 0)} {
+		if _, ok := StorageRootOf(other); ok {
+			t.Fatalf("expect no storage root in %x", other)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package types
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/binary"
+)
+
+//MaxAssetSymbolLength is the longest symbol of a native asset
+const MaxAssetSymbolLength = 12
+
+//NativeSymbol is the symbol of the coin of the chain, no asset may take it
+const NativeSymbol = "DREP"
+
+//Asset is a token issued natively, its balances are kept in the BalanceMap of the storage of their owners
+type Asset struct {
+	Symbol string               `json:"symbol"`
+	Issuer crypto.CommonAddress `json:"issuer"`
+	//Supply is the amount in circulation, raised by mints and lowered by burns
+	Supply common.Big `json:"supply"`
+	//MintAuthority may mint more of the asset, the empty address for a fixed supply
+	MintAuthority crypto.CommonAddress `json:"mintAuthority"`
+}
+
+//Mintable tells whether the supply of the asset may be raised
+func (asset *Asset) Mintable() bool {
+	return !asset.MintAuthority.IsEmpty()
+}
+
+//AssetIssue is the data of an IssueAssetType transaction, the supply is credited to the sender who becomes the issuer
+type AssetIssue struct {
+	Symbol        string               `json:"symbol"`
+	Supply        common.Big           `json:"supply"`
+	MintAuthority crypto.CommonAddress `json:"mintAuthority"`
+}
+
+//AssetAmount is the data of the transactions moving an asset
+type AssetAmount struct {
+	Symbol string     `json:"symbol"`
+	Amount common.Big `json:"amount"`
+}
+
+//GetAssetIssueFee returns the coin burnt to issue an asset whose symbol has len letters, like the alias fee the
+//short symbols cost the most so that they can not be taken for the gas alone
+func GetAssetIssueFee(len int) *big.Int {
+	switch {
+	case len <= 3:
+		return params.CoinFromNumer(100000)
+	case len == 4:
+		return params.CoinFromNumer(50000)
+	case len == 5:
+		return params.CoinFromNumer(20000)
+	case len == 6:
+		return params.CoinFromNumer(10000)
+	default:
+		return params.CoinFromNumer(5000)
+	}
+}
+
+//CheckAssetSymbol checks that symbol is made of 1 to MaxAssetSymbolLength upper case letters and digits and is
+//not the symbol of the coin of the chain
+func CheckAssetSymbol(symbol string) error {
+	if len(symbol) == 0 || len(symbol) > MaxAssetSymbolLength || symbol == NativeSymbol {
+		return ErrAssetSymbol
+	}
+	for _, c := range symbol {
+		if !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return ErrAssetSymbol
+		}
+	}
+	return nil
+}
+
+//NewIssueAssetTransaction creates the asset symbol with supply credited to the sender, mintAuthority may be the
+//empty address for a fixed supply
+func NewIssueAssetTransaction(symbol string, supply *big.Int, mintAuthority crypto.CommonAddress, gasPrice, gasLimit *big.Int, nonce uint64) (*Transaction, error) {
+	data, err := binary.Marshal(&AssetIssue{Symbol: symbol, Supply: common.Big(*supply), MintAuthority: mintAuthority})
+	if err != nil {
+		return nil, err
+	}
+	return newAssetTransaction(IssueAssetType, crypto.CommonAddress{}, data, gasPrice, gasLimit, nonce), nil
+}
+
+//NewAssetTransferTransaction pays amount of the asset symbol to to
+func NewAssetTransferTransaction(to crypto.CommonAddress, symbol string, amount, gasPrice, gasLimit *big.Int, nonce uint64) (*Transaction, error) {
+	return newAssetAmountTransaction(AssetTransferType, to, symbol, amount, gasPrice, gasLimit, nonce)
+}
+
+//NewAssetMintTransaction raises the supply of the asset symbol by amount credited to to, the sender must be the
+//mint authority of the asset
+func NewAssetMintTransaction(to crypto.CommonAddress, symbol string, amount, gasPrice, gasLimit *big.Int, nonce uint64) (*Transaction, error) {
+	return newAssetAmountTransaction(AssetMintType, to, symbol, amount, gasPrice, gasLimit, nonce)
+}
+
+//NewAssetBurnTransaction destroys amount of the asset symbol held by the sender
+func NewAssetBurnTransaction(symbol string, amount, gasPrice, gasLimit *big.Int, nonce uint64) (*Transaction, error) {
+	return newAssetAmountTransaction(AssetBurnType, crypto.CommonAddress{}, symbol, amount, gasPrice, gasLimit, nonce)
+}
+
+func newAssetAmountTransaction(txType TxType, to crypto.CommonAddress, symbol string, amount, gasPrice, gasLimit *big.Int, nonce uint64) (*Transaction, error) {
+	data, err := binary.Marshal(&AssetAmount{Symbol: symbol, Amount: common.Big(*amount)})
+	if err != nil {
+		return nil, err
+	}
+	return newAssetTransaction(txType, to, data, gasPrice, gasLimit, nonce), nil
+}
+
+func newAssetTransaction(txType TxType, to crypto.CommonAddress, data []byte, gasPrice, gasLimit *big.Int, nonce uint64) *Transaction {
+	txData := TransactionData{
+		Version:   common.Version,
+		Nonce:     nonce,
+		Type:      txType,
+		To:        to,
+		Amount:    *(*common.Big)(new(big.Int)),
+		GasPrice:  *(*common.Big)(gasPrice),
+		GasLimit:  *(*common.Big)(gasLimit),
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	}
+	return &Transaction{Data: txData}
+}
+
+//AssetIssue decodes the data of an IssueAssetType transaction
+func (tx *Transaction) AssetIssue() (*AssetIssue, error) {
+	issue := &AssetIssue{}
+	err := binary.Unmarshal(tx.GetData(), issue)
+	if err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+//AssetAmount decodes the data of the transactions moving an asset
+func (tx *Transaction) AssetAmount() (*AssetAmount, error) {
+	amount := &AssetAmount{}
+	err := binary.Unmarshal(tx.GetData(), amount)
+	if err != nil {
+		return nil, err
+	}
+	return amount, nil
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/binary"
+)
+
+func TestAssetBalancesEncoding(t *testing.T) {
+	storage := &Storage{BalanceMap: AssetBalances{}}
+	for i, symbol := range []string{"POINT", "GOLD", "A1", "ZZ", "MILES", "B"} {
+		storage.BalanceMap[symbol] = *big.NewInt(int64(i + 1))
+	}
+	encoded, err := binary.Marshal(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		again, _ := binary.Marshal(storage)
+		if !bytes.Equal(encoded, again) {
+			t.Fatal("expect the encoding of the balances independent of the order of the map")
+		}
+	}
+	decoded := &Storage{}
+	err = binary.Unmarshal(encoded, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.BalanceMap) != len(storage.BalanceMap) {
+		t.Fatalf("expect %d balances, got %d", len(storage.BalanceMap), len(decoded.BalanceMap))
+	}
+	for symbol, balance := range storage.BalanceMap {
+		got := decoded.BalanceMap[symbol]
+		if got.Cmp(&balance) != 0 {
+			t.Fatalf("expect %v %s, got %v", &balance, symbol, &got)
+		}
+	}
+
+	//a storage without assets keeps the encoding of the former map field
+	type oldStorage struct {
+		Balance    big.Int
+		Reputation big.Int
+		Nonce      uint64
+		ByteCode   []byte
+		CodeHash   [32]byte
+		Alias      string
+		BalanceMap map[string]big.Int
+	}
+	old, err := binary.Marshal(&oldStorage{Nonce: 3, Alias: "tom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := binary.Marshal(&Storage{Nonce: 3, Alias: "tom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(old, current) {
+		t.Fatalf("expect the former encoding %x, got %x", old, current)
+	}
+}
+
+func TestCheckAssetSymbol(t *testing.T) {
+	for symbol, valid := range map[string]bool{"POINT": true, "A1": true, "": false, "point": false, "DREP": false, "ABCDEFGHIJKLM": false, "PO-INT": false} {
+		if err := CheckAssetSymbol(symbol); valid != (err == nil) {
+			t.Fatalf("symbol %q: expect valid %v, got %v", symbol, valid, err)
+		}
+	}
+}
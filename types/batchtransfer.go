@@ -0,0 +1,52 @@
+package types
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/binary"
+)
+
+//MaxBatchPayouts is the most recipients a batch transfer may pay
+const MaxBatchPayouts = 1024
+
+//Payout is one recipient of a BatchTransferType transaction
+type Payout struct {
+	To     crypto.CommonAddress `json:"to"`
+	Amount common.Big           `json:"amount"`
+}
+
+//NewBatchTransferTransaction pays every payout in one transaction, its amount is the sum of the payouts
+func NewBatchTransferTransaction(payouts []Payout, gasPrice, gasLimit *big.Int, nonce uint64) (*Transaction, error) {
+	data, err := binary.Marshal(payouts)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for _, payout := range payouts {
+		total.Add(total, payout.Amount.ToInt())
+	}
+	txData := TransactionData{
+		Version:   common.Version,
+		Nonce:     nonce,
+		Type:      BatchTransferType,
+		Amount:    *(*common.Big)(total),
+		GasPrice:  *(*common.Big)(gasPrice),
+		GasLimit:  *(*common.Big)(gasLimit),
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	}
+	return &Transaction{Data: txData}, nil
+}
+
+//Payouts decodes the recipients of a batch transfer
+func (tx *Transaction) Payouts() ([]Payout, error) {
+	payouts := []Payout{}
+	err := binary.Unmarshal(tx.GetData(), &payouts)
+	if err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
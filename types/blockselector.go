@@ -0,0 +1,91 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+// BlockNumberOrHash selects the block whose state a query is executed against.
+// Exactly one of BlockNumber and BlockHash is set.
+type BlockNumberOrHash struct {
+	BlockNumber *common.BlockNumber `json:"blockNumber,omitempty"`
+	BlockHash   *crypto.Hash        `json:"blockHash,omitempty"`
+}
+
+// UnmarshalJSON parses the given JSON fragment into a BlockNumberOrHash. It supports:
+// - "latest", "earliest" or "pending" as string arguments
+// - the block height as a json number or a hex encoded string
+// - the block hash as a hex encoded string
+// - an object with either a "blockNumber" or a "blockHash" field
+func (bnh *BlockNumberOrHash) UnmarshalJSON(data []byte) error {
+	type selector BlockNumberOrHash
+	input := strings.TrimSpace(string(data))
+	if strings.HasPrefix(input, "{") {
+		e := selector{}
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		if (e.BlockNumber == nil) == (e.BlockHash == nil) {
+			return fmt.Errorf("exactly one of blockNumber and blockHash must be specified")
+		}
+		*bnh = BlockNumberOrHash(e)
+		return nil
+	}
+
+	if len(input) >= 2 && input[0] == '"' && input[len(input)-1] == '"' {
+		input = input[1 : len(input)-1]
+		if len(input) == 2+2*crypto.HashLength {
+			hash := crypto.Hash{}
+			if err := hash.UnmarshalText([]byte(input)); err != nil {
+				return err
+			}
+			bnh.BlockHash = &hash
+			return nil
+		}
+		number := common.BlockNumber(0)
+		if err := number.UnmarshalJSON([]byte(input)); err != nil {
+			return err
+		}
+		bnh.BlockNumber = &number
+		return nil
+	}
+
+	height, err := strconv.ParseInt(input, 10, 64)
+	if err != nil || height < 0 {
+		return fmt.Errorf("invalid block number %s", input)
+	}
+	number := common.BlockNumber(height)
+	bnh.BlockNumber = &number
+	return nil
+}
+
+// BlockNumberOrHashWithNumber returns a selector for the given block height or tag.
+func BlockNumberOrHashWithNumber(number common.BlockNumber) *BlockNumberOrHash {
+	return &BlockNumberOrHash{BlockNumber: &number}
+}
+
+// BlockNumberOrHashWithHash returns a selector for the block with the given hash.
+func BlockNumberOrHashWithHash(hash crypto.Hash) *BlockNumberOrHash {
+	return &BlockNumberOrHash{BlockHash: &hash}
+}
+
+func (bnh *BlockNumberOrHash) String() string {
+	if bnh.BlockHash != nil {
+		return bnh.BlockHash.String()
+	}
+	if bnh.BlockNumber != nil {
+		switch *bnh.BlockNumber {
+		case common.LatestBlockNumber:
+			return "latest"
+		case common.PendingBlockNumber:
+			return "pending"
+		}
+		return strconv.FormatInt(bnh.BlockNumber.Int64(), 10)
+	}
+	return "latest"
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+func TestBlockNumberOrHashUnmarshal(t *testing.T) {
+	hash := crypto.HexToHash("0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6")
+	tests := []struct {
+		input  string
+		number *common.BlockNumber
+		hash   *crypto.Hash
+		err    bool
+	}{
+		{input: `12`, number: bn(12)},
+		{input: `"0xc"`, number: bn(12)},
+		{input: `"latest"`, number: bn(common.LatestBlockNumber)},
+		{input: `"earliest"`, number: bn(common.EarliestBlockNumber)},
+		{input: `"pending"`, number: bn(common.PendingBlockNumber)},
+		{input: `"` + hash.String() + `"`, hash: &hash},
+		{input: `{"blockNumber":"0x1"}`, number: bn(1)},
+		{input: `{"blockHash":"` + hash.String() + `"}`, hash: &hash},
+		{input: `{"blockNumber":"0x1","blockHash":"` + hash.String() + `"}`, err: true},
+		{input: `{}`, err: true},
+		{input: `-1`, err: true},
+		{input: `"tip"`, err: true},
+	}
+
+	for _, test := range tests {
+		selector := BlockNumberOrHash{}
+		err := json.Unmarshal([]byte(test.input), &selector)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.input, err)
+			continue
+		}
+		if test.number != nil && (selector.BlockNumber == nil || *selector.BlockNumber != *test.number) {
+			t.Errorf("%s: block number mismatch, got %v", test.input, selector.BlockNumber)
+		}
+		if test.hash != nil && (selector.BlockHash == nil || *selector.BlockHash != *test.hash) {
+			t.Errorf("%s: block hash mismatch, got %v", test.input, selector.BlockHash)
+		}
+	}
+}
+
+func bn(number common.BlockNumber) *common.BlockNumber {
+	return &number
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/drep-project/binary"
+)
+
+//AssetBalances are the balances of the native assets of an account by symbol
+type AssetBalances map[string]big.Int
+
+//Symbols returns the symbols of the balances in order
+func (balances AssetBalances) Symbols() []string {
+	symbols := make([]string, 0, len(balances))
+	for symbol := range balances {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+//assetBalancesCodec writes the layout of the map codec of the binary package in the order of the symbols, the
+//encoding of a storage goes into the state root and must not depend on the iteration order of the map
+type assetBalancesCodec struct{}
+
+func (c *assetBalancesCodec) EncodeTo(e *binary.Encoder, rv reflect.Value) error {
+	balances := rv.Interface().(AssetBalances)
+	e.WriteUvarint(uint64(len(balances)))
+	for _, symbol := range balances.Symbols() {
+		e.WriteUint16(uint16(len(symbol)))
+		e.Write([]byte(symbol))
+		balance := balances[symbol]
+		contents := balance.Bytes()
+		e.WriteUvarint(uint64(len(contents)))
+		e.Write(contents)
+	}
+	return nil
+}
+
+func (c *assetBalancesCodec) DecodeTo(d *binary.Decoder, rv reflect.Value) error {
+	count, err := d.ReadUvarint()
+	if err != nil {
+		return err
+	}
+	balances := AssetBalances{}
+	for i := uint64(0); i < count; i++ {
+		length, err := d.ReadUint16()
+		if err != nil {
+			return err
+		}
+		symbol, err := d.Slice(int(length))
+		if err != nil {
+			return err
+		}
+		size, err := d.ReadUvarint()
+		if err != nil {
+			return err
+		}
+		contents, err := d.Slice(int(size))
+		if err != nil {
+			return err
+		}
+		balances[string(symbol)] = *new(big.Int).SetBytes(contents)
+	}
+	rv.Set(reflect.ValueOf(balances))
+	return nil
+}
+
+func init() {
+	binary.ImportCodeC(reflect.TypeOf(AssetBalances{}), &assetBalancesCodec{})
+}
@@ -16,6 +16,14 @@ const (
 	CandidateType        //Apply to be a candidate block node
 	CancelCandidateType  //Apply to be a candidate block node
 	RegisterProducer
+	CreateMultiSigType //Register an account spent by a threshold of keys
+	BatchTransferType  //Pay a list of recipients under one nonce
+	AliasTransferType  //Give the alias of the sender to another address
+	AliasReleaseType   //Free the alias of the sender
+	IssueAssetType     //Create a native asset
+	AssetTransferType  //Pay a native asset
+	AssetMintType      //Raise the supply of a native asset
+	AssetBurnType      //Destroy a native asset held by the sender
 )
 
 var (
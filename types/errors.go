@@ -3,5 +3,14 @@ package types
 import "errors"
 
 var (
-	ErrOutOfGas = errors.New("out of gas")
+	ErrOutOfGas          = errors.New("out of gas")
+	ErrMultiSigAccount   = errors.New("invalid multisig account")
+	ErrMultiSigBitmap    = errors.New("multisig bitmap does not match the keys of the account")
+	ErrMultiSigThreshold = errors.New("multisig signers below threshold")
+	ErrMultiSig          = errors.New("invalid multisig signature")
+	ErrNotMultiSig       = errors.New("transaction is not spent from a multisig account")
+	ErrUnverifiedSender  = errors.New("multisig sender not verified")
+	ErrNotSponsored      = errors.New("transaction has no sponsor")
+	ErrStorageEncoding   = errors.New("invalid storage encoding")
+	ErrAssetSymbol       = errors.New("asset symbol must be 1 to 12 upper case letters and digits other than DREP")
 )
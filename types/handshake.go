@@ -0,0 +1,77 @@
+package types
+
+import (
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/pkg/errors"
+)
+
+//Handshake sends the local status to the peer and reads the status of the peer, both sides send first so the
+//exchange does not depend on which one dialed. The peer is checked against the local status and fork schedule,
+//the cause of the returned error is the reason to disconnect it.
+func Handshake(rw p2p.MsgReadWriter, local *Status, forks *params.ForkConfig, timeout time.Duration) (*Status, error) {
+	sendCh := make(chan error, 1)
+	go func() {
+		sendCh <- p2p.Send(rw, MsgTypeStatus, local)
+	}()
+	readCh := make(chan error, 1)
+	remote := &Status{}
+	go func() {
+		readCh <- readStatus(rw, remote)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-sendCh:
+			if err != nil {
+				return nil, err
+			}
+		case err := <-readCh:
+			if err != nil {
+				return nil, err
+			}
+		case <-timer.C:
+			return nil, p2p.DiscReadTimeout
+		}
+	}
+	return remote, CheckStatus(local, remote, forks)
+}
+
+func readStatus(rw p2p.MsgReader, status *Status) error {
+	msg, err := rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer msg.Discard()
+	if msg.Code != MsgTypeStatus {
+		return errors.Wrapf(p2p.DiscProtocolError, "first message %d is not a status", msg.Code)
+	}
+	if msg.Size > MaxMsgSize {
+		return errors.Wrapf(p2p.DiscProtocolError, "status of %d bytes", msg.Size)
+	}
+	if err := msg.Decode(status); err != nil {
+		return errors.Wrapf(p2p.DiscProtocolError, "decode status: %v", err)
+	}
+	return nil
+}
+
+//CheckStatus checks that the peer speaks the same protocol version and follows the same chain as the local node
+func CheckStatus(local, remote *Status, forks *params.ForkConfig) error {
+	if remote.ProtocolVersion != local.ProtocolVersion {
+		return errors.Wrapf(p2p.DiscIncompatibleVersion, "protocol version %d, local %d", remote.ProtocolVersion, local.ProtocolVersion)
+	}
+	if remote.ChainId != local.ChainId {
+		return errors.Wrapf(p2p.DiscIncompatibleChain, "chain id %d, local %d", remote.ChainId, local.ChainId)
+	}
+	if remote.Genesis != local.Genesis {
+		return errors.Wrapf(p2p.DiscIncompatibleChain, "genesis %s, local %s", remote.Genesis.String(), local.Genesis.String())
+	}
+	if err := forks.CheckForkID(local.Genesis.Bytes(), local.Height, remote.ForkID); err != nil {
+		return errors.Wrapf(p2p.DiscIncompatibleChain, "fork id %x next %d: %v", remote.ForkID.Hash, remote.ForkID.Next, err)
+	}
+	return nil
+}
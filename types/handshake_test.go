@@ -0,0 +1,97 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/pkg/errors"
+)
+
+func TestHandshake(t *testing.T) {
+	forks := &params.ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 20}
+	genesis := crypto.Hash{1}
+	status := func(modify func(status *Status)) *Status {
+		status := &Status{
+			ProtocolVersion: BlockProtocolVersion,
+			ChainId:         1,
+			Genesis:         genesis,
+			BestHash:        crypto.Hash{2},
+			Height:          15,
+			ForkID:          forks.ForkID(genesis.Bytes(), 15),
+		}
+		if modify != nil {
+			modify(status)
+		}
+		return status
+	}
+
+	//a peer which moved a fork
+	otherForks := &params.ForkConfig{ContractStorageHeight: 10, SystemLogHeight: 12}
+	tests := []struct {
+		remote *Status
+		forks  *params.ForkConfig
+		reason error
+	}{
+		{status(nil), forks, nil},
+		//a peer behind on the same chain
+		{status(func(status *Status) { status.Height, status.ForkID = 5, forks.ForkID(genesis.Bytes(), 5) }), forks, nil},
+		{status(func(status *Status) { status.ProtocolVersion++ }), forks, p2p.DiscIncompatibleVersion},
+		{status(func(status *Status) { status.ChainId = 2 }), forks, p2p.DiscIncompatibleChain},
+		{status(func(status *Status) { status.Genesis = crypto.Hash{3} }), forks, p2p.DiscIncompatibleChain},
+		{status(func(status *Status) { status.ForkID = otherForks.ForkID(genesis.Bytes(), 15) }), otherForks, p2p.DiscIncompatibleChain},
+	}
+	for i, test := range tests {
+		local := status(nil)
+		rw1, rw2 := p2p.MsgPipe()
+		type result struct {
+			status *Status
+			err    error
+		}
+		remoteCh := make(chan result, 1)
+		go func() {
+			remote, err := Handshake(rw2, test.remote, test.forks, time.Second)
+			remoteCh <- result{remote, err}
+		}()
+		remote, err := Handshake(rw1, local, forks, time.Second)
+		other := <-remoteCh
+		rw1.Close()
+		rw2.Close()
+
+		if errors.Cause(err) != test.reason {
+			t.Fatalf("case %d: local side expected %v, got %v", i, test.reason, err)
+		}
+		if errors.Cause(other.err) != test.reason {
+			t.Fatalf("case %d: remote side expected %v, got %v", i, test.reason, other.err)
+		}
+		if remote.Height != test.remote.Height || other.status.Height != local.Height {
+			t.Fatalf("case %d: statuses not exchanged, got %+v and %+v", i, remote, other.status)
+		}
+	}
+}
+
+func TestHandshakeUnexpectedPeer(t *testing.T) {
+	forks := &params.ForkConfig{}
+	local := &Status{ProtocolVersion: BlockProtocolVersion, ForkID: forks.ForkID(crypto.Hash{}.Bytes(), 0)}
+
+	//a peer of the former protocol starts with a state request
+	rw1, rw2 := p2p.MsgPipe()
+	go func() {
+		p2p.Send(rw2, MsgTypePeerStateReq, &PeerStateReq{Height: 1})
+		p2p.ExpectMsg(rw2, MsgTypeStatus, local)
+	}()
+	if _, err := Handshake(rw1, local, forks, time.Second); errors.Cause(err) != p2p.DiscProtocolError {
+		t.Fatalf("expected %v, got %v", p2p.DiscProtocolError, err)
+	}
+	rw1.Close()
+
+	//a peer which never answers
+	rw1, rw2 = p2p.MsgPipe()
+	go p2p.ExpectMsg(rw2, MsgTypeStatus, local)
+	if _, err := Handshake(rw1, local, forks, 50*time.Millisecond); errors.Cause(err) != p2p.DiscReadTimeout {
+		t.Fatalf("expected %v, got %v", p2p.DiscReadTimeout, err)
+	}
+	rw1.Close()
+}
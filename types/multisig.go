@@ -0,0 +1,309 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1/schnorr"
+	"github.com/drep-project/binary"
+)
+
+const (
+	//MultiSigMark is the first byte of the sender signature of a transaction spent from a multisig account, followed by
+	//the encoded MultiSigProof. A compact signature starts with its recovery code, which is at least 27.
+	MultiSigMark = byte(1)
+	//MaxMultiSigKeys is the most keys a multisig account may register
+	MaxMultiSigKeys = 32
+)
+
+//keyCoefficientPrefix separates the hash of a key coefficient from the other hashes
+var keyCoefficientPrefix = []byte("DREP multisig key:")
+
+//MultiSigAccount is the data of a CreateMultiSigType transaction and the record of the created account,
+//transactions from the account must be signed by Threshold of Pubkeys
+type MultiSigAccount struct {
+	Threshold uint64
+	Pubkeys   []*secp256k1.PublicKey
+}
+
+//Check checks the threshold and that the keys are distinct
+func (account *MultiSigAccount) Check() error {
+	if len(account.Pubkeys) == 0 || len(account.Pubkeys) > MaxMultiSigKeys {
+		return fmt.Errorf("%v, %d keys, at most %d", ErrMultiSigAccount, len(account.Pubkeys), MaxMultiSigKeys)
+	}
+	if account.Threshold == 0 || account.Threshold > uint64(len(account.Pubkeys)) {
+		return fmt.Errorf("%v, threshold %d of %d keys", ErrMultiSigAccount, account.Threshold, len(account.Pubkeys))
+	}
+	for i, pubkey := range account.Pubkeys {
+		if pubkey == nil {
+			return fmt.Errorf("%v, key %d missing", ErrMultiSigAccount, i)
+		}
+		for _, other := range account.Pubkeys[:i] {
+			if pubkey.IsEqual(other) {
+				return fmt.Errorf("%v, key %d registered twice", ErrMultiSigAccount, i)
+			}
+		}
+	}
+	return nil
+}
+
+//KeyIndex returns the position of pubkey in the keys of the account, -1 if it is not one of them
+func (account *MultiSigAccount) KeyIndex(pubkey *secp256k1.PublicKey) int {
+	for i, key := range account.Pubkeys {
+		if key.IsEqual(pubkey) {
+			return i
+		}
+	}
+	return -1
+}
+
+//KeyCoefficient returns the factor of the key at index in the aggregated key of a schnorr signature. It hashes
+//all the keys of the account, so that a key can't be chosen to cancel the other keys out of the aggregate.
+func (account *MultiSigAccount) KeyCoefficient(index int) *big.Int {
+	data := [][]byte{keyCoefficientPrefix}
+	for _, pubkey := range account.Pubkeys {
+		data = append(data, pubkey.SerializeCompressed())
+	}
+	data = append(data, account.Pubkeys[index].SerializeCompressed())
+	coefficient := new(big.Int).SetBytes(crypto.Keccak256Hash(data...).Bytes())
+	return coefficient.Mod(coefficient, secp256k1.S256().N)
+}
+
+//AggregateKey returns the key verifying the schnorr signature combined by the keys at indexes, the sum of the
+//keys weighted by their KeyCoefficient
+func (account *MultiSigAccount) AggregateKey(indexes []int) *secp256k1.PublicKey {
+	curve := secp256k1.S256()
+	pubkeys := make([]*secp256k1.PublicKey, len(indexes))
+	for i, index := range indexes {
+		pubkey := account.Pubkeys[index]
+		x, y := curve.ScalarMult(pubkey.GetX(), pubkey.GetY(), account.KeyCoefficient(index).Bytes())
+		pubkeys[i] = secp256k1.NewPublicKey(x, y)
+	}
+	return schnorr.CombinePubkeys(pubkeys)
+}
+
+//SigningKey returns the private key the key at index signs its partial schnorr signature with, privkey weighted by
+//its KeyCoefficient
+func (account *MultiSigAccount) SigningKey(index int, privkey *secp256k1.PrivateKey) *secp256k1.PrivateKey {
+	d := new(big.Int).Mul(privkey.D, account.KeyCoefficient(index))
+	return secp256k1.NewPrivateKey(d.Mod(d, secp256k1.S256().N))
+}
+
+//Verify checks that proof holds the signatures of at least Threshold keys of the account over hash
+func (account *MultiSigAccount) Verify(hash []byte, proof *MultiSigProof) error {
+	if len(proof.Bitmap) != len(account.Pubkeys) {
+		return ErrMultiSigBitmap
+	}
+	signers := []int{}
+	for i, val := range proof.Bitmap {
+		switch val {
+		case 0:
+		case 1:
+			signers = append(signers, i)
+		default:
+			return ErrMultiSigBitmap
+		}
+	}
+	if uint64(len(signers)) < account.Threshold {
+		return ErrMultiSigThreshold
+	}
+
+	if len(proof.Sig) > 0 {
+		sig, err := schnorr.ParseSignature(proof.Sig)
+		if err != nil {
+			return ErrMultiSig
+		}
+		sigmaPk := account.AggregateKey(signers)
+		if sigmaPk == nil || !schnorr.Verify(sigmaPk, hash, sig.R, sig.S) {
+			return ErrMultiSig
+		}
+		return nil
+	}
+
+	if len(proof.Sigs) != len(signers) {
+		return ErrMultiSig
+	}
+	for i, sig := range proof.Sigs {
+		pubkey, _, err := secp256k1.RecoverCompact(sig, hash)
+		if err != nil || !pubkey.IsEqual(account.Pubkeys[signers[i]]) {
+			return ErrMultiSig
+		}
+	}
+	return nil
+}
+
+//VerifyTransaction checks the proof of tx, spent from the account, and makes it the sender of tx once proven
+func (account *MultiSigAccount) VerifyTransaction(tx *Transaction) error {
+	proof, err := tx.MultiSigProof()
+	if err != nil {
+		return err
+	}
+	err = account.Verify(tx.SigHash(), proof)
+	if err != nil {
+		return err
+	}
+	tx.from.Store(&proof.Account)
+	return nil
+}
+
+//MultiSigProof takes the place of the signature of a transaction spent from a multisig account. Bitmap has
+//one byte per key of the account, 1 if the key signed. Sig is the schnorr signature of the signers combined
+//with schnorr.CombineSigs, each of them signing with its MultiSigAccount.SigningKey. If it is empty Sigs holds the compact signature of every signer in key order.
+//While the signers collect their partial schnorr signatures they are kept in Sigs as well.
+type MultiSigProof struct {
+	Account crypto.CommonAddress
+	Bitmap  []byte
+	Sig     []byte
+	Sigs    [][]byte
+}
+
+//NewMultiSigProof returns a proof without signatures for an account with keys keys
+func NewMultiSigProof(account crypto.CommonAddress, keys int) *MultiSigProof {
+	return &MultiSigProof{Account: account, Bitmap: make([]byte, keys)}
+}
+
+//AddSig puts the signature of the key at index, replacing the one it may have given before
+func (proof *MultiSigProof) AddSig(index int, sig []byte) error {
+	if index < 0 || index >= len(proof.Bitmap) {
+		return ErrMultiSigBitmap
+	}
+	pos := 0
+	for i := 0; i < index; i++ {
+		if proof.Bitmap[i] == 1 {
+			pos++
+		}
+	}
+	if proof.Bitmap[index] == 1 {
+		proof.Sigs[pos] = sig
+		return nil
+	}
+	proof.Bitmap[index] = 1
+	proof.Sigs = append(proof.Sigs, nil)
+	copy(proof.Sigs[pos+1:], proof.Sigs[pos:])
+	proof.Sigs[pos] = sig
+	proof.Sig = nil
+	return nil
+}
+
+//CombinePartialSigs replaces the partial schnorr signatures in Sigs with their combination, every signer
+//must have signed with the public nonces of exactly the signers of the proof
+func (proof *MultiSigProof) CombinePartialSigs() error {
+	sigs := make([]*schnorr.Signature, len(proof.Sigs))
+	for i, data := range proof.Sigs {
+		sig, err := schnorr.ParseSignature(data)
+		if err != nil {
+			return err
+		}
+		sigs[i] = sig
+	}
+	sig, err := schnorr.CombineSigs(secp256k1.S256(), sigs)
+	if err != nil {
+		return err
+	}
+	proof.Sig = sig.Serialize()
+	proof.Sigs = nil
+	return nil
+}
+
+//IsPartial reports whether Sigs holds partial schnorr signatures instead of compact signatures
+func (proof *MultiSigProof) IsPartial() bool {
+	return len(proof.Sigs) > 0 && len(proof.Sigs[0]) == schnorr.SignatureSize
+}
+
+//MergeMultiSigProofs collects the signatures of proofs for the same transaction into one proof
+func MergeMultiSigProofs(proofs []*MultiSigProof) (*MultiSigProof, error) {
+	if len(proofs) == 0 {
+		return nil, ErrMultiSigBitmap
+	}
+	merged := NewMultiSigProof(proofs[0].Account, len(proofs[0].Bitmap))
+	for _, proof := range proofs {
+		if proof.Account != merged.Account || len(proof.Bitmap) != len(merged.Bitmap) {
+			return nil, fmt.Errorf("%v, proofs of different accounts", ErrMultiSigBitmap)
+		}
+		if len(proof.Sig) > 0 {
+			return proof, nil
+		}
+		pos := 0
+		for i, val := range proof.Bitmap {
+			if val != 1 {
+				continue
+			}
+			if pos >= len(proof.Sigs) {
+				return nil, ErrMultiSigBitmap
+			}
+			if err := merged.AddSig(i, proof.Sigs[pos]); err != nil {
+				return nil, err
+			}
+			pos++
+		}
+	}
+	return merged, nil
+}
+
+//ClaimedFrom returns the sender of tx, for a multisig transaction the account its proof names without checking the
+//proof. It serves the transactions of the chain, which were verified when their block was, use From for the others.
+func (tx *Transaction) ClaimedFrom() (*crypto.CommonAddress, error) {
+	if !tx.IsMultiSig() {
+		return tx.From()
+	}
+	if sc := tx.from.Load(); sc != nil {
+		return sc.(*crypto.CommonAddress), nil
+	}
+	proof, err := tx.MultiSigProof()
+	if err != nil {
+		return nil, err
+	}
+	return &proof.Account, nil
+}
+
+//IsMultiSig reports whether tx is spent from a multisig account
+func (tx *Transaction) IsMultiSig() bool {
+	sig := tx.SenderSig()
+	return len(sig) > 0 && sig[0] == MultiSigMark
+}
+
+//MultiSigProof decodes the proof of a transaction spent from a multisig account
+func (tx *Transaction) MultiSigProof() (*MultiSigProof, error) {
+	if !tx.IsMultiSig() {
+		return nil, ErrNotMultiSig
+	}
+	proof := &MultiSigProof{}
+	err := binary.Unmarshal(tx.SenderSig()[1:], proof)
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+//SetMultiSigProof sets proof as the signature of the sender of tx, like Sig it must be set before the sender of
+//tx is read
+func (tx *Transaction) SetMultiSigProof(proof *MultiSigProof) error {
+	data, err := binary.Marshal(proof)
+	if err != nil {
+		return err
+	}
+	return tx.SetSenderSig(append([]byte{MultiSigMark}, data...))
+}
+
+//NewCreateMultiSigTransaction creates a multisig account for account and moves amount to it
+func NewCreateMultiSigTransaction(account *MultiSigAccount, amount, gasPrice, gasLimit *big.Int, nonce uint64) (*Transaction, error) {
+	data, err := binary.Marshal(account)
+	if err != nil {
+		return nil, err
+	}
+	txData := TransactionData{
+		Version:   common.Version,
+		Nonce:     nonce,
+		Type:      CreateMultiSigType,
+		Amount:    *(*common.Big)(amount),
+		GasPrice:  *(*common.Big)(gasPrice),
+		GasLimit:  *(*common.Big)(gasLimit),
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	}
+	return &Transaction{Data: txData}, nil
+}
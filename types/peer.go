@@ -11,11 +11,13 @@ import (
 )
 
 var (
-	maxCacheBlockNum = 1024
-	maxCacheTxNum    = 1024 //Maximum number of cached transactions per account
+	maxCacheBlockNum  = 1024
+	maxCacheTxNum     = 1024 //Maximum number of cached transactions per account
+	maxCacheTxHashNum = 4096 //Maximum number of cached transaction announcements
 )
 
 type PeerInfoInterface interface {
+	GetPeer() *p2p.Peer
 	GetMsgRW() p2p.MsgReadWriter
 	GetHeight() uint64
 	GetAddr() string
@@ -23,6 +25,8 @@ type PeerInfoInterface interface {
 	SetHeight(height uint64)
 	KnownTx(tx *Transaction) bool
 	MarkTx(tx *Transaction)
+	KnownTxHash(hash *crypto.Hash) bool
+	MarkTxHash(hash *crypto.Hash)
 	KnownBlock(blk *Block) bool
 	MarkBlock(blk *Block)
 
@@ -39,6 +43,8 @@ type PeerInfo struct {
 	height      uint64                                //Peer current block height
 	exchangeTxs map[crypto.Hash]struct{}              //transaction records exchanged with Peer
 	knownTxs    map[crypto.CommonAddress]*sortedBiMap //sorted by NONCE
+	knownHashes *sortedBiMap                          //announced transactions, sorted by arrival
+	hashSeq     uint64                                //arrival counter of knownHashes
 	knownBlocks *sortedBiMap                          //sorted by height
 	peer        *p2p.Peer                             //p2p peer layer
 	rw          p2p.MsgReadWriter                     //the protocol corresponding to peer
@@ -52,6 +58,7 @@ func NewPeerInfo(p *p2p.Peer, rw p2p.MsgReadWriter) *PeerInfo {
 		rw:          rw,
 		height:      0,
 		knownTxs:    make(map[crypto.CommonAddress]*sortedBiMap),
+		knownHashes: newValueSortedBiMap(),
 		knownBlocks: newValueSortedBiMap(),
 		reqTime:     nil,
 		averageRtt:  0,
@@ -91,6 +98,11 @@ func (peer *PeerInfo) GetAddr() string {
 	return peer.peer.IP()
 }
 
+//Gets the p2p layer peer
+func (peer *PeerInfo) GetPeer() *p2p.Peer {
+	return peer.peer
+}
+
 //Gets the read-write handle
 func (peer *PeerInfo) GetMsgRW() p2p.MsgReadWriter {
 	return peer.rw
@@ -121,7 +133,7 @@ func (peer *PeerInfo) KnownTx(tx *Transaction) bool {
 		}
 	}
 
-	return false
+	return peer.knownHashes.Exist(hash)
 }
 
 //Record the corresponding tx to avoid sending each other multiple times
@@ -144,6 +156,26 @@ func (peer *PeerInfo) MarkTx(tx *Transaction) {
 	peer.knownTxs[*addr] = sortedTxs
 }
 
+//Whether the tx hash was announced to or by the peer
+func (peer *PeerInfo) KnownTxHash(hash *crypto.Hash) bool {
+	return peer.knownHashes.Exist(hash)
+}
+
+//Record a tx hash announced to or by the peer, the oldest announcements are forgotten first
+func (peer *PeerInfo) MarkTxHash(hash *crypto.Hash) {
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+
+	if peer.knownHashes.Exist(hash) {
+		return
+	}
+	if peer.knownHashes.Len() > maxCacheTxHashNum {
+		peer.knownHashes.BatchRemove(1)
+	}
+	peer.hashSeq++
+	peer.knownHashes.Put(hash, peer.hashSeq)
+}
+
 func (peer *PeerInfo) KnownBlock(blk *Block) bool {
 	h := blk.Header.Hash()
 	if h == nil {
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+func TestMarkTxHash(t *testing.T) {
+	peer := NewPeerInfo(nil, nil)
+	hashes := make([]crypto.Hash, maxCacheTxHashNum+2)
+	for i := range hashes {
+		hashes[i][0], hashes[i][1] = byte(i), byte(i>>8)
+	}
+	for i := range hashes {
+		peer.MarkTxHash(&hashes[i])
+	}
+	//marking again does not make an announcement younger
+	peer.MarkTxHash(&hashes[1])
+
+	if peer.KnownTxHash(&hashes[0]) {
+		t.Fatal("oldest announcement not forgotten")
+	}
+	for i := 1; i < len(hashes); i++ {
+		if !peer.KnownTxHash(&hashes[i]) {
+			t.Fatalf("announcement %d forgotten", i)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/binary"
+)
+
+//SponsorMark is the first byte of the Sig of a transaction whose gas is paid by a sponsor, followed by the
+//encoded SponsoredSig
+const SponsorMark = byte(2)
+
+//sponsorPrefix separates the message signed by a sponsor from the hash signed by a sender
+var sponsorPrefix = []byte("DREP sponsor:")
+
+//SponsoredSig takes the place of the signature of a sponsored transaction. Sig is the signature of the sender,
+//a compact signature, a multisig proof or either of them with a validity window, SponsorSig the compact signature
+//of the sponsor over SponsorHash.
+type SponsoredSig struct {
+	Sig        []byte
+	SponsorSig []byte
+}
+
+//SponsorHash returns the message a sponsor signs for the sender from, it commits to the hash of the transaction and
+//so to its gas price and limit and to its validity window, and to the sender so that the signature can't be moved
+//to the same transaction of another account
+func (tx *Transaction) SponsorHash(from *crypto.CommonAddress) []byte {
+	return crypto.Keccak256Hash(sponsorPrefix, tx.SigHash(), from.Bytes()).Bytes()
+}
+
+//IsSponsored reports whether the gas of tx is paid by a sponsor
+func (tx *Transaction) IsSponsored() bool {
+	return len(tx.Sig) > 0 && tx.Sig[0] == SponsorMark
+}
+
+//sponsoredSig decodes the signatures of a sponsored transaction
+func (tx *Transaction) sponsoredSig() (*SponsoredSig, error) {
+	if !tx.IsSponsored() {
+		return nil, ErrNotSponsored
+	}
+	sig := &SponsoredSig{}
+	err := binary.Unmarshal(tx.Sig[1:], sig)
+	if err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+//unsponsoredSig returns Sig without the envelope of a sponsor
+func (tx *Transaction) unsponsoredSig() []byte {
+	if !tx.IsSponsored() {
+		return tx.Sig
+	}
+	sig, err := tx.sponsoredSig()
+	if err != nil {
+		return nil
+	}
+	return sig.Sig
+}
+
+//SenderSig returns the signature of the sender over SigHash, Sig itself unless tx is sponsored or has a
+//validity window
+func (tx *Transaction) SenderSig() []byte {
+	sig := tx.unsponsoredSig()
+	if !isValiditySig(sig) {
+		return sig
+	}
+	validitySig, err := decodeValiditySig(sig)
+	if err != nil {
+		return nil
+	}
+	return validitySig.Sig
+}
+
+//SetSenderSig sets the signature of the sender, keeping the validity window and the signature of a sponsor
+func (tx *Transaction) SetSenderSig(senderSig []byte) error {
+	if window := tx.ValidityWindow(); window != nil {
+		data, err := binary.Marshal(&ValiditySig{Window: *window, Sig: senderSig})
+		if err != nil {
+			return err
+		}
+		senderSig = append([]byte{ValidityMark}, data...)
+	}
+	if !tx.IsSponsored() {
+		tx.Sig = senderSig
+		return nil
+	}
+	sig, err := tx.sponsoredSig()
+	if err != nil {
+		return err
+	}
+	sig.Sig = senderSig
+	return tx.setSponsoredSig(sig)
+}
+
+func (tx *Transaction) setSponsoredSig(sig *SponsoredSig) error {
+	data, err := binary.Marshal(sig)
+	if err != nil {
+		return err
+	}
+	tx.Sig = append([]byte{SponsorMark}, data...)
+	return nil
+}
+
+//SetSponsorSig adds the signature of a sponsor over SponsorHash, the sender may sign before or after
+func (tx *Transaction) SetSponsorSig(sponsorSig []byte) error {
+	return tx.setSponsoredSig(&SponsoredSig{Sig: tx.unsponsoredSig(), SponsorSig: sponsorSig})
+}
+
+//Sponsor returns the address paying the gas of a sponsored transaction
+func (tx *Transaction) Sponsor() (*crypto.CommonAddress, error) {
+	if sc := tx.sponsor.Load(); sc != nil {
+		return sc.(*crypto.CommonAddress), nil
+	}
+	sig, err := tx.sponsoredSig()
+	if err != nil {
+		return nil, err
+	}
+	from, err := tx.ClaimedFrom()
+	if err != nil {
+		return nil, err
+	}
+	pk, _, err := secp256k1.RecoverCompact(sig.SponsorSig, tx.SponsorHash(from))
+	if err != nil {
+		return nil, err
+	}
+	addr := crypto.PubkeyToAddress(pk)
+	tx.sponsor.Store(&addr)
+	return &addr, nil
+}
+
+//Payer returns the address paying the gas of tx, the sponsor if it has one, otherwise the sender
+func (tx *Transaction) Payer() (*crypto.CommonAddress, error) {
+	if tx.IsSponsored() {
+		return tx.Sponsor()
+	}
+	return tx.From()
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+//SystemLogAddress is the address of the logs emitted by the transactions executed natively (transfer, stake, alias),
+//no account lives at it
+var SystemLogAddress = crypto.HexToAddress("0x00000000000000000000000000000000000000ff")
+
+//The first topic of a system log identifies the event, the following topics are the involved addresses
+//left padded to 32 bytes.
+//  Transfer         topics: event, from, to     data: amount, 32 bytes big endian
+//  VoteCredit       topics: event, from, to     data: amount, 32 bytes big endian
+//  CancelVoteCredit topics: event, from, to     data: json encoded CancelCreditDetail
+//  Candidate        topics: event, from         data: amount, 32 bytes big endian
+//  CancelCandidate  topics: event, from         data: json encoded CancelCreditDetail
+//  SetAlias         topics: event, from, keccak256(alias)     data: alias
+//  AliasTransfer    topics: event, from, keccak256(alias), to data: alias
+//  AliasRelease     topics: event, from, keccak256(alias)     data: alias
+//  CreateMultiSig   topics: event, from         data: created account left padded to 32 bytes, amount
+//  Sponsor          topics: event, sponsor, from     data: fee paid by the sponsor, 32 bytes big endian
+//  IssueAsset       topics: event, from, keccak256(symbol)     data: supply, 32 bytes big endian, symbol
+//  AssetTransfer    topics: event, from, to, keccak256(symbol) data: amount, 32 bytes big endian, symbol
+//  AssetMint        topics: event, from, to, keccak256(symbol) data: amount, 32 bytes big endian, symbol
+//  AssetBurn        topics: event, from, keccak256(symbol)     data: amount, 32 bytes big endian, symbol
+var (
+	TransferEvent         = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	VoteCreditEvent       = crypto.Keccak256Hash([]byte("VoteCredit(address,address,uint256)"))
+	CancelVoteCreditEvent = crypto.Keccak256Hash([]byte("CancelVoteCredit(address,address,uint256)"))
+	CandidateEvent        = crypto.Keccak256Hash([]byte("Candidate(address,uint256)"))
+	CancelCandidateEvent  = crypto.Keccak256Hash([]byte("CancelCandidate(address,uint256)"))
+	SetAliasEvent         = crypto.Keccak256Hash([]byte("SetAlias(address,string)"))
+	AliasTransferEvent    = crypto.Keccak256Hash([]byte("AliasTransfer(address,string,address)"))
+	AliasReleaseEvent     = crypto.Keccak256Hash([]byte("AliasRelease(address,string)"))
+	CreateMultiSigEvent   = crypto.Keccak256Hash([]byte("CreateMultiSig(address,address,uint256)"))
+	SponsorEvent          = crypto.Keccak256Hash([]byte("Sponsor(address,address,uint256)"))
+	IssueAssetEvent       = crypto.Keccak256Hash([]byte("IssueAsset(address,string,uint256)"))
+	AssetTransferEvent    = crypto.Keccak256Hash([]byte("AssetTransfer(address,address,string,uint256)"))
+	AssetMintEvent        = crypto.Keccak256Hash([]byte("AssetMint(address,address,string,uint256)"))
+	AssetBurnEvent        = crypto.Keccak256Hash([]byte("AssetBurn(address,string,uint256)"))
+)
+
+//AddressTopic left pads an address to a log topic
+func AddressTopic(addr *crypto.CommonAddress) crypto.Hash {
+	return crypto.BytesToHash(addr.Bytes())
+}
+
+//AmountData encodes an amount as the 32 bytes big endian data of a system log
+func AmountData(amount *big.Int) []byte {
+	return common.LeftPadBytes(amount.Bytes(), crypto.HashLength)
+}
+
+//AssetData encodes an amount of an asset as the data of a system log, the amount is followed by the symbol
+func AssetData(amount *big.Int, symbol string) []byte {
+	return append(AmountData(amount), symbol...)
+}
+
+//NewSystemLog creates a log of a natively executed transaction
+func NewSystemLog(tx *Transaction, height uint64, data []byte, topics ...crypto.Hash) *Log {
+	return &Log{
+		TxType:  tx.Type(),
+		Address: SystemLogAddress,
+		Topics:  topics,
+		Data:    data,
+		ChainId: tx.ChainId(),
+		TxHash:  *tx.TxHash(),
+		Height:  height,
+	}
+}
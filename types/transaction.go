@@ -4,7 +4,6 @@ import (
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
-	"github.com/drep-project/DREP-Chain/crypto/sha3"
 	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/binary"
 	"math"
@@ -21,6 +20,7 @@ type Transaction struct {
 	signMessage atomic.Value `json:"-" binary:"ignore" bson:"-"`
 	message     atomic.Value `json:"-" binary:"ignore" bson:"-"`
 	from        atomic.Value `json:"-" binary:"ignore"`
+	sponsor     atomic.Value `json:"-" binary:"ignore"`
 }
 
 type TransactionData struct {
@@ -51,12 +51,18 @@ func (tx *Transaction) Gas() uint64 {
 	return (&bigInt).Uint64()
 }
 
+//From returns the sender of tx, the account of a multisig transaction only once its proof is checked against the
+//keys of the account, see MultiSigAccount.VerifyTransaction
 func (tx *Transaction) From() (*crypto.CommonAddress, error) {
 	if sc := tx.from.Load(); sc != nil {
 		return sc.(*crypto.CommonAddress), nil
 	}
 
-	pk, _, err := secp256k1.RecoverCompact(tx.Sig, tx.TxHash().Bytes())
+	if tx.IsMultiSig() {
+		return nil, ErrUnverifiedSender
+	}
+
+	pk, _, err := secp256k1.RecoverCompact(tx.SenderSig(), tx.SigHash())
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +81,7 @@ func (tx *Transaction) GetData() []byte {
 	return tx.Data.Data
 }
 
+//Cost returns the gas and the amount of tx, the sender of a sponsored transaction pays the amount only
 func (tx *Transaction) Cost() *big.Int {
 	total := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
 	total.Add(total, tx.Amount())
@@ -108,10 +115,8 @@ func (tx *Transaction) TxHash() *crypto.Hash {
 		return val.(*crypto.Hash)
 	}
 
-	b := tx.AsSignMessage()
-	h := sha3.Keccak256(b)
 	txHash := &crypto.Hash{}
-	txHash.SetBytes(h)
+	txHash.SetBytes(tx.hash())
 	tx.txHash.Store(txHash)
 	return txHash
 }
@@ -239,6 +244,37 @@ func NewAliasTransaction(alias string, gasPrice, gasLimit *big.Int, nonce uint64
 	return &Transaction{Data: data}
 }
 
+//Give the alias of the sender to the address to
+func NewAliasTransferTransaction(to crypto.CommonAddress, alias string, gasPrice, gasLimit *big.Int, nonce uint64) *Transaction {
+	data := TransactionData{
+		Version:   common.Version,
+		Nonce:     nonce,
+		Type:      AliasTransferType,
+		To:        to,
+		Amount:    *(*common.Big)(new(big.Int)),
+		GasPrice:  *(*common.Big)(gasPrice),
+		GasLimit:  *(*common.Big)(gasLimit),
+		Timestamp: time.Now().Unix(),
+		Data:      []byte(alias),
+	}
+	return &Transaction{Data: data}
+}
+
+//Free the alias of the sender
+func NewAliasReleaseTransaction(alias string, gasPrice, gasLimit *big.Int, nonce uint64) *Transaction {
+	data := TransactionData{
+		Version:   common.Version,
+		Nonce:     nonce,
+		Type:      AliasReleaseType,
+		Amount:    *(*common.Big)(new(big.Int)),
+		GasPrice:  *(*common.Big)(gasPrice),
+		GasLimit:  *(*common.Big)(gasLimit),
+		Timestamp: time.Now().Unix(),
+		Data:      []byte(alias),
+	}
+	return &Transaction{Data: data}
+}
+
 func NewVoteTransaction(to crypto.CommonAddress, amount, gasPrice, gasLimit *big.Int, nonce uint64) *Transaction {
 	data := TransactionData{
 		Version:   common.Version,
@@ -0,0 +1,105 @@
+package types
+
+import (
+	"bytes"
+	"container/heap"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+)
+
+//priceHeads is a heap of the next executable transaction of every account, the highest gas price on top.
+//Transactions paying the same price are ordered by hash so that every producer picks the same one.
+type priceHeads []txHead
+
+type txHead struct {
+	from crypto.CommonAddress
+	tx   *Transaction
+}
+
+func (h priceHeads) Len() int      { return len(h) }
+func (h priceHeads) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h priceHeads) Less(i, j int) bool {
+	switch h[i].tx.Data.GasPrice.ToInt().Cmp(h[j].tx.Data.GasPrice.ToInt()) {
+	case 1:
+		return true
+	case -1:
+		return false
+	}
+	return bytes.Compare(h[i].tx.TxHash()[:], h[j].tx.TxHash()[:]) < 0
+}
+
+func (h *priceHeads) Push(x interface{}) {
+	*h = append(*h, x.(txHead))
+}
+
+func (h *priceHeads) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
+
+//TransactionsByPriceAndNonce returns the transactions of many accounts by gas price while keeping the nonce
+//order of every account, only the transaction with the lowest nonce of an account competes at a time
+type TransactionsByPriceAndNonce struct {
+	txs   map[crypto.CommonAddress][]*Transaction //remaining transactions of every account by nonce
+	heads priceHeads
+}
+
+//NewTransactionsByPriceAndNonce takes the nonce sorted transactions of every account, txs is consumed
+func NewTransactionsByPriceAndNonce(txs map[crypto.CommonAddress][]*Transaction) *TransactionsByPriceAndNonce {
+	heads := make(priceHeads, 0, len(txs))
+	for from, accountTxs := range txs {
+		if len(accountTxs) == 0 {
+			delete(txs, from)
+			continue
+		}
+		heads = append(heads, txHead{from, accountTxs[0]})
+		txs[from] = accountTxs[1:]
+	}
+	heap.Init(&heads)
+	return &TransactionsByPriceAndNonce{txs: txs, heads: heads}
+}
+
+//Peek returns the best priced transaction, nil once all are taken
+func (t *TransactionsByPriceAndNonce) Peek() *Transaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0].tx
+}
+
+//Shift replaces the best priced transaction by the next transaction of its account
+func (t *TransactionsByPriceAndNonce) Shift() {
+	from := t.heads[0].from
+	if accountTxs := t.txs[from]; len(accountTxs) > 0 {
+		t.heads[0].tx, t.txs[from] = accountTxs[0], accountTxs[1:]
+		heap.Fix(&t.heads, 0)
+		return
+	}
+	heap.Pop(&t.heads)
+}
+
+//Pop drops the best priced transaction and the following ones of its account, which cannot be executed without it
+func (t *TransactionsByPriceAndNonce) Pop() {
+	heap.Pop(&t.heads)
+}
+
+//Pack takes the best priced transactions whose gas limits fit in gasLimit together. An account whose next
+//transaction does not fit in the gas left is skipped.
+func (t *TransactionsByPriceAndNonce) Pack(gasLimit uint64) []*Transaction {
+	var txs []*Transaction
+	for tx := t.Peek(); tx != nil && gasLimit >= params.TxGas; tx = t.Peek() {
+		if tx.Gas() > gasLimit {
+			t.Pop()
+			continue
+		}
+		gasLimit -= tx.Gas()
+		txs = append(txs, tx)
+		t.Shift()
+	}
+	return txs
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+)
+
+func newPricedTxs(from byte, prices ...int64) []*Transaction {
+	txs := make([]*Transaction, len(prices))
+	for i, price := range prices {
+		txs[i] = NewTransaction(crypto.CommonAddress{from}, big.NewInt(1), big.NewInt(price), big.NewInt(30000), uint64(i))
+	}
+	return txs
+}
+
+func TestTransactionsByPriceAndNonce(t *testing.T) {
+	a, b, c := newPricedTxs(1, 5, 1, 9), newPricedTxs(2, 3, 3), newPricedTxs(3, 5)
+	expect := []*Transaction{a[0], c[0], b[0], b[1], a[1], a[2]}
+	if a[0].TxHash().String() > c[0].TxHash().String() {
+		expect[0], expect[1] = c[0], a[0]
+	}
+	for round := 0; round < 10; round++ {
+		txs := NewTransactionsByPriceAndNonce(map[crypto.CommonAddress][]*Transaction{{1}: a, {2}: b, {3}: c})
+		for i, want := range expect {
+			got := txs.Peek()
+			if got != want {
+				t.Fatalf("round %d position %d: expect price %v nonce %d, got price %v nonce %d", round, i, want.GasPrice(), want.Nonce(), got.GasPrice(), got.Nonce())
+			}
+			txs.Shift()
+		}
+		if txs.Peek() != nil {
+			t.Fatal("expect all transactions taken")
+		}
+	}
+
+	//popping a transaction drops the rest of its account
+	txs := NewTransactionsByPriceAndNonce(map[crypto.CommonAddress][]*Transaction{{1}: a, {2}: b})
+	txs.Pop()
+	for _, want := range b {
+		if got := txs.Peek(); got != want {
+			t.Fatalf("expect nonce %d of the second account, got %v", want.Nonce(), got)
+		}
+		txs.Shift()
+	}
+	if txs.Peek() != nil {
+		t.Fatal("expect the first account dropped")
+	}
+
+	//an account whose next transaction does not fit is skipped, cheaper ones of other accounts still fit
+	large := NewTransaction(crypto.CommonAddress{4}, big.NewInt(1), big.NewInt(100), big.NewInt(50000), 0)
+	packed := NewTransactionsByPriceAndNonce(map[crypto.CommonAddress][]*Transaction{{3}: c, {4}: {large}}).Pack(40000)
+	if len(packed) != 1 || packed[0] != c[0] {
+		t.Fatalf("expect only the transaction which fits, got %d", len(packed))
+	}
+}
+
+//newPendingTxs returns 50000 transactions of 2500 accounts, as many as the pool keeps pending
+func newPendingTxs() map[crypto.CommonAddress][]*Transaction {
+	random := rand.New(rand.NewSource(1))
+	pending := map[crypto.CommonAddress][]*Transaction{}
+	for i := 0; i < 2500; i++ {
+		addr := crypto.BigToAddress(big.NewInt(int64(i + 1)))
+		txs := make([]*Transaction, 20)
+		for nonce := range txs {
+			price := big.NewInt(random.Int63n(1000) + 1)
+			txs[nonce] = NewTransaction(addr, big.NewInt(1), price, big.NewInt(30000), uint64(nonce))
+			txs[nonce].TxHash()
+		}
+		pending[addr] = txs
+	}
+	return pending
+}
+
+func benchmarkPacking(b *testing.B, gasLimit uint64) {
+	pending := newPendingTxs()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		accounts := make(map[crypto.CommonAddress][]*Transaction, len(pending))
+		for addr, txs := range pending {
+			accounts[addr] = txs
+		}
+		b.StartTimer()
+
+		packed := NewTransactionsByPriceAndNonce(accounts).Pack(gasLimit)
+		b.ReportMetric(float64(len(packed)), "txs/op")
+	}
+}
+
+//BenchmarkPackBlock fills a block of the maximum gas limit out of 50000 pending transactions
+func BenchmarkPackBlock(b *testing.B) {
+	benchmarkPacking(b, params.MaxGasLimit)
+}
+
+//BenchmarkPackAll orders all of 50000 pending transactions
+func BenchmarkPackAll(b *testing.B) {
+	benchmarkPacking(b, ^uint64(0))
+}
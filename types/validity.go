@@ -0,0 +1,96 @@
+package types
+
+import (
+	"sync/atomic"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/binary"
+)
+
+//ValidityMark is the first byte of the signature of a sender which limits the blocks its transaction may be
+//included in, followed by the encoded ValiditySig. The envelope of a sponsor goes around it.
+const ValidityMark = byte(3)
+
+//validityPrefix separates the message signed with a validity window from the hash of the transaction
+var validityPrefix = []byte("DREP validity:")
+
+//ValidityWindow bounds the blocks a transaction may be included in, a zero bound is no bound
+type ValidityWindow struct {
+	UntilHeight uint64 `json:"untilHeight"` //the last height which may include the transaction
+	UntilTime   int64  `json:"untilTime"`   //the last block timestamp which may include the transaction, unix seconds
+}
+
+//Expired reports whether the block at height with timestamp is past the window
+func (window *ValidityWindow) Expired(height uint64, timestamp int64) bool {
+	if window.UntilHeight != 0 && height > window.UntilHeight {
+		return true
+	}
+	return window.UntilTime != 0 && timestamp > window.UntilTime
+}
+
+//ValiditySig is the signature of a sender over the transaction and its window, see SigHash
+type ValiditySig struct {
+	Window ValidityWindow
+	Sig    []byte
+}
+
+func decodeValiditySig(sig []byte) (*ValiditySig, error) {
+	validitySig := &ValiditySig{}
+	err := binary.Unmarshal(sig[1:], validitySig)
+	if err != nil {
+		return nil, err
+	}
+	return validitySig, nil
+}
+
+func isValiditySig(sig []byte) bool {
+	return len(sig) > 0 && sig[0] == ValidityMark
+}
+
+//ValidityWindow returns the window of tx, nil if it may be included at any height
+func (tx *Transaction) ValidityWindow() *ValidityWindow {
+	sig := tx.unsponsoredSig()
+	if !isValiditySig(sig) {
+		return nil
+	}
+	validitySig, err := decodeValiditySig(sig)
+	if err != nil {
+		return nil
+	}
+	return &validitySig.Window
+}
+
+//SetValidityWindow limits the blocks tx may be included in. The signatures of the sender and of the sponsor are
+//removed as they no longer match, both sign SigHash afterwards.
+func (tx *Transaction) SetValidityWindow(window *ValidityWindow) error {
+	if window == nil {
+		tx.Sig = nil
+		tx.txHash = atomic.Value{}
+		return nil
+	}
+	data, err := binary.Marshal(&ValiditySig{Window: *window})
+	if err != nil {
+		return err
+	}
+	tx.Sig = append([]byte{ValidityMark}, data...)
+	tx.txHash = atomic.Value{}
+	return nil
+}
+
+//hash returns the hash of the data of tx or, if tx has a validity window, the hash of both. Transactions which
+//differ in their window only are different transactions.
+func (tx *Transaction) hash() []byte {
+	dataHash := sha3.Keccak256(tx.AsSignMessage())
+	window := tx.ValidityWindow()
+	if window == nil {
+		return dataHash
+	}
+	data, _ := binary.Marshal(window)
+	return crypto.Keccak256Hash(validityPrefix, dataHash, data).Bytes()
+}
+
+//SigHash returns the message the sender signs, the hash of the transaction which commits to its validity window
+func (tx *Transaction) SigHash() []byte {
+	return tx.TxHash().Bytes()
+}
@@ -1,22 +1,35 @@
 package types
 
-import "github.com/drep-project/DREP-Chain/crypto"
+import (
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+)
 
 //本模块的消息只能在调用本模块（chain及对应的子模块）的函数中使用
 const (
-	MsgTypeBlockReq     = 1 //同步块请求
-	MsgTypeBlockResp    = 2 //同步块回复
-	MsgTypeBlock        = 3 //新块通知
-	MsgTypeTransaction  = 4 //广播交易
-	MsgTypePeerState    = 5 //Peer状态回复/或者状态通知
-	MsgTypePeerStateReq = 6 //peer状态请求
-	MsgTypeHeaderReq    = 7 //请求区块头
-	MsgTypeHeaderRsp    = 8 //请求区块头回复
+	MsgTypeBlockReq     = 1  //同步块请求
+	MsgTypeBlockResp    = 2  //同步块回复
+	MsgTypeBlock        = 3  //新块通知
+	MsgTypeTransaction  = 4  //广播交易
+	MsgTypePeerState    = 5  //Peer状态回复/或者状态通知
+	MsgTypePeerStateReq = 6  //peer状态请求
+	MsgTypeHeaderReq    = 7  //请求区块头
+	MsgTypeHeaderRsp    = 8  //请求区块头回复
+	MsgTypeNodeDataReq  = 9  //请求状态树节点
+	MsgTypeNodeDataRsp  = 10 //请求状态树节点回复
+	MsgTypeStatus       = 11 //握手状态
+	MsgTypeTxHashes     = 12 //交易哈希通知
+	MsgTypeGetPooledTxs = 13 //请求交易池中的交易
+	MsgTypePooledTxs    = 14 //请求交易池中的交易回复
 
 	MaxMsgSize = 20 << 20 //每个消息最大大小20MB
 )
 
-var NumberOfMsg = 9 //本模块定义的消息个数
+var NumberOfMsg = 15 //本模块定义的消息个数
+
+//BlockProtocolVersion is announced in the status handshake, peers speaking another version are disconnected.
+//Version 2 relays transactions by hash announcements.
+const BlockProtocolVersion = 2
 
 type Transactions []Transaction
 
@@ -39,6 +52,47 @@ type BlockResp struct {
 	Blocks []*Block
 }
 
+//NodeDataReq requests state trie nodes by their hash
+type NodeDataReq struct {
+	ReqId  uint64 //echoed by the rsp, tells a late rsp to an earlier req apart
+	Hashes []crypto.Hash
+}
+
+//NodeDataRsp carries the requested trie nodes in the order of the request, a node the peer does not have
+//is left empty and the list may be cut short to bound the message size. The receiver checks every node
+//against the hash it requested.
+type NodeDataRsp struct {
+	ReqId uint64
+	Nodes [][]byte
+}
+
+//Status is the first message on a block protocol connection, both sides send theirs before reading the other one
+type Status struct {
+	ProtocolVersion uint32
+	ChainId         ChainIdType
+	Genesis         crypto.Hash
+	BestHash        crypto.Hash
+	Height          uint64
+	ForkID          params.ForkID
+}
+
+//TxHashes announces transactions which entered the pool of the sender, the receiver requests the ones it
+//misses with GetPooledTxs
+type TxHashes struct {
+	Hashes []crypto.Hash
+}
+
+//GetPooledTxs requests transactions from the pool of the peer by their hash
+type GetPooledTxs struct {
+	Hashes []crypto.Hash
+}
+
+//PooledTxs carries the requested transactions the peer still has in its pool, the list may be cut short to
+//bound the message size
+type PooledTxs struct {
+	Txs []*Transaction
+}
+
 type PeerState struct {
 	Height uint64
 }